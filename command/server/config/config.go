@@ -55,6 +55,19 @@ type Network struct {
 	MaxPeers         int64  `json:"max_peers,omitempty" yaml:"max_peers,omitempty"`
 	MaxOutboundPeers int64  `json:"max_outbound_peers,omitempty" yaml:"max_outbound_peers,omitempty"`
 	MaxInboundPeers  int64  `json:"max_inbound_peers,omitempty" yaml:"max_inbound_peers,omitempty"`
+
+	MaxStreamsPerConn         int `json:"max_streams_per_conn" yaml:"max_streams_per_conn"`
+	MaxTotalStreams           int `json:"max_total_streams" yaml:"max_total_streams"`
+	MaxOutboundStreamsPerPeer int `json:"max_outbound_streams_per_peer" yaml:"max_outbound_streams_per_peer"`
+	MaxTopics                 int `json:"max_topics" yaml:"max_topics"`
+
+	RestoreLastPeers bool          `json:"restore_last_peers" yaml:"restore_last_peers"`
+	DialStagger      time.Duration `json:"dial_stagger" yaml:"dial_stagger"`
+	DialBackoffBase  time.Duration `json:"dial_backoff_base" yaml:"dial_backoff_base"`
+	DialBackoffMax   time.Duration `json:"dial_backoff_max" yaml:"dial_backoff_max"`
+
+	DialFallbackInterval     time.Duration `json:"dial_fallback_interval" yaml:"dial_fallback_interval"`
+	DialFallbackSlowInterval time.Duration `json:"dial_fallback_slow_interval" yaml:"dial_fallback_slow_interval"`
 }
 
 // TxPool defines the TxPool configuration params
@@ -115,6 +128,17 @@ func DefaultConfig() *Config {
 				defaultNetworkConfig.Addr.IP,
 				defaultNetworkConfig.Addr.Port,
 			),
+			MaxStreamsPerConn: defaultNetworkConfig.MaxStreamsPerConn,
+			MaxTotalStreams:   defaultNetworkConfig.MaxTotalStreams,
+
+			MaxOutboundStreamsPerPeer: defaultNetworkConfig.MaxOutboundStreamsPerPeer,
+			MaxTopics:                 defaultNetworkConfig.MaxTopics,
+			RestoreLastPeers:          defaultNetworkConfig.RestoreLastPeers,
+			DialStagger:               defaultNetworkConfig.DialStagger,
+			DialBackoffBase:           defaultNetworkConfig.DialBackoffBase,
+			DialBackoffMax:            defaultNetworkConfig.DialBackoffMax,
+			DialFallbackInterval:      defaultNetworkConfig.DialFallbackInterval,
+			DialFallbackSlowInterval:  defaultNetworkConfig.DialFallbackSlowInterval,
 		},
 		Telemetry:  &Telemetry{},
 		ShouldSeal: true,
@@ -45,6 +45,18 @@ const (
 	webSocketReadLimitFlag      = "websocket-read-limit"
 
 	relayerTrackerPollIntervalFlag = "relayer-poll-interval"
+
+	maxStreamsPerConnFlag = "max-streams-per-conn"
+	maxTotalStreamsFlag   = "max-total-streams"
+
+	maxOutboundStreamsPerPeerFlag = "max-outbound-streams-per-peer"
+	maxTopicsFlag                 = "max-topics"
+	restoreLastPeersFlag          = "restore-last-peers"
+	dialStaggerFlag               = "dial-stagger"
+	dialBackoffBaseFlag           = "dial-backoff-base"
+	dialBackoffMaxFlag            = "dial-backoff-max"
+	dialFallbackIntervalFlag      = "dial-fallback-interval"
+	dialFallbackSlowIntervalFlag  = "dial-fallback-slow-interval"
 )
 
 // Flags that are deprecated, but need to be preserved for
@@ -175,6 +187,18 @@ func (p *serverParams) generateConfig() *server.Config {
 			MaxInboundPeers:  p.rawConfig.Network.MaxInboundPeers,
 			MaxOutboundPeers: p.rawConfig.Network.MaxOutboundPeers,
 			Chain:            p.genesisConfig,
+
+			MaxStreamsPerConn: p.rawConfig.Network.MaxStreamsPerConn,
+			MaxTotalStreams:   p.rawConfig.Network.MaxTotalStreams,
+
+			MaxOutboundStreamsPerPeer: p.rawConfig.Network.MaxOutboundStreamsPerPeer,
+			MaxTopics:                 p.rawConfig.Network.MaxTopics,
+			RestoreLastPeers:          p.rawConfig.Network.RestoreLastPeers,
+			DialStagger:               p.rawConfig.Network.DialStagger,
+			DialBackoffBase:           p.rawConfig.Network.DialBackoffBase,
+			DialBackoffMax:            p.rawConfig.Network.DialBackoffMax,
+			DialFallbackInterval:      p.rawConfig.Network.DialFallbackInterval,
+			DialFallbackSlowInterval:  p.rawConfig.Network.DialFallbackSlowInterval,
 		},
 		DataDir:            p.rawConfig.DataDir,
 		Seal:               p.rawConfig.ShouldSeal,
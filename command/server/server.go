@@ -7,6 +7,7 @@ import (
 	"github.com/0xPolygon/polygon-edge/command/helper"
 	"github.com/0xPolygon/polygon-edge/command/server/config"
 	"github.com/0xPolygon/polygon-edge/command/server/export"
+	"github.com/0xPolygon/polygon-edge/network"
 	"github.com/0xPolygon/polygon-edge/server"
 	"github.com/spf13/cobra"
 )
@@ -161,6 +162,82 @@ func setFlags(cmd *cobra.Command) {
 	cmd.Flag(maxOutboundPeersFlag).DefValue = fmt.Sprintf("%d", defaultConfig.Network.MaxOutboundPeers)
 	cmd.MarkFlagsMutuallyExclusive(maxPeersFlag, maxOutboundPeersFlag)
 
+	cmd.Flags().IntVar(
+		&params.rawConfig.Network.MaxStreamsPerConn,
+		maxStreamsPerConnFlag,
+		defaultConfig.Network.MaxStreamsPerConn,
+		"the maximum number of streams open on a single peer connection, unlimited if 0",
+	)
+
+	cmd.Flags().IntVar(
+		&params.rawConfig.Network.MaxTotalStreams,
+		maxTotalStreamsFlag,
+		defaultConfig.Network.MaxTotalStreams,
+		"the maximum number of streams open node-wide, unlimited if 0",
+	)
+
+	cmd.Flags().IntVar(
+		&params.rawConfig.Network.MaxOutboundStreamsPerPeer,
+		maxOutboundStreamsPerPeerFlag,
+		defaultConfig.Network.MaxOutboundStreamsPerPeer,
+		"the maximum number of streams opened to a single peer at the same time, unlimited if 0",
+	)
+
+	cmd.Flags().IntVar(
+		&params.rawConfig.Network.MaxTopics,
+		maxTopicsFlag,
+		defaultConfig.Network.MaxTopics,
+		"the maximum number of gossip topics joined at the same time, unlimited if 0",
+	)
+
+	cmd.Flags().BoolVar(
+		&params.rawConfig.Network.RestoreLastPeers,
+		restoreLastPeersFlag,
+		defaultConfig.Network.RestoreLastPeers,
+		"save the peers connected at shutdown to the data directory, and redial them first on the next start",
+	)
+
+	cmd.Flags().DurationVar(
+		&params.rawConfig.Network.DialStagger,
+		dialStaggerFlag,
+		defaultConfig.Network.DialStagger,
+		"the time after which a slow peer dial is raced with the next addresses of the peer, sequential if 0",
+	)
+
+	cmd.Flags().DurationVar(
+		&params.rawConfig.Network.DialBackoffBase,
+		dialBackoffBaseFlag,
+		defaultConfig.Network.DialBackoffBase,
+		"the backoff after a failed dial to a peer, doubled on every consecutive failure, disabled if 0",
+	)
+
+	cmd.Flags().DurationVar(
+		&params.rawConfig.Network.DialBackoffMax,
+		dialBackoffMaxFlag,
+		defaultConfig.Network.DialBackoffMax,
+		fmt.Sprintf(
+			"the maximum backoff after consecutive failed dials to a peer (default %s)",
+			network.DefaultDialBackoffMax,
+		),
+	)
+
+	cmd.Flags().DurationVar(
+		&params.rawConfig.Network.DialFallbackInterval,
+		dialFallbackIntervalFlag,
+		defaultConfig.Network.DialFallbackInterval,
+		"the interval of the rounds refilling an empty dial queue below the outbound peer target, disabled if 0",
+	)
+
+	cmd.Flags().DurationVar(
+		&params.rawConfig.Network.DialFallbackSlowInterval,
+		dialFallbackSlowIntervalFlag,
+		defaultConfig.Network.DialFallbackSlowInterval,
+		fmt.Sprintf(
+			"the interval the dial fallback rounds settle into once the first ones failed (default %s)",
+			network.DefaultDialFallbackSlowInterval,
+		),
+	)
+
 	cmd.Flags().Uint64Var(
 		&params.rawConfig.TxPool.PriceLimit,
 		priceLimitFlag,
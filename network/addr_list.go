@@ -0,0 +1,62 @@
+package network
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/multiformats/go-multiaddr"
+)
+
+// maxEncodedAddrSize is the maximum size of a single encoded address in an address list
+const maxEncodedAddrSize = 256
+
+// encodeAddrList encodes the addresses as a list of length-prefixed addresses
+func encodeAddrList(addrs []multiaddr.Multiaddr) []byte {
+	encoded := make([]byte, 0)
+
+	for _, addr := range addrs {
+		encoded = binary.AppendUvarint(encoded, uint64(len(addr.Bytes())))
+		encoded = append(encoded, addr.Bytes()...)
+	}
+
+	return encoded
+}
+
+// readAddrList reads a list of length-prefixed addresses, up to the maximum number of addresses.
+// Oversized and malformed addresses fail the whole list
+func readAddrList(stream io.Reader, maxAddrs int) ([]multiaddr.Multiaddr, error) {
+	reader := bufio.NewReader(io.LimitReader(stream, int64(maxAddrs*(maxEncodedAddrSize+binary.MaxVarintLen64))))
+	addrs := make([]multiaddr.Multiaddr, 0)
+
+	for len(addrs) < maxAddrs {
+		size, err := binary.ReadUvarint(reader)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		if size > maxEncodedAddrSize {
+			return nil, fmt.Errorf("address too large, %d bytes", size)
+		}
+
+		raw := make([]byte, size)
+		if _, err := io.ReadFull(reader, raw); err != nil {
+			return nil, err
+		}
+
+		addr, err := multiaddr.NewMultiaddrBytes(raw)
+		if err != nil {
+			return nil, err
+		}
+
+		addrs = append(addrs, addr)
+	}
+
+	return addrs, nil
+}
@@ -0,0 +1,95 @@
+package network
+
+import (
+	"github.com/0xPolygon/polygon-edge/network/common"
+	"github.com/armon/go-metrics"
+	"github.com/libp2p/go-libp2p/core/event"
+	"github.com/libp2p/go-libp2p/core/peer"
+)
+
+// reannouncePeerCount is the number of the closest routing table peers
+// the node announces its new addresses to
+const reannouncePeerCount = 8
+
+// watchLocalAddrs re-announces the node addresses to the closest routing table peers
+// whenever they change (e.g. NAT remap, new listen address, relay acquired),
+// so the peers holding the old addresses can reach the node before the next routing table refresh.
+// The connected peers learn the new addresses from the identify push of libp2p
+func (s *Server) watchLocalAddrs() error {
+	sub, err := s.host.EventBus().Subscribe(new(event.EvtLocalAddressesUpdated))
+	if err != nil {
+		return err
+	}
+
+	done := s.health.subscriptions.start()
+
+	go func() {
+		defer done()
+		defer sub.Close()
+
+		for {
+			select {
+			case <-s.closeCh:
+				return
+			case evnt, ok := <-sub.Out():
+				if !ok {
+					return
+				}
+
+				updated, ok := evnt.(event.EvtLocalAddressesUpdated)
+				if !ok || !addrsChanged(updated) {
+					continue
+				}
+
+				s.refreshAddrs()
+				s.reannounceAddrs(s.discovery.NearestPeers(s.host.ID(), reannouncePeerCount))
+			}
+		}
+	}()
+
+	return nil
+}
+
+// addrsChanged checks if the local addresses update added or removed any address
+func addrsChanged(updated event.EvtLocalAddressesUpdated) bool {
+	if !updated.Diffs {
+		return true
+	}
+
+	if len(updated.Removed) > 0 {
+		return true
+	}
+
+	for _, addr := range updated.Current {
+		if addr.Action == event.Added {
+			return true
+		}
+	}
+
+	return false
+}
+
+// reannounceAddrs dials the routing table peers the node is not connected to,
+// so they learn the new node addresses from the identify exchange.
+// The dials go through the dial queue, so the connection limits are respected
+func (s *Server) reannounceAddrs(routingPeers []peer.ID) {
+	queued := 0
+
+	for _, peerID := range routingPeers {
+		if s.IsConnected(peerID) {
+			continue
+		}
+
+		if s.addToDialQueue(s.GetPeerInfo(peerID), common.PriorityRandomDial, s.getPeerSource(peerID)) {
+			queued++
+		}
+	}
+
+	if queued == 0 {
+		return
+	}
+
+	s.logger.Info("Node addresses changed, re-announcing them", "addrs", s.AddrInfo().Addrs, "peers", queued)
+
+	metrics.IncrCounter([]string{networkMetrics, "addr_reannouncements"}, float32(queued))
+}
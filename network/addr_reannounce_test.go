@@ -0,0 +1,124 @@
+package network
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/libp2p/go-libp2p/core/event"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/multiformats/go-multiaddr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWatchLocalAddrs(t *testing.T) {
+	servers, createErr := createServers(2, nil)
+	require.NoError(t, createErr)
+
+	t.Cleanup(func() {
+		closeTestServers(t, servers)
+	})
+
+	server, routingPeer := servers[0], servers[1]
+
+	require.NoError(t, JoinAndWait(server, routingPeer, DefaultBufferTimeout, DefaultJoinTimeout))
+
+	oldAddrs := server.AddrInfo().Addrs
+
+	// The node starts listening on a new address
+	require.NoError(t, server.host.Network().Listen(multiaddr.StringCast("/ip4/127.0.0.1/tcp/0")))
+
+	hasNewAddr := func(addrs []multiaddr.Multiaddr) bool {
+		for _, addr := range addrs {
+			if !multiaddr.Contains(oldAddrs, addr) {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	// The advertised addresses are refreshed, and the connected peer learns them
+	assert.Eventually(t, func() bool {
+		return hasNewAddr(server.AddrInfo().Addrs)
+	}, 10*time.Second, 50*time.Millisecond)
+
+	assert.Eventually(t, func() bool {
+		return hasNewAddr(routingPeer.host.Peerstore().Addrs(server.host.ID()))
+	}, 10*time.Second, 50*time.Millisecond)
+}
+
+func TestReannounceAddrs_UnconnectedPeers(t *testing.T) {
+	servers, createErr := createServers(3, nil)
+	require.NoError(t, createErr)
+
+	t.Cleanup(func() {
+		closeTestServers(t, servers)
+	})
+
+	server, connected, unconnected := servers[0], servers[1], servers[2]
+
+	require.NoError(t, JoinAndWait(server, connected, DefaultBufferTimeout, DefaultJoinTimeout))
+
+	server.AddToPeerStore(unconnected.AddrInfo())
+
+	// Only the unconnected peer is dialed, and learns the node addresses on connect
+	server.reannounceAddrs([]peer.ID{connected.host.ID(), unconnected.host.ID()})
+
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultJoinTimeout)
+	defer cancel()
+
+	_, err := WaitUntilPeerConnectsTo(ctx, server, unconnected.host.ID())
+	require.NoError(t, err)
+
+	assert.Eventually(t, func() bool {
+		return len(unconnected.host.Peerstore().Addrs(server.host.ID())) > 0
+	}, 5*time.Second, 50*time.Millisecond)
+}
+
+func TestAddrsChanged(t *testing.T) {
+	addr := multiaddr.StringCast("/ip4/10.1.2.3/tcp/1478")
+
+	testTable := []struct {
+		name    string
+		updated event.EvtLocalAddressesUpdated
+		changed bool
+	}{
+		{
+			"no diffs reported",
+			event.EvtLocalAddressesUpdated{Diffs: false},
+			true,
+		},
+		{
+			"address added",
+			event.EvtLocalAddressesUpdated{
+				Diffs:   true,
+				Current: []event.UpdatedAddress{{Address: addr, Action: event.Added}},
+			},
+			true,
+		},
+		{
+			"address removed",
+			event.EvtLocalAddressesUpdated{
+				Diffs:   true,
+				Removed: []event.UpdatedAddress{{Address: addr, Action: event.Removed}},
+			},
+			true,
+		},
+		{
+			"addresses maintained",
+			event.EvtLocalAddressesUpdated{
+				Diffs:   true,
+				Current: []event.UpdatedAddress{{Address: addr, Action: event.Maintained}},
+			},
+			false,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			assert.Equal(t, testCase.changed, addrsChanged(testCase.updated))
+		})
+	}
+}
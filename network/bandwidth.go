@@ -0,0 +1,217 @@
+package network
+
+import (
+	"context"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/libp2p/go-libp2p/core/host"
+	"github.com/libp2p/go-libp2p/core/network"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/libp2p/go-libp2p/core/protocol"
+	"golang.org/x/time/rate"
+)
+
+// bandwidthBurstWindow is the time window worth of traffic
+// that can be sent in a single burst by the bandwidth limiter
+const bandwidthBurstWindow = 100 * time.Millisecond
+
+// bandwidthLimiter is a token bucket shared by all outbound streams,
+// which keeps the aggregate outbound rate below the configured limit
+type bandwidthLimiter struct {
+	limiter *rate.Limiter
+}
+
+// newBandwidthLimiter creates a new bandwidth limiter for the specified rate (bytes/sec).
+// Returns nil if the rate is not limited
+func newBandwidthLimiter(bytesPerSec int64) *bandwidthLimiter {
+	if bytesPerSec <= 0 {
+		return nil
+	}
+
+	burst := int(bytesPerSec * int64(bandwidthBurstWindow) / int64(time.Second))
+	if burst < 1 {
+		burst = 1
+	}
+
+	return &bandwidthLimiter{
+		limiter: rate.NewLimiter(rate.Limit(bytesPerSec), burst),
+	}
+}
+
+// waitN blocks until n bytes can be sent, returning the number of bytes
+// allowed at once, which is never more than the bucket size [BLOCKING]
+func (bl *bandwidthLimiter) waitN(ctx context.Context, n int) (int, error) {
+	if burst := bl.limiter.Burst(); n > burst {
+		n = burst
+	}
+
+	if err := bl.limiter.WaitN(ctx, n); err != nil {
+		return 0, err
+	}
+
+	return n, nil
+}
+
+// throttledStream is a stream whose writes are throttled
+// by the shared bandwidth limiter
+type throttledStream struct {
+	network.Stream
+
+	limiter *bandwidthLimiter
+
+	ctx    context.Context    // canceled once the stream is closed or reset, aborting the pending waits
+	cancel context.CancelFunc // cancels the stream context
+
+	deadlineLock  sync.Mutex
+	writeDeadline time.Time // the write deadline of the stream, the waits are bounded by it as well
+}
+
+// Write writes the data to the stream in chunks, waiting for the bandwidth limiter to allow each chunk.
+// The waits are bounded by the write deadline of the stream, and aborted once the stream is closed or reset
+func (ts *throttledStream) Write(p []byte) (int, error) {
+	ctx, cancel := ts.writeContext()
+	defer cancel()
+
+	written := 0
+
+	for written < len(p) {
+		allowed, err := ts.limiter.waitN(ctx, len(p)-written)
+		if err != nil {
+			return written, ts.waitError()
+		}
+
+		n, err := ts.Stream.Write(p[written : written+allowed])
+		written += n
+
+		if err != nil {
+			return written, err
+		}
+	}
+
+	return written, nil
+}
+
+// writeContext returns the context of the bandwidth limiter waits, bounded by the write deadline
+func (ts *throttledStream) writeContext() (context.Context, context.CancelFunc) {
+	ts.deadlineLock.Lock()
+	deadline := ts.writeDeadline
+	ts.deadlineLock.Unlock()
+
+	if deadline.IsZero() {
+		return context.WithCancel(ts.ctx)
+	}
+
+	return context.WithDeadline(ts.ctx, deadline)
+}
+
+// waitError returns the error of a write whose bandwidth limiter wait failed
+func (ts *throttledStream) waitError() error {
+	if ts.ctx.Err() != nil {
+		return io.ErrClosedPipe
+	}
+
+	// The wait is refused upfront if it can't complete before the deadline
+	return os.ErrDeadlineExceeded
+}
+
+// SetDeadline sets the read and write deadlines of the stream
+func (ts *throttledStream) SetDeadline(t time.Time) error {
+	ts.setWriteDeadline(t)
+
+	return ts.Stream.SetDeadline(t)
+}
+
+// SetWriteDeadline sets the write deadline of the stream
+func (ts *throttledStream) SetWriteDeadline(t time.Time) error {
+	ts.setWriteDeadline(t)
+
+	return ts.Stream.SetWriteDeadline(t)
+}
+
+// setWriteDeadline records the write deadline, which bounds the bandwidth limiter waits
+func (ts *throttledStream) setWriteDeadline(t time.Time) {
+	ts.deadlineLock.Lock()
+	defer ts.deadlineLock.Unlock()
+
+	ts.writeDeadline = t
+}
+
+// Close closes the stream, aborting the pending writes
+func (ts *throttledStream) Close() error {
+	ts.cancel()
+
+	return ts.Stream.Close()
+}
+
+// Reset resets the stream, aborting the pending writes
+func (ts *throttledStream) Reset() error {
+	ts.cancel()
+
+	return ts.Stream.Reset()
+}
+
+// throttledHost is a libp2p host which throttles all outbound
+// and inbound streams, including the ones used by pubsub
+type throttledHost struct {
+	host.Host
+
+	limiter *bandwidthLimiter
+}
+
+// newThrottledHost wraps the host so all of its streams share the bandwidth limiter
+func newThrottledHost(h host.Host, limiter *bandwidthLimiter) host.Host {
+	return &throttledHost{
+		Host:    h,
+		limiter: limiter,
+	}
+}
+
+// NewStream opens a new throttled stream to the peer
+func (th *throttledHost) NewStream(
+	ctx context.Context,
+	p peer.ID,
+	pids ...protocol.ID,
+) (network.Stream, error) {
+	stream, err := th.Host.NewStream(ctx, p, pids...)
+	if err != nil {
+		return nil, err
+	}
+
+	return th.wrap(stream), nil
+}
+
+// SetStreamHandler sets the protocol handler, which is handed throttled streams
+func (th *throttledHost) SetStreamHandler(pid protocol.ID, handler network.StreamHandler) {
+	th.Host.SetStreamHandler(pid, th.wrapHandler(handler))
+}
+
+// SetStreamHandlerMatch sets the protocol handler, which is handed throttled streams
+func (th *throttledHost) SetStreamHandlerMatch(
+	pid protocol.ID,
+	match func(protocol.ID) bool,
+	handler network.StreamHandler,
+) {
+	th.Host.SetStreamHandlerMatch(pid, match, th.wrapHandler(handler))
+}
+
+// wrapHandler wraps the handler so it operates on throttled streams
+func (th *throttledHost) wrapHandler(handler network.StreamHandler) network.StreamHandler {
+	return func(stream network.Stream) {
+		handler(th.wrap(stream))
+	}
+}
+
+// wrap wraps the stream so its writes are throttled
+func (th *throttledHost) wrap(stream network.Stream) network.Stream {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	return &throttledStream{
+		Stream:  stream,
+		limiter: th.limiter,
+		ctx:     ctx,
+		cancel:  cancel,
+	}
+}
@@ -0,0 +1,152 @@
+package network
+
+import (
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/libp2p/go-libp2p/core/network"
+	"github.com/libp2p/go-libp2p/core/protocol"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBandwidthLimiter_Disabled(t *testing.T) {
+	assert.Nil(t, newBandwidthLimiter(0))
+	assert.Nil(t, newBandwidthLimiter(-1))
+}
+
+func TestMaxOutboundBandwidth(t *testing.T) {
+	const (
+		sinkProto    = "/sink/0.1"
+		maxBandwidth = 64 * 1024
+		numReceivers = 3
+		bytesPerPeer = maxBandwidth
+	)
+
+	servers, createErr := createServers(numReceivers+1, map[int]*CreateServerParams{
+		0: {ConfigCallback: func(c *Config) {
+			c.NoDiscover = true
+			c.MaxOutboundBandwidth = maxBandwidth
+		}},
+		1: {ConfigCallback: func(c *Config) { c.NoDiscover = true }},
+		2: {ConfigCallback: func(c *Config) { c.NoDiscover = true }},
+		3: {ConfigCallback: func(c *Config) { c.NoDiscover = true }},
+	})
+	require.NoError(t, createErr)
+
+	t.Cleanup(func() {
+		closeTestServers(t, servers)
+	})
+
+	sender, receivers := servers[0], servers[1:]
+
+	for _, receiver := range receivers {
+		receiver.host.SetStreamHandler(protocol.ID(sinkProto), func(stream network.Stream) {
+			_, _ = io.Copy(io.Discard, stream)
+			_ = stream.Close()
+		})
+
+		require.NoError(t, JoinAndWait(sender, receiver, DefaultBufferTimeout, DefaultJoinTimeout))
+	}
+
+	var wg sync.WaitGroup
+
+	start := time.Now()
+
+	for _, receiver := range receivers {
+		stream, err := sender.NewStream(sinkProto, receiver.host.ID())
+		require.NoError(t, err)
+
+		wg.Add(1)
+
+		go func(stream network.Stream) {
+			defer wg.Done()
+
+			n, err := stream.Write(make([]byte, bytesPerPeer))
+			assert.NoError(t, err)
+			assert.Equal(t, bytesPerPeer, n)
+			assert.NoError(t, stream.Close())
+		}(stream)
+	}
+
+	wg.Wait()
+
+	elapsed := time.Since(start)
+	combinedRate := float64(numReceivers*bytesPerPeer) / elapsed.Seconds()
+
+	// The initial burst allows for a rate slightly above the limit
+	assert.LessOrEqual(t, combinedRate, maxBandwidth*1.1)
+	assert.GreaterOrEqual(t, combinedRate, maxBandwidth*0.5)
+}
+
+func TestThrottledStream_WriteWaitBounds(t *testing.T) {
+	const (
+		sinkProto    = "/sink/0.1"
+		maxBandwidth = 1024
+	)
+
+	servers, createErr := createServers(2, map[int]*CreateServerParams{
+		0: {ConfigCallback: func(c *Config) {
+			c.NoDiscover = true
+			c.MaxOutboundBandwidth = maxBandwidth
+		}},
+		1: {ConfigCallback: func(c *Config) { c.NoDiscover = true }},
+	})
+	require.NoError(t, createErr)
+
+	t.Cleanup(func() {
+		closeTestServers(t, servers)
+	})
+
+	sender, receiver := servers[0], servers[1]
+
+	receiver.host.SetStreamHandler(protocol.ID(sinkProto), func(stream network.Stream) {
+		_, _ = io.Copy(io.Discard, stream)
+		_ = stream.Close()
+	})
+
+	require.NoError(t, JoinAndWait(sender, receiver, DefaultBufferTimeout, DefaultJoinTimeout))
+
+	// A write which can't be sent before the deadline fails with it, instead of waiting on
+	deadlineStream, err := sender.NewStream(sinkProto, receiver.host.ID())
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		_ = deadlineStream.Reset()
+	})
+
+	require.NoError(t, deadlineStream.SetWriteDeadline(time.Now().Add(200*time.Millisecond)))
+
+	start := time.Now()
+	_, err = deadlineStream.Write(make([]byte, 16*maxBandwidth))
+
+	// Either the limiter wait or the underlying write hits the deadline
+	var timeoutErr interface{ Timeout() bool }
+
+	require.ErrorAs(t, err, &timeoutErr)
+	assert.True(t, timeoutErr.Timeout())
+	assert.Less(t, time.Since(start), time.Second)
+
+	// A write waiting for the bandwidth limiter is aborted once the stream is closed
+	closedStream, err := sender.NewStream(sinkProto, receiver.host.ID())
+	require.NoError(t, err)
+
+	writeErr := make(chan error, 1)
+
+	go func() {
+		_, err := closedStream.Write(make([]byte, 16*maxBandwidth))
+		writeErr <- err
+	}()
+
+	time.Sleep(200 * time.Millisecond)
+	require.NoError(t, closedStream.Close())
+
+	select {
+	case err := <-writeErr:
+		assert.Error(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("write not aborted by the close")
+	}
+}
@@ -0,0 +1,55 @@
+package network
+
+import (
+	"crypto/rand"
+	"math/big"
+
+	"github.com/0xPolygon/polygon-edge/network/common"
+	"github.com/libp2p/go-libp2p/core/peer"
+)
+
+// DefaultMinBootnodeConnections is the default number of bootnode connections kept alive
+const DefaultMinBootnodeConnections int64 = 1
+
+// minBootnodeConnections returns the configured bootnode connection target or the default,
+// capped at the number of set bootnodes
+func (s *Server) minBootnodeConnections() int64 {
+	target := s.config.MinBootnodeConnections
+	if target <= 0 {
+		target = DefaultMinBootnodeConnections
+	}
+
+	if count := int64(s.bootnodes.getBootnodeCount()); target > count {
+		target = count
+	}
+
+	return target
+}
+
+// maintainBootnodeConnections dials random unconnected bootnodes,
+// in case fewer bootnode connections than the minimum are active
+func (s *Server) maintainBootnodeConnections() {
+	missing := s.minBootnodeConnections() - s.bootnodes.getBootnodeConnCount()
+	if missing <= 0 {
+		return
+	}
+
+	candidates := make([]*peer.AddrInfo, 0, s.bootnodes.getBootnodeCount())
+
+	for _, bootnode := range s.bootnodes.getBootnodes() {
+		if !s.hasPeer(bootnode.ID) {
+			candidates = append(candidates, bootnode)
+		}
+	}
+
+	for ; missing > 0 && len(candidates) > 0; missing-- {
+		randNum, _ := rand.Int(rand.Reader, big.NewInt(int64(len(candidates))))
+		index := randNum.Int64()
+
+		s.addToDialQueue(candidates[index], common.PriorityRandomDial, PeerSourceBootnode)
+
+		// Each bootnode is dialed at most once per round
+		candidates[index] = candidates[len(candidates)-1]
+		candidates = candidates[:len(candidates)-1]
+	}
+}
@@ -0,0 +1,203 @@
+package network
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/0xPolygon/polygon-edge/network/common"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/libp2p/go-libp2p/core/peerstore"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMinBootnodeConnections(t *testing.T) {
+	const bootnodeCount = 3
+
+	params := make(map[int]*CreateServerParams, bootnodeCount)
+	for i := 0; i < bootnodeCount; i++ {
+		params[i] = &CreateServerParams{ConfigCallback: func(c *Config) { c.NoDiscover = true }}
+	}
+
+	bootnodes, createErr := createServers(bootnodeCount, params)
+	require.NoError(t, createErr)
+
+	t.Cleanup(func() {
+		closeTestServers(t, bootnodes)
+	})
+
+	bootnodeAddrs := make([]string, 0, bootnodeCount)
+
+	for _, bootnode := range bootnodes {
+		addr, err := common.AddrInfoToString(bootnode.AddrInfo())
+		require.NoError(t, err)
+
+		bootnodeAddrs = append(bootnodeAddrs, addr)
+	}
+
+	server, createErr := CreateServer(&CreateServerParams{
+		ConfigCallback: func(c *Config) {
+			c.MinBootnodeConnections = 2
+			// The bootnodes say goodbye when disconnecting,
+			// which shouldn't delay the redial too much
+			c.GoodbyeBackoff = time.Millisecond
+		},
+		ServerCallback: func(server *Server) {
+			server.config.Chain.Bootnodes = bootnodeAddrs
+		},
+	})
+	require.NoError(t, createErr)
+
+	t.Cleanup(func() {
+		assert.NoError(t, server.Close())
+	})
+
+	assert.Equal(t, int64(2), server.minBootnodeConnections())
+
+	// All the bootnodes are dialed by the discovery service
+	require.Eventually(t, func() bool {
+		return server.GetBootnodeConnCount() == bootnodeCount
+	}, DefaultJoinTimeout, 50*time.Millisecond)
+
+	// Losing a bootnode above the minimum doesn't need a redial, while losing
+	// the next one does. Either way the minimum is restored
+	for _, bootnode := range bootnodes[:2] {
+		bootnode.DisconnectFromPeer(server.host.ID(), "bye")
+
+		disconnectCtx, disconnectFn := context.WithTimeout(context.Background(), DefaultJoinTimeout)
+		_, disconnectErr := WaitUntilPeerDisconnectsFrom(disconnectCtx, bootnode, server.host.ID())
+
+		disconnectFn()
+		require.NoError(t, disconnectErr)
+
+		require.Eventually(t, func() bool {
+			return server.GetBootnodeConnCount() >= 2
+		}, DefaultJoinTimeout, 50*time.Millisecond)
+	}
+
+	assert.Len(t, server.Peers(), int(server.GetBootnodeConnCount()))
+}
+
+func TestMinBootnodeConnections_CappedAtBootnodeCount(t *testing.T) {
+	server := &Server{
+		config:    &Config{MinBootnodeConnections: 5},
+		bootnodes: &bootnodesWrapper{bootnodeArr: make([]*peer.AddrInfo, 2)},
+	}
+
+	assert.Equal(t, int64(2), server.minBootnodeConnections())
+
+	server.config.MinBootnodeConnections = 0
+	assert.Equal(t, DefaultMinBootnodeConnections, server.minBootnodeConnections())
+}
+
+func TestConnectedBootnodes(t *testing.T) {
+	const bootnodeCount = 3
+
+	params := make(map[int]*CreateServerParams, bootnodeCount)
+	for i := 0; i < bootnodeCount; i++ {
+		params[i] = &CreateServerParams{ConfigCallback: func(c *Config) { c.NoDiscover = true }}
+	}
+
+	bootnodes, createErr := createServers(bootnodeCount, params)
+	require.NoError(t, createErr)
+
+	bootnodeAddrs := make([]string, 0, bootnodeCount)
+
+	for _, bootnode := range bootnodes {
+		addr, err := common.AddrInfoToString(bootnode.AddrInfo())
+		require.NoError(t, err)
+
+		bootnodeAddrs = append(bootnodeAddrs, addr)
+	}
+
+	// The last bootnode is unreachable, so only the others can be connected
+	online, offline := bootnodes[:bootnodeCount-1], bootnodes[bootnodeCount-1]
+	require.NoError(t, offline.Close())
+
+	t.Cleanup(func() {
+		closeTestServers(t, online)
+	})
+
+	server, createErr := CreateServer(&CreateServerParams{
+		ConfigCallback: func(c *Config) {
+			c.MinBootnodeConnections = bootnodeCount
+		},
+		ServerCallback: func(server *Server) {
+			server.config.Chain.Bootnodes = bootnodeAddrs
+		},
+	})
+	require.NoError(t, createErr)
+
+	t.Cleanup(func() {
+		assert.NoError(t, server.Close())
+	})
+
+	require.Eventually(t, func() bool {
+		return server.GetBootnodeConnCount() == int64(len(online))
+	}, DefaultJoinTimeout, 50*time.Millisecond)
+
+	expected := make([]peer.ID, 0, len(online))
+	for _, bootnode := range online {
+		expected = append(expected, bootnode.host.ID())
+	}
+
+	assert.ElementsMatch(t, expected, server.ConnectedBootnodes())
+	assert.NotContains(t, server.ConnectedBootnodes(), offline.host.ID())
+}
+
+func TestBootnodeAddrs_NeverExpire(t *testing.T) {
+	clock := newFakeClock()
+
+	bootnode, createErr := CreateServer(&CreateServerParams{
+		ConfigCallback: func(c *Config) { c.NoDiscover = true },
+	})
+	require.NoError(t, createErr)
+
+	t.Cleanup(func() {
+		assert.NoError(t, bootnode.Close())
+	})
+
+	bootnodeAddr, err := common.AddrInfoToString(bootnode.AddrInfo())
+	require.NoError(t, err)
+
+	server, createErr := CreateServer(&CreateServerParams{
+		ConfigCallback: func(c *Config) {
+			c.Clock = clock
+			// The bootnode is only dialed by the test
+			c.StartupDialDelay = time.Hour
+		},
+		ServerCallback: func(server *Server) {
+			server.config.Chain.Bootnodes = []string{bootnodeAddr}
+		},
+	})
+	require.NoError(t, createErr)
+
+	t.Cleanup(func() {
+		assert.NoError(t, server.Close())
+	})
+
+	// Regular peer addresses expire after the address TTL
+	randomPeers, err := generateRandomPeers(t, 1)
+	require.NoError(t, err)
+
+	regular := randomPeers[0].peerID
+	server.AddToPeerStore(&peer.AddrInfo{ID: regular, Addrs: generateTestAddrs(t, 1)})
+
+	clock.Advance(2 * peerstore.AddressTTL)
+
+	assert.Empty(t, server.host.Peerstore().Addrs(regular))
+	assert.ElementsMatch(t, bootnode.AddrInfo().Addrs, server.host.Peerstore().Addrs(bootnode.host.ID()))
+
+	// The bootnode addresses survive the peer removal
+	server.RemoveFromPeerStore(&peer.AddrInfo{ID: bootnode.host.ID()})
+	clock.Advance(2 * peerstore.AddressTTL)
+
+	require.NotEmpty(t, server.host.Peerstore().Addrs(bootnode.host.ID()))
+
+	// The bootnode is dialable by its stored addresses alone
+	ctx, cancelFn := context.WithTimeout(context.Background(), DefaultJoinTimeout)
+	defer cancelFn()
+
+	require.NoError(t, server.host.Connect(ctx, peer.AddrInfo{ID: bootnode.host.ID()}))
+}
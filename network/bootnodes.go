@@ -18,6 +18,13 @@ type bootnodesWrapper struct {
 	bootnodeConnCount int64
 }
 
+// getBootnode returns the bootnode info, if the node is a bootnode
+func (bw *bootnodesWrapper) getBootnode(nodeID peer.ID) (*peer.AddrInfo, bool) {
+	bootnode, ok := bw.bootnodesMap[nodeID]
+
+	return bootnode, ok
+}
+
 // isBootnode checks if the node ID belongs to a set bootnode
 func (bw *bootnodesWrapper) isBootnode(nodeID peer.ID) bool {
 	_, ok := bw.bootnodesMap[nodeID]
@@ -0,0 +1,61 @@
+package network
+
+import (
+	"context"
+	"time"
+)
+
+// Clock is the source of time for the timeouts, backoffs, peer records and periodic checks
+// of the networking server, so time-dependent behavior can be driven by a fake clock in tests.
+// The stream deadlines, the gossip flush polling, the outbound target debounce and
+// the dial queue wait times are measured by the system clock regardless
+type Clock interface {
+	// Now returns the current time
+	Now() time.Time
+
+	// After returns a channel which receives the current time once the duration elapses
+	After(d time.Duration) <-chan time.Time
+}
+
+// realClock is the Clock backed by the system time
+type realClock struct{}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+func (realClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
+
+// configuredClock returns the configured clock, or the system one if not set
+func configuredClock(config *Config) Clock {
+	if config.Clock == nil {
+		return realClock{}
+	}
+
+	return config.Clock
+}
+
+// withClockTimeout returns a context which is canceled once the timeout elapses on the clock
+func withClockTimeout(
+	ctx context.Context,
+	clock Clock,
+	timeout time.Duration,
+) (context.Context, context.CancelFunc) {
+	if _, ok := clock.(realClock); ok {
+		return context.WithTimeout(ctx, timeout)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+
+	go func() {
+		select {
+		case <-clock.After(timeout):
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	return ctx, cancel
+}
@@ -0,0 +1,228 @@
+package network
+
+import (
+	"context"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/0xPolygon/polygon-edge/helper/tests"
+	"github.com/0xPolygon/polygon-edge/network/common"
+	peerEvent "github.com/0xPolygon/polygon-edge/network/event"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeClock is a Clock which only moves when it is advanced
+type fakeClock struct {
+	lock    sync.Mutex
+	now     time.Time
+	waiters []fakeClockWaiter
+}
+
+// fakeClockWaiter is a pending After call of the fake clock
+type fakeClockWaiter struct {
+	deadline time.Time
+	ch       chan time.Time
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{now: time.Unix(1_700_000_000, 0)}
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	return c.now
+}
+
+func (c *fakeClock) After(d time.Duration) <-chan time.Time {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	ch := make(chan time.Time, 1)
+
+	if d <= 0 {
+		ch <- c.now
+
+		return ch
+	}
+
+	c.waiters = append(c.waiters, fakeClockWaiter{deadline: c.now.Add(d), ch: ch})
+
+	return ch
+}
+
+// Advance moves the clock forward, firing the After calls which are due
+func (c *fakeClock) Advance(d time.Duration) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.now = c.now.Add(d)
+
+	pending := c.waiters[:0]
+
+	for _, waiter := range c.waiters {
+		if waiter.deadline.After(c.now) {
+			pending = append(pending, waiter)
+
+			continue
+		}
+
+		waiter.ch <- c.now
+	}
+
+	c.waiters = pending
+}
+
+// waiterCount returns the number of After calls which are not due yet
+func (c *fakeClock) waiterCount() int {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	return len(c.waiters)
+}
+
+func TestKeepAliveMinimumPeerConnections_FakeClock(t *testing.T) {
+	bootnode, createErr := CreateServer(&CreateServerParams{
+		ConfigCallback: func(c *Config) { c.NoDiscover = true },
+	})
+	require.NoError(t, createErr)
+
+	t.Cleanup(func() {
+		assert.NoError(t, bootnode.Close())
+	})
+
+	bootnodeAddr, err := common.AddrInfoToString(bootnode.AddrInfo())
+	require.NoError(t, err)
+
+	clock := newFakeClock()
+
+	server, createErr := CreateServer(&CreateServerParams{
+		ConfigCallback: func(c *Config) {
+			// The bootnode is never dialed, so the node stays below the minimum peer count
+			c.StartupDialDelay = DefaultJoinTimeout
+			c.DiscoveryStartTimeout = DefaultJoinTimeout
+			c.Clock = clock
+		},
+		ServerCallback: func(server *Server) {
+			server.config.Chain.Bootnodes = []string{bootnodeAddr}
+		},
+	})
+	require.NoError(t, createErr)
+
+	t.Cleanup(func() {
+		assert.NoError(t, server.Close())
+	})
+
+	// Wait for the check to be scheduled on the fake clock
+	require.Eventually(t, func() bool {
+		return clock.waiterCount() > 0
+	}, 5*time.Second, 10*time.Millisecond)
+
+	queuedCh := make(chan struct{}, 16)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	require.NoError(t, server.Subscribe(ctx, func(evnt *peerEvent.PeerEvent) {
+		if evnt.Type == peerEvent.PeerAddedToDialQueue && evnt.PeerID == bootnode.AddrInfo().ID {
+			select {
+			case queuedCh <- struct{}{}:
+			default:
+			}
+		}
+	}))
+
+	// Nothing happens while the clock stands still
+	select {
+	case <-queuedCh:
+		t.Fatal("bootnode redialed before the check interval elapsed")
+	case <-time.After(200 * time.Millisecond):
+	}
+
+	clock.Advance(peerConnectionsCheckInterval)
+
+	select {
+	case <-queuedCh:
+	case <-time.After(5 * time.Second):
+		t.Fatal("bootnode not redialed after the check interval elapsed")
+	}
+}
+
+func TestJoinAndWait_FakeClockTimeout(t *testing.T) {
+	clock := newFakeClock()
+
+	servers, createErr := createServers(2, map[int]*CreateServerParams{
+		0: {ConfigCallback: func(c *Config) {
+			c.NoDiscover = true
+			c.Clock = clock
+		}},
+		1: {ConfigCallback: func(c *Config) {
+			c.NoDiscover = true
+		}},
+	})
+	require.NoError(t, createErr)
+
+	t.Cleanup(func() {
+		assert.NoError(t, servers[0].Close())
+	})
+
+	// The destination is gone, so the join never completes
+	require.NoError(t, servers[1].Close())
+
+	waitersBefore := clock.waiterCount()
+	errCh := make(chan error, 1)
+
+	go func() {
+		errCh <- JoinAndWait(servers[0], servers[1], DefaultJoinTimeout, DefaultJoinTimeout)
+	}()
+
+	require.Eventually(t, func() bool {
+		return clock.waiterCount() > waitersBefore
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// The join doesn't time out while the clock stands still
+	select {
+	case err := <-errCh:
+		t.Fatalf("join returned before the timeout elapsed: %v", err)
+	case <-time.After(200 * time.Millisecond):
+	}
+
+	clock.Advance(DefaultJoinTimeout)
+
+	select {
+	case err := <-errCh:
+		assert.ErrorIs(t, err, tests.ErrTimeout)
+	case <-time.After(5 * time.Second):
+		t.Fatal("join didn't time out after the timeout elapsed")
+	}
+}
+
+func TestBanIP_FakeClock(t *testing.T) {
+	clock := newFakeClock()
+
+	server, createErr := CreateServer(&CreateServerParams{ConfigCallback: func(c *Config) {
+		c.NoDiscover = true
+		c.Clock = clock
+	}})
+	require.NoError(t, createErr)
+
+	t.Cleanup(func() {
+		assert.NoError(t, server.Close())
+	})
+
+	ip := net.ParseIP("203.0.113.7")
+
+	server.BanIP(ip, time.Hour)
+	assert.True(t, server.gater.isIPBanned(ip))
+
+	clock.Advance(time.Hour - time.Second)
+	assert.True(t, server.gater.isIPBanned(ip))
+
+	// The ban is lifted once it elapses on the clock
+	clock.Advance(2 * time.Second)
+	assert.False(t, server.gater.isIPBanned(ip))
+}
@@ -13,13 +13,19 @@ import (
 type DialPriority uint64
 
 const (
-	PriorityRequestedDial DialPriority = 1
+	PriorityPinnedDial    DialPriority = 0
+	PriorityJoinDial      DialPriority = 1 // explicit joins, dialed ahead of all the other dials but the pinned ones
+	PriorityRequestedDial DialPriority = 2
+	PriorityRestoredDial  DialPriority = 3
 	PriorityRandomDial    DialPriority = 10
 )
 
 const (
 	DiscProto     = "/disc/0.1"
 	IdentityProto = "/id/0.1"
+	GoodbyeProto  = "/goodbye/0.1"
+	DialBackProto = "/dialback/0.1"
+	PexProto      = "/pex/0.1"
 )
 
 // DNSRegex is a regex string to match against a valid dns/dns4/dns6 addr
@@ -2,10 +2,14 @@ package network
 
 import (
 	"net"
+	"time"
 
 	"github.com/0xPolygon/polygon-edge/chain"
+	"github.com/0xPolygon/polygon-edge/network/dial"
 	"github.com/0xPolygon/polygon-edge/secrets"
+	"github.com/libp2p/go-libp2p/core/peer"
 	"github.com/multiformats/go-multiaddr"
+	madns "github.com/multiformats/go-multiaddr-dns"
 )
 
 // Config details the params for the base networking server
@@ -14,12 +18,111 @@ type Config struct {
 	Addr             *net.TCPAddr           // the base address
 	NatAddr          net.IP                 // the NAT address
 	DNS              multiaddr.Multiaddr    // the DNS address
+	ListenAddrs      []multiaddr.Multiaddr  // the addresses to listen on (e.g. dual-stack), the base address if empty
 	DataDir          string                 // the base data directory for the client
+	RestoreLastPeers bool                   // flag indicating if the peers connected at shutdown are redialed on start
 	MaxPeers         int64                  // the maximum number of peer connections
 	MaxInboundPeers  int64                  // the maximum number of inbound peer connections
 	MaxOutboundPeers int64                  // the maximum number of outbound peer connections
 	Chain            *chain.Chain           // the reference to the chain configuration
 	SecretsManager   secrets.SecretsManager // the secrets manager used for key storage
+	GoodbyeTimeout   time.Duration          // the maximum time spent on sending a goodbye message
+	GoodbyeBackoff   time.Duration          // the time a peer that said goodbye is not redialed
+
+	MaxOutboundBandwidth int64         // the maximum aggregate outbound rate (bytes/sec), unlimited if 0
+	MaxPeerAddrs         int           // the maximum number of addresses stored per peer
+	EnableRelayService   bool          // flag indicating if the node relays connections for other peers
+	EnableHolePunching   bool          // flag indicating if relayed connections are upgraded to direct ones (DCUtR)
+	EnableNATPortMap     bool          // flag indicating if a UPnP / NAT-PMP port mapping of the listen port is requested
+	DialOrder            DialOrder     // the order in which the peer address types are dialed
+	DialStagger          time.Duration // the time after which a slow dial races the next addresses, sequential if 0
+	Muxers               []string      // the stream multiplexers in the order of preference, the libp2p default if empty
+	DisablePubSub        bool          // flag indicating if the gossip (pubsub) service is turned off
+	MaxTopics            int           // the maximum number of gossip topics joined at the same time, unlimited if 0
+	GossipSeenCacheSize  int           // the number of recently seen message IDs remembered per topic, disabled if 0
+	GossipFlushTimeout   time.Duration // the maximum time draining the gossip queues on shutdown, up to the deadline if 0
+	EnablePex            bool          // flag indicating if the peer exchange (PEX) protocol should be turned on
+
+	ReachabilityCheckInterval time.Duration // the interval of the bootnode dial-back checks, disabled if 0
+	PexInterval               time.Duration // the time between the peer exchanges, if the peer exchange is turned on
+
+	EnableReachabilityDialRatio bool // flag indicating if the connection limits are shifted to the node reachability
+
+	NetworkChangeCheckInterval time.Duration // the interval of the network interface checks, disabled if 0
+	GossipSilenceThreshold     time.Duration // the time without received gossip after which a warning is logged
+
+	MaxDialsPerPeer int           // the maximum number of dials to a single peer within the dial rate window
+	DialRateWindow  time.Duration // the time window in which the dials to a single peer are limited
+	MaxPendingJoins int           // the maximum number of join requests waiting to be dialed
+	DialRampInitial int           // the number of concurrent dials allowed right after start
+	DialRampWindow  time.Duration // the time over which the concurrent dials ramp up to the maximum, disabled if negative
+
+	MaxConcurrentDials int // the maximum number of dials in progress at the same time, the outbound peer limit if 0
+
+	DialBackoffBase time.Duration // the backoff after a first failed dial, doubled on every next one, disabled if 0
+	DialBackoffMax  time.Duration // the maximum backoff after consecutive failed dials to a peer, the default if 0
+
+	DialFallbackInterval     time.Duration // the interval of the rounds refilling an empty dial queue, disabled if 0
+	DialFallbackSlowInterval time.Duration // the interval of the rounds once the aggressive ones failed, the default if 0
+
+	DialPriorityAging []dial.AgingThreshold // the promotions of the dials waiting long in the queue, disabled if empty
+
+	StartupDialDelay   time.Duration // the time the first dials are held back for after start, disabled if 0
+	StartupGracePeriod time.Duration // the time after start in which failed dials don't escalate the dial backoff, disabled if 0
+
+	HandshakeGracePeriod time.Duration // the maximum time a connection is pending before the handshake, unbounded if 0
+	HandshakeMaxMsgSize  int           // the maximum size of an identity handshake request or response (bytes)
+	MaxInboundBacklog    int           // the maximum number of accepted, not yet secured connections, unlimited if 0
+	PeerIdleTimeout      time.Duration // the time without traffic after which a peer is pinged, disabled if 0
+	MaxStreamsPerConn    int           // the maximum number of streams open on a single connection, unlimited if 0
+	MaxTotalStreams      int           // the maximum number of streams open node-wide, unlimited if 0
+	QuarantineDuration   time.Duration // the time a peer violating the protocol is refused connections for
+	MaxPenaltyRecords    int           // the maximum number of reputation penalty records kept, the default if 0
+	PenaltyMaxAge        time.Duration // the time without violations after which a peer penalty is forgotten, the default if 0
+
+	MaxOutboundStreamsPerPeer int // the maximum number of streams opened to a single peer at the same time, unlimited if 0
+
+	TotalConnectDeadline time.Duration // the maximum time from the dial start until the peer is ready, disabled if 0
+
+	QuarantineOnHandlerPanic bool // flag indicating if the peers whose streams make a protocol handler panic are quarantined
+	LimitBootnodeInbound     bool // flag indicating if the inbound bootnode connections are subject to the inbound limit
+	TraceConnStates          bool // flag indicating if the detailed state of the connection with each peer is tracked
+	ShortPeerIDLogs          bool // flag indicating if the log messages name the peers by a short form of their IDs
+
+	DisconnectLogWindow time.Duration // the window coalescing the identical disconnect logs, disabled if negative
+
+	PeerStreamIdleTimeout time.Duration // the time without protocol streams or shared gossip topics before dropping a peer
+
+	DualConnPolicy DualConnPolicy // the handling of the peers connected in both directions
+
+	TargetOutboundPeers    int64         // the outbound peer count at which the node is well-connected, disabled if 0
+	OutboundTargetDebounce time.Duration // the time the outbound peer count has to be below the target to report it
+	MinBootnodeConnections int64         // the number of bootnode connections kept alive, 1 if 0
+
+	DNSResolver madns.BasicResolver // the resolver of DNS multiaddrs, the system one is used if not set
+
+	PeerstoreDatastorePath string // the directory of the disk-backed peerstore, the in-memory one is used if empty
+
+	AgentVersion              string  // the software version advertised to the peers, the libp2p default if empty
+	VersionDominanceThreshold float64 // the peer share of a single software version above which a warning is logged, disabled if 0
+
+	DiscoveryMaxMsgSize   int           // the maximum size of a discovery request or response (bytes)
+	DiscoveryCallTimeout  time.Duration // the maximum time spent on a single discovery call
+	DiscoveryStartTimeout time.Duration // the maximum time the crawling waits for a bootnode connection on start
+	DiscoveryMaxQueries   int           // the maximum number of concurrent outbound discovery queries, the default if 0
+
+	DiscoveryBackend Discoverer // the peer discovery backend, the Kademlia one if not set
+
+	RoutableAddrFilter RoutableAddrFilter // reports if a peer address is worth dialing, public addresses on public nodes if not set
+
+	PingIntervals map[PeerRole]time.Duration // the keepalive ping interval of the peers by role, disabled if not set
+
+	PeerScorer PeerScorer // scores the peers evicted, pruned and trimmed, the default scorer if not set
+
+	Clock Clock // the source of time for the timeouts, backoffs, peer records and checks, the system clock if not set
+
+	AllowlistOnly bool      // flag indicating if only the allowlisted peers can connect
+	PeerAllowlist []peer.ID // the peers allowed to connect, if the allowlist-only mode is on
 }
 
 func DefaultConfig() *Config {
@@ -36,5 +139,31 @@ func DefaultConfig() *Config {
 		// The default ratio for outbound / inbound connections is 0.25
 		MaxInboundPeers:  32,
 		MaxOutboundPeers: 8,
+		// Goodbye messages are best-effort, so they should not stall disconnects
+		GoodbyeTimeout: DefaultGoodbyeTimeout,
+		GoodbyeBackoff: DefaultGoodbyeBackoff,
+		// Bound the peer store size and dial fan-out per peer
+		MaxPeerAddrs: DefaultMaxPeerAddrs,
+		// Guard against dialing the same peer in a tight loop
+		MaxDialsPerPeer: DefaultMaxDialsPerPeer,
+		DialRateWindow:  DefaultDialRateWindow,
+		// Guard against join request floods growing the dial queue unboundedly
+		MaxPendingJoins: DefaultMaxPendingJoins,
+		// Smooth the dial load on startup
+		DialRampInitial: DefaultDialRampInitial,
+		DialRampWindow:  DefaultDialRampWindow,
+		// Bootstrap from a long dial queue quickly, without a dial storm
+		MaxConcurrentDials: DefaultMaxConcurrentDials,
+		// Reject accept floods before they reach the security handshake
+		MaxInboundBacklog: DefaultMaxInboundBacklog,
+		// Keep misbehaving peers away for a while, instead of just disconnecting them
+		QuarantineDuration: DefaultQuarantineDuration,
+		// Bound the memory used for the reputation of long-gone peers
+		MaxPenaltyRecords: DefaultMaxPenaltyRecords,
+		PenaltyMaxAge:     DefaultPenaltyMaxAge,
+		// Keep the node reachable through the bootnodes, even as they drop
+		MinBootnodeConnections: DefaultMinBootnodeConnections,
+		// Report the nodes left out of the gossip by their peers
+		GossipSilenceThreshold: DefaultGossipSilenceThreshold,
 	}
 }
@@ -0,0 +1,92 @@
+package network
+
+import (
+	"net"
+
+	"github.com/libp2p/go-libp2p/core/network"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/libp2p/go-libp2p/core/protocol"
+	"github.com/multiformats/go-multiaddr"
+	manet "github.com/multiformats/go-multiaddr/net"
+)
+
+// relayTransport is the reported transport of the connections through a circuit relay
+const relayTransport = "p2p-circuit"
+
+// ConnCharacteristics describes the transport of a connection to a peer,
+// for the performance diagnostics
+type ConnCharacteristics struct {
+	ConnID    string            // the ID of the described connection, empty if the peer is not connected
+	Transport string            // the transport the connection is established over (e.g. tcp)
+	Direction network.Direction // the direction the connection was established in
+	Relayed   bool              // flag indicating if the connection goes through a circuit relay
+	Security  protocol.ID       // the negotiated security protocol (e.g. noise)
+	Muxer     protocol.ID       // the negotiated stream multiplexer
+	MTU       int               // the MTU of the local interface the connection uses, 0 if unknown
+}
+
+// ConnCharacteristics returns the transport characteristics of the connection to the peer.
+// With multiple connections open, a direct one is described over a relayed one.
+// The zero value is returned if the peer is not connected
+func (s *Server) ConnCharacteristics(peerID peer.ID) ConnCharacteristics {
+	var selected network.Conn
+
+	for _, conn := range s.host.Network().ConnsToPeer(peerID) {
+		if selected == nil || (isRelayAddr(selected.RemoteMultiaddr()) && !isRelayAddr(conn.RemoteMultiaddr())) {
+			selected = conn
+		}
+	}
+
+	if selected == nil {
+		return ConnCharacteristics{}
+	}
+
+	state := selected.ConnState()
+	relayed := isRelayAddr(selected.RemoteMultiaddr())
+
+	characteristics := ConnCharacteristics{
+		ConnID:    selected.ID(),
+		Transport: state.Transport,
+		Direction: selected.Stat().Direction,
+		Relayed:   relayed,
+		Security:  state.Security,
+		Muxer:     state.StreamMultiplexer,
+	}
+
+	if relayed {
+		// The relayed connection is a stream within the connection to the relay
+		characteristics.Transport = relayTransport
+	} else {
+		characteristics.MTU = interfaceMTU(selected.LocalMultiaddr())
+	}
+
+	return characteristics
+}
+
+// interfaceMTU returns the MTU of the local interface the address is bound to, or 0 if unknown
+func interfaceMTU(addr multiaddr.Multiaddr) int {
+	ip, err := manet.ToIP(addr)
+	if err != nil {
+		return 0
+	}
+
+	interfaces, err := net.Interfaces()
+	if err != nil {
+		return 0
+	}
+
+	for _, iface := range interfaces {
+		ifaceAddrs, err := iface.Addrs()
+		if err != nil {
+			continue
+		}
+
+		for _, ifaceAddr := range ifaceAddrs {
+			if ipNet, ok := ifaceAddr.(*net.IPNet); ok && ipNet.IP.Equal(ip) {
+				return iface.MTU
+			}
+		}
+	}
+
+	return 0
+}
@@ -0,0 +1,70 @@
+package network
+
+import (
+	"net"
+	"testing"
+
+	"github.com/libp2p/go-libp2p/core/network"
+	"github.com/libp2p/go-libp2p/p2p/muxer/yamux"
+	"github.com/libp2p/go-libp2p/p2p/security/noise"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConnCharacteristics(t *testing.T) {
+	servers, createErr := createServers(2, map[int]*CreateServerParams{
+		0: {ConfigCallback: func(c *Config) {
+			c.NoDiscover = true
+		}},
+		1: {ConfigCallback: func(c *Config) {
+			c.NoDiscover = true
+		}},
+	})
+	require.NoError(t, createErr)
+
+	t.Cleanup(func() {
+		closeTestServers(t, servers)
+	})
+
+	// Nothing is reported for a peer which is not connected
+	assert.Equal(t, ConnCharacteristics{}, servers[0].ConnCharacteristics(servers[1].AddrInfo().ID))
+
+	require.NoError(t, JoinAndWait(servers[0], servers[1], DefaultBufferTimeout, DefaultJoinTimeout))
+
+	// The test servers listen on the loopback interface
+	loopbackMTU := 0
+
+	interfaces, err := net.Interfaces()
+	require.NoError(t, err)
+
+	for _, iface := range interfaces {
+		if iface.Flags&net.FlagLoopback != 0 {
+			loopbackMTU = iface.MTU
+		}
+	}
+
+	require.NotZero(t, loopbackMTU)
+
+	expected := func(direction network.Direction) ConnCharacteristics {
+		return ConnCharacteristics{
+			Transport: "tcp",
+			Direction: direction,
+			Relayed:   false,
+			Security:  noise.ID,
+			Muxer:     yamux.ID,
+			MTU:       loopbackMTU,
+		}
+	}
+
+	outbound := servers[0].ConnCharacteristics(servers[1].AddrInfo().ID)
+	assert.NotEmpty(t, outbound.ConnID)
+
+	outbound.ConnID = ""
+	assert.Equal(t, expected(network.DirOutbound), outbound)
+
+	inbound := servers[1].ConnCharacteristics(servers[0].AddrInfo().ID)
+	assert.NotEmpty(t, inbound.ConnID)
+
+	inbound.ConnID = ""
+	assert.Equal(t, expected(network.DirInbound), inbound)
+}
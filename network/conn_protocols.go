@@ -0,0 +1,101 @@
+package network
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/libp2p/go-libp2p/core/protocol"
+)
+
+// connProtocolTracker keeps track of the protocols used on the live peer connections.
+// Unlike the peerstore protocols, which are the ones advertised by the peer,
+// these are the protocols streams were actually opened on
+type connProtocolTracker struct {
+	sync.Mutex
+
+	protocols map[peer.ID]map[protocol.ID]struct{}
+}
+
+// newConnProtocolTracker creates a new connection protocol tracker
+func newConnProtocolTracker() *connProtocolTracker {
+	return &connProtocolTracker{
+		protocols: make(map[peer.ID]map[protocol.ID]struct{}),
+	}
+}
+
+// record marks the protocol as used on the peer connection [Thread safe]
+func (t *connProtocolTracker) record(peerID peer.ID, proto protocol.ID) {
+	if proto == "" {
+		return
+	}
+
+	t.Lock()
+	defer t.Unlock()
+
+	protocols, ok := t.protocols[peerID]
+	if !ok {
+		protocols = make(map[protocol.ID]struct{})
+		t.protocols[peerID] = protocols
+	}
+
+	protocols[proto] = struct{}{}
+}
+
+// get returns the protocols used on the peer connection [Thread safe]
+func (t *connProtocolTracker) get(peerID peer.ID) []protocol.ID {
+	t.Lock()
+	defer t.Unlock()
+
+	protocols := make([]protocol.ID, 0, len(t.protocols[peerID]))
+	for proto := range t.protocols[peerID] {
+		protocols = append(protocols, proto)
+	}
+
+	return protocols
+}
+
+// remove removes the protocols used on the peer connection [Thread safe]
+func (t *connProtocolTracker) remove(peerID peer.ID) {
+	t.Lock()
+	defer t.Unlock()
+
+	delete(t.protocols, peerID)
+}
+
+// ConnProtocols returns the protocols negotiated on the live connections to the peer,
+// including the ones of streams that were already closed. These can differ from
+// the protocols the peer advertises, which are returned by GetProtocols
+func (s *Server) ConnProtocols(peerID peer.ID) []protocol.ID {
+	conns := s.host.Network().ConnsToPeer(peerID)
+	if len(conns) == 0 {
+		return nil
+	}
+
+	seen := make(map[protocol.ID]struct{})
+
+	for _, proto := range s.connProtocols.get(peerID) {
+		seen[proto] = struct{}{}
+	}
+
+	// Streams opened outside of the server (e.g. by the libp2p services)
+	// are picked up from the live connections
+	for _, conn := range conns {
+		for _, stream := range conn.GetStreams() {
+			if proto := stream.Protocol(); proto != "" {
+				seen[proto] = struct{}{}
+			}
+		}
+	}
+
+	protocols := make([]protocol.ID, 0, len(seen))
+	for proto := range seen {
+		protocols = append(protocols, proto)
+	}
+
+	sort.Slice(protocols, func(i, j int) bool {
+		return protocols[i] < protocols[j]
+	})
+
+	return protocols
+}
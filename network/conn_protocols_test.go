@@ -0,0 +1,92 @@
+package network
+
+import (
+	"testing"
+	"time"
+
+	"github.com/libp2p/go-libp2p/core/network"
+	"github.com/libp2p/go-libp2p/core/protocol"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConnProtocols(t *testing.T) {
+	servers, createErr := createServers(2, nil)
+	require.NoError(t, createErr)
+
+	t.Cleanup(func() {
+		closeTestServers(t, servers)
+	})
+
+	server, remote := servers[0], servers[1]
+	remoteID := remote.host.ID()
+
+	require.NoError(t, JoinAndWait(server, remote, DefaultBufferTimeout, DefaultJoinTimeout))
+
+	const (
+		usedProto   = "/test-used/0.1"
+		closedProto = "/test-closed/0.1"
+		unusedProto = "/test-unused/0.1"
+	)
+
+	for _, proto := range []string{usedProto, closedProto, unusedProto} {
+		remote.wrapStream(proto, func(stream network.Stream) {
+			_ = stream.Close()
+		})
+	}
+
+	// Writing completes the (possibly lazy) protocol negotiation on the remote side
+	usedStream, err := server.NewStream(usedProto, remoteID)
+	require.NoError(t, err)
+
+	_, err = usedStream.Write([]byte{1})
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		_ = usedStream.Reset()
+	})
+
+	closedStream, err := server.NewStream(closedProto, remoteID)
+	require.NoError(t, err)
+
+	_, err = closedStream.Write([]byte{1})
+	require.NoError(t, err)
+	require.NoError(t, closedStream.Close())
+
+	protocols := server.ConnProtocols(remoteID)
+
+	// Closed streams are still reported, as the protocol was negotiated on the connection
+	assert.Contains(t, protocols, protocol.ID(usedProto))
+	assert.Contains(t, protocols, protocol.ID(closedProto))
+
+	// Protocols the peer only advertises are not reported
+	assert.NotContains(t, protocols, protocol.ID(unusedProto))
+
+	// The remote side reports the protocols of the inbound streams
+	require.Eventually(t, func() bool {
+		remoteProtocols := remote.ConnProtocols(server.host.ID())
+
+		for _, proto := range []protocol.ID{usedProto, closedProto} {
+			found := false
+
+			for _, negotiated := range remoteProtocols {
+				if negotiated == proto {
+					found = true
+				}
+			}
+
+			if !found {
+				return false
+			}
+		}
+
+		return true
+	}, 5*time.Second, 50*time.Millisecond)
+
+	// Disconnected peers have no negotiated protocols
+	server.DisconnectFromPeer(remoteID, "Bye")
+
+	require.Eventually(t, func() bool {
+		return len(server.ConnProtocols(remoteID)) == 0
+	}, 5*time.Second, 50*time.Millisecond)
+}
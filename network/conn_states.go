@@ -0,0 +1,198 @@
+package network
+
+import (
+	"context"
+	"sync"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/libp2p/go-libp2p/core/event"
+	"github.com/libp2p/go-libp2p/core/peer"
+)
+
+// ConnState is the detailed state of the connection with a peer
+type ConnState uint
+
+const (
+	ConnStateUnknown       ConnState = iota // the connection states are not traced
+	ConnStateDialing                        // the peer is being dialed
+	ConnStateConnected                      // the connection is established
+	ConnStateHandshaking                    // the identity handshake is in progress
+	ConnStateReady                          // the handshake completed, the peer is usable
+	ConnStateDisconnecting                  // the connection is being closed by the node
+	ConnStateClosed                         // no connection with the peer is open
+)
+
+var connStateToName = map[ConnState]string{
+	ConnStateUnknown:       "unknown",
+	ConnStateDialing:       "dialing",
+	ConnStateConnected:     "connected",
+	ConnStateHandshaking:   "handshaking",
+	ConnStateReady:         "ready",
+	ConnStateDisconnecting: "disconnecting",
+	ConnStateClosed:        "closed",
+}
+
+func (s ConnState) String() string {
+	name, ok := connStateToName[s]
+	if !ok {
+		return "unknown"
+	}
+
+	return name
+}
+
+// ConnStateEvent is emitted on every connection state transition,
+// if the connection states are traced
+type ConnStateEvent struct {
+	// PeerID is the id of the peer the connection is with
+	PeerID peer.ID
+
+	// From is the state before the transition
+	From ConnState
+
+	// To is the state after the transition
+	To ConnState
+}
+
+// connStateTracker keeps the detailed state of the connection with each peer.
+// The closed connections are forgotten, so the tracker doesn't grow with the peers seen
+type connStateTracker struct {
+	sync.Mutex
+
+	states  map[peer.ID]ConnState
+	emitter event.Emitter
+	logger  hclog.Logger
+}
+
+// newConnStateTracker creates a new connection state tracker
+func newConnStateTracker(emitter event.Emitter, logger hclog.Logger) *connStateTracker {
+	return &connStateTracker{
+		states:  make(map[peer.ID]ConnState),
+		emitter: emitter,
+		logger:  logger,
+	}
+}
+
+// transition moves the connection with the peer to the state, and reports the transition.
+// An open connection doesn't go back to the dialing or connected state,
+// when the peer is dialed again or opens another connection [Thread safe]
+func (t *connStateTracker) transition(peerID peer.ID, to ConnState) {
+	t.transitionIf(peerID, to, func(from ConnState) bool {
+		return to > ConnStateConnected || from <= to || from == ConnStateClosed
+	})
+}
+
+// transitionFrom moves the connection with the peer to the state,
+// only if the connection is in the expected state [Thread safe]
+func (t *connStateTracker) transitionFrom(peerID peer.ID, from, to ConnState) {
+	t.transitionIf(peerID, to, func(current ConnState) bool {
+		return current == from
+	})
+}
+
+// transitionIf moves the connection with the peer to the state,
+// if the current state satisfies the condition [Thread safe]
+func (t *connStateTracker) transitionIf(peerID peer.ID, to ConnState, allowed func(ConnState) bool) {
+	t.Lock()
+
+	from := t.stateOf(peerID)
+	if from == to || !allowed(from) {
+		t.Unlock()
+
+		return
+	}
+
+	if to == ConnStateClosed {
+		delete(t.states, peerID)
+	} else {
+		t.states[peerID] = to
+	}
+
+	t.Unlock()
+
+	t.logger.Debug("Connection state changed", "id", peerID, "from", from, "to", to)
+
+	if err := t.emitter.Emit(ConnStateEvent{PeerID: peerID, From: from, To: to}); err != nil {
+		t.logger.Info("failed to emit connection state event", "peer", peerID, "err", err)
+	}
+}
+
+// get returns the state of the connection with the peer [Thread safe]
+func (t *connStateTracker) get(peerID peer.ID) ConnState {
+	t.Lock()
+	defer t.Unlock()
+
+	return t.stateOf(peerID)
+}
+
+// stateOf returns the state of the connection with the peer, closed if it is not tracked
+func (t *connStateTracker) stateOf(peerID peer.ID) ConnState {
+	state, ok := t.states[peerID]
+	if !ok {
+		return ConnStateClosed
+	}
+
+	return state
+}
+
+// setConnState moves the connection with the peer to the state, if the connection states are traced
+func (s *Server) setConnState(peerID peer.ID, state ConnState) {
+	if s.connStates == nil {
+		return
+	}
+
+	s.connStates.transition(peerID, state)
+}
+
+// setConnStateFrom moves the connection with the peer to the state, if the connection states
+// are traced and the connection is in the expected state
+func (s *Server) setConnStateFrom(peerID peer.ID, from, to ConnState) {
+	if s.connStates == nil {
+		return
+	}
+
+	s.connStates.transitionFrom(peerID, from, to)
+}
+
+// ConnState returns the detailed state of the connection with the peer,
+// or ConnStateUnknown if the connection states are not traced [Thread safe]
+func (s *Server) ConnState(peerID peer.ID) ConnState {
+	if s.connStates == nil {
+		return ConnStateUnknown
+	}
+
+	return s.connStates.get(peerID)
+}
+
+// SubscribeConnStates runs the handler on every connection state transition,
+// until the context is done. Nothing is reported if the connection states are not traced
+func (s *Server) SubscribeConnStates(ctx context.Context, handler func(evnt *ConnStateEvent)) error {
+	sub, err := s.host.EventBus().Subscribe(new(ConnStateEvent))
+	if err != nil {
+		return err
+	}
+
+	done := s.health.subscriptions.start()
+
+	go func() {
+		defer done()
+		defer sub.Close()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+
+			case <-s.closeCh:
+				return
+
+			case evnt := <-sub.Out():
+				if obj, ok := evnt.(ConnStateEvent); ok {
+					handler(&obj)
+				}
+			}
+		}
+	}()
+
+	return nil
+}
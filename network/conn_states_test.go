@@ -0,0 +1,107 @@
+package network
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConnState_Lifecycle(t *testing.T) {
+	servers, createErr := createServers(2, map[int]*CreateServerParams{
+		0: {ConfigCallback: func(c *Config) {
+			c.NoDiscover = true
+			c.TraceConnStates = true
+		}},
+		1: {ConfigCallback: func(c *Config) {
+			c.NoDiscover = true
+		}},
+	})
+	require.NoError(t, createErr)
+
+	t.Cleanup(func() {
+		closeTestServers(t, servers)
+	})
+
+	peerID := servers[1].AddrInfo().ID
+	transitionCh := make(chan ConnStateEvent, 16)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	require.NoError(t, servers[0].SubscribeConnStates(ctx, func(evnt *ConnStateEvent) {
+		if evnt.PeerID == peerID {
+			transitionCh <- *evnt
+		}
+	}))
+
+	// The tracing is off on the other node
+	assert.Equal(t, ConnStateUnknown, servers[1].ConnState(servers[0].AddrInfo().ID))
+	assert.Equal(t, ConnStateClosed, servers[0].ConnState(peerID))
+
+	require.NoError(t, JoinAndWait(servers[0], servers[1], DefaultBufferTimeout, DefaultJoinTimeout))
+	assert.Equal(t, ConnStateReady, servers[0].ConnState(peerID))
+
+	servers[0].DisconnectFromPeer(peerID, "Bye")
+
+	disconnectCtx, disconnectFn := context.WithTimeout(context.Background(), DefaultJoinTimeout)
+	defer disconnectFn()
+
+	_, disconnectErr := WaitUntilPeerDisconnectsFrom(disconnectCtx, servers[0], peerID)
+	require.NoError(t, disconnectErr)
+
+	expected := []ConnState{
+		ConnStateDialing,
+		ConnStateConnected,
+		ConnStateHandshaking,
+		ConnStateReady,
+		ConnStateDisconnecting,
+		ConnStateClosed,
+	}
+
+	from := ConnStateClosed
+
+	for _, to := range expected {
+		select {
+		case transition := <-transitionCh:
+			assert.Equal(t, from, transition.From)
+			assert.Equal(t, to, transition.To, "expected %s, got %s", to, transition.To)
+
+			from = transition.To
+		case <-time.After(5 * time.Second):
+			t.Fatalf("no transition to %s", to)
+		}
+	}
+
+	assert.Equal(t, ConnStateClosed, servers[0].ConnState(peerID))
+}
+
+func TestConnStateTracker_KeepsOpenConnection(t *testing.T) {
+	server, createErr := CreateServer(&CreateServerParams{ConfigCallback: func(c *Config) {
+		c.NoDiscover = true
+		c.TraceConnStates = true
+	}})
+	require.NoError(t, createErr)
+
+	t.Cleanup(func() {
+		require.NoError(t, server.Close())
+	})
+
+	peerID := peer.ID("A")
+
+	server.setConnState(peerID, ConnStateConnected)
+	server.setConnState(peerID, ConnStateHandshaking)
+	server.setConnState(peerID, ConnStateReady)
+
+	// Another connection or dial doesn't regress an open connection
+	server.setConnState(peerID, ConnStateConnected)
+	server.setConnState(peerID, ConnStateDialing)
+	assert.Equal(t, ConnStateReady, server.ConnState(peerID))
+
+	// A failed dial doesn't close the connection the peer opened
+	server.setConnStateFrom(peerID, ConnStateDialing, ConnStateClosed)
+	assert.Equal(t, ConnStateReady, server.ConnState(peerID))
+}
@@ -0,0 +1,144 @@
+package network
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/armon/go-metrics"
+	"github.com/libp2p/go-libp2p/core/peer"
+)
+
+// connectDeadlineBackoff is the time a peer which exceeded the total connect deadline is not redialed
+const connectDeadlineBackoff = time.Minute
+
+// ErrConnectDeadlineExceeded is returned when a dial doesn't complete within the total connect deadline
+var ErrConnectDeadlineExceeded = errors.New("total connect deadline exceeded")
+
+// readyWaiters keeps track of the dials waiting for the peers to become ready (handshaken)
+type readyWaiters struct {
+	sync.Mutex
+
+	waiters map[peer.ID]map[chan bool]struct{} // peerID -> waiters
+}
+
+// newReadyWaiters creates a new peer readiness waiter tracker
+func newReadyWaiters() *readyWaiters {
+	return &readyWaiters{
+		waiters: make(map[peer.ID]map[chan bool]struct{}),
+	}
+}
+
+// add registers a waiter for the peer readiness. The returned channel receives true once
+// the peer is ready, or false if the peer disconnects before. The returned function
+// unregisters the waiter [Thread safe]
+func (w *readyWaiters) add(peerID peer.ID) (<-chan bool, func()) {
+	w.Lock()
+	defer w.Unlock()
+
+	waiter := make(chan bool, 1)
+
+	if _, ok := w.waiters[peerID]; !ok {
+		w.waiters[peerID] = make(map[chan bool]struct{})
+	}
+
+	w.waiters[peerID][waiter] = struct{}{}
+
+	return waiter, func() {
+		w.Lock()
+		defer w.Unlock()
+
+		if waiters, ok := w.waiters[peerID]; ok {
+			delete(waiters, waiter)
+
+			if len(waiters) == 0 {
+				delete(w.waiters, peerID)
+			}
+		}
+	}
+}
+
+// notify reports the outcome of the peer connection to all its waiters,
+// and unregisters them [Thread safe]
+func (w *readyWaiters) notify(peerID peer.ID, ready bool) {
+	w.Lock()
+	defer w.Unlock()
+
+	for waiter := range w.waiters[peerID] {
+		waiter <- ready
+	}
+
+	delete(w.waiters, peerID)
+}
+
+// connectPeer dials the peer, and waits for it to become ready (handshaken) within the
+// total connect deadline, if set. A dial exceeding the deadline fails, while a peer connected
+// but not ready by the deadline is disconnected. Either way, the peer is backed off [BLOCKING]
+func (s *Server) connectPeer(ctx context.Context, peerInfo peer.AddrInfo) error {
+	if s.config.TotalConnectDeadline <= 0 {
+		return s.dialPeer(ctx, peerInfo)
+	}
+
+	readyCh, stopWaiting := s.readyWaiters.add(peerInfo.ID)
+	defer stopWaiting()
+
+	deadlineCtx, cancel := withClockTimeout(ctx, s.clock, s.config.TotalConnectDeadline)
+	defer cancel()
+
+	if err := s.dialPeer(deadlineCtx, peerInfo); err != nil {
+		if deadlineCtx.Err() != nil && ctx.Err() == nil {
+			s.backOffSlowPeer(peerInfo.ID)
+
+			return fmt.Errorf("%w: %v", ErrConnectDeadlineExceeded, err)
+		}
+
+		return err
+	}
+
+	select {
+	case <-readyCh:
+		// The handshake outcome is handled by the identity service
+		return nil
+	case <-deadlineCtx.Done():
+		if ctx.Err() != nil {
+			return nil
+		}
+	}
+
+	s.backOffSlowPeer(peerInfo.ID)
+	s.DisconnectFromPeer(peerInfo.ID, "total connect deadline exceeded")
+
+	return nil
+}
+
+// backOffSlowPeer puts the peer which exceeded the total connect deadline in a backoff,
+// so it is not redialed right away [Thread safe]
+func (s *Server) backOffSlowPeer(peerID peer.ID) {
+	s.logger.Debug("Peer exceeded the total connect deadline", "peer", peerID)
+
+	metrics.IncrCounter([]string{networkMetrics, "connect_deadline_exceeded"}, 1)
+
+	s.slowConnects.Store(peerID, s.clock.Now().Add(connectDeadlineBackoff))
+}
+
+// connectDeadlineBackoffRemaining returns the time the peer stays in the backoff
+// after exceeding the total connect deadline, 0 if it's not in one [Thread safe]
+func (s *Server) connectDeadlineBackoffRemaining(peerID peer.ID) time.Duration {
+	value, ok := s.slowConnects.Load(peerID)
+	if !ok {
+		return 0
+	}
+
+	until, _ := value.(time.Time)
+
+	remaining := until.Sub(s.clock.Now())
+	if remaining <= 0 {
+		s.slowConnects.Delete(peerID)
+
+		return 0
+	}
+
+	return remaining
+}
@@ -0,0 +1,104 @@
+package network
+
+import (
+	"testing"
+	"time"
+
+	"github.com/0xPolygon/polygon-edge/network/common"
+	"github.com/libp2p/go-libp2p/core/network"
+	"github.com/libp2p/go-libp2p/core/protocol"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTotalConnectDeadline_AbortsSlowHandshake(t *testing.T) {
+	const (
+		deadline       = 500 * time.Millisecond
+		handshakeDelay = 2 * time.Second
+	)
+
+	servers, createErr := createServers(2, map[int]*CreateServerParams{
+		0: {
+			ConfigCallback: func(c *Config) {
+				c.NoDiscover = true
+				c.TotalConnectDeadline = deadline
+			},
+		},
+		1: {
+			ConfigCallback: func(c *Config) {
+				c.NoDiscover = true
+			},
+		},
+	})
+	require.NoError(t, createErr)
+
+	t.Cleanup(func() {
+		closeTestServers(t, servers)
+	})
+
+	server, target := servers[0], servers[1]
+
+	// The target answers the handshake, but slower than the total connect deadline.
+	// The handshake itself is still well within the grace period
+	identityHandler := target.protocols[common.IdentityProto].Handler()
+	target.host.SetStreamHandler(protocol.ID(common.IdentityProto), func(stream network.Stream) {
+		time.Sleep(handshakeDelay)
+		identityHandler(stream)
+	})
+
+	require.NoError(t, server.joinPeer(target.AddrInfo()))
+
+	// The connection is abandoned once the deadline passes
+	require.Eventually(t, func() bool {
+		reason, _, backedOff := server.PeerBackoffInfo(target.AddrInfo().ID)
+
+		return backedOff && reason == "exceeded the total connect deadline"
+	}, 5*deadline, 20*time.Millisecond)
+
+	assert.Eventually(t, func() bool {
+		return len(server.host.Network().ConnsToPeer(target.AddrInfo().ID)) == 0
+	}, handshakeDelay, 20*time.Millisecond)
+
+	// The peer is never accepted, even after the slow handshake would have completed
+	assert.Never(t, func() bool {
+		return server.hasPeer(target.AddrInfo().ID)
+	}, 2*handshakeDelay, 50*time.Millisecond)
+}
+
+func TestTotalConnectDeadline_Disabled(t *testing.T) {
+	servers, createErr := createServers(2, map[int]*CreateServerParams{
+		0: {
+			ConfigCallback: func(c *Config) {
+				c.NoDiscover = true
+			},
+		},
+		1: {
+			ConfigCallback: func(c *Config) {
+				c.NoDiscover = true
+			},
+		},
+	})
+	require.NoError(t, createErr)
+
+	t.Cleanup(func() {
+		closeTestServers(t, servers)
+	})
+
+	server, target := servers[0], servers[1]
+
+	identityHandler := target.protocols[common.IdentityProto].Handler()
+	target.host.SetStreamHandler(protocol.ID(common.IdentityProto), func(stream network.Stream) {
+		time.Sleep(time.Second)
+		identityHandler(stream)
+	})
+
+	require.NoError(t, server.joinPeer(target.AddrInfo()))
+
+	// Without the deadline, the slow handshake completes
+	require.Eventually(t, func() bool {
+		return server.hasPeer(target.AddrInfo().ID)
+	}, 5*time.Second, 50*time.Millisecond)
+
+	_, _, backedOff := server.PeerBackoffInfo(target.AddrInfo().ID)
+	assert.False(t, backedOff)
+}
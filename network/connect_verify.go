@@ -0,0 +1,89 @@
+package network
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	peerEvent "github.com/0xPolygon/polygon-edge/network/event"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/libp2p/go-libp2p/core/protocol"
+)
+
+var (
+	ErrConnectFailed        = errors.New("unable to connect to peer")
+	ErrProtocolNotSupported = errors.New("peer doesn't support the required protocol")
+)
+
+// ConnectAndVerify connects to the peer, waits for the handshake to complete, and confirms
+// the peer advertises the required protocol. A peer which doesn't is disconnected.
+// A peer which is already connected is only verified [BLOCKING]
+func (s *Server) ConnectAndVerify(ctx context.Context, addr *peer.AddrInfo, requiredProto string) error {
+	if !s.hasPeer(addr.ID) {
+		if err := s.connectAndWait(ctx, addr); err != nil {
+			return err
+		}
+	}
+
+	// The protocols are known once the peer is identified, which happens before the handshake
+	supported, err := s.host.Peerstore().SupportsProtocols(addr.ID, protocol.ID(requiredProto))
+	if err != nil {
+		return fmt.Errorf("unable to read the protocols of peer %s, %w", addr.ID, err)
+	}
+
+	if len(supported) == 0 {
+		s.DisconnectFromPeer(addr.ID, "Required protocol not supported")
+
+		return fmt.Errorf("%w: peer %s, protocol %s", ErrProtocolNotSupported, addr.ID, requiredProto)
+	}
+
+	return nil
+}
+
+// connectAndWait joins the peer, and waits until the connection
+// (including the handshake) completes or fails
+func (s *Server) connectAndWait(ctx context.Context, addr *peer.AddrInfo) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	resultCh := make(chan peerEvent.PeerEventType, 1)
+
+	// Subscribe before dialing, so the outcome is not missed
+	if err := s.Subscribe(ctx, func(evnt *peerEvent.PeerEvent) {
+		if evnt.PeerID != addr.ID {
+			return
+		}
+
+		switch evnt.Type {
+		case peerEvent.PeerConnected, peerEvent.PeerFailedToConnect:
+			select {
+			case resultCh <- evnt.Type:
+			default:
+			}
+		}
+	}); err != nil {
+		return fmt.Errorf("unable to subscribe to network events, %w", err)
+	}
+
+	// The peer could have connected in the meantime
+	if s.hasPeer(addr.ID) {
+		return nil
+	}
+
+	s.AddToPeerStore(addr)
+
+	if err := s.joinPeer(addr); err != nil {
+		return err
+	}
+
+	select {
+	case result := <-resultCh:
+		if result == peerEvent.PeerFailedToConnect {
+			return fmt.Errorf("%w %s", ErrConnectFailed, addr.ID)
+		}
+
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
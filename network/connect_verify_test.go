@@ -0,0 +1,50 @@
+package network
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConnectAndVerify(t *testing.T) {
+	const requiredProto = "/required/0.1"
+
+	servers, createErr := createServers(3, map[int]*CreateServerParams{
+		0: {ConfigCallback: func(c *Config) { c.NoDiscover = true }},
+		1: {ConfigCallback: func(c *Config) { c.NoDiscover = true }},
+		2: {ConfigCallback: func(c *Config) { c.NoDiscover = true }},
+	})
+	require.NoError(t, createErr)
+
+	t.Cleanup(func() {
+		closeTestServers(t, servers)
+	})
+
+	server, supporting, lacking := servers[0], servers[1], servers[2]
+
+	supporting.RegisterProtocol(requiredProto, holdProtocol{})
+
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultJoinTimeout)
+	defer cancel()
+
+	// The peer speaking the protocol stays connected
+	require.NoError(t, server.ConnectAndVerify(ctx, supporting.AddrInfo(), requiredProto))
+	assert.True(t, server.IsConnected(supporting.host.ID()))
+
+	// An already connected peer is only verified
+	require.NoError(t, server.ConnectAndVerify(ctx, supporting.AddrInfo(), requiredProto))
+
+	// The peer lacking the protocol is disconnected
+	err := server.ConnectAndVerify(ctx, lacking.AddrInfo(), requiredProto)
+
+	require.ErrorIs(t, err, ErrProtocolNotSupported)
+	assert.Contains(t, err.Error(), lacking.host.ID().String())
+	assert.Contains(t, err.Error(), requiredProto)
+
+	_, disconnectErr := WaitUntilPeerDisconnectsFrom(ctx, server, lacking.host.ID())
+	require.NoError(t, disconnectErr)
+
+	assert.False(t, server.IsConnected(lacking.host.ID()))
+}
@@ -0,0 +1,175 @@
+package network
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/libp2p/go-libp2p/core/network"
+	"github.com/libp2p/go-libp2p/core/peer"
+)
+
+// connectionTrimInterval is the time between disconnecting
+// excess peers, after the connection limits are lowered
+const connectionTrimInterval = 200 * time.Millisecond
+
+// ErrConnLimitTooLow is returned if a connection limit is set below the minimum number of peer connections
+var ErrConnLimitTooLow = errors.New("connection limit below the minimum peer connections")
+
+// SetConnectionLimits validates and updates the maximum number of inbound and outbound connections at runtime,
+// same as UpdateConnectionLimits
+func (s *Server) SetConnectionLimits(inbound, outbound int64) error {
+	return s.UpdateConnectionLimits(inbound, outbound)
+}
+
+// UpdateConnectionLimits updates the maximum number of inbound and outbound connections at runtime,
+// e.g. on a configuration reload.
+// Neither limit can be set below the minimum number of peer connections, which the node always keeps up.
+// The limits are adjusted to the node reachability, same as the configured ones, if that is turned on.
+// If a new limit is below the current number of connections, the excess lowest-value,
+// unprotected peers are gradually disconnected to comply with it
+func (s *Server) UpdateConnectionLimits(maxInbound, maxOutbound int64) error {
+	if maxInbound < MinimumPeerConnections || maxOutbound < MinimumPeerConnections {
+		return fmt.Errorf(
+			"%w: inbound %d, outbound %d, minimum %d",
+			ErrConnLimitTooLow,
+			maxInbound,
+			maxOutbound,
+			MinimumPeerConnections,
+		)
+	}
+
+	s.connLimits.Lock()
+	defer s.connLimits.Unlock()
+
+	s.connLimits.maxInbound = maxInbound
+	s.connLimits.maxOutbound = maxOutbound
+
+	s.applyConnectionLimits(s.reachabilityConnLimits(maxInbound, maxOutbound))
+
+	return nil
+}
+
+// applyConnectionLimits sets the effective connection limits, and starts trimming
+// the excess connections if needed
+func (s *Server) applyConnectionLimits(maxInbound, maxOutbound int64) {
+	s.logger.Info(
+		"Updating connection limits",
+		"max_inbound", maxInbound,
+		"max_outbound", maxOutbound,
+	)
+
+	s.connectionCounts.SetConnLimits(maxInbound, maxOutbound)
+	s.resizeDialSlots(maxOutbound)
+
+	if s.connectionCounts.excessConnCount(network.DirInbound) > 0 ||
+		s.connectionCounts.excessConnCount(network.DirOutbound) > 0 {
+		s.startTrimming()
+	}
+}
+
+// getDialSlots returns the outbound dial slots
+func (s *Server) getDialSlots() *ResizableSlots {
+	return s.dialSlots.Load()
+}
+
+// resizeDialSlots resizes the outbound dial slots for the new limit. The taken slots
+// are kept, so a dial waiting for a slot only proceeds once one is free under the new limit
+func (s *Server) resizeDialSlots(maxOutbound int64) {
+	slots := s.dialSlots.Load()
+	if slots == nil {
+		// The dial loop is not running yet, and picks up the new limit on start
+		return
+	}
+
+	slots.Resize(maxOutbound)
+}
+
+// startTrimming starts trimming the excess connections, unless it is already in progress
+func (s *Server) startTrimming() {
+	if !s.trimming.CompareAndSwap(false, true) {
+		return
+	}
+
+	go func() {
+		defer s.trimming.Store(false)
+
+		s.trimExcessConnections()
+	}()
+}
+
+// trimExcessConnections disconnects the lowest-value, unprotected peers one by one,
+// until the connection counts comply with the limits
+func (s *Server) trimExcessConnections() {
+	trimmed := make(map[peer.ID]struct{})
+
+	for {
+		trimmedAny := false
+
+		for _, direction := range []network.Direction{network.DirInbound, network.DirOutbound} {
+			if s.connectionCounts.excessConnCount(direction) <= 0 {
+				continue
+			}
+
+			peerID, ok := s.lowestValuePeer(direction, trimmed)
+			if !ok {
+				s.logger.Warn("No unprotected peers left to trim", "direction", direction)
+
+				continue
+			}
+
+			trimmed[peerID] = struct{}{}
+			trimmedAny = true
+
+			s.DisconnectFromPeer(peerID, "connection limit lowered")
+		}
+
+		if !trimmedAny {
+			return
+		}
+
+		select {
+		case <-s.clock.After(connectionTrimInterval):
+		case <-s.closeCh:
+			return
+		}
+	}
+}
+
+// lowestValuePeer returns the least valuable, unprotected peer
+// connected in the specified direction, skipping the excluded ones
+func (s *Server) lowestValuePeer(direction network.Direction, excluded map[peer.ID]struct{}) (peer.ID, bool) {
+	candidates := make([]peer.ID, 0)
+
+	for _, peerID := range s.peersByDirection(direction) {
+		if _, ok := excluded[peerID]; ok || s.IsProtected(peerID) {
+			continue
+		}
+
+		candidates = append(candidates, peerID)
+	}
+
+	if len(candidates) == 0 {
+		return "", false
+	}
+
+	ranked := s.rankPeersByValue(candidates)
+
+	return ranked[len(ranked)-1], true
+}
+
+// peersByDirection returns the peers connected in the specified direction [Thread safe]
+func (s *Server) peersByDirection(direction network.Direction) []peer.ID {
+	s.peersLock.Lock()
+	defer s.peersLock.Unlock()
+
+	peers := make([]peer.ID, 0)
+
+	for peerID, connectionInfo := range s.peers {
+		if connectionInfo.direction == direction {
+			peers = append(peers, peerID)
+		}
+	}
+
+	return peers
+}
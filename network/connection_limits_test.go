@@ -0,0 +1,206 @@
+package network
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/0xPolygon/polygon-edge/network/common"
+	"github.com/libp2p/go-libp2p/core/network"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUpdateConnectionLimits_TrimInbound(t *testing.T) {
+	const numPeers = 4
+
+	params := make(map[int]*CreateServerParams)
+	for i := 0; i <= numPeers; i++ {
+		params[i] = &CreateServerParams{ConfigCallback: func(c *Config) {
+			c.NoDiscover = true
+		}}
+	}
+
+	servers, createErr := createServers(numPeers+1, params)
+	require.NoError(t, createErr)
+
+	t.Cleanup(func() {
+		closeTestServers(t, servers)
+	})
+
+	server, peers := servers[0], servers[1:]
+
+	// All the peers dial the server, so they are inbound peers
+	for _, peerServer := range peers {
+		require.NoError(t, JoinAndWait(peerServer, server, DefaultBufferTimeout, DefaultJoinTimeout))
+	}
+
+	require.Eventually(t, func() bool {
+		return server.connectionCounts.GetInboundConnCount() == numPeers
+	}, DefaultJoinTimeout, 50*time.Millisecond)
+
+	peerIDs := make([]peer.ID, numPeers)
+	for i, peerServer := range peers {
+		peerIDs[i] = peerServer.host.ID()
+	}
+
+	// Peer 0 is the most valuable one, and peer 3 the least valuable one, but protected
+	server.topicMembership = mockTopicMembership{
+		"txs":    {peerIDs[0], peerIDs[1], peerIDs[2]},
+		"blocks": {peerIDs[0], peerIDs[1]},
+		"votes":  {peerIDs[0]},
+	}
+	server.ProtectPeer(peerIDs[3])
+
+	require.NoError(t, server.UpdateConnectionLimits(2, 16))
+
+	assert.False(t, server.HasFreeConnectionSlot(network.DirInbound))
+	assert.Equal(t, int64(16), server.getDialSlots().Size())
+
+	require.Eventually(t, func() bool {
+		return server.connectionCounts.GetInboundConnCount() == 2
+	}, 5*time.Second, 50*time.Millisecond)
+
+	// The trimming stops once the limit is met
+	time.Sleep(3 * connectionTrimInterval)
+
+	assert.Equal(t, int64(2), server.connectionCounts.GetInboundConnCount())
+	assert.ElementsMatch(t, []peer.ID{peerIDs[0], peerIDs[3]}, server.peersByDirection(network.DirInbound))
+}
+
+func TestSetConnectionLimits(t *testing.T) {
+	const numPeers = 3
+
+	servers, createErr := createServers(numPeers+1, nil)
+	require.NoError(t, createErr)
+
+	t.Cleanup(func() {
+		closeTestServers(t, servers)
+	})
+
+	server, peers := servers[0], servers[1:]
+
+	// The server dials all the peers, so they are outbound peers
+	for _, peerServer := range peers {
+		require.NoError(t, JoinAndWait(server, peerServer, DefaultBufferTimeout, DefaultJoinTimeout))
+	}
+
+	require.Eventually(t, func() bool {
+		return server.connectionCounts.GetOutboundConnCount() == numPeers
+	}, DefaultJoinTimeout, 50*time.Millisecond)
+
+	// The limits can't go below the minimum peer connections
+	for _, limits := range [][2]int64{
+		{MinimumPeerConnections - 1, 8},
+		{8, MinimumPeerConnections - 1},
+		{-1, -1},
+	} {
+		assert.ErrorIs(t, server.SetConnectionLimits(limits[0], limits[1]), ErrConnLimitTooLow)
+	}
+
+	assert.True(t, server.HasFreeConnectionSlot(network.DirOutbound))
+
+	// The new limits are honored right away, and the excess peers are trimmed
+	require.NoError(t, server.SetConnectionLimits(4, 1))
+
+	assert.False(t, server.HasFreeConnectionSlot(network.DirOutbound))
+	assert.True(t, server.HasFreeConnectionSlot(network.DirInbound))
+
+	require.Eventually(t, func() bool {
+		return server.connectionCounts.GetOutboundConnCount() == 1
+	}, 5*time.Second, 50*time.Millisecond)
+
+	// Loosening the limits frees the slots again
+	require.NoError(t, server.SetConnectionLimits(4, 8))
+
+	assert.True(t, server.HasFreeConnectionSlot(network.DirOutbound))
+}
+
+func TestBootnodeInbound_BypassesInboundLimit(t *testing.T) {
+	bootnode, createErr := CreateServer(&CreateServerParams{
+		ConfigCallback: func(c *Config) { c.NoDiscover = true },
+	})
+	require.NoError(t, createErr)
+
+	t.Cleanup(func() {
+		assert.NoError(t, bootnode.Close())
+	})
+
+	bootnodeAddr, err := common.AddrInfoToString(bootnode.AddrInfo())
+	require.NoError(t, err)
+
+	server, createErr := CreateServer(&CreateServerParams{
+		ConfigCallback: func(c *Config) {
+			c.MaxInboundPeers = 1
+			// The bootnode is not dialed by the server, so it connects inbound
+			c.StartupDialDelay = time.Hour
+		},
+		ServerCallback: func(server *Server) {
+			server.config.Chain.Bootnodes = []string{bootnodeAddr}
+		},
+	})
+	require.NoError(t, createErr)
+
+	t.Cleanup(func() {
+		assert.NoError(t, server.Close())
+	})
+
+	peers, createErr := createServers(2, map[int]*CreateServerParams{
+		0: {ConfigCallback: func(c *Config) { c.NoDiscover = true }},
+		1: {ConfigCallback: func(c *Config) { c.NoDiscover = true }},
+	})
+	require.NoError(t, createErr)
+
+	t.Cleanup(func() {
+		closeTestServers(t, peers)
+	})
+
+	regular, excess := peers[0], peers[1]
+
+	// The regular peer takes the only inbound slot
+	require.NoError(t, JoinAndWait(regular, server, DefaultBufferTimeout, DefaultJoinTimeout))
+	require.False(t, server.HasFreeConnectionSlot(network.DirInbound))
+
+	// The bootnode connecting inbound is accepted, and evicts no one
+	require.NoError(t, JoinAndWait(bootnode, server, DefaultBufferTimeout, DefaultJoinTimeout))
+
+	assert.Never(t, func() bool {
+		return !server.hasPeer(bootnode.host.ID()) || !server.hasPeer(regular.host.ID())
+	}, time.Second, 50*time.Millisecond)
+
+	assert.ElementsMatch(
+		t,
+		[]peer.ID{regular.host.ID(), bootnode.host.ID()},
+		server.peersByDirection(network.DirInbound),
+	)
+
+	// Regular peers are still subject to the inbound limit
+	ctx, cancelFn := context.WithTimeout(context.Background(), DefaultJoinTimeout)
+	defer cancelFn()
+
+	require.NoError(t, excess.host.Connect(ctx, *server.AddrInfo()))
+
+	assert.Never(t, func() bool {
+		return server.hasPeer(excess.host.ID())
+	}, time.Second, 50*time.Millisecond)
+}
+
+func TestBootnodeInbound_Limited(t *testing.T) {
+	bootnode := &peer.AddrInfo{ID: peer.ID("bootnode")}
+	server := &Server{
+		config: &Config{},
+		bootnodes: &bootnodesWrapper{
+			bootnodeArr:  []*peer.AddrInfo{bootnode},
+			bootnodesMap: map[peer.ID]*peer.AddrInfo{bootnode.ID: bootnode},
+		},
+	}
+
+	assert.True(t, server.IsConnLimitExempt(bootnode.ID, network.DirInbound))
+	assert.False(t, server.IsConnLimitExempt(bootnode.ID, network.DirOutbound))
+	assert.False(t, server.IsConnLimitExempt(peer.ID("regular"), network.DirInbound))
+
+	server.config.LimitBootnodeInbound = true
+
+	assert.False(t, server.IsConnLimitExempt(bootnode.ID, network.DirInbound))
+}
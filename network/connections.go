@@ -97,16 +97,33 @@ func (ci *ConnectionInfo) HasFreeInboundConn() bool {
 	return ci.GetInboundConnCount()+ci.GetPendingInboundConnCount() < ci.maxInboundConnCount()
 }
 
-// maxOutboundConnCount returns the maximum number of outbound connections.
-// [Thread safe] since this value is unchanged during runtime
+// maxOutboundConnCount returns the maximum number of outbound connections [Thread safe]
 func (ci *ConnectionInfo) maxOutboundConnCount() int64 {
-	return ci.maxOutboundConnectionCount
+	return atomic.LoadInt64(&ci.maxOutboundConnectionCount)
 }
 
-// maxInboundConnCount returns the minimum number of outbound connections
-// [Thread safe] since this value is unchanged during runtime
+// maxInboundConnCount returns the maximum number of inbound connections [Thread safe]
 func (ci *ConnectionInfo) maxInboundConnCount() int64 {
-	return ci.maxInboundConnectionCount
+	return atomic.LoadInt64(&ci.maxInboundConnectionCount)
+}
+
+// SetConnLimits sets the maximum number of inbound and outbound connections [Thread safe]
+func (ci *ConnectionInfo) SetConnLimits(maxInboundConnCount, maxOutboundConnCount int64) {
+	atomic.StoreInt64(&ci.maxInboundConnectionCount, maxInboundConnCount)
+	atomic.StoreInt64(&ci.maxOutboundConnectionCount, maxOutboundConnCount)
+}
+
+// excessConnCount returns the number of active connections
+// in the specified direction above the limit [Thread safe]
+func (ci *ConnectionInfo) excessConnCount(direction network.Direction) int64 {
+	switch direction {
+	case network.DirInbound:
+		return ci.GetInboundConnCount() - ci.maxInboundConnCount()
+	case network.DirOutbound:
+		return ci.GetOutboundConnCount() - ci.maxOutboundConnCount()
+	}
+
+	return 0
 }
 
 // UpdateConnCountByDirection updates the connection count by delta
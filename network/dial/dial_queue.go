@@ -3,7 +3,9 @@ package dial
 import (
 	"container/heap"
 	"context"
+	"sort"
 	"sync"
+	"time"
 
 	"github.com/0xPolygon/polygon-edge/network/common"
 
@@ -17,18 +19,105 @@ type DialQueue struct {
 	heap  dialQueueImpl
 	tasks map[peer.ID]*DialTask
 
+	aging []AgingThreshold // the priority aging thresholds, sorted by the wait time
+	clock Clock            // the clock of the time spent in the queue
+
 	updateCh chan struct{}
 	closeCh  chan struct{}
 }
 
+// Clock is the source of the current time of the dial queue
+type Clock interface {
+	// Now returns the current time
+	Now() time.Time
+}
+
+// realClock is the Clock backed by the system time
+type realClock struct{}
+
+// Now returns the current system time
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+// Option configures a DialQueue
+type Option func(d *DialQueue)
+
+// WithClock sets the clock the time spent by the tasks in the queue is measured with
+func WithClock(clock Clock) Option {
+	return func(d *DialQueue) {
+		d.clock = clock
+	}
+}
+
 // NewDialQueue creates a new DialQueue instance
-func NewDialQueue() *DialQueue {
-	return &DialQueue{
+func NewDialQueue(opts ...Option) *DialQueue {
+	d := &DialQueue{
 		heap:     dialQueueImpl{},
 		tasks:    map[peer.ID]*DialTask{},
+		clock:    realClock{},
 		updateCh: make(chan struct{}, 1),
 		closeCh:  make(chan struct{}),
 	}
+
+	for _, opt := range opts {
+		opt(d)
+	}
+
+	return d
+}
+
+// AgingThreshold promotes the dial tasks which waited in the queue
+// for at least the wait time to at least the priority
+type AgingThreshold struct {
+	Wait     time.Duration       // the time the task has to wait in the queue for
+	Priority common.DialPriority // the priority the waiting task is promoted to
+}
+
+// SetPriorityAging sets the priority aging thresholds, so the tasks waiting long
+// are not starved by the ones of higher priority. Aging is turned off if none are set
+func (d *DialQueue) SetPriorityAging(thresholds []AgingThreshold) {
+	d.Lock()
+	defer d.Unlock()
+
+	d.aging = append([]AgingThreshold(nil), thresholds...)
+
+	sort.Slice(d.aging, func(i, j int) bool {
+		return d.aging[i].Wait < d.aging[j].Wait
+	})
+}
+
+// agedPriority returns the priority the task is promoted to after waiting for the wait time.
+// The dial queue lock has to be held
+func (d *DialQueue) agedPriority(task *DialTask, wait time.Duration) uint64 {
+	priority := task.priority
+
+	for _, threshold := range d.aging {
+		if wait < threshold.Wait {
+			break
+		}
+
+		if uint64(threshold.Priority) < priority {
+			priority = uint64(threshold.Priority)
+		}
+	}
+
+	return priority
+}
+
+// applyAging promotes the tasks which crossed the aging thresholds.
+// The dial queue lock has to be held
+func (d *DialQueue) applyAging(now time.Time) {
+	if len(d.aging) == 0 {
+		return
+	}
+
+	for _, task := range d.tasks {
+		if aged := d.agedPriority(task, now.Sub(task.enqueuedAt)); aged < task.queuePriority {
+			task.queuePriority = aged
+			heap.Fix(&d.heap, task.index)
+		}
+	}
 }
 
 // Close closes the running DialQueue
@@ -54,6 +143,8 @@ func (d *DialQueue) PopTask() *DialTask {
 	d.Lock()
 	defer d.Unlock()
 
+	d.applyAging(d.clock.Now())
+
 	if len(d.heap) != 0 {
 		// pop the first value and remove it from the heap
 		task, ok := heap.Pop(&d.heap).(*DialTask)
@@ -69,6 +160,14 @@ func (d *DialQueue) PopTask() *DialTask {
 	return nil
 }
 
+// Len returns the number of queued dial tasks
+func (d *DialQueue) Len() int {
+	d.Lock()
+	defer d.Unlock()
+
+	return len(d.heap)
+}
+
 // DeleteTask deletes a task from the dial queue for the specified peer
 func (d *DialQueue) DeleteTask(peer peer.ID) {
 	d.Lock()
@@ -101,6 +200,11 @@ func (d *DialQueue) addTaskImpl(addrInfo *peer.AddrInfo, priority common.DialPri
 		if item.priority > uint64(priority) {
 			item.addrInfo = addrInfo
 			item.priority = uint64(priority)
+
+			if item.priority < item.queuePriority {
+				item.queuePriority = item.priority
+			}
+
 			heap.Fix(&d.heap, item.index)
 
 			return true
@@ -110,8 +214,10 @@ func (d *DialQueue) addTaskImpl(addrInfo *peer.AddrInfo, priority common.DialPri
 	}
 
 	task := &DialTask{
-		addrInfo: addrInfo,
-		priority: uint64(priority),
+		addrInfo:      addrInfo,
+		priority:      uint64(priority),
+		queuePriority: uint64(priority),
+		enqueuedAt:    d.clock.Now(),
 	}
 	d.tasks[addrInfo.ID] = task
 	heap.Push(&d.heap, task)
@@ -2,9 +2,11 @@ package dial
 
 import (
 	"context"
+	"fmt"
 	"testing"
 	"time"
 
+	"github.com/0xPolygon/polygon-edge/network/common"
 	"github.com/libp2p/go-libp2p/core/peer"
 	"github.com/stretchr/testify/assert"
 )
@@ -182,3 +184,89 @@ func TestDel(t *testing.T) {
 		})
 	}
 }
+
+func TestDialQueue_EnqueuedAt(t *testing.T) {
+	q := NewDialQueue()
+	info := &peer.AddrInfo{ID: peer.ID("a")}
+
+	q.AddTask(info, 8)
+
+	enqueuedAt := q.tasks[info.ID].GetEnqueuedAt()
+	assert.False(t, enqueuedAt.IsZero())
+
+	// The priority change doesn't reset the time spent in the queue
+	q.AddTask(info, 1)
+
+	task := q.PopTask()
+	assert.Equal(t, enqueuedAt, task.GetEnqueuedAt())
+}
+
+func TestDialQueue_PriorityAging(t *testing.T) {
+	const agingWait = 50 * time.Millisecond
+
+	q := NewDialQueue()
+	q.SetPriorityAging([]AgingThreshold{
+		{Wait: 2 * agingWait, Priority: common.PriorityRequestedDial},
+		{Wait: agingWait, Priority: common.PriorityRestoredDial},
+	})
+
+	lowPriority := &peer.AddrInfo{ID: peer.ID("low")}
+	q.AddTask(lowPriority, common.PriorityRandomDial)
+
+	// A steady stream of higher priority tasks, faster than they are popped
+	deadline := time.Now().Add(20 * agingWait)
+
+	for i := 0; time.Now().Before(deadline); i++ {
+		q.AddTask(&peer.AddrInfo{ID: peer.ID(fmt.Sprintf("high-%d", 2*i))}, common.PriorityRequestedDial)
+		q.AddTask(&peer.AddrInfo{ID: peer.ID(fmt.Sprintf("high-%d", 2*i+1))}, common.PriorityRequestedDial)
+
+		task := q.PopTask()
+		if task.GetAddrInfo().ID == lowPriority.ID {
+			// The aged task is dialed, and keeps its own priority
+			assert.Equal(t, common.PriorityRandomDial, task.GetPriority())
+			assert.GreaterOrEqual(t, time.Since(task.GetEnqueuedAt()), 2*agingWait)
+
+			return
+		}
+
+		time.Sleep(agingWait / 10)
+	}
+
+	t.Fatal("low priority task starved")
+}
+
+// manualClock is a dial queue clock moved forward by the tests
+type manualClock struct {
+	now time.Time
+}
+
+func (c *manualClock) Now() time.Time {
+	return c.now
+}
+
+func TestDialQueue_WithClock(t *testing.T) {
+	const agingWait = time.Minute
+
+	clock := &manualClock{now: time.Unix(1000, 0)}
+
+	q := NewDialQueue(WithClock(clock))
+	q.SetPriorityAging([]AgingThreshold{
+		{Wait: agingWait, Priority: common.PriorityRequestedDial},
+	})
+
+	lowPriority := &peer.AddrInfo{ID: peer.ID("low")}
+	q.AddTask(lowPriority, common.PriorityRandomDial)
+
+	assert.Equal(t, clock.now, q.tasks[lowPriority.ID].GetEnqueuedAt())
+
+	// The task doesn't age before the wait elapses on the queue clock
+	clock.now = clock.now.Add(agingWait - time.Second)
+
+	q.AddTask(&peer.AddrInfo{ID: peer.ID("high-1")}, common.PriorityRequestedDial)
+	assert.Equal(t, peer.ID("high-1"), q.PopTask().GetAddrInfo().ID)
+
+	clock.now = clock.now.Add(time.Second)
+
+	q.AddTask(&peer.AddrInfo{ID: peer.ID("high-2")}, common.PriorityRequestedDial)
+	assert.Equal(t, lowPriority.ID, q.PopTask().GetAddrInfo().ID)
+}
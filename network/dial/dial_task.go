@@ -1,6 +1,11 @@
 package dial
 
-import "github.com/libp2p/go-libp2p/core/peer"
+import (
+	"time"
+
+	"github.com/0xPolygon/polygon-edge/network/common"
+	"github.com/libp2p/go-libp2p/core/peer"
+)
 
 type DialTask struct {
 	index int
@@ -10,9 +15,25 @@ type DialTask struct {
 
 	// priority of the task (the higher the better)
 	priority uint64
+
+	// priority the task is ordered by in the queue, raised over the priority by aging
+	queuePriority uint64
+
+	// time the task was added to the queue, kept when the priority changes
+	enqueuedAt time.Time
 }
 
 // GetAddrInfo returns the peer information associated with the dial
 func (dt *DialTask) GetAddrInfo() *peer.AddrInfo {
 	return dt.addrInfo
 }
+
+// GetPriority returns the priority of the dial
+func (dt *DialTask) GetPriority() common.DialPriority {
+	return common.DialPriority(dt.priority)
+}
+
+// GetEnqueuedAt returns the time the dial was added to the queue
+func (dt *DialTask) GetEnqueuedAt() time.Time {
+	return dt.enqueuedAt
+}
@@ -7,9 +7,14 @@ type dialQueueImpl []*DialTask
 // Len returns the length of the queue
 func (t dialQueueImpl) Len() int { return len(t) }
 
-// Less compares the priorities of two tasks at the passed in indexes (A < B)
+// Less compares the priorities of two tasks at the passed in indexes (A < B).
+// The tasks of the same priority are ordered by the time they were added to the queue
 func (t dialQueueImpl) Less(i, j int) bool {
-	return t[i].priority < t[j].priority
+	if t[i].queuePriority != t[j].queuePriority {
+		return t[i].queuePriority < t[j].queuePriority
+	}
+
+	return t[i].enqueuedAt.Before(t[j].enqueuedAt)
 }
 
 // Swap swaps the places of the tasks at the passed-in indexes
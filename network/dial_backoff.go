@@ -0,0 +1,122 @@
+package network
+
+import (
+	"sync"
+	"time"
+
+	"github.com/libp2p/go-libp2p/core/peer"
+)
+
+const (
+	// DefaultDialBackoffMax is the default maximum backoff after consecutive failed dials to a peer
+	DefaultDialBackoffMax = 5 * time.Minute
+
+	// maxDialBackoffRecords is the maximum number of peers whose failed dials are tracked
+	maxDialBackoffRecords = 4096
+)
+
+// dialBackoffRecord is the dial backoff state of an unreachable peer
+type dialBackoffRecord struct {
+	failures int       // the number of consecutive failed dials
+	until    time.Time // the time until which the peer is not dialed
+}
+
+// dialBackoff backs off the peers exponentially on consecutive failed dials,
+// so the unreachable peers rediscovered over and over don't take up the outbound slots.
+// The number of records is bounded, so the peers which failed a dial the longest ago are forgotten first
+type dialBackoff struct {
+	sync.Mutex
+
+	base time.Duration // the backoff after the first failure, the backoff is disabled if 0
+	max  time.Duration // the cap of the backoff
+
+	records *boundedPeerRecords // peerID -> dialBackoffRecord
+}
+
+// newDialBackoff creates a new dial backoff tracker.
+// The default cap is used for the zero value
+func newDialBackoff(base, max time.Duration) *dialBackoff {
+	if max <= 0 {
+		max = DefaultDialBackoffMax
+	}
+
+	return &dialBackoff{
+		base:    base,
+		max:     max,
+		records: newBoundedPeerRecords(maxDialBackoffRecords, nil),
+	}
+}
+
+// fail records a failed dial to the peer, and returns the backoff applied to it,
+// which doubles on every consecutive failure up to the cap [Thread safe]
+func (b *dialBackoff) fail(peerID peer.ID, now time.Time) time.Duration {
+	if b.base <= 0 {
+		return 0
+	}
+
+	b.Lock()
+	defer b.Unlock()
+
+	record := b.getLocked(peerID)
+	record.failures++
+
+	backoff := b.base
+	for i := 1; i < record.failures && backoff < b.max; i++ {
+		backoff *= 2
+	}
+
+	if backoff > b.max {
+		backoff = b.max
+	}
+
+	record.until = now.Add(backoff)
+	b.records.add(peerID, record)
+
+	return backoff
+}
+
+// reset clears the backoff of the peer [Thread safe]
+func (b *dialBackoff) reset(peerID peer.ID) {
+	b.Lock()
+	defer b.Unlock()
+
+	b.records.remove(peerID)
+}
+
+// get returns the number of consecutive failed dials to the peer,
+// and the time left until it can be dialed again, zero if it's not in a backoff [Thread safe]
+func (b *dialBackoff) get(peerID peer.ID, now time.Time) (int, time.Duration) {
+	b.Lock()
+	defer b.Unlock()
+
+	record := b.getLocked(peerID)
+	if !now.Before(record.until) {
+		return record.failures, 0
+	}
+
+	return record.failures, record.until.Sub(now)
+}
+
+// getLocked returns the dial backoff state of the peer, the zero value if there is none.
+// The lock has to be held
+func (b *dialBackoff) getLocked(peerID peer.ID) dialBackoffRecord {
+	record, _ := b.records.get(peerID)
+	state, _ := record.(dialBackoffRecord)
+
+	return state
+}
+
+// dialBackoffRemaining returns the time left until the peer can be dialed again
+// after consecutive failed dials, zero if it's not in a backoff [Thread safe]
+func (s *Server) dialBackoffRemaining(peerID peer.ID) time.Duration {
+	_, remaining := s.dialBackoff.get(peerID, s.clock.Now())
+
+	return remaining
+}
+
+// backOffFailedDial escalates the dial backoff of the peer after a failed dial [Thread safe]
+func (s *Server) backOffFailedDial(peerID peer.ID) {
+	if backoff := s.dialBackoff.fail(peerID, s.clock.Now()); backoff > 0 {
+		s.logger.Debug("Backing off the failed dials to the peer", "peer", peerID, "backoff", backoff)
+	}
+}
@@ -0,0 +1,183 @@
+package network
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/0xPolygon/polygon-edge/network/common"
+	peerEvent "github.com/0xPolygon/polygon-edge/network/event"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/multiformats/go-multiaddr"
+	manet "github.com/multiformats/go-multiaddr/net"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDialBackoff_Escalation(t *testing.T) {
+	var (
+		backoff = newDialBackoff(time.Second, 10*time.Second)
+		peerID  = peer.ID("peer")
+		now     = time.Now()
+	)
+
+	for _, expected := range []time.Duration{
+		time.Second,
+		2 * time.Second,
+		4 * time.Second,
+		8 * time.Second,
+		10 * time.Second,
+		10 * time.Second,
+	} {
+		assert.Equal(t, expected, backoff.fail(peerID, now))
+	}
+
+	failures, remaining := backoff.get(peerID, now)
+	assert.Equal(t, 6, failures)
+	assert.Equal(t, 10*time.Second, remaining)
+
+	// The backoff expires, but the failures still count
+	failures, remaining = backoff.get(peerID, now.Add(10*time.Second))
+	assert.Equal(t, 6, failures)
+	assert.Zero(t, remaining)
+
+	// A connection starts over
+	backoff.reset(peerID)
+
+	assert.Equal(t, time.Second, backoff.fail(peerID, now))
+}
+
+func TestDialBackoff_Capacity(t *testing.T) {
+	var (
+		backoff = newDialBackoff(time.Second, 10*time.Second)
+		start   = time.Now()
+	)
+
+	for i := 0; i < maxDialBackoffRecords+10; i++ {
+		backoff.fail(peer.ID(fmt.Sprintf("peer-%d", i)), start.Add(time.Duration(i)*time.Second))
+	}
+
+	// The peers which failed a dial the longest ago are dropped
+	assert.Equal(t, maxDialBackoffRecords, backoff.records.len())
+
+	failures, _ := backoff.get(peer.ID("peer-0"), start)
+	assert.Zero(t, failures)
+
+	failures, _ = backoff.get(peer.ID(fmt.Sprintf("peer-%d", maxDialBackoffRecords+9)), start)
+	assert.Equal(t, 1, failures)
+}
+
+func TestDialBackoff_Disabled(t *testing.T) {
+	for _, base := range []time.Duration{0, -1} {
+		backoff := newDialBackoff(base, 0)
+
+		assert.Zero(t, backoff.fail(peer.ID("peer"), time.Now()))
+
+		_, remaining := backoff.get(peer.ID("peer"), time.Now())
+		assert.Zero(t, remaining)
+	}
+}
+
+func TestDialBackoff_UnreachablePeer(t *testing.T) {
+	clock := newFakeClock()
+
+	server, createErr := CreateServer(&CreateServerParams{ConfigCallback: func(c *Config) {
+		c.NoDiscover = true
+		c.Clock = clock
+		c.StartupGracePeriod = 0
+		c.DialBackoffBase = time.Second
+		c.DialBackoffMax = time.Minute
+	}})
+	require.NoError(t, createErr)
+
+	t.Cleanup(func() {
+		assert.NoError(t, server.Close())
+	})
+
+	// Nothing listens on the address of the peer
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	closedAddr, err := manet.FromNetAddr(listener.Addr())
+	require.NoError(t, err)
+	require.NoError(t, listener.Close())
+
+	peers, err := generateRandomPeers(t, 1)
+	require.NoError(t, err)
+
+	unreachable := &peer.AddrInfo{ID: peers[0].peerID, Addrs: []multiaddr.Multiaddr{closedAddr}}
+
+	failedCh := make(chan struct{}, 4)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	require.NoError(t, server.Subscribe(ctx, func(evnt *peerEvent.PeerEvent) {
+		if evnt.Type == peerEvent.PeerFailedToConnect && evnt.PeerID == unreachable.ID {
+			failedCh <- struct{}{}
+		}
+	}))
+
+	dialAndFail := func() {
+		t.Helper()
+
+		require.True(t, server.addToDialQueue(unreachable, common.PriorityRandomDial, PeerSourceDiscovery))
+
+		select {
+		case <-failedCh:
+		case <-time.After(10 * time.Second):
+			t.Fatal("dial to the unreachable peer didn't fail")
+		}
+	}
+
+	// Every consecutive failure doubles the backoff
+	for _, expected := range []time.Duration{time.Second, 2 * time.Second, 4 * time.Second} {
+		dialAndFail()
+
+		assert.Equal(t, expected, server.ExplainPeer(unreachable.ID).DialBackoff)
+
+		reason, remaining, backedOff := server.PeerBackoffInfo(unreachable.ID)
+		assert.True(t, backedOff)
+		assert.Contains(t, reason, "consecutive failed dials")
+		assert.Equal(t, expected, remaining)
+
+		// The peer is not queued while it is backed off
+		assert.False(t, server.addToDialQueue(unreachable, common.PriorityRandomDial, PeerSourceDiscovery))
+
+		clock.Advance(expected)
+	}
+
+	// The pinned dials are not backed off
+	dialAndFail()
+	assert.True(t, server.addToDialQueue(unreachable, common.PriorityPinnedDial, PeerSourceDiscovery))
+}
+
+func TestDialBackoff_ResetOnConnect(t *testing.T) {
+	servers, createErr := createServers(2, map[int]*CreateServerParams{
+		0: {ConfigCallback: func(c *Config) {
+			c.NoDiscover = true
+			c.DialBackoffBase = time.Second
+		}},
+		1: {ConfigCallback: func(c *Config) { c.NoDiscover = true }},
+	})
+	require.NoError(t, createErr)
+
+	t.Cleanup(func() {
+		closeTestServers(t, servers)
+	})
+
+	server, remote := servers[0], servers[1]
+
+	server.backOffFailedDial(remote.host.ID())
+	require.Positive(t, server.dialBackoffRemaining(remote.host.ID()))
+
+	// The peer connects on its own, which proves it is reachable again
+	require.NoError(t, JoinAndWait(remote, server, DefaultBufferTimeout, DefaultJoinTimeout))
+
+	assert.Zero(t, server.dialBackoffRemaining(remote.host.ID()))
+
+	failures, _ := server.dialBackoff.get(remote.host.ID(), time.Now())
+	assert.Zero(t, failures)
+}
@@ -0,0 +1,79 @@
+package network
+
+import (
+	"context"
+	"sync"
+
+	"github.com/armon/go-metrics"
+	"github.com/libp2p/go-libp2p/core/peer"
+)
+
+// inFlightDials keeps track of the cancel functions of the dials in progress, per peer
+type inFlightDials struct {
+	sync.Mutex
+
+	nextID  uint64
+	cancels map[peer.ID]map[uint64]context.CancelFunc
+}
+
+// newInFlightDials creates a new in-flight dial tracker
+func newInFlightDials() *inFlightDials {
+	return &inFlightDials{
+		cancels: make(map[peer.ID]map[uint64]context.CancelFunc),
+	}
+}
+
+// add registers the cancel function of a dial to the peer,
+// returning the function removing it once the dial is over [Thread safe]
+func (d *inFlightDials) add(peerID peer.ID, cancel context.CancelFunc) func() {
+	d.Lock()
+	defer d.Unlock()
+
+	id := d.nextID
+	d.nextID++
+
+	if d.cancels[peerID] == nil {
+		d.cancels[peerID] = make(map[uint64]context.CancelFunc)
+	}
+
+	d.cancels[peerID][id] = cancel
+
+	return func() {
+		d.Lock()
+		defer d.Unlock()
+
+		delete(d.cancels[peerID], id)
+
+		if len(d.cancels[peerID]) == 0 {
+			delete(d.cancels, peerID)
+		}
+	}
+}
+
+// cancel cancels all the dials in progress to the peer, returning their number [Thread safe]
+func (d *inFlightDials) cancel(peerID peer.ID) int {
+	d.Lock()
+	defer d.Unlock()
+
+	cancels := d.cancels[peerID]
+
+	for _, cancel := range cancels {
+		cancel()
+	}
+
+	delete(d.cancels, peerID)
+
+	return len(cancels)
+}
+
+// CancelDials drops the queued dials to the peer, and aborts the ones in progress,
+// e.g. once the peer turned out to be unwanted. Connections already made are not affected [Thread safe]
+func (s *Server) CancelDials(peerID peer.ID) {
+	s.deleteDialTask(peerID)
+
+	if canceled := s.inFlightDials.cancel(peerID); canceled > 0 {
+		s.logger.Debug("Canceled the dials in progress", "peer", peerID, "dials", canceled)
+
+		metrics.IncrCounter([]string{networkMetrics, "canceled_dials"}, float32(canceled))
+	}
+}
@@ -0,0 +1,125 @@
+package network
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/0xPolygon/polygon-edge/network/common"
+	peerEvent "github.com/0xPolygon/polygon-edge/network/event"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/multiformats/go-multiaddr"
+	manet "github.com/multiformats/go-multiaddr/net"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCancelDials_QueuedDial(t *testing.T) {
+	server, createErr := CreateServer(&CreateServerParams{ConfigCallback: func(c *Config) {
+		c.NoDiscover = true
+		// The queued dials are held back
+		c.StartupDialDelay = DefaultJoinTimeout
+	}})
+	require.NoError(t, createErr)
+
+	t.Cleanup(func() {
+		assert.NoError(t, server.Close())
+	})
+
+	peers, err := generateRandomPeers(t, 1)
+	require.NoError(t, err)
+
+	server.addToDialQueue(
+		&peer.AddrInfo{ID: peers[0].peerID, Addrs: server.host.Addrs()},
+		common.PriorityRandomDial,
+		PeerSourcePeerstore,
+	)
+	require.Equal(t, 1, server.dialQueue.Len())
+
+	server.CancelDials(peers[0].peerID)
+
+	assert.Zero(t, server.dialQueue.Len())
+}
+
+func TestCancelDials_BanAbortsInFlightDial(t *testing.T) {
+	// The listener accepts the connections, but never completes the security handshake,
+	// so the dials to it stay in flight
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		_ = listener.Close()
+	})
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+
+			t.Cleanup(func() {
+				_ = conn.Close()
+			})
+		}
+	}()
+
+	stalledAddr, err := manet.FromNetAddr(listener.Addr())
+	require.NoError(t, err)
+
+	server, createErr := CreateServer(&CreateServerParams{ConfigCallback: func(c *Config) {
+		c.NoDiscover = true
+		c.DialRampWindow = -1
+		c.DialBackoffBase = time.Second
+	}})
+	require.NoError(t, createErr)
+
+	t.Cleanup(func() {
+		assert.NoError(t, server.Close())
+	})
+
+	peers, err := generateRandomPeers(t, 1)
+	require.NoError(t, err)
+
+	peerID := peers[0].peerID
+
+	failedCh := make(chan struct{}, 1)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	require.NoError(t, server.Subscribe(ctx, func(evnt *peerEvent.PeerEvent) {
+		if evnt.Type == peerEvent.PeerFailedToConnect && evnt.PeerID == peerID {
+			select {
+			case failedCh <- struct{}{}:
+			default:
+			}
+		}
+	}))
+
+	require.NoError(t, server.joinPeer(&peer.AddrInfo{
+		ID:    peerID,
+		Addrs: []multiaddr.Multiaddr{stalledAddr},
+	}))
+
+	require.Eventually(t, func() bool {
+		return server.InternalHealth().DialWorkers == 1
+	}, 5*time.Second, 10*time.Millisecond)
+
+	server.BanPeer(peerID, time.Minute, "test")
+
+	// The dial is aborted right away, instead of waiting for the handshake timeout
+	select {
+	case <-failedCh:
+	case <-time.After(2 * time.Second):
+		t.Fatal("in-flight dial not aborted by the ban")
+	}
+
+	require.Eventually(t, func() bool {
+		return server.InternalHealth().DialWorkers == 0
+	}, 2*time.Second, 10*time.Millisecond)
+
+	// The canceled dial is not held against the peer
+	assert.Zero(t, server.dialBackoffRemaining(peerID))
+}
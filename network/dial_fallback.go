@@ -0,0 +1,152 @@
+package network
+
+import (
+	"time"
+
+	"github.com/0xPolygon/polygon-edge/network/common"
+	"github.com/armon/go-metrics"
+)
+
+const (
+	// DefaultDialFallbackSlowInterval is the default interval of the dial fallback rounds,
+	// once the aggressive ones didn't refill the dial queue
+	DefaultDialFallbackSlowInterval = time.Minute
+
+	// dialFallbackDiscoveryRounds is the number of aggressive discovery rounds,
+	// before the known peerstore peers are re-dialed
+	dialFallbackDiscoveryRounds = 3
+)
+
+// dialFallbackStage is the escalation stage of the dial fallback
+type dialFallbackStage int
+
+const (
+	// dialFallbackDiscovery re-queries the discovery at the aggressive interval
+	dialFallbackDiscovery dialFallbackStage = iota
+
+	// dialFallbackPeerstore re-dials the known peerstore peers
+	dialFallbackPeerstore
+
+	// dialFallbackSlow does both, at the slow interval
+	dialFallbackSlow
+)
+
+// dialFallbackSlowInterval returns the configured slow dial fallback interval, or the default one
+func (s *Server) dialFallbackSlowInterval() time.Duration {
+	if s.config.DialFallbackSlowInterval > 0 {
+		return s.config.DialFallbackSlowInterval
+	}
+
+	return DefaultDialFallbackSlowInterval
+}
+
+// outboundDialTarget returns the outbound peer count the node dials towards,
+// the outbound peer target if set, or the outbound peer limit
+func (s *Server) outboundDialTarget() int64 {
+	if target := s.config.TargetOutboundPeers; target > 0 {
+		return target
+	}
+
+	return s.connectionCounts.maxOutboundConnCount()
+}
+
+// needsDialFallback checks if the node is below its outbound peer target,
+// with nothing left in the dial queue to reach it
+func (s *Server) needsDialFallback() bool {
+	return s.dialQueue.Len() == 0 &&
+		s.connectionCounts.GetOutboundConnCount() < s.outboundDialTarget()
+}
+
+// runDialFallback refills the dial queue while the node is under-connected and the queue is empty.
+// The discovery is re-queried aggressively first, and failing that, the known peerstore peers are re-dialed,
+// before the rounds settle into a slower cadence until the node recovers
+func (s *Server) runDialFallback() {
+	var (
+		stage  = dialFallbackDiscovery
+		rounds = 0
+	)
+
+	for {
+		interval := s.config.DialFallbackInterval
+		if stage == dialFallbackSlow {
+			interval = s.dialFallbackSlowInterval()
+		}
+
+		select {
+		case <-s.clock.After(interval):
+		case <-s.closeCh:
+			return
+		}
+
+		if !s.needsDialFallback() {
+			// The node recovered, or the dial queue is being worked on
+			stage, rounds = dialFallbackDiscovery, 0
+
+			continue
+		}
+
+		switch stage {
+		case dialFallbackDiscovery:
+			s.refreshDiscovery()
+
+			if rounds++; rounds >= dialFallbackDiscoveryRounds {
+				stage = dialFallbackPeerstore
+			}
+		case dialFallbackPeerstore:
+			s.redialPeerstorePeers()
+
+			stage = dialFallbackSlow
+
+			s.logger.Warn(
+				"Unable to reach the outbound peer target, retrying at a slower pace",
+				"target", s.outboundDialTarget(),
+				"interval", s.dialFallbackSlowInterval(),
+			)
+		case dialFallbackSlow:
+			s.refreshDiscovery()
+			s.redialPeerstorePeers()
+		}
+	}
+}
+
+// refreshDiscovery runs a round of the peer discovery right away, if the discovery is on
+func (s *Server) refreshDiscovery() {
+	if s.discoverer == nil {
+		return
+	}
+
+	s.logger.Debug("Dial queue is empty below the outbound peer target, re-querying the discovery")
+
+	metrics.IncrCounter([]string{networkMetrics, "dial_fallback", "discovery"}, 1)
+
+	s.discoverer.Refresh()
+}
+
+// redialPeerstorePeers queues the dials to the known unconnected peerstore peers,
+// up to the number of peers missing from the outbound peer target
+func (s *Server) redialPeerstorePeers() {
+	missing := s.outboundDialTarget() - s.connectionCounts.GetOutboundConnCount()
+	if missing <= 0 {
+		return
+	}
+
+	queued := int64(0)
+
+	for _, peerID := range s.host.Peerstore().PeersWithAddrs() {
+		if queued >= missing {
+			break
+		}
+
+		if !s.isPeerstoreDialCandidate(peerID) {
+			continue
+		}
+
+		if s.addToDialQueue(s.GetPeerInfo(peerID), common.PriorityRandomDial, PeerSourcePeerstore) {
+			queued++
+		}
+	}
+
+	s.logger.Debug("Dial queue is empty below the outbound peer target, re-dialing the known peers", "queued", queued)
+
+	metrics.IncrCounter([]string{networkMetrics, "dial_fallback", "peerstore"}, float32(queued))
+}
@@ -0,0 +1,123 @@
+package network
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/0xPolygon/polygon-edge/network/common"
+	peerEvent "github.com/0xPolygon/polygon-edge/network/event"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDialFallback_RedialsPeerstorePeers(t *testing.T) {
+	const interval = time.Second
+
+	clock := newFakeClock()
+
+	server, createErr := CreateServer(&CreateServerParams{
+		ConfigCallback: func(c *Config) {
+			c.NoDiscover = true
+			c.Clock = clock
+			c.DialFallbackInterval = interval
+		},
+	})
+	require.NoError(t, createErr)
+
+	t.Cleanup(func() {
+		assert.NoError(t, server.Close())
+	})
+
+	// The known peer is in the peerstore, but nothing queues a dial to it
+	randomPeers, err := generateRandomPeers(t, 1)
+	require.NoError(t, err)
+
+	knownPeer := randomPeers[0]
+	server.AddToPeerStore(&peer.AddrInfo{ID: knownPeer.peerID, Addrs: server.host.Addrs()})
+
+	queuedCh := make(chan struct{}, 16)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	require.NoError(t, server.Subscribe(ctx, func(evnt *peerEvent.PeerEvent) {
+		if evnt.Type == peerEvent.PeerAddedToDialQueue && evnt.PeerID == knownPeer.peerID {
+			select {
+			case queuedCh <- struct{}{}:
+			default:
+			}
+		}
+	}))
+
+	// waitRound waits for the next fallback round to be scheduled on the fake clock
+	waitRound := func() {
+		require.Eventually(t, func() bool {
+			clock.lock.Lock()
+			defer clock.lock.Unlock()
+
+			for _, waiter := range clock.waiters {
+				if waiter.deadline.Equal(clock.now.Add(interval)) {
+					return true
+				}
+			}
+
+			return false
+		}, 5*time.Second, 10*time.Millisecond)
+	}
+
+	// The aggressive discovery rounds come first
+	for i := 0; i < dialFallbackDiscoveryRounds; i++ {
+		waitRound()
+		clock.Advance(interval)
+	}
+
+	waitRound()
+
+	select {
+	case <-queuedCh:
+		t.Fatal("known peer queued before the discovery rounds ran out")
+	default:
+	}
+
+	// Failing those, the known peerstore peers are re-dialed
+	clock.Advance(interval)
+
+	select {
+	case <-queuedCh:
+	case <-time.After(5 * time.Second):
+		t.Fatal("known peer not queued by the dial fallback")
+	}
+}
+
+func TestDialFallback_NotNeededWithQueuedDials(t *testing.T) {
+	server, createErr := CreateServer(&CreateServerParams{
+		ConfigCallback: func(c *Config) {
+			c.NoDiscover = true
+			c.DialFallbackInterval = -1
+			c.StartupDialDelay = DefaultJoinTimeout
+		},
+	})
+	require.NoError(t, createErr)
+
+	t.Cleanup(func() {
+		assert.NoError(t, server.Close())
+	})
+
+	// Below the target with an empty queue
+	assert.True(t, server.needsDialFallback())
+
+	randomPeers, err := generateRandomPeers(t, 1)
+	require.NoError(t, err)
+
+	knownPeer := randomPeers[0]
+	server.addToDialQueue(
+		&peer.AddrInfo{ID: knownPeer.peerID, Addrs: server.host.Addrs()},
+		common.PriorityRandomDial,
+		PeerSourcePeerstore,
+	)
+
+	// The queued dial is yet to be made
+	assert.False(t, server.needsDialFallback())
+}
@@ -0,0 +1,159 @@
+package network
+
+import (
+	"context"
+	"time"
+
+	"github.com/libp2p/go-libp2p/core/network"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/multiformats/go-multiaddr"
+)
+
+// DialOrder defines the order in which the addresses of a peer are dialed
+type DialOrder int
+
+const (
+	// DialOrderDirectFirst dials the direct (TCP / QUIC) addresses first,
+	// and falls back to the circuit relay addresses only if they all fail
+	DialOrderDirectFirst DialOrder = iota
+
+	// DialOrderAny dials all the addresses of the peer at once,
+	// leaving the address ranking to libp2p
+	DialOrderAny
+)
+
+// String returns the string representation of the dial order
+func (o DialOrder) String() string {
+	switch o {
+	case DialOrderDirectFirst:
+		return "direct-first"
+	case DialOrderAny:
+		return "any"
+	default:
+		return "unknown"
+	}
+}
+
+// isRelayAddr checks if the address is a circuit relay address
+func isRelayAddr(addr multiaddr.Multiaddr) bool {
+	_, err := addr.ValueForProtocol(multiaddr.P_CIRCUIT)
+
+	return err == nil
+}
+
+// splitAddrsByType splits the addresses into direct and circuit relay ones,
+// preserving their relative order
+func splitAddrsByType(addrs []multiaddr.Multiaddr) (direct, relayed []multiaddr.Multiaddr) {
+	for _, addr := range addrs {
+		if isRelayAddr(addr) {
+			relayed = append(relayed, addr)
+		} else {
+			direct = append(direct, addr)
+		}
+	}
+
+	return direct, relayed
+}
+
+// dialGroup is a set of peer addresses dialed in a single attempt
+type dialGroup struct {
+	addrs       []multiaddr.Multiaddr
+	forceDirect bool // flag indicating if libp2p should skip the relay addresses of the peer
+}
+
+// dialPeer connects to the peer using the configured dial order.
+// With the direct-first order, the direct addresses of the peer (both the given
+// and the stored ones) are attempted before the circuit relay addresses,
+// so relays are preferred only when the peer can't be reached directly
+func (s *Server) dialPeer(ctx context.Context, peerInfo peer.AddrInfo) error {
+	if s.config.DialOrder == DialOrderAny {
+		return s.host.Connect(ctx, peerInfo)
+	}
+
+	direct, relayed := splitAddrsByType(
+		mergeAddrs(peerInfo.Addrs, s.host.Peerstore().Addrs(peerInfo.ID)),
+	)
+
+	if len(direct) == 0 || (len(direct) == 1 && len(relayed) == 0) {
+		// There is nothing to order
+		return s.host.Connect(ctx, peerInfo)
+	}
+
+	// Each direct address is raced on its own, so a single unresponsive address
+	// doesn't hold back the rest. Force the direct dials, otherwise libp2p dials
+	// the relay addresses already in the peer store as well
+	groups := make([]dialGroup, 0, len(direct)+1)
+	for _, addr := range direct {
+		groups = append(groups, dialGroup{addrs: []multiaddr.Multiaddr{addr}, forceDirect: true})
+	}
+
+	if len(relayed) > 0 {
+		groups = append(groups, dialGroup{addrs: relayed})
+	}
+
+	return s.dialStaggered(ctx, peerInfo.ID, groups)
+}
+
+// dialStaggered dials the address groups of the peer in the happy eyeballs fashion.
+// The addresses within a group are dialed concurrently by libp2p, while each next group
+// is dialed once the previous one fails, or once it has been pending for the dial stagger.
+// The first successful dial wins, and cancels the ones still in progress.
+// Without a dial stagger, a group is dialed only after the previous one fails
+func (s *Server) dialStaggered(ctx context.Context, peerID peer.ID, groups []dialGroup) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	results := make(chan error, len(groups))
+
+	started, pending := 0, 0
+	startNext := func() {
+		group := groups[started]
+
+		dialCtx := ctx
+		if group.forceDirect {
+			dialCtx = network.WithForceDirectDial(ctx, "prefer direct addresses")
+		}
+
+		go func() {
+			results <- s.host.Connect(dialCtx, peer.AddrInfo{ID: peerID, Addrs: group.addrs})
+		}()
+
+		started++
+		pending++
+	}
+
+	stagger := s.config.DialStagger
+
+	var lastErr error
+
+	for startNext(); pending > 0; {
+		var staggerC <-chan time.Time
+		if stagger > 0 && started < len(groups) {
+			staggerC = s.clock.After(stagger)
+		}
+
+		select {
+		case err := <-results:
+			pending--
+
+			if err == nil {
+				return nil
+			}
+
+			lastErr = err
+
+			// There is no point in dialing the next addresses once the dial is cancelled
+			if started < len(groups) && ctx.Err() == nil {
+				s.logger.Debug("Unable to dial peer, falling back to the next addresses", "peer", peerID, "err", err)
+
+				startNext()
+			}
+		case <-staggerC:
+			s.logger.Debug("Dialing peer is slow, racing the next addresses", "peer", peerID)
+
+			startNext()
+		}
+	}
+
+	return lastErr
+}
@@ -0,0 +1,303 @@
+package network
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/libp2p/go-libp2p/core/host"
+	"github.com/libp2p/go-libp2p/core/network"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/multiformats/go-multiaddr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// dialAttempt is a single recorded host.Connect call
+type dialAttempt struct {
+	addrs       []multiaddr.Multiaddr
+	forceDirect bool
+}
+
+// recordingHost is a host that records the connection attempts,
+// fails the ones for which the fail callback returns true,
+// and blocks the ones for which the hang callback returns true until they are cancelled
+type recordingHost struct {
+	host.Host
+
+	lock     sync.Mutex
+	attempts []dialAttempt
+	fail     func(attempt dialAttempt) bool
+	hang     func(attempt dialAttempt) bool
+}
+
+func (h *recordingHost) Connect(ctx context.Context, pi peer.AddrInfo) error {
+	forceDirect, _ := network.GetForceDirectDial(ctx)
+	attempt := dialAttempt{addrs: pi.Addrs, forceDirect: forceDirect}
+
+	h.lock.Lock()
+	h.attempts = append(h.attempts, attempt)
+	h.lock.Unlock()
+
+	if h.hang != nil && h.hang(attempt) {
+		<-ctx.Done()
+
+		return ctx.Err()
+	}
+
+	if h.fail(attempt) {
+		return errors.New("dial failed")
+	}
+
+	return nil
+}
+
+// getAttempts returns the recorded connection attempts
+func (h *recordingHost) getAttempts() []dialAttempt {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	return append([]dialAttempt(nil), h.attempts...)
+}
+
+func TestSplitAddrsByType(t *testing.T) {
+	addrs := []multiaddr.Multiaddr{
+		multiaddr.StringCast("/ip4/10.0.0.1/tcp/1478/p2p-circuit"),
+		multiaddr.StringCast("/ip4/10.0.0.2/tcp/1478"),
+		multiaddr.StringCast("/ip4/10.0.0.3/udp/1478/quic"),
+	}
+
+	direct, relayed := splitAddrsByType(addrs)
+
+	assert.Equal(t, []multiaddr.Multiaddr{addrs[1], addrs[2]}, direct)
+	assert.Equal(t, []multiaddr.Multiaddr{addrs[0]}, relayed)
+}
+
+func TestDialPeer_DirectFirst(t *testing.T) {
+	server, createErr := CreateServer(&CreateServerParams{ConfigCallback: func(c *Config) {
+		c.NoDiscover = true
+	}})
+	require.NoError(t, createErr)
+
+	t.Cleanup(func() {
+		assert.NoError(t, server.Close())
+	})
+
+	randomPeers, err := generateRandomPeers(t, 1)
+	require.NoError(t, err)
+
+	directAddr := multiaddr.StringCast("/ip4/10.0.0.2/tcp/1478")
+	relayAddr := multiaddr.StringCast(
+		"/ip4/10.0.0.1/tcp/1478/p2p/" + server.host.ID().String() + "/p2p-circuit",
+	)
+	peerInfo := peer.AddrInfo{
+		ID:    randomPeers[0].peerID,
+		Addrs: []multiaddr.Multiaddr{relayAddr, directAddr},
+	}
+
+	originalHost := server.host
+
+	t.Cleanup(func() {
+		server.host = originalHost
+	})
+
+	testTable := []struct {
+		name             string
+		directFails      bool
+		expectedAttempts []dialAttempt
+	}{
+		{
+			"direct dial succeeds",
+			false,
+			[]dialAttempt{
+				{addrs: []multiaddr.Multiaddr{directAddr}, forceDirect: true},
+			},
+		},
+		{
+			"fall back to relay",
+			true,
+			[]dialAttempt{
+				{addrs: []multiaddr.Multiaddr{directAddr}, forceDirect: true},
+				{addrs: []multiaddr.Multiaddr{relayAddr}, forceDirect: false},
+			},
+		},
+	}
+
+	for _, testCase := range testTable {
+		testCase := testCase
+
+		t.Run(testCase.name, func(t *testing.T) {
+			recorder := &recordingHost{
+				Host: originalHost,
+				fail: func(attempt dialAttempt) bool {
+					return attempt.forceDirect && testCase.directFails
+				},
+			}
+			server.host = recorder
+
+			assert.NoError(t, server.dialPeer(context.Background(), peerInfo))
+			assert.Equal(t, testCase.expectedAttempts, recorder.getAttempts())
+		})
+	}
+}
+
+func TestDialPeer_HappyEyeballs(t *testing.T) {
+	const stagger = 50 * time.Millisecond
+
+	testTable := []struct {
+		name        string
+		stagger     time.Duration
+		expectedErr bool
+	}{
+		{
+			"hanging direct dial is raced with relay",
+			stagger,
+			false,
+		},
+		{
+			"sequential dial waits for the direct dial",
+			0,
+			true,
+		},
+	}
+
+	for _, testCase := range testTable {
+		testCase := testCase
+
+		t.Run(testCase.name, func(t *testing.T) {
+			server, createErr := CreateServer(&CreateServerParams{ConfigCallback: func(c *Config) {
+				c.NoDiscover = true
+				c.DialStagger = testCase.stagger
+			}})
+			require.NoError(t, createErr)
+
+			t.Cleanup(func() {
+				assert.NoError(t, server.Close())
+			})
+
+			randomPeers, err := generateRandomPeers(t, 1)
+			require.NoError(t, err)
+
+			directAddr := multiaddr.StringCast("/ip4/10.0.0.2/tcp/1478")
+			relayAddr := multiaddr.StringCast(
+				"/ip4/10.0.0.1/tcp/1478/p2p/" + server.host.ID().String() + "/p2p-circuit",
+			)
+			peerInfo := peer.AddrInfo{
+				ID:    randomPeers[0].peerID,
+				Addrs: []multiaddr.Multiaddr{directAddr, relayAddr},
+			}
+
+			originalHost := server.host
+			recorder := &recordingHost{
+				Host: originalHost,
+				fail: func(dialAttempt) bool { return false },
+				// The direct address never responds
+				hang: func(attempt dialAttempt) bool { return attempt.forceDirect },
+			}
+			server.host = recorder
+
+			t.Cleanup(func() {
+				server.host = originalHost
+			})
+
+			ctx, cancel := context.WithTimeout(context.Background(), 20*stagger)
+			defer cancel()
+
+			start := time.Now()
+			err = server.dialPeer(ctx, peerInfo)
+
+			if testCase.expectedErr {
+				assert.ErrorIs(t, err, context.DeadlineExceeded)
+				assert.Equal(t, []dialAttempt{
+					{addrs: []multiaddr.Multiaddr{directAddr}, forceDirect: true},
+				}, recorder.getAttempts())
+
+				return
+			}
+
+			// The working address connects right after the stagger,
+			// without waiting for the hanging dial to time out
+			assert.NoError(t, err)
+			assert.Less(t, time.Since(start), 10*stagger)
+			assert.Equal(t, []dialAttempt{
+				{addrs: []multiaddr.Multiaddr{directAddr}, forceDirect: true},
+				{addrs: []multiaddr.Multiaddr{relayAddr}, forceDirect: false},
+			}, recorder.getAttempts())
+		})
+	}
+}
+
+func TestDialPeer_StaggersDirectAddrs(t *testing.T) {
+	const stagger = 50 * time.Millisecond
+
+	testTable := []struct {
+		name      string
+		withRelay bool
+	}{
+		{"direct-only peer", false},
+		{"peer with relay addresses", true},
+	}
+
+	for _, testCase := range testTable {
+		testCase := testCase
+
+		t.Run(testCase.name, func(t *testing.T) {
+			server, createErr := CreateServer(&CreateServerParams{ConfigCallback: func(c *Config) {
+				c.NoDiscover = true
+				c.DialStagger = stagger
+			}})
+			require.NoError(t, createErr)
+
+			t.Cleanup(func() {
+				assert.NoError(t, server.Close())
+			})
+
+			randomPeers, err := generateRandomPeers(t, 1)
+			require.NoError(t, err)
+
+			hangingAddr := multiaddr.StringCast("/ip4/10.0.0.2/tcp/1478")
+			workingAddr := multiaddr.StringCast("/ip4/10.0.0.3/tcp/1478")
+			peerInfo := peer.AddrInfo{
+				ID:    randomPeers[0].peerID,
+				Addrs: []multiaddr.Multiaddr{hangingAddr, workingAddr},
+			}
+
+			if testCase.withRelay {
+				peerInfo.Addrs = append(peerInfo.Addrs, multiaddr.StringCast(
+					"/ip4/10.0.0.1/tcp/1478/p2p/"+server.host.ID().String()+"/p2p-circuit",
+				))
+			}
+
+			originalHost := server.host
+			recorder := &recordingHost{
+				Host: originalHost,
+				fail: func(dialAttempt) bool { return false },
+				// The first direct address never responds
+				hang: func(attempt dialAttempt) bool {
+					return len(attempt.addrs) == 1 && attempt.addrs[0].Equal(hangingAddr)
+				},
+			}
+			server.host = recorder
+
+			t.Cleanup(func() {
+				server.host = originalHost
+			})
+
+			ctx, cancel := context.WithTimeout(context.Background(), 20*stagger)
+			defer cancel()
+
+			start := time.Now()
+
+			// The next direct address connects right after the stagger,
+			// without falling back to the relay
+			assert.NoError(t, server.dialPeer(ctx, peerInfo))
+			assert.Less(t, time.Since(start), 10*stagger)
+			assert.Equal(t, []dialAttempt{
+				{addrs: []multiaddr.Multiaddr{hangingAddr}, forceDirect: true},
+				{addrs: []multiaddr.Multiaddr{workingAddr}, forceDirect: true},
+			}, recorder.getAttempts())
+		})
+	}
+}
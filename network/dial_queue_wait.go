@@ -0,0 +1,54 @@
+package network
+
+import (
+	"sync"
+	"time"
+
+	"github.com/0xPolygon/polygon-edge/network/dial"
+	"github.com/armon/go-metrics"
+)
+
+// dialQueueWaitStats keeps track of the time the dials wait in the dial queue,
+// from being queued until a dialing slot is available for them
+type dialQueueWaitStats struct {
+	sync.Mutex
+
+	count int64         // the number of dials made
+	total time.Duration // the total time the dials made waited
+}
+
+// record adds the wait time of a dial [Thread safe]
+func (w *dialQueueWaitStats) record(wait time.Duration) {
+	w.Lock()
+	defer w.Unlock()
+
+	w.count++
+	w.total += wait
+}
+
+// average returns the average wait time of the dials made, 0 if none were [Thread safe]
+func (w *dialQueueWaitStats) average() time.Duration {
+	w.Lock()
+	defer w.Unlock()
+
+	if w.count == 0 {
+		return 0
+	}
+
+	return w.total / time.Duration(w.count)
+}
+
+// recordDialQueueWait records the time the dial task waited, as it is about to be dialed
+func (s *Server) recordDialQueueWait(task *dial.DialTask) {
+	wait := s.clock.Now().Sub(task.GetEnqueuedAt())
+
+	s.dialQueueWait.record(wait)
+
+	metrics.AddSample([]string{networkMetrics, "dial_queue_wait_ms"}, float32(wait.Milliseconds()))
+}
+
+// AverageDialQueueWait returns the average time the dials waited in the dial queue.
+// High wait times indicate too few outbound slots or concurrent dials [Thread safe]
+func (s *Server) AverageDialQueueWait() time.Duration {
+	return s.dialQueueWait.average()
+}
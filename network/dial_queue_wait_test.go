@@ -0,0 +1,67 @@
+package network
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDialQueueWaitStats(t *testing.T) {
+	var stats dialQueueWaitStats
+
+	assert.Zero(t, stats.average())
+
+	stats.record(time.Second)
+	stats.record(3 * time.Second)
+
+	assert.Equal(t, 2*time.Second, stats.average())
+}
+
+func TestAverageDialQueueWait(t *testing.T) {
+	const slotHoldTime = 500 * time.Millisecond
+
+	clock := newFakeClock()
+
+	servers, createErr := createServers(2, map[int]*CreateServerParams{
+		0: {ConfigCallback: func(c *Config) {
+			c.NoDiscover = true
+			c.MaxOutboundPeers = 1
+			c.Clock = clock
+		}},
+		1: {ConfigCallback: func(c *Config) { c.NoDiscover = true }},
+	})
+	require.NoError(t, createErr)
+
+	t.Cleanup(func() {
+		closeTestServers(t, servers)
+	})
+
+	server, target := servers[0], servers[1]
+
+	require.Eventually(t, func() bool {
+		return server.dialSlots.Load() != nil
+	}, DefaultJoinTimeout, 10*time.Millisecond)
+
+	assert.Zero(t, server.AverageDialQueueWait())
+
+	// Hold the only dial slot, so the queued dial waits for it
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	require.False(t, server.getDialSlots().Take(ctx))
+
+	require.NoError(t, server.joinPeer(target.AddrInfo()))
+
+	// The wait is measured on the server clock
+	clock.Advance(slotHoldTime)
+	server.getDialSlots().Release()
+
+	require.Eventually(t, func() bool {
+		return server.hasPeer(target.host.ID())
+	}, DefaultJoinTimeout, 10*time.Millisecond)
+
+	assert.Equal(t, slotHoldTime, server.AverageDialQueueWait())
+}
@@ -0,0 +1,127 @@
+package network
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultDialRampInitial is the default number of concurrent dials allowed right after start
+	DefaultDialRampInitial = 2
+
+	// DefaultDialRampWindow is the default time over which the concurrent dial limit
+	// increases from the initial one to the maximum one
+	DefaultDialRampWindow = 30 * time.Second
+
+	// dialRampCheckInterval is the time between the checks of a grown concurrent dial limit
+	dialRampCheckInterval = 100 * time.Millisecond
+
+	// DefaultMaxConcurrentDials is the default maximum number of dials in progress at the same time
+	DefaultMaxConcurrentDials = 8
+)
+
+// dialRamp limits the number of concurrent dials, starting with a low limit which
+// increases linearly to the maximum over the warm-up window, to smooth the load on startup
+type dialRamp struct {
+	sync.Mutex
+
+	initial int           // the concurrent dial limit at the start of the ramp
+	window  time.Duration // the warm-up window, the ramp is disabled if negative
+
+	clock    Clock         // the source of time for the warm-up window
+	started  time.Time     // the time the ramp started
+	inFlight int           // the number of dials in progress
+	released chan struct{} // channel signaling that a dial finished
+}
+
+// newDialRamp creates a new dial concurrency ramp
+func newDialRamp(initial int, window time.Duration, clock Clock) *dialRamp {
+	if initial <= 0 {
+		initial = DefaultDialRampInitial
+	}
+
+	if window == 0 {
+		window = DefaultDialRampWindow
+	}
+
+	return &dialRamp{
+		initial:  initial,
+		window:   window,
+		clock:    clock,
+		released: make(chan struct{}, 1),
+	}
+}
+
+// start starts the warm-up window [Thread safe]
+func (r *dialRamp) start(now time.Time) {
+	r.Lock()
+	defer r.Unlock()
+
+	r.started = now
+}
+
+// limit returns the concurrent dial limit after the elapsed time of the warm-up window
+func (r *dialRamp) limit(elapsed time.Duration, maxDials int) int {
+	if r.window <= 0 || elapsed >= r.window || r.initial >= maxDials {
+		return maxDials
+	}
+
+	if elapsed < 0 {
+		elapsed = 0
+	}
+
+	return r.initial + int(float64(maxDials-r.initial)*float64(elapsed)/float64(r.window))
+}
+
+// tryAcquire takes a dial from the current concurrent dial limit, if any is left [Thread safe]
+func (r *dialRamp) tryAcquire(now time.Time, maxDials int) bool {
+	r.Lock()
+	defer r.Unlock()
+
+	if r.inFlight >= r.limit(now.Sub(r.started), maxDials) {
+		return false
+	}
+
+	r.inFlight++
+
+	return true
+}
+
+// acquire waits until a dial is allowed by the current concurrent dial limit.
+// Returns false if the context is done first [Thread safe]
+func (r *dialRamp) acquire(ctx context.Context, maxDials int) bool {
+	for !r.tryAcquire(r.clock.Now(), maxDials) {
+		select {
+		case <-ctx.Done():
+			return false
+		case <-r.released:
+		case <-r.clock.After(dialRampCheckInterval):
+			// The limit grows over time
+		}
+	}
+
+	return true
+}
+
+// release marks a dial as finished [Thread safe]
+func (r *dialRamp) release() {
+	r.Lock()
+	r.inFlight--
+	r.Unlock()
+
+	select {
+	case r.released <- struct{}{}:
+	default:
+	}
+}
+
+// maxConcurrentDials returns the configured maximum number of dials in progress at the same time,
+// or the outbound peer limit if not set
+func (s *Server) maxConcurrentDials() int {
+	if s.config.MaxConcurrentDials > 0 {
+		return s.config.MaxConcurrentDials
+	}
+
+	return int(s.connectionCounts.maxOutboundConnCount())
+}
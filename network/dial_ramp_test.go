@@ -0,0 +1,155 @@
+package network
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/multiformats/go-multiaddr"
+	manet "github.com/multiformats/go-multiaddr/net"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDialRamp_Limit(t *testing.T) {
+	const maxDials = 12
+
+	ramp := newDialRamp(2, 10*time.Second, realClock{})
+
+	assert.Equal(t, 2, ramp.limit(0, maxDials))
+	assert.Equal(t, 7, ramp.limit(5*time.Second, maxDials))
+	assert.Equal(t, maxDials, ramp.limit(10*time.Second, maxDials))
+	assert.Equal(t, maxDials, ramp.limit(time.Minute, maxDials))
+
+	// The ramp can be turned off
+	assert.Equal(t, maxDials, newDialRamp(2, -1, realClock{}).limit(0, maxDials))
+}
+
+func TestDialRamp_Acquire(t *testing.T) {
+	const (
+		maxDials = 8
+		window   = 10 * time.Second
+	)
+
+	// countConcurrent returns the number of dials allowed concurrently at the time
+	countConcurrent := func(ramp *dialRamp, now time.Time) int {
+		count := 0
+		for ramp.tryAcquire(now, maxDials) {
+			count++
+		}
+
+		for i := 0; i < count; i++ {
+			ramp.release()
+		}
+
+		return count
+	}
+
+	start := time.Now()
+
+	ramp := newDialRamp(2, window, realClock{})
+	ramp.start(start)
+
+	early := countConcurrent(ramp, start)
+	steady := countConcurrent(ramp, start.Add(window))
+
+	assert.Equal(t, 2, early)
+	assert.Equal(t, maxDials, steady)
+	assert.Less(t, early, steady)
+
+	// Once the limit is reached, the dials wait for a running one to finish
+	require.True(t, ramp.acquire(context.Background(), maxDials))
+	require.True(t, ramp.acquire(context.Background(), maxDials))
+
+	acquiredCh := make(chan bool)
+
+	go func() {
+		acquiredCh <- ramp.acquire(context.Background(), maxDials)
+	}()
+
+	select {
+	case <-acquiredCh:
+		t.Fatal("dial allowed over the concurrent limit")
+	case <-time.After(3 * dialRampCheckInterval):
+	}
+
+	ramp.release()
+
+	select {
+	case acquired := <-acquiredCh:
+		assert.True(t, acquired)
+	case <-time.After(time.Second):
+		t.Fatal("dial not allowed after a running one finished")
+	}
+
+	// Waiting dials are abandoned once the context is done
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	assert.False(t, ramp.acquire(ctx, maxDials))
+}
+
+func TestMaxConcurrentDials(t *testing.T) {
+	const (
+		maxConcurrentDials = 2
+		numPeers           = 6
+	)
+
+	// The listener accepts the connections, but never completes the security handshake,
+	// so the dials to it stay in flight
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		_ = listener.Close()
+	})
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+
+			t.Cleanup(func() {
+				_ = conn.Close()
+			})
+		}
+	}()
+
+	stalledAddr, err := manet.FromNetAddr(listener.Addr())
+	require.NoError(t, err)
+
+	server, createErr := CreateServer(&CreateServerParams{ConfigCallback: func(c *Config) {
+		c.NoDiscover = true
+		c.MaxConcurrentDials = maxConcurrentDials
+		c.DialRampWindow = -1
+	}})
+	require.NoError(t, createErr)
+
+	peers, err := generateRandomPeers(t, numPeers)
+	require.NoError(t, err)
+
+	for _, randomPeer := range peers {
+		require.NoError(t, server.joinPeer(&peer.AddrInfo{
+			ID:    randomPeer.peerID,
+			Addrs: []multiaddr.Multiaddr{stalledAddr},
+		}))
+	}
+
+	// The dials are made concurrently, but only up to the limit
+	require.Eventually(t, func() bool {
+		return server.InternalHealth().DialWorkers == maxConcurrentDials
+	}, 5*time.Second, 10*time.Millisecond)
+
+	assert.Never(t, func() bool {
+		return server.InternalHealth().DialWorkers > maxConcurrentDials
+	}, time.Second, 10*time.Millisecond)
+
+	// Closing the server drains the in-flight dials
+	require.NoError(t, server.Close())
+
+	assert.Zero(t, server.InternalHealth().DialWorkers)
+}
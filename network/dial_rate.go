@@ -0,0 +1,155 @@
+package network
+
+import (
+	"sync"
+	"time"
+
+	"github.com/libp2p/go-libp2p/core/peer"
+)
+
+const (
+	// DefaultMaxDialsPerPeer is the default maximum number of dials to a single peer within the dial rate window
+	DefaultMaxDialsPerPeer = 5
+
+	// DefaultDialRateWindow is the default time window in which the dials to a single peer are limited
+	DefaultDialRateWindow = time.Minute
+)
+
+// dialRateLimiter limits the number of dials to a single peer within a sliding time window,
+// regardless of the code path that queued the dials
+type dialRateLimiter struct {
+	sync.Mutex
+
+	maxDials int
+	window   time.Duration
+
+	attempts  map[peer.ID][]time.Time // peerID -> times of the dials within the window
+	deferred  map[peer.ID]struct{}    // peers with a dial deferred to the end of the window
+	lastPrune time.Time               // the time the peers with no dials within the window were last removed
+}
+
+// newDialRateLimiter creates a new dial rate limiter
+func newDialRateLimiter(maxDials int, window time.Duration) *dialRateLimiter {
+	if maxDials <= 0 {
+		maxDials = DefaultMaxDialsPerPeer
+	}
+
+	if window <= 0 {
+		window = DefaultDialRateWindow
+	}
+
+	return &dialRateLimiter{
+		maxDials: maxDials,
+		window:   window,
+		attempts: make(map[peer.ID][]time.Time),
+		deferred: make(map[peer.ID]struct{}),
+	}
+}
+
+// allow records a dial to the peer at the given time if it is within the limit.
+// Otherwise, it returns the time left until the next dial is allowed [Thread safe]
+func (l *dialRateLimiter) allow(peerID peer.ID, now time.Time) (bool, time.Duration) {
+	l.Lock()
+	defer l.Unlock()
+
+	l.prune(now)
+
+	attempts := l.attempts[peerID]
+
+	// Drop the dials that are outside the window
+	for len(attempts) > 0 && now.Sub(attempts[0]) >= l.window {
+		attempts = attempts[1:]
+	}
+
+	if len(attempts) >= l.maxDials {
+		l.attempts[peerID] = attempts
+
+		return false, l.window - now.Sub(attempts[0])
+	}
+
+	if len(attempts) == 0 {
+		// Don't keep the stale backing array around
+		attempts = nil
+	}
+
+	l.attempts[peerID] = append(attempts, now)
+
+	return true, 0
+}
+
+// prune removes the peers with no dials within the window, at most once per window.
+// The lock has to be held
+func (l *dialRateLimiter) prune(now time.Time) {
+	if now.Sub(l.lastPrune) < l.window {
+		return
+	}
+
+	l.lastPrune = now
+
+	for peerID, attempts := range l.attempts {
+		if len(attempts) == 0 || now.Sub(attempts[len(attempts)-1]) >= l.window {
+			delete(l.attempts, peerID)
+		}
+	}
+}
+
+// retryAfter returns the time left until the next dial to the peer is allowed,
+// or zero if the peer can be dialed right away [Thread safe]
+func (l *dialRateLimiter) retryAfter(peerID peer.ID, now time.Time) time.Duration {
+	l.Lock()
+	defer l.Unlock()
+
+	attempts := l.attempts[peerID]
+
+	for len(attempts) > 0 && now.Sub(attempts[0]) >= l.window {
+		attempts = attempts[1:]
+	}
+
+	if len(attempts) < l.maxDials {
+		return 0
+	}
+
+	return l.window - now.Sub(attempts[0])
+}
+
+// refund removes the latest dial to the peer from the window,
+// so it doesn't count against the limit [Thread safe]
+func (l *dialRateLimiter) refund(peerID peer.ID) {
+	l.Lock()
+	defer l.Unlock()
+
+	if attempts := l.attempts[peerID]; len(attempts) > 0 {
+		l.attempts[peerID] = attempts[:len(attempts)-1]
+	}
+}
+
+// markDeferred marks the peer as having a deferred dial.
+// Returns false if a dial is already deferred for the peer [Thread safe]
+func (l *dialRateLimiter) markDeferred(peerID peer.ID) bool {
+	l.Lock()
+	defer l.Unlock()
+
+	if _, ok := l.deferred[peerID]; ok {
+		return false
+	}
+
+	l.deferred[peerID] = struct{}{}
+
+	return true
+}
+
+// clearDeferred removes the deferred dial mark of the peer [Thread safe]
+func (l *dialRateLimiter) clearDeferred(peerID peer.ID) {
+	l.Lock()
+	defer l.Unlock()
+
+	delete(l.deferred, peerID)
+}
+
+// remove removes all the records of the peer [Thread safe]
+func (l *dialRateLimiter) remove(peerID peer.ID) {
+	l.Lock()
+	defer l.Unlock()
+
+	delete(l.attempts, peerID)
+}
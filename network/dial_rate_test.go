@@ -0,0 +1,129 @@
+package network
+
+import (
+	"testing"
+	"time"
+
+	"github.com/0xPolygon/polygon-edge/network/common"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDialRateLimiter(t *testing.T) {
+	limiter := newDialRateLimiter(2, time.Hour)
+	peerID := peer.ID("RandomPeer")
+	now := time.Now()
+
+	for i := 0; i < 2; i++ {
+		ok, _ := limiter.allow(peerID, now)
+		assert.True(t, ok)
+	}
+
+	ok, retryAfter := limiter.allow(peerID, now)
+	assert.False(t, ok)
+	assert.Greater(t, retryAfter, time.Duration(0))
+	assert.LessOrEqual(t, retryAfter, time.Hour)
+
+	// Other peers are limited separately
+	ok, _ = limiter.allow(peer.ID("OtherPeer"), now)
+	assert.True(t, ok)
+
+	// Only a single dial is deferred per peer
+	assert.True(t, limiter.markDeferred(peerID))
+	assert.False(t, limiter.markDeferred(peerID))
+
+	limiter.clearDeferred(peerID)
+	assert.True(t, limiter.markDeferred(peerID))
+
+	limiter.remove(peerID)
+
+	ok, _ = limiter.allow(peerID, now)
+	assert.True(t, ok)
+}
+
+func TestDialRateLimiter_PrunesStalePeers(t *testing.T) {
+	const window = 50 * time.Millisecond
+
+	limiter := newDialRateLimiter(2, window)
+	now := time.Now()
+
+	for _, peerID := range []peer.ID{"FirstPeer", "SecondPeer"} {
+		ok, _ := limiter.allow(peerID, now)
+		assert.True(t, ok)
+	}
+
+	assert.Len(t, limiter.attempts, 2)
+
+	// The peers not dialed within the window are removed on a later dial
+	ok, _ := limiter.allow(peer.ID("ThirdPeer"), now.Add(2*window))
+	assert.True(t, ok)
+
+	assert.Len(t, limiter.attempts, 1)
+	assert.Contains(t, limiter.attempts, peer.ID("ThirdPeer"))
+}
+
+func TestDialRateLimit_MultiplePaths(t *testing.T) {
+	const (
+		maxDials = 2
+		window   = time.Second
+	)
+
+	server, createErr := CreateServer(&CreateServerParams{ConfigCallback: func(c *Config) {
+		c.NoDiscover = true
+		c.MaxDialsPerPeer = maxDials
+		c.DialRateWindow = window
+	}})
+	require.NoError(t, createErr)
+
+	originalHost := server.host
+
+	// Every dial fails, so the peer keeps getting requeued
+	recorder := &recordingHost{
+		Host: originalHost,
+		fail: func(dialAttempt) bool {
+			return true
+		},
+	}
+	server.host = recorder
+
+	t.Cleanup(func() {
+		server.host = originalHost
+
+		assert.NoError(t, server.Close())
+	})
+
+	randomPeers, err := generateRandomPeers(t, 1)
+	require.NoError(t, err)
+
+	peerInfo := &peer.AddrInfo{
+		ID:    randomPeers[0].peerID,
+		Addrs: generateTestAddrs(t, 1),
+	}
+
+	// Queue the same peer rapidly through different code paths
+	start := time.Now()
+
+	for i := 0; i < 10; i++ {
+		switch i % 3 {
+		case 0:
+			require.NoError(t, server.joinPeer(peerInfo))
+		case 1:
+			server.addToDialQueue(peerInfo, common.PriorityRandomDial, PeerSourceDiscovery)
+		case 2:
+			server.AddToPeerStore(peerInfo)
+			server.addToDialQueue(server.GetPeerInfo(peerInfo.ID), common.PriorityRandomDial, PeerSourcePeerstore)
+		}
+
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	// The dials within the window stay within the limit
+	require.Less(t, time.Since(start), window)
+	assert.Len(t, recorder.getAttempts(), maxDials)
+
+	// The excess dial is deferred, and retried once the window passes
+	assert.Eventually(t, func() bool {
+		return len(recorder.getAttempts()) == maxDials+1
+	}, 2*window, 50*time.Millisecond)
+}
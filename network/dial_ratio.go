@@ -0,0 +1,142 @@
+package network
+
+import (
+	"math"
+	"sync"
+
+	"github.com/libp2p/go-libp2p/core/event"
+	"github.com/libp2p/go-libp2p/core/network"
+)
+
+// reachabilityRatioShift is the share of the peer slots moved between
+// the inbound and outbound limits, once the node reachability is determined
+const reachabilityRatioShift = 0.1
+
+// connLimitsState keeps the configured connection limits,
+// which are adjusted to the node reachability to get the effective ones
+type connLimitsState struct {
+	sync.Mutex
+
+	maxInbound   int64                // the configured maximum number of inbound connections
+	maxOutbound  int64                // the configured maximum number of outbound connections
+	reachability network.Reachability // the node reachability, as determined by AutoNAT
+}
+
+// DialRatio returns the effective share of the peer slots used for outbound connections.
+// With the reachability dial ratio turned on, it is higher for privately reachable nodes,
+// which peers can't dial, and lower for publicly reachable ones [Thread safe]
+func (s *Server) DialRatio() float64 {
+	maxInbound := s.connectionCounts.maxInboundConnCount()
+	maxOutbound := s.connectionCounts.maxOutboundConnCount()
+
+	if maxInbound+maxOutbound <= 0 {
+		return 0
+	}
+
+	return float64(maxOutbound) / float64(maxInbound+maxOutbound)
+}
+
+// Reachability returns the node reachability, as determined by AutoNAT [Thread safe]
+func (s *Server) Reachability() network.Reachability {
+	s.connLimits.Lock()
+	defer s.connLimits.Unlock()
+
+	return s.connLimits.reachability
+}
+
+// reachabilityConnLimits returns the effective connection limits for the node reachability.
+// The configured limits are kept as they are, unless the reachability dial ratio is turned on
+func (s *Server) reachabilityConnLimits(maxInbound, maxOutbound int64) (int64, int64) {
+	if !s.config.EnableReachabilityDialRatio {
+		return maxInbound, maxOutbound
+	}
+
+	return effectiveConnLimits(maxInbound, maxOutbound, s.connLimits.reachability)
+}
+
+// effectiveConnLimits shifts the connection limits toward outbound connections for privately
+// reachable nodes, and toward inbound connections for publicly reachable ones.
+// Limits which rule out a direction entirely are kept as they are
+func effectiveConnLimits(
+	maxInbound,
+	maxOutbound int64,
+	reachability network.Reachability,
+) (int64, int64) {
+	if maxInbound <= 0 || maxOutbound <= 0 {
+		return maxInbound, maxOutbound
+	}
+
+	shift := int64(math.Ceil(float64(maxInbound+maxOutbound) * reachabilityRatioShift))
+
+	switch reachability {
+	case network.ReachabilityPrivate:
+		if shift > maxInbound {
+			shift = maxInbound
+		}
+
+		return maxInbound - shift, maxOutbound + shift
+	case network.ReachabilityPublic:
+		// The node keeps dialing at least the minimum number of peers
+		if shift > maxOutbound-MinimumPeerConnections {
+			shift = maxOutbound - MinimumPeerConnections
+		}
+
+		return maxInbound + shift, maxOutbound - shift
+	default:
+		return maxInbound, maxOutbound
+	}
+}
+
+// setReachability records the new node reachability, and adjusts the effective
+// connection limits to it if the reachability dial ratio is turned on
+func (s *Server) setReachability(reachability network.Reachability) {
+	s.connLimits.Lock()
+	defer s.connLimits.Unlock()
+
+	if s.connLimits.reachability == reachability {
+		return
+	}
+
+	s.logger.Info("Node reachability changed", "reachability", reachability)
+
+	s.connLimits.reachability = reachability
+
+	if !s.config.EnableReachabilityDialRatio {
+		return
+	}
+
+	s.applyConnectionLimits(s.reachabilityConnLimits(s.connLimits.maxInbound, s.connLimits.maxOutbound))
+}
+
+// watchReachability adjusts the connection limits whenever AutoNAT determines
+// a different reachability of the node
+func (s *Server) watchReachability() error {
+	sub, err := s.host.EventBus().Subscribe(new(event.EvtLocalReachabilityChanged))
+	if err != nil {
+		return err
+	}
+
+	done := s.health.subscriptions.start()
+
+	go func() {
+		defer done()
+		defer sub.Close()
+
+		for {
+			select {
+			case <-s.closeCh:
+				return
+			case evnt, ok := <-sub.Out():
+				if !ok {
+					return
+				}
+
+				if changed, ok := evnt.(event.EvtLocalReachabilityChanged); ok {
+					s.setReachability(changed.Reachability)
+				}
+			}
+		}
+	}()
+
+	return nil
+}
@@ -0,0 +1,119 @@
+package network
+
+import (
+	"testing"
+	"time"
+
+	"github.com/libp2p/go-libp2p/core/event"
+	"github.com/libp2p/go-libp2p/core/network"
+	"github.com/libp2p/go-libp2p/p2p/host/eventbus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEffectiveConnLimits(t *testing.T) {
+	testTable := []struct {
+		name         string
+		maxInbound   int64
+		maxOutbound  int64
+		reachability network.Reachability
+		expInbound   int64
+		expOutbound  int64
+	}{
+		{"unknown", 32, 8, network.ReachabilityUnknown, 32, 8},
+		{"private", 32, 8, network.ReachabilityPrivate, 28, 12},
+		{"public", 32, 8, network.ReachabilityPublic, 36, 4},
+		{"public keeps an outbound slot", 10, 1, network.ReachabilityPublic, 10, 1},
+		{"private without inbound", 0, 8, network.ReachabilityPrivate, 0, 8},
+	}
+
+	for _, testCase := range testTable {
+		testCase := testCase
+
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			maxInbound, maxOutbound := effectiveConnLimits(
+				testCase.maxInbound,
+				testCase.maxOutbound,
+				testCase.reachability,
+			)
+
+			assert.Equal(t, testCase.expInbound, maxInbound)
+			assert.Equal(t, testCase.expOutbound, maxOutbound)
+		})
+	}
+}
+
+func TestDialRatio_ReachabilityChange(t *testing.T) {
+	server, createErr := CreateServer(&CreateServerParams{ConfigCallback: func(c *Config) {
+		c.NoDiscover = true
+		c.EnableReachabilityDialRatio = true
+	}})
+	require.NoError(t, createErr)
+
+	t.Cleanup(func() {
+		require.NoError(t, server.Close())
+	})
+
+	baseRatio := server.DialRatio()
+
+	emitter, err := server.host.EventBus().Emitter(new(event.EvtLocalReachabilityChanged), eventbus.Stateful)
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		_ = emitter.Close()
+	})
+
+	// A private node relies more on its outbound connections
+	require.NoError(t, emitter.Emit(event.EvtLocalReachabilityChanged{Reachability: network.ReachabilityPrivate}))
+
+	require.Eventually(t, func() bool {
+		return server.DialRatio() > baseRatio
+	}, 5*time.Second, 50*time.Millisecond)
+
+	// A public node leaves more of the slots to the peers dialing it
+	require.NoError(t, emitter.Emit(event.EvtLocalReachabilityChanged{Reachability: network.ReachabilityPublic}))
+
+	require.Eventually(t, func() bool {
+		return server.DialRatio() < baseRatio
+	}, 5*time.Second, 50*time.Millisecond)
+
+	assert.Equal(t, network.ReachabilityPublic, server.Reachability())
+
+	// The configured limits are kept as the base for the adjustment
+	require.NoError(t, server.UpdateConnectionLimits(32, 8))
+	assert.Equal(t, int64(4), server.connectionCounts.maxOutboundConnCount())
+}
+
+func TestDialRatio_OffByDefault(t *testing.T) {
+	server, createErr := CreateServer(&CreateServerParams{ConfigCallback: func(c *Config) {
+		c.NoDiscover = true
+	}})
+	require.NoError(t, createErr)
+
+	t.Cleanup(func() {
+		require.NoError(t, server.Close())
+	})
+
+	maxOutbound := server.connectionCounts.maxOutboundConnCount()
+
+	emitter, err := server.host.EventBus().Emitter(new(event.EvtLocalReachabilityChanged), eventbus.Stateful)
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		_ = emitter.Close()
+	})
+
+	// The reachability is recorded, but the configured limits are kept
+	require.NoError(t, emitter.Emit(event.EvtLocalReachabilityChanged{Reachability: network.ReachabilityPublic}))
+
+	require.Eventually(t, func() bool {
+		return server.Reachability() == network.ReachabilityPublic
+	}, 5*time.Second, 50*time.Millisecond)
+
+	assert.Equal(t, maxOutbound, server.connectionCounts.maxOutboundConnCount())
+
+	require.NoError(t, server.UpdateConnectionLimits(32, 8))
+	assert.Equal(t, int64(8), server.connectionCounts.maxOutboundConnCount())
+}
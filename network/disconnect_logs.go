@@ -0,0 +1,136 @@
+package network
+
+import (
+	"sync"
+	"time"
+
+	"github.com/libp2p/go-libp2p/core/peer"
+)
+
+// DefaultDisconnectLogWindow is the default window in which
+// the identical disconnects of a peer are logged only once
+const DefaultDisconnectLogWindow = 10 * time.Second
+
+// disconnectLogKey identifies the disconnects which are logged together
+type disconnectLogKey struct {
+	peerID peer.ID
+	reason string
+}
+
+// disconnectLogEntry is the log state of the identical disconnects of a peer
+type disconnectLogEntry struct {
+	loggedAt   time.Time // the time the disconnect was last logged
+	suppressed int       // the number of disconnects not logged since
+}
+
+// suppressedDisconnects are the identical disconnects of a peer that were not logged
+type suppressedDisconnects struct {
+	peerID peer.ID
+	reason string
+	count  int
+}
+
+// disconnectLogLimiter coalesces the identical disconnects of a peer within a window into
+// a single log line, so a flapping peer doesn't flood the logs. The disconnects left out
+// are counted, and reported with the next logged one, or once the entry expires
+type disconnectLogLimiter struct {
+	sync.Mutex
+
+	window  time.Duration
+	entries map[disconnectLogKey]*disconnectLogEntry
+}
+
+// newDisconnectLogLimiter creates a new disconnect log limiter, which logs every disconnect if the window is negative
+func newDisconnectLogLimiter(window time.Duration) *disconnectLogLimiter {
+	if window == 0 {
+		window = DefaultDisconnectLogWindow
+	}
+
+	return &disconnectLogLimiter{
+		window:  window,
+		entries: make(map[disconnectLogKey]*disconnectLogEntry),
+	}
+}
+
+// allow checks if the disconnect is to be logged, returning the number of the identical disconnects
+// left out since it was last logged. The expired entries are pruned, and the ones with disconnects
+// left out are returned, so they can be reported [Thread safe]
+func (l *disconnectLogLimiter) allow(
+	peerID peer.ID,
+	reason string,
+	now time.Time,
+) (bool, int, []suppressedDisconnects) {
+	if l.window < 0 {
+		return true, 0, nil
+	}
+
+	l.Lock()
+	defer l.Unlock()
+
+	key := disconnectLogKey{peerID: peerID, reason: reason}
+
+	if entry, ok := l.entries[key]; ok && now.Sub(entry.loggedAt) < l.window {
+		entry.suppressed++
+
+		return false, 0, nil
+	}
+
+	suppressed := 0
+	if entry, ok := l.entries[key]; ok {
+		suppressed = entry.suppressed
+	}
+
+	l.entries[key] = &disconnectLogEntry{loggedAt: now}
+
+	return true, suppressed, l.prune(now)
+}
+
+// prune removes the expired entries, returning the ones with disconnects left out
+func (l *disconnectLogLimiter) prune(now time.Time) []suppressedDisconnects {
+	var expired []suppressedDisconnects
+
+	for key, entry := range l.entries {
+		if now.Sub(entry.loggedAt) < l.window {
+			continue
+		}
+
+		if entry.suppressed > 0 {
+			expired = append(expired, suppressedDisconnects{
+				peerID: key.peerID,
+				reason: key.reason,
+				count:  entry.suppressed,
+			})
+		}
+
+		delete(l.entries, key)
+	}
+
+	return expired
+}
+
+// logDisconnect logs closing the connection to the peer,
+// coalescing the identical disconnects within the log window
+func (s *Server) logDisconnect(peerID peer.ID, reason string) {
+	logged, suppressed, expired := s.disconnectLogs.allow(peerID, reason, s.clock.Now())
+
+	for _, entry := range expired {
+		s.logger.Info(
+			s.peerLogMsg("Connection closed repeatedly", entry.peerID),
+			"id", entry.peerID,
+			"reason", entry.reason,
+			"repeated", entry.count,
+		)
+	}
+
+	if !logged {
+		return
+	}
+
+	if suppressed > 0 {
+		s.logger.Info(s.peerLogMsg("Closing connection", peerID), "id", peerID, "reason", reason, "repeated", suppressed)
+
+		return
+	}
+
+	s.logger.Info(s.peerLogMsg("Closing connection", peerID), "id", peerID, "reason", reason)
+}
@@ -0,0 +1,126 @@
+package network
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDisconnectLogLimiter(t *testing.T) {
+	const window = time.Minute
+
+	peers, err := generateRandomPeers(t, 2)
+	require.NoError(t, err)
+
+	flapping, other := peers[0].peerID, peers[1].peerID
+
+	limiter := newDisconnectLogLimiter(window)
+	now := time.Now()
+
+	logged, suppressed, _ := limiter.allow(flapping, "reason", now)
+	assert.True(t, logged)
+	assert.Zero(t, suppressed)
+
+	// The identical disconnects within the window are left out
+	for i := 0; i < 5; i++ {
+		logged, _, _ = limiter.allow(flapping, "reason", now.Add(time.Second))
+		assert.False(t, logged)
+	}
+
+	// Other reasons and peers are logged separately
+	logged, _, _ = limiter.allow(flapping, "other reason", now)
+	assert.True(t, logged)
+
+	logged, _, _ = limiter.allow(other, "reason", now)
+	assert.True(t, logged)
+
+	// Once the window passes, the disconnects left out are reported with the next one
+	logged, suppressed, _ = limiter.allow(flapping, "reason", now.Add(window))
+	assert.True(t, logged)
+	assert.Equal(t, 5, suppressed)
+
+	// The expired entries with disconnects left out are reported, even without a next one
+	_, _, _ = limiter.allow(flapping, "reason", now.Add(window+time.Second))
+
+	_, _, expired := limiter.allow(other, "reason", now.Add(3*window))
+	require.Len(t, expired, 1)
+	assert.Equal(t, suppressedDisconnects{peerID: flapping, reason: "reason", count: 1}, expired[0])
+
+	// A negative window turns the coalescing off
+	disabled := newDisconnectLogLimiter(-1)
+
+	for i := 0; i < 3; i++ {
+		logged, _, _ = disabled.allow(flapping, "reason", now)
+		assert.True(t, logged)
+	}
+}
+
+func TestDisconnectLogs_Coalesced(t *testing.T) {
+	const flaps = 4
+
+	output := &syncBuffer{}
+	clock := newFakeClock()
+
+	servers, createErr := createServers(2, map[int]*CreateServerParams{
+		0: {
+			ConfigCallback: func(c *Config) {
+				c.NoDiscover = true
+				c.Clock = clock
+				c.MaxDialsPerPeer = 100
+			},
+			Logger: hclog.New(&hclog.LoggerOptions{Output: output, Level: hclog.Info}),
+		},
+		1: {ConfigCallback: func(c *Config) { c.NoDiscover = true }},
+	})
+	require.NoError(t, createErr)
+
+	t.Cleanup(func() {
+		closeTestServers(t, servers)
+	})
+
+	server, flapping := servers[0], servers[1]
+
+	flap := func() {
+		require.NoError(t, JoinAndWait(server, flapping, DefaultBufferTimeout, DefaultJoinTimeout))
+
+		server.DisconnectFromPeer(flapping.host.ID(), "flapping")
+
+		ctx, cancel := context.WithTimeout(context.Background(), DefaultJoinTimeout)
+		defer cancel()
+
+		_, err := WaitUntilPeerDisconnectsFrom(ctx, server, flapping.host.ID())
+		require.NoError(t, err)
+	}
+
+	closingLines := func() []string {
+		lines := make([]string, 0)
+
+		for _, line := range strings.Split(output.String(), "\n") {
+			if strings.Contains(line, "Closing connection") && strings.Contains(line, "reason=flapping") {
+				lines = append(lines, line)
+			}
+		}
+
+		return lines
+	}
+
+	for i := 0; i < flaps; i++ {
+		flap()
+	}
+
+	// Only the first disconnect is logged within the window
+	require.Len(t, closingLines(), 1)
+
+	// The next one after the window carries the count of the ones left out
+	clock.Advance(DefaultDisconnectLogWindow)
+	flap()
+
+	lines := closingLines()
+	require.Len(t, lines, 2)
+	assert.Contains(t, lines[1], "repeated=3")
+}
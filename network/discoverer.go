@@ -0,0 +1,64 @@
+package network
+
+import (
+	"github.com/0xPolygon/polygon-edge/network/common"
+	"github.com/libp2p/go-libp2p/core/peer"
+)
+
+// Discoverer is a peer discovery backend (e.g. Kademlia, a rendezvous server, a static registry).
+// The backend only finds the peers, and feeds them to the networking server,
+// which takes care of the dialing, the connection limits and the peer vetting
+type Discoverer interface {
+	// Start starts discovering peers, feeding the found ones to the networking server
+	Start(feed DiscoveryFeed) error
+
+	// Refresh runs a discovery round right away, e.g. once the node is under-connected
+	Refresh()
+
+	// Close stops discovering peers
+	Close()
+}
+
+// DiscoveryFeed stores the discovered peer, and queues a dial to it [Thread safe]
+type DiscoveryFeed func(peerInfo *peer.AddrInfo)
+
+// kademliaDiscoverer is the default discovery backend,
+// crawling the Kademlia routing tables of the connected peers and bootnodes
+type kademliaDiscoverer struct {
+	server *Server
+}
+
+// Start sets up the discovery service. The peers added to its routing table
+// are queued for dialing by the table itself, so the feed is not used
+func (k *kademliaDiscoverer) Start(_ DiscoveryFeed) error {
+	return k.server.setupDiscovery()
+}
+
+// Refresh runs a round of the peer and bootnode discovery right away
+func (k *kademliaDiscoverer) Refresh() {
+	k.server.discovery.Refresh()
+}
+
+// Close stops the discovery service
+func (k *kademliaDiscoverer) Close() {
+	k.server.discovery.Close()
+}
+
+// discoveryBackend returns the configured discovery backend, or the Kademlia one
+func (s *Server) discoveryBackend() Discoverer {
+	if s.config.DiscoveryBackend != nil {
+		return s.config.DiscoveryBackend
+	}
+
+	return &kademliaDiscoverer{server: s}
+}
+
+// feedDiscoveredPeer stores the peer found by the discovery backend, and queues a dial to it
+func (s *Server) feedDiscoveredPeer(peerInfo *peer.AddrInfo) {
+	if peerInfo == nil || peerInfo.ID == s.host.ID() || s.IsConnected(peerInfo.ID) {
+		return
+	}
+
+	s.AddToPeerStore(peerInfo)
+	s.addToDialQueue(peerInfo, common.PriorityRandomDial, s.discoveredPeerSource(peerInfo.ID))
+}
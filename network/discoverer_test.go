@@ -0,0 +1,78 @@
+package network
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stubDiscoverer is a discovery backend which finds the peers it is told to
+type stubDiscoverer struct {
+	feedCh    chan DiscoveryFeed
+	refreshes atomic.Int64
+	closed    atomic.Bool
+}
+
+func (d *stubDiscoverer) Start(feed DiscoveryFeed) error {
+	d.feedCh <- feed
+
+	return nil
+}
+
+func (d *stubDiscoverer) Refresh() {
+	d.refreshes.Add(1)
+}
+
+func (d *stubDiscoverer) Close() {
+	d.closed.Store(true)
+}
+
+func TestDiscoveryBackend_Custom(t *testing.T) {
+	discoverer := &stubDiscoverer{feedCh: make(chan DiscoveryFeed, 1)}
+
+	server, createErr := CreateServer(&CreateServerParams{ConfigCallback: func(c *Config) {
+		c.DiscoveryBackend = discoverer
+	}})
+	require.NoError(t, createErr)
+
+	discovered, createErr := CreateServer(&CreateServerParams{ConfigCallback: func(c *Config) {
+		c.NoDiscover = true
+	}})
+	require.NoError(t, createErr)
+
+	t.Cleanup(func() {
+		assert.NoError(t, discovered.Close())
+	})
+
+	var feed DiscoveryFeed
+
+	select {
+	case feed = <-discoverer.feedCh:
+	case <-time.After(5 * time.Second):
+		t.Fatal("discovery backend not started")
+	}
+
+	// The custom backend replaces the Kademlia one
+	assert.Nil(t, server.discovery)
+
+	// The fed peers are dialed
+	feed(&peer.AddrInfo{ID: discovered.host.ID(), Addrs: discovered.host.Addrs()})
+
+	require.Eventually(t, func() bool {
+		return server.hasPeer(discovered.host.ID())
+	}, DefaultJoinTimeout, 50*time.Millisecond)
+
+	assert.Equal(t, PeerSourceDiscovery, server.getPeerSource(discovered.host.ID()))
+
+	// The discovery refreshes reach the backend
+	server.refreshDiscovery()
+	assert.Equal(t, int64(1), discoverer.refreshes.Load())
+
+	// The backend is stopped along with the server
+	require.NoError(t, server.Close())
+	assert.True(t, discoverer.closed.Load())
+}
@@ -4,10 +4,12 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"sync/atomic"
 	"time"
 
 	"github.com/0xPolygon/polygon-edge/network/common"
 	"github.com/0xPolygon/polygon-edge/network/event"
+	"github.com/armon/go-metrics"
 	"github.com/hashicorp/go-hclog"
 	"github.com/libp2p/go-libp2p/core/network"
 
@@ -29,6 +31,15 @@ const (
 	// bootnodeDiscoveryInterval is the interval at which
 	// random bootnodes are dialed for their peer sets
 	bootnodeDiscoveryInterval = 60 * time.Second
+
+	// findPeerQueryCount is the number of nearest peers
+	// queried when looking up the addresses of a single peer
+	findPeerQueryCount = 3
+)
+
+var (
+	ErrPeerNotFound    = errors.New("peer not found")
+	ErrDiscoveryClosed = errors.New("discovery service is closed")
 )
 
 // networkingServer defines the base communication interface between
@@ -94,19 +105,32 @@ type DiscoveryService struct {
 	logger       hclog.Logger     // The DiscoveryService logger
 	routingTable *kb.RoutingTable // Kademlia 'k-bucket' routing table that contains connected nodes info
 
+	serveDisabled atomic.Bool // Flag indicating if the discovery queries of other peers are answered empty
+
+	querySlots      chan struct{} // Semaphore bounding the concurrent outbound queries, unlimited if nil
+	queriesInFlight atomic.Int64  // The number of outbound queries in flight
+
 	closeCh chan struct{} // Channel used for stopping the DiscoveryService
 }
 
-// NewDiscoveryService creates a new instance of the discovery service
+// NewDiscoveryService creates a new instance of the discovery service.
+// The excess outbound queries over the concurrency limit are queued, unlimited if 0
 func NewDiscoveryService(
 	server networkingServer,
 	routingTable *kb.RoutingTable,
 	logger hclog.Logger,
+	maxConcurrentQueries int,
 ) *DiscoveryService {
+	var querySlots chan struct{}
+	if maxConcurrentQueries > 0 {
+		querySlots = make(chan struct{}, maxConcurrentQueries)
+	}
+
 	return &DiscoveryService{
 		logger:       logger.Named("discovery"),
 		baseServer:   server,
 		routingTable: routingTable,
+		querySlots:   querySlots,
 		closeCh:      make(chan struct{}),
 	}
 }
@@ -121,6 +145,38 @@ func (d *DiscoveryService) Close() {
 	close(d.closeCh)
 }
 
+// QueriesInFlight returns the number of outbound discovery queries in flight [Thread safe]
+func (d *DiscoveryService) QueriesInFlight() int64 {
+	return d.queriesInFlight.Load()
+}
+
+// acquireQuery waits for a free outbound query slot.
+// Every successful call has to be followed by a releaseQuery call
+func (d *DiscoveryService) acquireQuery(ctx context.Context) error {
+	if d.querySlots != nil {
+		select {
+		case d.querySlots <- struct{}{}:
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-d.closeCh:
+			return ErrDiscoveryClosed
+		}
+	}
+
+	metrics.SetGauge([]string{"network", "discovery_queries_in_flight"}, float32(d.queriesInFlight.Add(1)))
+
+	return nil
+}
+
+// releaseQuery frees the outbound query slot taken by acquireQuery
+func (d *DiscoveryService) releaseQuery() {
+	metrics.SetGauge([]string{"network", "discovery_queries_in_flight"}, float32(d.queriesInFlight.Add(-1)))
+
+	if d.querySlots != nil {
+		<-d.querySlots
+	}
+}
+
 // RoutingTableSize returns the size of the routing table
 func (d *DiscoveryService) RoutingTableSize() int {
 	return d.routingTable.Size()
@@ -131,6 +187,12 @@ func (d *DiscoveryService) RoutingTablePeers() []peer.ID {
 	return d.routingTable.ListPeers()
 }
 
+// NearestPeers fetches at most count routing table peers,
+// ordered by their distance to the target peer
+func (d *DiscoveryService) NearestPeers(target peer.ID, count int) []peer.ID {
+	return d.routingTable.NearestPeers(kb.ConvertPeerID(target), count)
+}
+
 // HandleNetworkEvent handles base network events for the DiscoveryService
 func (d *DiscoveryService) HandleNetworkEvent(peerEvent *event.PeerEvent) {
 	peerID := peerEvent.PeerID
@@ -237,6 +299,12 @@ func (d *DiscoveryService) findPeersCall(
 	peerID peer.ID,
 	shouldCloseConn bool,
 ) ([]string, error) {
+	if err := d.acquireQuery(context.Background()); err != nil {
+		return nil, err
+	}
+
+	defer d.releaseQuery()
+
 	clt, clientErr := d.baseServer.NewDiscoveryClient(peerID)
 	if clientErr != nil {
 		return nil, fmt.Errorf("unable to create new discovery client connection, %w", clientErr)
@@ -252,6 +320,11 @@ func (d *DiscoveryService) findPeersCall(
 		return nil, err
 	}
 
+	// The peer is not trusted to respect the requested count
+	if len(resp.Nodes) > maxDiscoveryPeerReqCount {
+		resp.Nodes = resp.Nodes[:maxDiscoveryPeerReqCount]
+	}
+
 	// Check if the connection should be closed after getting the data
 	if shouldCloseConn {
 		if closeErr := d.baseServer.CloseProtocolStream(common.DiscProto, peerID); closeErr != nil {
@@ -262,6 +335,76 @@ func (d *DiscoveryService) findPeersCall(
 	return resp.Nodes, nil
 }
 
+// FindPeer looks up the addresses of the specified peer, by querying
+// the connected peers that are nearest to it [BLOCKING]
+func (d *DiscoveryService) FindPeer(ctx context.Context, peerID peer.ID) (*peer.AddrInfo, error) {
+	// The peer might already be known locally
+	if info := d.baseServer.GetPeerInfo(peerID); info != nil && len(info.Addrs) > 0 {
+		return info, nil
+	}
+
+	nearestPeers := d.routingTable.NearestPeers(
+		kb.ConvertPeerID(peerID),
+		findPeerQueryCount,
+	)
+
+	for _, nearestPeer := range nearestPeers {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		resp, err := d.findPeerCall(ctx, nearestPeer, peerID)
+		if err != nil {
+			d.logger.Debug("unable to query peer", "peer", nearestPeer, "err", err)
+
+			continue
+		}
+
+		for _, nodeAddrStr := range resp.Nodes {
+			nodeInfo, err := common.StringToAddrInfo(nodeAddrStr)
+			if err != nil {
+				continue
+			}
+
+			if nodeInfo.ID == peerID {
+				return nodeInfo, nil
+			}
+		}
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	return nil, ErrPeerNotFound
+}
+
+// findPeerCall queries the set peer for the peers nearest to the target peer
+func (d *DiscoveryService) findPeerCall(
+	ctx context.Context,
+	peerID peer.ID,
+	target peer.ID,
+) (*proto.FindPeersResp, error) {
+	if err := d.acquireQuery(ctx); err != nil {
+		return nil, err
+	}
+
+	defer d.releaseQuery()
+
+	clt, clientErr := d.baseServer.NewDiscoveryClient(peerID)
+	if clientErr != nil {
+		return nil, fmt.Errorf("unable to create new discovery client connection, %w", clientErr)
+	}
+
+	return clt.FindPeers(
+		ctx,
+		&proto.FindPeersReq{
+			Key:   target.String(),
+			Count: maxDiscoveryPeerReqCount,
+		},
+	)
+}
+
 // startDiscovery starts the DiscoveryService loop,
 // in which random peers are dialed for their peer sets,
 // and random bootnodes are dialed for their peer sets
@@ -382,6 +525,24 @@ func (d *DiscoveryService) bootnodePeerDiscovery() {
 	d.addPeersToTable(foundNodes)
 }
 
+// Refresh runs a round of the peer and bootnode discovery right away,
+// instead of waiting for the next one, e.g. once the network of the node changed
+func (d *DiscoveryService) Refresh() {
+	go d.regularPeerDiscovery()
+	go d.bootnodePeerDiscovery()
+}
+
+// SetServeEnabled sets if the discovery queries of other peers are answered.
+// The node's own peer discovery is not affected [Thread safe]
+func (d *DiscoveryService) SetServeEnabled(enabled bool) {
+	d.serveDisabled.Store(!enabled)
+}
+
+// IsServeEnabled checks if the discovery queries of other peers are answered [Thread safe]
+func (d *DiscoveryService) IsServeEnabled() bool {
+	return !d.serveDisabled.Load()
+}
+
 // FindPeers implements the proto service for finding the target's peers
 func (d *DiscoveryService) FindPeers(
 	ctx context.Context,
@@ -395,6 +556,13 @@ func (d *DiscoveryService) FindPeers(
 
 	from := grpcContext.PeerID
 
+	// The node can still crawl while it doesn't serve others, e.g. under heavy load
+	if d.serveDisabled.Load() {
+		d.logger.Debug("Declining discovery request, serving is disabled", "peer", from)
+
+		return &proto.FindPeersResp{Nodes: []string{}}, nil
+	}
+
 	// Sanity check for result set size
 	if req.Count > maxDiscoveryPeerReqCount {
 		req.Count = maxDiscoveryPeerReqCount
@@ -3,11 +3,13 @@ package discovery
 import (
 	"context"
 	"errors"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/0xPolygon/polygon-edge/helper/tests"
 	"github.com/0xPolygon/polygon-edge/network/common"
+	networkGrpc "github.com/0xPolygon/polygon-edge/network/grpc"
 	"github.com/0xPolygon/polygon-edge/network/proto"
 	networkTesting "github.com/0xPolygon/polygon-edge/network/testing"
 	"github.com/hashicorp/go-hclog"
@@ -301,3 +303,205 @@ func TestDiscoveryService_RegularPeerDiscoveryUnconnected(t *testing.T) {
 	// Make sure that no peers were added to the peer store
 	assert.Len(t, peerStore, 0)
 }
+
+// TestDiscoveryService_FindPeer makes sure the addresses of a single peer
+// can be resolved by querying the nearest connected peers
+func TestDiscoveryService_FindPeer(t *testing.T) {
+	randomPeers := getRandomPeers(t, 3)
+	queriedPeer, targetPeer, unknownPeer := randomPeers[0], randomPeers[1], randomPeers[2]
+
+	discoveryService, setupErr := newDiscoveryService(
+		func(server *networkTesting.MockNetworkingServer) {
+			// Define the discovery client find peers hook
+			server.GetMockDiscoveryClient().HookFindPeers(
+				func(
+					ctx context.Context,
+					in *proto.FindPeersReq,
+					opts ...grpc.CallOption,
+				) (*proto.FindPeersResp, error) {
+					addr, err := common.AddrInfoToString(targetPeer)
+					if err != nil {
+						return nil, err
+					}
+
+					return &proto.FindPeersResp{
+						Nodes: []string{addr},
+					}, nil
+				},
+			)
+		},
+	)
+	if setupErr != nil {
+		t.Fatalf("Unable to setup the discovery service")
+	}
+
+	_, err := discoveryService.routingTable.TryAddPeer(queriedPeer.ID, false, false)
+	assert.NoError(t, err)
+
+	t.Run("peer is found", func(t *testing.T) {
+		info, err := discoveryService.FindPeer(context.Background(), targetPeer.ID)
+
+		assert.NoError(t, err)
+		assert.Equal(t, targetPeer, info)
+	})
+
+	t.Run("peer is not found", func(t *testing.T) {
+		info, err := discoveryService.FindPeer(context.Background(), unknownPeer.ID)
+
+		assert.ErrorIs(t, err, ErrPeerNotFound)
+		assert.Nil(t, info)
+	})
+
+	t.Run("context is done", func(t *testing.T) {
+		ctx, cancelFn := context.WithCancel(context.Background())
+		cancelFn()
+
+		info, err := discoveryService.FindPeer(ctx, targetPeer.ID)
+
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.Nil(t, info)
+	})
+}
+
+// TestDiscoveryService_ServeDisabled makes sure the discovery queries of other peers
+// are answered empty once serving is disabled, while the node keeps crawling
+func TestDiscoveryService_ServeDisabled(t *testing.T) {
+	randomPeers := getRandomPeers(t, 3)
+	requester, knownPeer, crawledPeer := randomPeers[0], randomPeers[1], randomPeers[2]
+	peerStore := make(map[peer.ID]*peer.AddrInfo)
+
+	discoveryService, setupErr := newDiscoveryService(
+		func(server *networkTesting.MockNetworkingServer) {
+			server.HookGetPeerInfo(func(id peer.ID) *peer.AddrInfo {
+				return knownPeer
+			})
+
+			server.HookAddToPeerStore(func(info *peer.AddrInfo) {
+				peerStore[info.ID] = info
+			})
+
+			// The queried peer knows about the crawled peer
+			server.GetMockDiscoveryClient().HookFindPeers(
+				func(
+					ctx context.Context,
+					in *proto.FindPeersReq,
+					opts ...grpc.CallOption,
+				) (*proto.FindPeersResp, error) {
+					addr, err := common.AddrInfoToString(crawledPeer)
+					if err != nil {
+						return nil, err
+					}
+
+					return &proto.FindPeersResp{
+						Nodes: []string{addr},
+					}, nil
+				},
+			)
+		},
+	)
+	if setupErr != nil {
+		t.Fatalf("Unable to setup the discovery service")
+	}
+
+	_, err := discoveryService.routingTable.TryAddPeer(knownPeer.ID, false, false)
+	assert.NoError(t, err)
+
+	request := &proto.FindPeersReq{Count: maxDiscoveryPeerReqCount}
+	requestCtx := &networkGrpc.Context{Context: context.Background(), PeerID: requester.ID}
+
+	// The known peer is served by default
+	assert.True(t, discoveryService.IsServeEnabled())
+
+	resp, err := discoveryService.FindPeers(requestCtx, request)
+	assert.NoError(t, err)
+	assert.Len(t, resp.Nodes, 1)
+
+	discoveryService.SetServeEnabled(false)
+	assert.False(t, discoveryService.IsServeEnabled())
+
+	resp, err = discoveryService.FindPeers(requestCtx, request)
+	assert.NoError(t, err)
+	assert.Empty(t, resp.Nodes)
+
+	// The node's own crawl still finds new peers
+	assert.NoError(t, discoveryService.attemptToFindPeers(knownPeer.ID))
+	assert.Contains(t, peerStore, crawledPeer.ID)
+}
+
+// TestDiscoveryService_QueryConcurrency makes sure the excess outbound
+// discovery queries are queued, so the concurrency stays within the cap
+func TestDiscoveryService_QueryConcurrency(t *testing.T) {
+	const (
+		maxQueries = 2
+		numQueries = 10
+	)
+
+	var (
+		lock        sync.Mutex
+		concurrent  int
+		maxObserved int
+	)
+
+	releaseCh := make(chan struct{})
+
+	discoveryService, setupErr := newDiscoveryService(
+		func(server *networkTesting.MockNetworkingServer) {
+			// The queries are held until released
+			server.GetMockDiscoveryClient().HookFindPeers(
+				func(
+					ctx context.Context,
+					in *proto.FindPeersReq,
+					opts ...grpc.CallOption,
+				) (*proto.FindPeersResp, error) {
+					lock.Lock()
+					concurrent++
+					if concurrent > maxObserved {
+						maxObserved = concurrent
+					}
+					lock.Unlock()
+
+					<-releaseCh
+
+					lock.Lock()
+					concurrent--
+					lock.Unlock()
+
+					return &proto.FindPeersResp{Nodes: []string{}}, nil
+				},
+			)
+		},
+	)
+	if setupErr != nil {
+		t.Fatalf("Unable to setup the discovery service")
+	}
+
+	discoveryService.querySlots = make(chan struct{}, maxQueries)
+
+	randomPeers := getRandomPeers(t, numQueries)
+
+	var wg sync.WaitGroup
+
+	for _, randomPeer := range randomPeers {
+		wg.Add(1)
+
+		go func(peerID peer.ID) {
+			defer wg.Done()
+
+			assert.NoError(t, discoveryService.attemptToFindPeers(peerID))
+		}(randomPeer.ID)
+	}
+
+	// The queries over the cap wait for a free slot
+	assert.Eventually(t, func() bool {
+		return discoveryService.QueriesInFlight() == maxQueries
+	}, time.Second, 10*time.Millisecond)
+
+	time.Sleep(100 * time.Millisecond)
+	assert.Equal(t, int64(maxQueries), discoveryService.QueriesInFlight())
+
+	close(releaseCh)
+	wg.Wait()
+
+	assert.Equal(t, maxQueries, maxObserved)
+	assert.Zero(t, discoveryService.QueriesInFlight())
+}
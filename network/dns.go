@@ -0,0 +1,15 @@
+package network
+
+import madns "github.com/multiformats/go-multiaddr-dns"
+
+// newMultiaddrResolver creates the resolver of DNS multiaddrs, which uses
+// the configured DNS resolver, or the system one if it is not set.
+// DNS multiaddrs, e.g. the bootnode ones, are kept as they are and resolved
+// on every dial, so the changes of the DNS records are picked up
+func newMultiaddrResolver(config *Config) (*madns.Resolver, error) {
+	if config.DNSResolver == nil {
+		return madns.DefaultResolver, nil
+	}
+
+	return madns.NewResolver(madns.WithDefaultResolver(config.DNSResolver))
+}
@@ -0,0 +1,142 @@
+package network
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/multiformats/go-multiaddr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stubResolver resolves the domains from a static table, and records the lookups
+type stubResolver struct {
+	lock    sync.Mutex
+	records map[string]net.IP
+	lookups []string
+}
+
+func (r *stubResolver) LookupIPAddr(_ context.Context, domain string) ([]net.IPAddr, error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.lookups = append(r.lookups, domain)
+
+	ip, ok := r.records[domain]
+	if !ok {
+		return nil, errors.New("no such host")
+	}
+
+	return []net.IPAddr{{IP: ip}}, nil
+}
+
+func (r *stubResolver) LookupTXT(_ context.Context, _ string) ([]string, error) {
+	return nil, errors.New("no TXT records")
+}
+
+func (r *stubResolver) getLookups() []string {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	return append([]string(nil), r.lookups...)
+}
+
+func TestDNSResolver_Bootnodes(t *testing.T) {
+	const bootnodeDomain = "bootnode.test"
+
+	bootnode, createErr := CreateServer(nil)
+	require.NoError(t, createErr)
+
+	t.Cleanup(func() {
+		assert.NoError(t, bootnode.Close())
+	})
+
+	resolver := &stubResolver{
+		records: map[string]net.IP{
+			bootnodeDomain: net.ParseIP("127.0.0.1"),
+		},
+	}
+
+	bootnodeAddr := fmt.Sprintf(
+		"/dns4/%s/tcp/%d/p2p/%s",
+		bootnodeDomain,
+		bootnode.config.Addr.Port,
+		bootnode.host.ID(),
+	)
+
+	server, createErr := CreateServer(&CreateServerParams{
+		ConfigCallback: func(c *Config) {
+			c.DNSResolver = resolver
+		},
+		ServerCallback: func(server *Server) {
+			server.config.Chain.Bootnodes = []string{bootnodeAddr}
+		},
+	})
+	require.NoError(t, createErr)
+
+	t.Cleanup(func() {
+		assert.NoError(t, server.Close())
+	})
+
+	// The bootnode DNS address is kept, so it is resolved again on every dial
+	assert.Equal(
+		t,
+		[]multiaddr.Multiaddr{
+			multiaddr.StringCast(fmt.Sprintf("/dns4/%s/tcp/%d", bootnodeDomain, bootnode.config.Addr.Port)),
+		},
+		server.bootnodes.getBootnodes()[0].Addrs,
+	)
+
+	waitCtx, cancelWait := context.WithTimeout(context.Background(), DefaultJoinTimeout)
+	defer cancelWait()
+
+	_, err := WaitUntilPeerConnectsTo(waitCtx, server, bootnode.host.ID())
+	require.NoError(t, err)
+
+	// The bootnode name is resolved through the custom resolver when dialing
+	assert.Contains(t, resolver.getLookups(), bootnodeDomain)
+}
+
+func TestDNSResolver_Dial(t *testing.T) {
+	const peerDomain = "peer.test"
+
+	resolver := &stubResolver{
+		records: map[string]net.IP{
+			peerDomain: net.ParseIP("127.0.0.1"),
+		},
+	}
+
+	servers, createErr := createServers(2, map[int]*CreateServerParams{
+		0: {ConfigCallback: func(c *Config) {
+			c.NoDiscover = true
+			c.DNSResolver = resolver
+		}},
+		1: {ConfigCallback: func(c *Config) { c.NoDiscover = true }},
+	})
+	require.NoError(t, createErr)
+
+	t.Cleanup(func() {
+		closeTestServers(t, servers)
+	})
+
+	// The DNS address of the peer is resolved through the custom resolver when dialing
+	peerInfo := servers[1].AddrInfo()
+	peerInfo.Addrs = []multiaddr.Multiaddr{
+		multiaddr.StringCast(fmt.Sprintf("/dns4/%s/tcp/%d", peerDomain, servers[1].config.Addr.Port)),
+	}
+
+	require.NoError(t, servers[0].joinPeer(peerInfo))
+
+	waitCtx, cancelWait := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancelWait()
+
+	_, err := WaitUntilPeerConnectsTo(waitCtx, servers[0], peerInfo.ID)
+	require.NoError(t, err)
+
+	assert.Contains(t, resolver.getLookups(), peerDomain)
+}
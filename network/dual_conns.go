@@ -0,0 +1,166 @@
+package network
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/libp2p/go-libp2p/core/network"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/multiformats/go-multiaddr"
+)
+
+// ErrUnknownConn is returned when the peer has no open connection with the given ID
+var ErrUnknownConn = errors.New("connection not found")
+
+// DualConnPolicy defines how the connections of a peer
+// connected in both directions at the same time are handled.
+// Either way, the peer is accounted as a single logical peer
+type DualConnPolicy int
+
+const (
+	// DualConnKeep keeps both connections open
+	DualConnKeep DualConnPolicy = iota
+
+	// DualConnCloseRedundant closes the redundant connection
+	DualConnCloseRedundant
+)
+
+// String returns the string representation of the dual connection policy
+func (p DualConnPolicy) String() string {
+	switch p {
+	case DualConnKeep:
+		return "keep"
+	case DualConnCloseRedundant:
+		return "close-redundant"
+	default:
+		return "unknown"
+	}
+}
+
+// keptDirection returns the direction of the connection kept when a peer is
+// connected in both directions. The connection dialed by the peer with the lower ID
+// is kept, so both peers close the same connection
+func keptDirection(localID, remoteID peer.ID) network.Direction {
+	if localID < remoteID {
+		return network.DirOutbound
+	}
+
+	return network.DirInbound
+}
+
+// closeRedundantConns closes the connections to the peer in the redundant direction,
+// if the peer is connected in both directions
+func (s *Server) closeRedundantConns(peerID peer.ID) {
+	conns := s.host.Network().ConnsToPeer(peerID)
+	kept := keptDirection(s.host.ID(), peerID)
+
+	hasKept := false
+
+	for _, conn := range conns {
+		if conn.Stat().Direction == kept {
+			hasKept = true
+
+			break
+		}
+	}
+
+	if !hasKept {
+		return
+	}
+
+	for _, conn := range conns {
+		if conn.Stat().Direction == kept {
+			continue
+		}
+
+		s.logger.Debug("Closing redundant connection", "peer", peerID, "direction", conn.Stat().Direction)
+
+		if err := conn.Close(); err != nil {
+			s.logger.Error("Unable to close redundant connection", "peer", peerID, "err", err)
+		}
+	}
+}
+
+// refreshPeerDirections updates the connection directions of the peer after
+// one of its connections is closed, while others are still open. If the peer was
+// accounted by the closed connection, it is accounted by a remaining one instead
+func (s *Server) refreshPeerDirections(peerID peer.ID) {
+	directions := make(map[network.Direction]bool)
+	for _, conn := range s.host.Network().ConnsToPeer(peerID) {
+		directions[conn.Stat().Direction] = true
+	}
+
+	s.peersLock.Lock()
+
+	connectionInfo, ok := s.peers[peerID]
+	if !ok || len(directions) == 0 {
+		s.peersLock.Unlock()
+
+		return
+	}
+
+	connectionInfo.connDirections = directions
+
+	if directions[connectionInfo.direction] {
+		s.peersLock.Unlock()
+
+		return
+	}
+
+	for direction := range directions {
+		s.connectionCounts.UpdateConnCountByDirection(-1, connectionInfo.direction)
+		s.updateConnCountMetrics(connectionInfo.direction)
+
+		connectionInfo.direction = direction
+
+		s.connectionCounts.UpdateConnCountByDirection(1, direction)
+		s.updateConnCountMetrics(direction)
+
+		break
+	}
+
+	s.peersLock.Unlock()
+
+	s.checkOutboundTarget(peerID)
+}
+
+// PeerConnection describes a single open connection to a peer
+type PeerConnection struct {
+	ID         string              // the connection ID, unique within the node run
+	Direction  network.Direction   // the direction the connection was established in
+	RemoteAddr multiaddr.Multiaddr // the peer address the connection is established over
+	Relayed    bool                // flag indicating if the connection goes through a circuit relay
+}
+
+// PeerConnections returns the open connections to the peer
+func (s *Server) PeerConnections(peerID peer.ID) []PeerConnection {
+	conns := s.host.Network().ConnsToPeer(peerID)
+	connections := make([]PeerConnection, 0, len(conns))
+
+	for _, conn := range conns {
+		connections = append(connections, PeerConnection{
+			ID:         conn.ID(),
+			Direction:  conn.Stat().Direction,
+			RemoteAddr: conn.RemoteMultiaddr(),
+			Relayed:    isRelayAddr(conn.RemoteMultiaddr()),
+		})
+	}
+
+	return connections
+}
+
+// CloseConnection closes a single connection to the peer, identified by its ID.
+// The peer stays connected over its other connections, if there are any
+func (s *Server) CloseConnection(peerID peer.ID, connID string) error {
+	for _, conn := range s.host.Network().ConnsToPeer(peerID) {
+		if conn.ID() != connID {
+			continue
+		}
+
+		s.logger.Debug("Closing peer connection", "peer", peerID, "conn", connID, "direction", conn.Stat().Direction)
+
+		return conn.Close()
+	}
+
+	return fmt.Errorf("%w: %s", ErrUnknownConn, connID)
+}
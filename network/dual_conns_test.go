@@ -0,0 +1,192 @@
+package network
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/libp2p/go-libp2p"
+	"github.com/libp2p/go-libp2p/core/host"
+	"github.com/libp2p/go-libp2p/core/network"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// connectInBothDirections makes the peer connect to the server in the opposite direction
+// of the existing connection, through a second host with the identity of the peer
+func connectInBothDirections(t *testing.T, server, peerServer *Server) host.Host {
+	t.Helper()
+
+	peerKey := peerServer.host.Peerstore().PrivKey(peerServer.host.ID())
+	require.NotNil(t, peerKey)
+
+	twin, err := libp2p.New(
+		libp2p.Identity(peerKey),
+		libp2p.ListenAddrStrings("/ip4/127.0.0.1/tcp/0"),
+	)
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		_ = twin.Close()
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultJoinTimeout)
+	defer cancel()
+
+	require.NoError(t, twin.Connect(ctx, *server.AddrInfo()))
+
+	return twin
+}
+
+// connDirections returns the directions of the open connections to the peer
+func connDirections(server *Server, peerServer *Server) []network.Direction {
+	directions := make([]network.Direction, 0)
+
+	for _, conn := range server.host.Network().ConnsToPeer(peerServer.host.ID()) {
+		directions = append(directions, conn.Stat().Direction)
+	}
+
+	return directions
+}
+
+func TestDualConnections_SingleLogicalPeer(t *testing.T) {
+	servers, createErr := createServers(2, map[int]*CreateServerParams{
+		0: {ConfigCallback: func(c *Config) { c.NoDiscover = true }},
+		1: {ConfigCallback: func(c *Config) { c.NoDiscover = true }},
+	})
+	require.NoError(t, createErr)
+
+	t.Cleanup(func() {
+		closeTestServers(t, servers)
+	})
+
+	server, peerServer := servers[0], servers[1]
+	peerID := peerServer.host.ID()
+
+	require.NoError(t, JoinAndWait(server, peerServer, DefaultBufferTimeout, DefaultJoinTimeout))
+
+	twin := connectInBothDirections(t, server, peerServer)
+
+	// The handshake over the second connection registers the inbound direction
+	require.Eventually(t, func() bool {
+		peers := server.Peers()
+
+		return len(peers) == 1 && peers[0].connDirections[network.DirInbound]
+	}, DefaultJoinTimeout, 50*time.Millisecond)
+
+	// Both connections are kept, but the peer is accounted once
+	assert.ElementsMatch(t, []network.Direction{network.DirOutbound, network.DirInbound}, connDirections(server, peerServer))
+	assert.Equal(t, int64(1), server.connectionCounts.GetOutboundConnCount())
+	assert.Equal(t, int64(0), server.connectionCounts.GetInboundConnCount())
+
+	// Closing the accounted connection moves the accounting to the remaining one
+	for _, conn := range server.host.Network().ConnsToPeer(peerID) {
+		if conn.Stat().Direction == network.DirOutbound {
+			require.NoError(t, conn.Close())
+		}
+	}
+
+	require.Eventually(t, func() bool {
+		return server.connectionCounts.GetInboundConnCount() == 1
+	}, DefaultJoinTimeout, 50*time.Millisecond)
+
+	assert.Equal(t, int64(0), server.connectionCounts.GetOutboundConnCount())
+	assert.True(t, server.hasPeer(peerID))
+
+	// The peer is removed once the last connection is closed
+	require.NoError(t, twin.Network().ClosePeer(server.host.ID()))
+
+	require.Eventually(t, func() bool {
+		return !server.hasPeer(peerID)
+	}, DefaultJoinTimeout, 50*time.Millisecond)
+
+	assert.Equal(t, int64(0), server.connectionCounts.GetInboundConnCount())
+	assert.Equal(t, int64(0), server.connectionCounts.GetOutboundConnCount())
+}
+
+func TestDualConnections_CloseRedundant(t *testing.T) {
+	servers, createErr := createServers(2, map[int]*CreateServerParams{
+		0: {ConfigCallback: func(c *Config) {
+			c.NoDiscover = true
+			c.DualConnPolicy = DualConnCloseRedundant
+		}},
+		1: {ConfigCallback: func(c *Config) { c.NoDiscover = true }},
+	})
+	require.NoError(t, createErr)
+
+	t.Cleanup(func() {
+		closeTestServers(t, servers)
+	})
+
+	server, peerServer := servers[0], servers[1]
+	peerID := peerServer.host.ID()
+
+	require.NoError(t, JoinAndWait(server, peerServer, DefaultBufferTimeout, DefaultJoinTimeout))
+
+	connectInBothDirections(t, server, peerServer)
+
+	kept := keptDirection(server.host.ID(), peerID)
+
+	// Only the connection in the kept direction remains, and the peer is accounted by it
+	require.Eventually(t, func() bool {
+		directions := connDirections(server, peerServer)
+
+		return len(directions) == 1 && directions[0] == kept
+	}, DefaultJoinTimeout, 50*time.Millisecond)
+
+	require.Eventually(t, func() bool {
+		return server.connectionCounts.GetInboundConnCount()+server.connectionCounts.GetOutboundConnCount() == 1
+	}, DefaultJoinTimeout, 50*time.Millisecond)
+
+	assert.True(t, server.hasPeer(peerID))
+	assert.Equal(t, []peer.ID{peerID}, server.peersByDirection(kept))
+}
+
+func TestCloseConnection(t *testing.T) {
+	servers, createErr := createServers(2, map[int]*CreateServerParams{
+		0: {ConfigCallback: func(c *Config) { c.NoDiscover = true }},
+		1: {ConfigCallback: func(c *Config) { c.NoDiscover = true }},
+	})
+	require.NoError(t, createErr)
+
+	t.Cleanup(func() {
+		closeTestServers(t, servers)
+	})
+
+	server, peerServer := servers[0], servers[1]
+	peerID := peerServer.host.ID()
+
+	require.NoError(t, JoinAndWait(server, peerServer, DefaultBufferTimeout, DefaultJoinTimeout))
+
+	connectInBothDirections(t, server, peerServer)
+
+	require.Eventually(t, func() bool {
+		return len(server.PeerConnections(peerID)) == 2
+	}, DefaultJoinTimeout, 50*time.Millisecond)
+
+	var closed, remaining PeerConnection
+
+	for _, conn := range server.PeerConnections(peerID) {
+		if conn.Direction == network.DirInbound {
+			closed = conn
+		} else {
+			remaining = conn
+		}
+	}
+
+	require.NoError(t, server.CloseConnection(peerID, closed.ID))
+
+	// Only the closed connection is gone, and the peer stays connected over the other one
+	require.Eventually(t, func() bool {
+		connections := server.PeerConnections(peerID)
+
+		return len(connections) == 1 && connections[0].ID == remaining.ID
+	}, DefaultJoinTimeout, 50*time.Millisecond)
+
+	assert.True(t, server.IsConnected(peerID))
+	assert.True(t, server.hasPeer(peerID))
+
+	// Closing an unknown connection fails
+	assert.ErrorIs(t, server.CloseConnection(peerID, closed.ID), ErrUnknownConn)
+}
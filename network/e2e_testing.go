@@ -43,9 +43,12 @@ func JoinAndWait(
 	}
 
 	// Mark the destination address as ready for dialing
-	source.joinPeer(destination.AddrInfo())
+	if err := source.joinPeer(destination.AddrInfo()); err != nil {
+		return err
+	}
 
-	connectCtx, cancelFn := context.WithTimeout(context.Background(), connectTimeout)
+	// The timeout elapses on the clock of the source, which can be a fake one
+	connectCtx, cancelFn := withClockTimeout(context.Background(), source.clock, connectTimeout)
 	defer cancelFn()
 
 	// Wait for the peer to be connected
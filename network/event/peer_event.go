@@ -5,19 +5,27 @@ import "github.com/libp2p/go-libp2p/core/peer"
 type PeerEventType uint
 
 const (
-	PeerConnected        PeerEventType = iota // Emitted when a peer connected
-	PeerFailedToConnect                       // Emitted when a peer failed to connect
-	PeerDisconnected                          // Emitted when a peer disconnected from node
-	PeerDialCompleted                         // Emitted when a peer completed dial
-	PeerAddedToDialQueue                      // Emitted when a peer is added to dial queue
+	PeerConnected         PeerEventType = iota // Emitted when a peer connected
+	PeerFailedToConnect                        // Emitted when a peer failed to connect
+	PeerDisconnected                           // Emitted when a peer disconnected from node
+	PeerDialCompleted                          // Emitted when a peer completed dial
+	PeerAddedToDialQueue                       // Emitted when a peer is added to dial queue
+	OutboundTargetReached                      // Emitted when the outbound peer count reaches the target
+	OutboundTargetLost                         // Emitted when the outbound peer count drops below the target
+	PeerConnUpgraded                           // Emitted when a relayed peer connection is upgraded to a direct one
+	PeerProtocolsUpdated                       // Emitted when a connected peer announces a change of its protocols
 )
 
 var peerEventToName = map[PeerEventType]string{
-	PeerConnected:        "PeerConnected",
-	PeerFailedToConnect:  "PeerFailedToConnect",
-	PeerDisconnected:     "PeerDisconnected",
-	PeerDialCompleted:    "PeerDialCompleted",
-	PeerAddedToDialQueue: "PeerAddedToDialQueue",
+	PeerConnected:         "PeerConnected",
+	PeerFailedToConnect:   "PeerFailedToConnect",
+	PeerDisconnected:      "PeerDisconnected",
+	PeerDialCompleted:     "PeerDialCompleted",
+	PeerAddedToDialQueue:  "PeerAddedToDialQueue",
+	OutboundTargetReached: "OutboundTargetReached",
+	OutboundTargetLost:    "OutboundTargetLost",
+	PeerConnUpgraded:      "PeerConnUpgraded",
+	PeerProtocolsUpdated:  "PeerProtocolsUpdated",
 }
 
 type PeerEvent struct {
@@ -27,6 +35,14 @@ type PeerEvent struct {
 
 	// Type is the type of the event
 	Type PeerEventType
+
+	// AddedProtocols are the protocols the peer started supporting,
+	// set for the PeerProtocolsUpdated events only
+	AddedProtocols []string
+
+	// RemovedProtocols are the protocols the peer stopped supporting,
+	// set for the PeerProtocolsUpdated events only
+	RemovedProtocols []string
 }
 
 func (s PeerEventType) String() string {
@@ -37,3 +53,14 @@ func (s PeerEventType) String() string {
 
 	return name
 }
+
+// PeerBatchEvent is a batch of consecutive peer events of the same type,
+// coalesced during a burst of events
+type PeerBatchEvent struct {
+	// PeerIDs are the ids of the peers that triggered
+	// the events, in the order of the events
+	PeerIDs []peer.ID
+
+	// Type is the type of the events
+	Type PeerEventType
+}
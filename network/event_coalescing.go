@@ -0,0 +1,128 @@
+package network
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	peerEvent "github.com/0xPolygon/polygon-edge/network/event"
+	"github.com/libp2p/go-libp2p/core/peer"
+)
+
+// eventCoalescer accumulates the peer events that haven't been delivered yet,
+// merging the consecutive events of the same type into batches
+type eventCoalescer struct {
+	sync.Mutex
+
+	pending []*peerEvent.PeerBatchEvent
+}
+
+// add adds the event to the last batch if it is of the same type,
+// or starts a new batch otherwise, so the event order is preserved [Thread safe]
+func (c *eventCoalescer) add(evnt peerEvent.PeerEvent) {
+	c.Lock()
+	defer c.Unlock()
+
+	if last := len(c.pending) - 1; last >= 0 && c.pending[last].Type == evnt.Type {
+		c.pending[last].PeerIDs = append(c.pending[last].PeerIDs, evnt.PeerID)
+
+		return
+	}
+
+	c.pending = append(c.pending, &peerEvent.PeerBatchEvent{
+		PeerIDs: []peer.ID{evnt.PeerID},
+		Type:    evnt.Type,
+	})
+}
+
+// drain returns and removes the accumulated batches [Thread safe]
+func (c *eventCoalescer) drain() []*peerEvent.PeerBatchEvent {
+	c.Lock()
+	defer c.Unlock()
+
+	batches := c.pending
+	c.pending = nil
+
+	return batches
+}
+
+// SubscribeCoalesced is an opt-in alternative to Subscribe for slow subscribers.
+// The events are delivered at most once per window, and the events received
+// in the meantime are coalesced into batches. A single event after a quiet period
+// is delivered right away, so only bursts of events are delayed
+func (s *Server) SubscribeCoalesced(
+	ctx context.Context,
+	window time.Duration,
+	handler func(batch *peerEvent.PeerBatchEvent),
+) error {
+	sub, err := s.host.EventBus().Subscribe(new(peerEvent.PeerEvent))
+	if err != nil {
+		return err
+	}
+
+	var (
+		coalescer eventCoalescer
+		notifyCh  = make(chan struct{}, 1)
+	)
+
+	readDone, deliverDone := s.health.subscriptions.start(), s.health.subscriptions.start()
+
+	// The events are read independently of the delivery,
+	// so a slow handler doesn't hold up the event bus
+	go func() {
+		defer readDone()
+		defer sub.Close()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+
+			case <-s.closeCh:
+				return
+
+			case evnt := <-sub.Out():
+				obj, ok := evnt.(peerEvent.PeerEvent)
+				if !ok {
+					continue
+				}
+
+				coalescer.add(obj)
+
+				select {
+				case notifyCh <- struct{}{}:
+				default:
+				}
+			}
+		}
+	}()
+
+	go func() {
+		defer deliverDone()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-s.closeCh:
+				return
+			case <-notifyCh:
+			}
+
+			for _, batch := range coalescer.drain() {
+				handler(batch)
+			}
+
+			// Throttle the delivery, so the next events are coalesced
+			select {
+			case <-ctx.Done():
+				return
+			case <-s.closeCh:
+				return
+			case <-s.clock.After(window):
+			}
+		}
+	}()
+
+	return nil
+}
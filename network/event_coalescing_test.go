@@ -0,0 +1,101 @@
+package network
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	peerEvent "github.com/0xPolygon/polygon-edge/network/event"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEventCoalescer(t *testing.T) {
+	var coalescer eventCoalescer
+
+	coalescer.add(peerEvent.PeerEvent{PeerID: "A", Type: peerEvent.PeerConnected})
+	coalescer.add(peerEvent.PeerEvent{PeerID: "B", Type: peerEvent.PeerConnected})
+	coalescer.add(peerEvent.PeerEvent{PeerID: "A", Type: peerEvent.PeerDisconnected})
+	coalescer.add(peerEvent.PeerEvent{PeerID: "C", Type: peerEvent.PeerConnected})
+
+	// Only the consecutive events are merged, so the event order is preserved
+	assert.Equal(t, []*peerEvent.PeerBatchEvent{
+		{PeerIDs: []peer.ID{"A", "B"}, Type: peerEvent.PeerConnected},
+		{PeerIDs: []peer.ID{"A"}, Type: peerEvent.PeerDisconnected},
+		{PeerIDs: []peer.ID{"C"}, Type: peerEvent.PeerConnected},
+	}, coalescer.drain())
+
+	assert.Empty(t, coalescer.drain())
+}
+
+func TestSubscribeCoalesced_Burst(t *testing.T) {
+	const (
+		numEvents = 200
+		window    = 100 * time.Millisecond
+	)
+
+	server, createErr := CreateServer(&CreateServerParams{ConfigCallback: func(c *Config) {
+		c.NoDiscover = true
+	}})
+	require.NoError(t, createErr)
+
+	t.Cleanup(func() {
+		require.NoError(t, server.Close())
+	})
+
+	var (
+		batchesLock sync.Mutex
+		batches     = make([]*peerEvent.PeerBatchEvent, 0)
+	)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	require.NoError(t, server.SubscribeCoalesced(ctx, window, func(batch *peerEvent.PeerBatchEvent) {
+		batchesLock.Lock()
+		batches = append(batches, batch)
+		batchesLock.Unlock()
+
+		// Simulate a slow subscriber
+		time.Sleep(10 * time.Millisecond)
+	}))
+
+	expectedPeers := make([]peer.ID, numEvents)
+
+	// Generate a burst of connect events
+	for i := 0; i < numEvents; i++ {
+		expectedPeers[i] = peer.ID(fmt.Sprintf("peer-%d", i))
+		server.emitEvent(expectedPeers[i], peerEvent.PeerConnected)
+	}
+
+	receivedPeers := func() []peer.ID {
+		batchesLock.Lock()
+		defer batchesLock.Unlock()
+
+		peers := make([]peer.ID, 0)
+		for _, batch := range batches {
+			peers = append(peers, batch.PeerIDs...)
+		}
+
+		return peers
+	}
+
+	require.Eventually(t, func() bool {
+		return len(receivedPeers()) == numEvents
+	}, 5*time.Second, 50*time.Millisecond)
+
+	// All the events are delivered in order, but in far fewer batches
+	assert.Equal(t, expectedPeers, receivedPeers())
+
+	batchesLock.Lock()
+	defer batchesLock.Unlock()
+
+	assert.Less(t, len(batches), numEvents/10)
+
+	for _, batch := range batches {
+		assert.Equal(t, peerEvent.PeerConnected, batch.Type)
+	}
+}
@@ -0,0 +1,345 @@
+package network
+
+import (
+	"net"
+	"sync"
+	"time"
+
+	"github.com/armon/go-metrics"
+	"github.com/libp2p/go-libp2p/core/control"
+	"github.com/libp2p/go-libp2p/core/network"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/multiformats/go-multiaddr"
+	manet "github.com/multiformats/go-multiaddr/net"
+)
+
+const (
+	// DefaultMaxInboundBacklog is the default maximum number of accepted inbound
+	// connections which haven't finished the security handshake yet
+	DefaultMaxInboundBacklog = 64
+
+	// inboundBacklogTimeout is the time after which an accepted inbound connection
+	// is no longer part of the backlog, even if its connection scope was never released.
+	// It is a backstop only, matching the libp2p upgrade timeout
+	inboundBacklogTimeout = 15 * time.Second
+)
+
+// connectionGater is the libp2p connection gater of the networking server,
+// which decides which peer connections are allowed
+type connectionGater struct {
+	allowlistOnly bool                 // flag indicating if only allowlisted peers can connect
+	allowlist     map[peer.ID]struct{} // the set of allowlisted peers
+	allowlistLock sync.RWMutex         // lock for the allowlist mode and set
+
+	maxInboundBacklog int                      // the maximum inbound backlog size, unlimited if 0
+	backlog           map[string]*backlogEntry // accepted, not yet secured connections; remote address -> entry
+	backlogLock       sync.Mutex               // lock for the backlog map
+
+	bannedIPs     map[string]time.Time // IP -> time until which the IP is banned, zero if indefinitely
+	bannedIPsLock sync.RWMutex         // lock for the banned IPs map
+
+	quarantined     map[peer.ID]quarantineRecord // peerID -> quarantine of the peer
+	bannedPeers     map[peer.ID]quarantineRecord // peerID -> operator ban of the peer
+	quarantinedLock sync.RWMutex                 // lock for the quarantined and banned peers maps
+
+	clock Clock // the source of time for the backlog timeouts, bans and quarantines
+}
+
+// backlogEntry is an accepted inbound connection which hasn't finished the security handshake yet
+type backlogEntry struct {
+	expiry time.Time // the time after which the connection leaves the backlog regardless
+}
+
+// quarantineRecord is the quarantine of a misbehaving peer, or the operator ban of a peer
+type quarantineRecord struct {
+	until  time.Time // the time until which the peer is refused, zero if indefinitely (bans only)
+	reason string    // the protocol violation the peer is quarantined for, or the reason it is banned for
+}
+
+// isExpired checks if the quarantine or ban is over
+func (r quarantineRecord) isExpired(now time.Time) bool {
+	return !r.until.IsZero() && now.After(r.until)
+}
+
+// newConnectionGater creates a new connection gater from the networking configuration
+func newConnectionGater(config *Config) *connectionGater {
+	g := &connectionGater{
+		maxInboundBacklog: config.MaxInboundBacklog,
+		backlog:           make(map[string]*backlogEntry),
+		bannedIPs:         make(map[string]time.Time),
+		quarantined:       make(map[peer.ID]quarantineRecord),
+		bannedPeers:       make(map[peer.ID]quarantineRecord),
+		clock:             configuredClock(config),
+	}
+
+	g.setAllowlist(config.AllowlistOnly, config.PeerAllowlist)
+
+	return g
+}
+
+// setAllowlist replaces the allowlist mode and the set of allowlisted peers [Thread safe]
+func (g *connectionGater) setAllowlist(allowlistOnly bool, peerIDs []peer.ID) {
+	allowlist := make(map[peer.ID]struct{}, len(peerIDs))
+	for _, peerID := range peerIDs {
+		allowlist[peerID] = struct{}{}
+	}
+
+	g.allowlistLock.Lock()
+	defer g.allowlistLock.Unlock()
+
+	g.allowlistOnly = allowlistOnly
+	g.allowlist = allowlist
+}
+
+// isAllowed checks if a connection with the peer is allowed [Thread safe]
+func (g *connectionGater) isAllowed(peerID peer.ID) bool {
+	g.allowlistLock.RLock()
+	defer g.allowlistLock.RUnlock()
+
+	if !g.allowlistOnly {
+		return true
+	}
+
+	_, ok := g.allowlist[peerID]
+
+	return ok
+}
+
+// InterceptPeerDial checks if the peer can be dialed
+func (g *connectionGater) InterceptPeerDial(peerID peer.ID) bool {
+	return g.isAllowed(peerID) && !g.isRefused(peerID)
+}
+
+// InterceptAddrDial checks if the peer address can be dialed
+func (g *connectionGater) InterceptAddrDial(peerID peer.ID, addr multiaddr.Multiaddr) bool {
+	return g.isAllowed(peerID) && !g.isAddrBanned(addr)
+}
+
+// InterceptAccept checks if an inbound connection can be accepted,
+// before the remote peer is known. Connections from banned IPs,
+// and the ones beyond the backlog cap are rejected
+func (g *connectionGater) InterceptAccept(addrs network.ConnMultiaddrs) bool {
+	if g.isAddrBanned(addrs.RemoteMultiaddr()) {
+		return false
+	}
+
+	g.backlogLock.Lock()
+	defer g.backlogLock.Unlock()
+
+	g.pruneBacklog()
+
+	if g.maxInboundBacklog > 0 && len(g.backlog) >= g.maxInboundBacklog {
+		metrics.IncrCounter([]string{networkMetrics, "inbound_backlog_rejected"}, 1)
+
+		return false
+	}
+
+	g.backlog[backlogKey(addrs.RemoteMultiaddr())] = &backlogEntry{expiry: g.clock.Now().Add(inboundBacklogTimeout)}
+	g.updateBacklogMetrics()
+
+	return true
+}
+
+// InterceptSecured checks if a connection can be established,
+// after the remote peer is authenticated
+func (g *connectionGater) InterceptSecured(direction network.Direction, peerID peer.ID, addrs network.ConnMultiaddrs) bool {
+	if direction == network.DirInbound {
+		// The security handshake is done, so the connection leaves the backlog
+		g.backlogLock.Lock()
+		delete(g.backlog, backlogKey(addrs.RemoteMultiaddr()))
+		g.updateBacklogMetrics()
+		g.backlogLock.Unlock()
+	}
+
+	return g.isAllowed(peerID) && !g.isRefused(peerID)
+}
+
+// InterceptUpgraded checks if a fully upgraded connection can be used
+func (g *connectionGater) InterceptUpgraded(_ network.Conn) (bool, control.DisconnectReason) {
+	return true, 0
+}
+
+// inboundBacklog returns the number of accepted inbound connections
+// which haven't finished the security handshake yet [Thread safe]
+func (g *connectionGater) inboundBacklog() int {
+	g.backlogLock.Lock()
+	defer g.backlogLock.Unlock()
+
+	g.pruneBacklog()
+
+	return len(g.backlog)
+}
+
+// getBacklogEntry returns the backlog entry of the accepted connection from the address, if any [Thread safe]
+func (g *connectionGater) getBacklogEntry(remoteAddr multiaddr.Multiaddr) *backlogEntry {
+	g.backlogLock.Lock()
+	defer g.backlogLock.Unlock()
+
+	return g.backlog[backlogKey(remoteAddr)]
+}
+
+// releaseBacklogEntry removes the entry from the backlog, unless it was already removed
+// (e.g. once the connection was secured), or replaced by a newer connection [Thread safe]
+func (g *connectionGater) releaseBacklogEntry(remoteAddr multiaddr.Multiaddr, entry *backlogEntry) {
+	g.backlogLock.Lock()
+	defer g.backlogLock.Unlock()
+
+	key := backlogKey(remoteAddr)
+	if g.backlog[key] != entry {
+		return
+	}
+
+	delete(g.backlog, key)
+	g.updateBacklogMetrics()
+}
+
+// pruneBacklog removes the timed out connections from the backlog.
+// The connections normally leave the backlog once secured, or once their connection
+// scope is released (see backlogResourceManager), so this only catches the leftovers
+func (g *connectionGater) pruneBacklog() {
+	now := g.clock.Now()
+
+	for key, entry := range g.backlog {
+		if now.After(entry.expiry) {
+			delete(g.backlog, key)
+		}
+	}
+}
+
+// updateBacklogMetrics updates the inbound backlog metrics
+func (g *connectionGater) updateBacklogMetrics() {
+	metrics.SetGauge([]string{networkMetrics, "inbound_backlog"}, float32(len(g.backlog)))
+}
+
+// backlogKey returns the key of the connection from the remote address in the backlog
+func backlogKey(remoteAddr multiaddr.Multiaddr) string {
+	return remoteAddr.String()
+}
+
+// banIP bans the IP until the specified time, or indefinitely if the time is zero [Thread safe]
+func (g *connectionGater) banIP(ip net.IP, until time.Time) {
+	g.bannedIPsLock.Lock()
+	defer g.bannedIPsLock.Unlock()
+
+	g.bannedIPs[ip.String()] = until
+}
+
+// unbanIP removes the IP ban [Thread safe]
+func (g *connectionGater) unbanIP(ip net.IP) {
+	g.bannedIPsLock.Lock()
+	defer g.bannedIPsLock.Unlock()
+
+	delete(g.bannedIPs, ip.String())
+}
+
+// isIPBanned checks if the IP is currently banned [Thread safe]
+func (g *connectionGater) isIPBanned(ip net.IP) bool {
+	g.bannedIPsLock.RLock()
+	until, ok := g.bannedIPs[ip.String()]
+	g.bannedIPsLock.RUnlock()
+
+	if !ok {
+		return false
+	}
+
+	if !until.IsZero() && g.clock.Now().After(until) {
+		g.unbanIP(ip)
+
+		return false
+	}
+
+	return true
+}
+
+// isAddrBanned checks if the IP of the address is currently banned.
+// Addresses without an IP (e.g. relayed ones) are never banned [Thread safe]
+func (g *connectionGater) isAddrBanned(addr multiaddr.Multiaddr) bool {
+	ip, err := manet.ToIP(addr)
+	if err != nil {
+		return false
+	}
+
+	return g.isIPBanned(ip)
+}
+
+// quarantinePeer refuses any connections with the peer until the specified time.
+// The quarantine is kept apart from a ban of the peer, so neither lifts the other [Thread safe]
+func (g *connectionGater) quarantinePeer(peerID peer.ID, until time.Time, reason string) {
+	g.quarantinedLock.Lock()
+	defer g.quarantinedLock.Unlock()
+
+	g.quarantined[peerID] = quarantineRecord{until: until, reason: reason}
+}
+
+// isQuarantined checks if the peer is currently quarantined [Thread safe]
+func (g *connectionGater) isQuarantined(peerID peer.ID) bool {
+	_, ok := g.getQuarantine(peerID)
+
+	return ok
+}
+
+// getQuarantine returns the current quarantine of the peer, if any [Thread safe]
+func (g *connectionGater) getQuarantine(peerID peer.ID) (quarantineRecord, bool) {
+	return g.getRecord(g.quarantined, peerID)
+}
+
+// banPeer refuses any connections with the peer until the specified time,
+// or indefinitely if the time is zero [Thread safe]
+func (g *connectionGater) banPeer(peerID peer.ID, until time.Time, reason string) {
+	g.quarantinedLock.Lock()
+	defer g.quarantinedLock.Unlock()
+
+	g.bannedPeers[peerID] = quarantineRecord{until: until, reason: reason}
+}
+
+// unbanPeer removes the peer ban, if any. A quarantine of the peer is kept [Thread safe]
+func (g *connectionGater) unbanPeer(peerID peer.ID) {
+	g.quarantinedLock.Lock()
+	defer g.quarantinedLock.Unlock()
+
+	delete(g.bannedPeers, peerID)
+}
+
+// isPeerBanned checks if the peer is currently banned [Thread safe]
+func (g *connectionGater) isPeerBanned(peerID peer.ID) bool {
+	_, ok := g.getPeerBan(peerID)
+
+	return ok
+}
+
+// getPeerBan returns the current ban of the peer, if any [Thread safe]
+func (g *connectionGater) getPeerBan(peerID peer.ID) (quarantineRecord, bool) {
+	return g.getRecord(g.bannedPeers, peerID)
+}
+
+// isRefused checks if the peer is currently quarantined or banned [Thread safe]
+func (g *connectionGater) isRefused(peerID peer.ID) bool {
+	return g.isQuarantined(peerID) || g.isPeerBanned(peerID)
+}
+
+// getRecord returns the current record of the peer in the quarantined or banned peers map, if any.
+// Expired records are removed [Thread safe]
+func (g *connectionGater) getRecord(records map[peer.ID]quarantineRecord, peerID peer.ID) (quarantineRecord, bool) {
+	g.quarantinedLock.RLock()
+	record, ok := records[peerID]
+	g.quarantinedLock.RUnlock()
+
+	if !ok {
+		return quarantineRecord{}, false
+	}
+
+	if record.isExpired(g.clock.Now()) {
+		g.quarantinedLock.Lock()
+
+		// The record may have been replaced in the meantime
+		if current, ok := records[peerID]; ok && current == record {
+			delete(records, peerID)
+		}
+
+		g.quarantinedLock.Unlock()
+
+		return quarantineRecord{}, false
+	}
+
+	return record, true
+}
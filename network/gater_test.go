@@ -0,0 +1,219 @@
+package network
+
+import (
+	"net"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/0xPolygon/polygon-edge/network/common"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/multiformats/go-multiaddr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAllowlistOnly(t *testing.T) {
+	servers, createErr := createServers(3, map[int]*CreateServerParams{
+		0: {ConfigCallback: func(c *Config) { c.NoDiscover = true }},
+		1: {ConfigCallback: func(c *Config) { c.NoDiscover = true }},
+		2: {ConfigCallback: func(c *Config) { c.NoDiscover = true }},
+	})
+	require.NoError(t, createErr)
+
+	t.Cleanup(func() {
+		closeTestServers(t, servers)
+	})
+
+	private, allowed, notAllowed := servers[0], servers[1], servers[2]
+
+	// The peer IDs are known only after the servers are created
+	private.gater.setAllowlist(true, []peer.ID{allowed.host.ID()})
+
+	smallTimeout := 5 * time.Second
+
+	// The allowlisted peer can connect
+	require.NoError(t, JoinAndWait(allowed, private, DefaultBufferTimeout, DefaultJoinTimeout))
+
+	// The non-allowlisted peer can't connect in
+	assert.Error(t, JoinAndWait(notAllowed, private, smallTimeout, smallTimeout))
+
+	// The non-allowlisted peer can't be dialed out
+	assert.Error(t, JoinAndWait(private, notAllowed, smallTimeout, smallTimeout))
+
+	assert.Len(t, private.Peers(), 1)
+	assert.True(t, private.hasPeer(allowed.host.ID()))
+}
+
+func TestAllowlistOnly_Bootnodes(t *testing.T) {
+	bootnode, createErr := CreateServer(nil)
+	require.NoError(t, createErr)
+
+	t.Cleanup(func() {
+		assert.NoError(t, bootnode.Close())
+	})
+
+	bootnodeAddr, err := common.AddrInfoToString(bootnode.AddrInfo())
+	require.NoError(t, err)
+
+	testTable := []struct {
+		name      string
+		allowlist []peer.ID
+		expectErr bool
+	}{
+		{
+			"bootnode is allowlisted",
+			[]peer.ID{bootnode.host.ID()},
+			false,
+		},
+		{
+			"bootnode is not allowlisted",
+			[]peer.ID{},
+			true,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			server, err := CreateServer(&CreateServerParams{
+				ConfigCallback: func(c *Config) {
+					c.AllowlistOnly = true
+					c.PeerAllowlist = testCase.allowlist
+				},
+				ServerCallback: func(server *Server) {
+					server.config.Chain.Bootnodes = []string{bootnodeAddr}
+				},
+			})
+
+			if testCase.expectErr {
+				assert.ErrorIs(t, err, ErrBootnodeNotAllowlisted)
+
+				// The server didn't start, so only the libp2p host is running
+				assert.NoError(t, server.host.Close())
+			} else {
+				assert.NoError(t, err)
+				assert.NoError(t, server.Close())
+			}
+		})
+	}
+}
+
+func TestMaxInboundBacklog(t *testing.T) {
+	const (
+		maxBacklog = 5
+		numFlood   = 10
+	)
+
+	servers, createErr := createServers(2, map[int]*CreateServerParams{
+		0: {ConfigCallback: func(c *Config) {
+			c.NoDiscover = true
+			c.MaxInboundBacklog = maxBacklog
+		}},
+		1: {ConfigCallback: func(c *Config) { c.NoDiscover = true }},
+	})
+	require.NoError(t, createErr)
+
+	t.Cleanup(func() {
+		closeTestServers(t, servers)
+	})
+
+	server := servers[0]
+
+	// Flood the server with raw TCP connections, which never start the security handshake
+	conns := make([]net.Conn, numFlood)
+
+	for i := 0; i < numFlood; i++ {
+		conn, err := net.Dial("tcp", server.config.Addr.String())
+		require.NoError(t, err)
+
+		conns[i] = conn
+
+		t.Cleanup(func() {
+			_ = conn.Close()
+		})
+	}
+
+	require.Eventually(t, func() bool {
+		return server.InboundBacklog() == maxBacklog
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// The connections beyond the cap are closed right away
+	rejected := 0
+
+	for _, conn := range conns {
+		_ = conn.SetReadDeadline(time.Now().Add(500 * time.Millisecond))
+
+		if _, err := conn.Read(make([]byte, 1)); err != nil && !os.IsTimeout(err) {
+			rejected++
+		}
+	}
+
+	assert.Equal(t, numFlood-maxBacklog, rejected)
+	assert.Equal(t, maxBacklog, server.InboundBacklog())
+
+	// The backlog drains as soon as the flood connections are closed,
+	// well before they would time out, after which regular peers can connect again
+	for _, conn := range conns {
+		_ = conn.Close()
+	}
+
+	require.Eventually(t, func() bool {
+		return server.InboundBacklog() == 0
+	}, inboundBacklogTimeout/3, 10*time.Millisecond)
+
+	require.NoError(t, JoinAndWait(servers[1], server, DefaultBufferTimeout, DefaultJoinTimeout))
+	assert.Equal(t, 0, server.InboundBacklog())
+}
+
+func TestBanIP(t *testing.T) {
+	servers, createErr := createServers(3, map[int]*CreateServerParams{
+		0: {ConfigCallback: func(c *Config) { c.NoDiscover = true }},
+		1: {ConfigCallback: func(c *Config) { c.NoDiscover = true }},
+		2: {ConfigCallback: func(c *Config) { c.NoDiscover = true }},
+	})
+	require.NoError(t, createErr)
+
+	t.Cleanup(func() {
+		closeTestServers(t, servers)
+	})
+
+	server, peerA, peerB := servers[0], servers[1], servers[2]
+
+	// Both peers connect from the same IP
+	require.NoError(t, JoinAndWait(peerA, server, DefaultBufferTimeout, DefaultJoinTimeout))
+	require.NoError(t, JoinAndWait(peerB, server, DefaultBufferTimeout, DefaultJoinTimeout))
+
+	bannedIP := net.ParseIP("127.0.0.1")
+
+	server.BanIP(bannedIP, time.Minute)
+
+	require.Eventually(t, func() bool {
+		return len(server.host.Network().Peers()) == 0
+	}, 5*time.Second, 50*time.Millisecond)
+
+	assert.Empty(t, server.Peers())
+
+	// Further connections from the IP are refused
+	smallTimeout := 5 * time.Second
+
+	assert.Error(t, JoinAndWait(peerA, server, smallTimeout, smallTimeout))
+	assert.Empty(t, server.Peers())
+
+	// The peers can connect again once the ban is lifted
+	server.UnbanIP(bannedIP)
+
+	require.NoError(t, JoinAndWait(peerA, server, DefaultBufferTimeout, DefaultJoinTimeout))
+}
+
+func TestConnectionGater_BanExpiry(t *testing.T) {
+	gater := newConnectionGater(DefaultConfig())
+	ip := net.ParseIP("10.0.0.1")
+
+	gater.banIP(ip, time.Now().Add(-time.Second))
+	assert.False(t, gater.isIPBanned(ip))
+
+	gater.banIP(ip, time.Time{})
+	assert.True(t, gater.isIPBanned(ip))
+	assert.True(t, gater.isAddrBanned(multiaddr.StringCast("/ip4/10.0.0.1/tcp/1478")))
+	assert.False(t, gater.isAddrBanned(multiaddr.StringCast("/ip4/10.0.0.2/tcp/1478")))
+}
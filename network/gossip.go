@@ -3,12 +3,15 @@ package network
 import (
 	"context"
 	"errors"
+	"fmt"
 	"reflect"
+	"sort"
 	"sync"
 	"sync/atomic"
 
 	"github.com/armon/go-metrics"
 	"github.com/hashicorp/go-hclog"
+	lru "github.com/hashicorp/golang-lru"
 	pubsub "github.com/libp2p/go-libp2p-pubsub"
 	"github.com/libp2p/go-libp2p/core/peer"
 	"google.golang.org/protobuf/proto"
@@ -21,14 +24,27 @@ const (
 	subscribeOutputBufferSize = 1024
 )
 
+var ErrTooManyTopics = errors.New("too many gossip topics joined")
+
 type Topic struct {
 	logger hclog.Logger
 
+	ps        *pubsub.PubSub
 	topic     *pubsub.Topic
+	localID   peer.ID
 	typ       reflect.Type
 	closeCh   chan struct{}
 	closed    atomic.Bool
 	waitGroup sync.WaitGroup
+	flush     *gossipFlushTracker
+	seen      *lru.Cache // the recently seen message IDs, nil if the seen-cache is turned off
+
+	readers  *goroutineCounter // the counter of the read loops, nil if they are not counted
+	handlers *goroutineCounter // the counter of the message handlers in progress, nil if they are not counted
+
+	onClose     func()                                            // callback executed once the topic is closed
+	onValidated func(from peer.ID, result GossipValidationResult) // callback executed once a relayed message is validated
+	onReceived  func()                                            // callback executed once a remote message is received
 }
 
 func (t *Topic) createObj() proto.Message {
@@ -51,9 +67,16 @@ func (t *Topic) Close() {
 
 	// if all subscribers are finished, close the topic
 	if t.topic != nil {
+		// The validator is only registered if set
+		_ = t.ps.UnregisterTopicValidator(t.topic.String())
+
 		t.topic.Close()
 		t.topic = nil
 	}
+
+	if t.onClose != nil {
+		t.onClose()
+	}
 }
 
 func (t *Topic) Publish(obj proto.Message) error {
@@ -64,7 +87,15 @@ func (t *Topic) Publish(obj proto.Message) error {
 
 	metrics.SetGauge([]string{networkMetrics, "egress_bytes"}, float32(len(data)))
 
-	return t.topic.Publish(context.Background(), data)
+	t.flush.beginPublish()
+
+	if err := t.topic.Publish(context.Background(), data); err != nil {
+		t.flush.abortPublish()
+
+		return err
+	}
+
+	return nil
 }
 
 func (t *Topic) Subscribe(handler func(obj interface{}, from peer.ID)) error {
@@ -76,7 +107,13 @@ func (t *Topic) Subscribe(handler func(obj interface{}, from peer.ID)) error {
 	// Mark topic active.
 	t.closed.Store(false)
 
-	go t.readLoop(sub, handler)
+	readDone := t.readers.start()
+
+	go func() {
+		defer readDone()
+
+		t.readLoop(sub, handler)
+	}()
 
 	return nil
 }
@@ -106,7 +143,11 @@ func (t *Topic) readLoop(sub *pubsub.Subscription, handler func(obj interface{},
 			continue
 		}
 
+		handleDone := t.handlers.start()
+
 		go func() {
+			defer handleDone()
+
 			obj := t.createObj()
 			if err := proto.Unmarshal(msg.Data, obj); err != nil {
 				t.logger.Error("failed to unmarshal topic", "err", err)
@@ -117,24 +158,97 @@ func (t *Topic) readLoop(sub *pubsub.Subscription, handler func(obj interface{},
 
 			metrics.SetGauge([]string{networkMetrics, "ingress_bytes"}, float32(len(msg.Data)))
 
+			if msg.ReceivedFrom != t.localID && t.onReceived != nil {
+				t.onReceived()
+			}
+
 			handler(obj, msg.GetFrom())
 		}()
 	}
 }
 
 func (s *Server) NewTopic(protoID string, obj proto.Message) (*Topic, error) {
+	if s.ps == nil {
+		return nil, ErrPubSubDisabled
+	}
+
+	// The lock is held while joining, so concurrent joins can't exceed the limit
+	s.joinedTopicsLock.Lock()
+	defer s.joinedTopicsLock.Unlock()
+
+	if maxTopics := s.config.MaxTopics; maxTopics > 0 && len(s.joinedTopics) >= maxTopics {
+		metrics.IncrCounter([]string{networkMetrics, "gossip_topics_rejected"}, 1)
+
+		return nil, fmt.Errorf("%w: unable to join %s, %d topics joined", ErrTooManyTopics, protoID, maxTopics)
+	}
+
+	seen, err := newSeenCache(s.config.GossipSeenCacheSize)
+	if err != nil {
+		return nil, err
+	}
+
 	topic, err := s.ps.Join(protoID)
 	if err != nil {
 		return nil, err
 	}
 
 	tt := &Topic{
-		logger:  s.logger.Named(protoID),
-		topic:   topic,
-		typ:     reflect.TypeOf(obj).Elem(),
-		closeCh: make(chan struct{}),
+		logger:      s.logger.Named(protoID),
+		ps:          s.ps,
+		topic:       topic,
+		localID:     s.host.ID(),
+		typ:         reflect.TypeOf(obj).Elem(),
+		closeCh:     make(chan struct{}),
+		flush:       s.gossipFlush,
+		seen:        seen,
+		readers:     &s.health.topicReaders,
+		handlers:    &s.health.messageHandlers,
+		onValidated: s.recordGossipValidation,
+		onReceived:  s.recordGossipReceipt,
 	}
 	tt.closed.Store(false)
 
+	s.joinedTopics[protoID] = tt
+	s.updateTopicMetrics()
+
+	tt.onClose = func() {
+		s.joinedTopicsLock.Lock()
+		defer s.joinedTopicsLock.Unlock()
+
+		// The topic could have been joined again in the meantime
+		if s.joinedTopics[protoID] == tt {
+			delete(s.joinedTopics, protoID)
+			s.updateTopicMetrics()
+		}
+	}
+
 	return tt, nil
 }
+
+// TopicCount returns the number of currently joined topics [Thread safe]
+func (s *Server) TopicCount() int {
+	s.joinedTopicsLock.RLock()
+	defer s.joinedTopicsLock.RUnlock()
+
+	return len(s.joinedTopics)
+}
+
+// updateTopicMetrics updates the joined topic metrics
+func (s *Server) updateTopicMetrics() {
+	metrics.SetGauge([]string{networkMetrics, "gossip_topics"}, float32(len(s.joinedTopics)))
+}
+
+// SubscribedTopics returns the sorted names of the currently joined topics [Thread safe]
+func (s *Server) SubscribedTopics() []string {
+	s.joinedTopicsLock.RLock()
+	defer s.joinedTopicsLock.RUnlock()
+
+	topics := make([]string, 0, len(s.joinedTopics))
+	for topic := range s.joinedTopics {
+		topics = append(topics, topic)
+	}
+
+	sort.Strings(topics)
+
+	return topics
+}
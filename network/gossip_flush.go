@@ -0,0 +1,210 @@
+package network
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	pb "github.com/libp2p/go-libp2p-pubsub/pb"
+	"github.com/libp2p/go-libp2p/core/host"
+	"github.com/libp2p/go-libp2p/core/network"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/libp2p/go-libp2p/core/protocol"
+)
+
+// gossipFlushPollInterval is the interval at which the gossip queues are checked while flushing
+const gossipFlushPollInterval = 10 * time.Millisecond
+
+// gossipQueueState is the state of the outbound gossip queue of a peer
+type gossipQueueState struct {
+	queued  int64         // the number of RPCs put in the queue, including the hello packet
+	written int64         // the number of RPCs written to the current outbound stream
+	stream  *gossipStream // the current outbound stream, which drains the queue
+}
+
+// gossipFlushTracker keeps track of the outbound gossip queues, so they can be drained on shutdown.
+// The RPCs put in the queues are reported by pubsub (as an event tracer), while the written ones
+// are counted on the outbound streams pubsub opens
+type gossipFlushTracker struct {
+	localID peer.ID
+
+	lock      sync.Mutex
+	published uint64                        // the number of local messages handed to pubsub
+	routed    uint64                        // the number of local messages processed by the router
+	queues    map[peer.ID]*gossipQueueState // peerID -> outbound queue state
+}
+
+// newGossipFlushTracker creates a new gossip flush tracker
+func newGossipFlushTracker(localID peer.ID) *gossipFlushTracker {
+	return &gossipFlushTracker{
+		localID: localID,
+		queues:  make(map[peer.ID]*gossipQueueState),
+	}
+}
+
+// beginPublish marks a local message as handed to pubsub [Thread safe]
+func (t *gossipFlushTracker) beginPublish() {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	t.published++
+}
+
+// abortPublish reverts beginPublish, for a message pubsub refused [Thread safe]
+func (t *gossipFlushTracker) abortPublish() {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	t.published--
+}
+
+// allRouted checks if all the local messages handed to pubsub are processed by the router [Thread safe]
+func (t *gossipFlushTracker) allRouted() bool {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	return t.routed >= t.published
+}
+
+// pending returns the number of the RPCs still waiting in the outbound queues [Thread safe]
+func (t *gossipFlushTracker) pending() int64 {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	var pending int64
+
+	for _, queue := range t.queues {
+		if queue.queued > queue.written {
+			pending += queue.queued - queue.written
+		}
+	}
+
+	return pending
+}
+
+// queue returns the outbound queue state of the peer. Has to be called with the lock held
+func (t *gossipFlushTracker) queue(peerID peer.ID) *gossipQueueState {
+	queue, ok := t.queues[peerID]
+	if !ok {
+		queue = &gossipQueueState{}
+		t.queues[peerID] = queue
+	}
+
+	return queue
+}
+
+// streamOpened makes the stream the one draining the outbound queue of the peer [Thread safe]
+func (t *gossipFlushTracker) streamOpened(peerID peer.ID, stream *gossipStream) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	queue := t.queue(peerID)
+	queue.stream = stream
+
+	// Every new queue starts with the hello packet, which is not traced
+	queue.queued++
+}
+
+// rpcWritten counts an RPC written to the outbound stream of the peer [Thread safe]
+func (t *gossipFlushTracker) rpcWritten(peerID peer.ID, stream *gossipStream) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	// Writes of a stream which belonged to a removed queue are not accounted
+	if queue, ok := t.queues[peerID]; ok && queue.stream == stream {
+		queue.written++
+	}
+}
+
+// Trace accounts the local messages processed by the router, the RPCs put
+// in the outbound queues, and the queues discarded by pubsub [pubsub.EventTracer]
+func (t *gossipFlushTracker) Trace(evt *pb.TraceEvent) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	switch evt.GetType() {
+	case pb.TraceEvent_DELIVER_MESSAGE:
+		if peer.ID(evt.GetDeliverMessage().GetReceivedFrom()) == t.localID {
+			t.routed++
+		}
+	case pb.TraceEvent_SEND_RPC:
+		t.queue(peer.ID(evt.GetSendRPC().GetSendTo())).queued++
+	case pb.TraceEvent_REMOVE_PEER:
+		delete(t.queues, peer.ID(evt.GetRemovePeer().GetPeerID()))
+	}
+}
+
+// gossipHost is the host handed to pubsub, which tracks the outbound gossip streams
+type gossipHost struct {
+	host.Host
+
+	flush *gossipFlushTracker
+}
+
+// NewStream opens an outbound gossip stream, counting the RPCs written to it
+func (h *gossipHost) NewStream(ctx context.Context, p peer.ID, pids ...protocol.ID) (network.Stream, error) {
+	stream, err := h.Host.NewStream(ctx, p, pids...)
+	if err != nil {
+		return nil, err
+	}
+
+	tracked := &gossipStream{Stream: stream, flush: h.flush}
+	h.flush.streamOpened(p, tracked)
+
+	return tracked, nil
+}
+
+// gossipStream is an outbound gossip stream. Pubsub writes each RPC in a single write
+type gossipStream struct {
+	network.Stream
+
+	flush *gossipFlushTracker
+}
+
+// Write writes the RPC to the stream, and counts it as written
+func (s *gossipStream) Write(b []byte) (int, error) {
+	n, err := s.Stream.Write(b)
+	if err == nil {
+		s.flush.rpcWritten(s.Conn().RemotePeer(), s)
+	}
+
+	return n, err
+}
+
+// FlushGossip waits for the gossip messages published so far to be written to the peers,
+// until the outbound pubsub queues are drained or the context is done.
+// The flush is best-effort: the messages are written to the peer streams, but there is
+// no guarantee the peers received them, and the messages queued for peers which
+// disconnect in the meantime are lost. Returns the context error if the queues were not drained
+func (s *Server) FlushGossip(ctx context.Context) error {
+	if s.ps == nil || s.gossipFlush == nil {
+		return nil
+	}
+
+	ticker := time.NewTicker(gossipFlushPollInterval)
+	defer ticker.Stop()
+
+	wait := func(done func() bool) error {
+		for !done() {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-ticker.C:
+			}
+		}
+
+		return nil
+	}
+
+	if err := wait(s.gossipFlush.allRouted); err != nil {
+		return err
+	}
+
+	// The router queues a message for the peers after it is processed,
+	// so wait for the pubsub event loop to complete the current iteration
+	_ = s.ps.GetTopics()
+
+	return wait(func() bool {
+		return s.gossipFlush.pending() == 0
+	})
+}
@@ -0,0 +1,83 @@
+package network
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	testproto "github.com/0xPolygon/polygon-edge/network/proto"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFlushGossip(t *testing.T) {
+	const (
+		topicName   = "flushed-topic"
+		numMessages = 64
+	)
+
+	servers, createErr := createServers(2, map[int]*CreateServerParams{
+		0: {ConfigCallback: func(c *Config) {
+			c.NoDiscover = true
+			c.GossipFlushTimeout = 5 * time.Second
+		}},
+		1: {ConfigCallback: func(c *Config) { c.NoDiscover = true }},
+	})
+	require.NoError(t, createErr)
+
+	publisher, receiver := servers[0], servers[1]
+
+	// The publisher is closed by the test
+	t.Cleanup(func() {
+		closeTestServers(t, servers[1:])
+	})
+
+	require.NoError(t, JoinAndWait(publisher, receiver, DefaultBufferTimeout, DefaultJoinTimeout))
+
+	publisherTopic, err := publisher.NewTopic(topicName, &testproto.GenericMessage{})
+	require.NoError(t, err)
+
+	receiverTopic, err := receiver.NewTopic(topicName, &testproto.GenericMessage{})
+	require.NoError(t, err)
+
+	var (
+		receivedLock sync.Mutex
+		received     = make(map[string]struct{})
+	)
+
+	require.NoError(t, receiverTopic.Subscribe(func(obj interface{}, _ peer.ID) {
+		message, _ := obj.(*testproto.GenericMessage)
+
+		receivedLock.Lock()
+		received[message.Message] = struct{}{}
+		receivedLock.Unlock()
+	}))
+
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultJoinTimeout)
+	defer cancel()
+
+	require.NoError(t, WaitForSubscribers(ctx, publisher, topicName, 1))
+
+	for i := 0; i < numMessages; i++ {
+		require.NoError(t, publisherTopic.Publish(&testproto.GenericMessage{Message: fmt.Sprintf("message %d", i)}))
+	}
+
+	flushCtx, flushCancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer flushCancel()
+
+	// All the published messages are written to the receiver before the publisher closes
+	require.NoError(t, publisher.FlushGossip(flushCtx))
+	assert.Zero(t, publisher.gossipFlush.pending())
+
+	require.NoError(t, publisher.Close())
+
+	require.Eventually(t, func() bool {
+		receivedLock.Lock()
+		defer receivedLock.Unlock()
+
+		return len(received) == numMessages
+	}, 5*time.Second, 50*time.Millisecond)
+}
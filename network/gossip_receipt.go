@@ -0,0 +1,73 @@
+package network
+
+import (
+	"sync/atomic"
+	"time"
+
+	"github.com/armon/go-metrics"
+)
+
+// DefaultGossipSilenceThreshold is the default time without received gossip
+// after which a node with peers is reported as isolated
+const DefaultGossipSilenceThreshold = 2 * time.Minute
+
+// gossipReceiptState is the state of the gossip receipt, used for detecting
+// nodes that are connected, but isolated at the application layer
+type gossipReceiptState struct {
+	last   atomic.Int64 // the time the last gossip message was received (unix nano), the creation time if none was
+	silent atomic.Bool  // flag indicating if the current gossip silence was already reported
+}
+
+// recordGossipReceipt marks a gossip message from a remote peer as received now [Thread safe]
+func (s *Server) recordGossipReceipt() {
+	s.gossipReceipt.last.Store(s.clock.Now().UnixNano())
+}
+
+// TimeSinceLastGossip returns the time elapsed since a gossip message was last received
+// on any topic, or since the server was created if none was [Thread safe]
+func (s *Server) TimeSinceLastGossip() time.Duration {
+	return s.clock.Now().Sub(time.Unix(0, s.gossipReceipt.last.Load()))
+}
+
+// runGossipSilenceChecks periodically checks if the node receives gossip, and reports
+// when it receives none for longer than the threshold despite having peers
+func (s *Server) runGossipSilenceChecks() {
+	threshold := s.config.GossipSilenceThreshold
+
+	for {
+		select {
+		case <-s.clock.After(threshold / 2):
+		case <-s.closeCh:
+			return
+		}
+
+		s.checkGossipSilence(threshold)
+	}
+}
+
+// checkGossipSilence reports the gossip silence exceeding the threshold,
+// once per silence period
+func (s *Server) checkGossipSilence(threshold time.Duration) {
+	silence := s.TimeSinceLastGossip()
+
+	metrics.SetGauge([]string{networkMetrics, "time_since_last_gossip"}, float32(silence.Seconds()))
+
+	// Without peers or topics there is no gossip to expect
+	if silence < threshold || s.numPeers() == 0 || s.TopicCount() == 0 {
+		s.gossipReceipt.silent.Store(false)
+
+		return
+	}
+
+	if s.gossipReceipt.silent.Swap(true) {
+		return
+	}
+
+	metrics.IncrCounter([]string{networkMetrics, "gossip_silences"}, 1)
+
+	s.logger.Warn(
+		"No gossip received despite having peers, the node may be isolated",
+		"silence", silence,
+		"peers", s.numPeers(),
+	)
+}
@@ -0,0 +1,81 @@
+package network
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	testproto "github.com/0xPolygon/polygon-edge/network/proto"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTimeSinceLastGossip(t *testing.T) {
+	const (
+		topicName = "gossip-receipt"
+		threshold = time.Minute
+	)
+
+	clock := newFakeClock()
+
+	servers, createErr := createServers(2, map[int]*CreateServerParams{
+		0: {ConfigCallback: func(c *Config) { c.NoDiscover = true }},
+		1: {ConfigCallback: func(c *Config) {
+			c.NoDiscover = true
+			c.Clock = clock
+		}},
+	})
+	require.NoError(t, createErr)
+
+	t.Cleanup(func() {
+		closeTestServers(t, servers)
+	})
+
+	sender, receiver := servers[0], servers[1]
+
+	require.NoError(t, JoinAndWait(sender, receiver, DefaultBufferTimeout, DefaultJoinTimeout))
+
+	senderTopic, err := sender.NewTopic(topicName, &testproto.GenericMessage{})
+	require.NoError(t, err)
+
+	receiverTopic, err := receiver.NewTopic(topicName, &testproto.GenericMessage{})
+	require.NoError(t, err)
+
+	received := make(chan struct{}, 1)
+
+	require.NoError(t, receiverTopic.Subscribe(func(_ interface{}, _ peer.ID) {
+		received <- struct{}{}
+	}))
+
+	// No gossip is received yet, so the silence grows from the creation
+	assert.Zero(t, receiver.TimeSinceLastGossip())
+
+	clock.Advance(2 * threshold)
+	assert.Equal(t, 2*threshold, receiver.TimeSinceLastGossip())
+
+	// The silence beyond the threshold is reported, as the node has peers and topics
+	receiver.checkGossipSilence(threshold)
+	assert.True(t, receiver.gossipReceipt.silent.Load())
+
+	ctx, cancelFn := context.WithTimeout(context.Background(), DefaultJoinTimeout)
+	defer cancelFn()
+
+	require.NoError(t, WaitForSubscribers(ctx, sender, topicName, 1))
+	require.NoError(t, senderTopic.Publish(&testproto.GenericMessage{Message: "hello"}))
+
+	select {
+	case <-received:
+	case <-ctx.Done():
+		t.Fatal("gossip message not received")
+	}
+
+	// The receipt resets the silence
+	assert.Zero(t, receiver.TimeSinceLastGossip())
+
+	receiver.checkGossipSilence(threshold)
+	assert.False(t, receiver.gossipReceipt.silent.Load())
+
+	clock.Advance(time.Second)
+	assert.Equal(t, time.Second, receiver.TimeSinceLastGossip())
+}
@@ -0,0 +1,49 @@
+package network
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+
+	lru "github.com/hashicorp/golang-lru"
+	"google.golang.org/protobuf/proto"
+)
+
+// newSeenCache creates the cache of the recently seen message IDs of a topic,
+// or returns nil if the cache is turned off
+func newSeenCache(size int) (*lru.Cache, error) {
+	if size <= 0 {
+		return nil, nil
+	}
+
+	return lru.New(size)
+}
+
+// MessageID returns the ID of the message, derived from its content,
+// so the same message published twice has the same ID
+func (t *Topic) MessageID(obj proto.Message) (string, error) {
+	data, err := proto.MarshalOptions{Deterministic: true}.Marshal(obj)
+	if err != nil {
+		return "", err
+	}
+
+	hash := sha256.Sum256(data)
+
+	return hex.EncodeToString(hash[:]), nil
+}
+
+// AlreadySeen marks the message as seen, and reports if it was already seen on the topic.
+// Only the most recently seen message IDs are remembered, up to the configured cache size.
+// It always returns false if the seen-cache is turned off [Thread safe]
+func (t *Topic) AlreadySeen(msgID string) bool {
+	if t.seen == nil {
+		return false
+	}
+
+	seen, _ := t.seen.ContainsOrAdd(msgID, struct{}{})
+	if seen {
+		// A repeatedly seen message is kept the longest
+		t.seen.Get(msgID)
+	}
+
+	return seen
+}
@@ -0,0 +1,86 @@
+package network
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	testproto "github.com/0xPolygon/polygon-edge/network/proto"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTopic_AlreadySeen(t *testing.T) {
+	const topicName = "/seen/1.0"
+
+	servers, createErr := createServers(2, map[int]*CreateServerParams{
+		1: {ConfigCallback: func(c *Config) {
+			c.GossipSeenCacheSize = 16
+		}},
+	})
+	require.NoError(t, createErr)
+
+	t.Cleanup(func() {
+		closeTestServers(t, servers)
+	})
+
+	require.NoError(t, JoinAndWait(servers[0], servers[1], DefaultBufferTimeout, DefaultJoinTimeout))
+
+	publisherTopic, err := servers[0].NewTopic(topicName, &testproto.GenericMessage{})
+	require.NoError(t, err)
+
+	subscriberTopic, err := servers[1].NewTopic(topicName, &testproto.GenericMessage{})
+	require.NoError(t, err)
+
+	seenCh := make(chan bool, 2)
+
+	require.NoError(t, subscriberTopic.Subscribe(func(obj interface{}, _ peer.ID) {
+		message, ok := obj.(*testproto.GenericMessage)
+		require.True(t, ok)
+
+		msgID, err := subscriberTopic.MessageID(message)
+		require.NoError(t, err)
+
+		seenCh <- subscriberTopic.AlreadySeen(msgID)
+	}))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	require.NoError(t, WaitForSubscribers(ctx, servers[0], topicName, 1))
+
+	// The same content is published twice, which the gossip layer doesn't dedupe
+	for i := 0; i < 2; i++ {
+		require.NoError(t, publisherTopic.Publish(&testproto.GenericMessage{Message: "duplicate"}))
+
+		select {
+		case seen := <-seenCh:
+			assert.Equal(t, i > 0, seen, "message %d", i)
+		case <-time.After(10 * time.Second):
+			t.Fatalf("message %d not received", i)
+		}
+	}
+
+	// The seen-cache is turned off on the publisher
+	msgID, err := publisherTopic.MessageID(&testproto.GenericMessage{Message: "duplicate"})
+	require.NoError(t, err)
+	assert.False(t, publisherTopic.AlreadySeen(msgID))
+	assert.False(t, publisherTopic.AlreadySeen(msgID))
+}
+
+func TestTopic_AlreadySeenBounded(t *testing.T) {
+	seen, err := newSeenCache(2)
+	require.NoError(t, err)
+
+	topic := &Topic{seen: seen}
+
+	assert.False(t, topic.AlreadySeen("A"))
+	assert.False(t, topic.AlreadySeen("B"))
+	assert.True(t, topic.AlreadySeen("A"))
+
+	// The least recently seen message is forgotten first
+	assert.False(t, topic.AlreadySeen("C"))
+	assert.False(t, topic.AlreadySeen("B"))
+	assert.False(t, topic.AlreadySeen("A"))
+}
@@ -4,11 +4,14 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"sync"
 	"testing"
 	"time"
 
 	testproto "github.com/0xPolygon/polygon-edge/network/proto"
 	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func NumSubscribers(srv *Server, topic string) int {
@@ -118,3 +121,148 @@ func Test_RepeatedClose(t *testing.T) {
 	topic.Close()
 	topic.Close()
 }
+
+func TestDisablePubSub(t *testing.T) {
+	servers, createErr := createServers(2, map[int]*CreateServerParams{
+		0: {
+			ConfigCallback: func(c *Config) {
+				c.DisablePubSub = true
+			},
+		},
+	})
+	if createErr != nil {
+		t.Fatalf("Unable to create servers, %v", createErr)
+	}
+
+	t.Cleanup(func() {
+		closeTestServers(t, servers)
+	})
+
+	if _, err := servers[0].NewTopic("/test/1.0", &testproto.GenericMessage{}); !errors.Is(err, ErrPubSubDisabled) {
+		t.Fatalf("Expected the pubsub disabled error, got %v", err)
+	}
+
+	// The rest of the networking stack works without pubsub
+	if joinErr := JoinAndWait(servers[0], servers[1], DefaultBufferTimeout, DefaultJoinTimeout); joinErr != nil {
+		t.Fatalf("Unable to join servers, %v", joinErr)
+	}
+}
+
+func TestSubscribedTopics(t *testing.T) {
+	server, createErr := CreateServer(nil)
+	if createErr != nil {
+		t.Fatalf("Unable to create server, %v", createErr)
+	}
+
+	t.Cleanup(func() {
+		assert.NoError(t, server.Close())
+	})
+
+	joinTopic := func(name string) *Topic {
+		topic, err := server.NewTopic(name, &testproto.GenericMessage{})
+		require.NoError(t, err)
+
+		return topic
+	}
+
+	assert.Empty(t, server.SubscribedTopics())
+
+	topicA := joinTopic("/a/1.0")
+	topicB := joinTopic("/b/1.0")
+
+	assert.Equal(t, []string{"/a/1.0", "/b/1.0"}, server.SubscribedTopics())
+
+	topicA.Close()
+	topicA.Close()
+
+	assert.Equal(t, []string{"/b/1.0"}, server.SubscribedTopics())
+
+	// A left topic can be joined again
+	topicA = joinTopic("/a/1.0")
+
+	assert.Equal(t, []string{"/a/1.0", "/b/1.0"}, server.SubscribedTopics())
+
+	// Join and leave topics concurrently, keeping every other one
+	const numTopics = 20
+
+	var wg sync.WaitGroup
+
+	expectedTopics := []string{"/a/1.0", "/b/1.0"}
+
+	for i := 0; i < numTopics; i++ {
+		name := fmt.Sprintf("/concurrent/%02d", i)
+		if i%2 == 0 {
+			expectedTopics = append(expectedTopics, name)
+		}
+
+		wg.Add(1)
+
+		go func(i int, name string) {
+			defer wg.Done()
+
+			topic, err := server.NewTopic(name, &testproto.GenericMessage{})
+			assert.NoError(t, err)
+
+			if i%2 == 1 {
+				topic.Close()
+			}
+		}(i, name)
+	}
+
+	wg.Wait()
+
+	assert.Equal(t, expectedTopics, server.SubscribedTopics())
+
+	topicA.Close()
+	topicB.Close()
+
+	assert.Len(t, server.SubscribedTopics(), numTopics/2)
+}
+
+func TestMaxTopics(t *testing.T) {
+	const maxTopics = 3
+
+	server, createErr := CreateServer(&CreateServerParams{ConfigCallback: func(c *Config) {
+		c.MaxTopics = maxTopics
+	}})
+	require.NoError(t, createErr)
+
+	t.Cleanup(func() {
+		assert.NoError(t, server.Close())
+	})
+
+	topics := make([]*Topic, 0, maxTopics)
+
+	for i := 0; i < maxTopics; i++ {
+		topic, err := server.NewTopic(fmt.Sprintf("/limited/%d", i), &testproto.GenericMessage{})
+		require.NoError(t, err)
+
+		topics = append(topics, topic)
+	}
+
+	assert.Equal(t, maxTopics, server.TopicCount())
+
+	// The join over the limit fails, without joining the pubsub topic
+	_, err := server.NewTopic("/limited/extra", &testproto.GenericMessage{})
+	assert.ErrorIs(t, err, ErrTooManyTopics)
+	assert.Equal(t, maxTopics, server.TopicCount())
+
+	// No pubsub topic handle is left behind, as it couldn't be joined again otherwise
+	leaked, err := server.ps.Join("/limited/extra")
+	require.NoError(t, err)
+	require.NoError(t, leaked.Close())
+
+	// A left topic frees up room for another one
+	topics[0].Close()
+
+	extra, err := server.NewTopic("/limited/extra", &testproto.GenericMessage{})
+	require.NoError(t, err)
+
+	extra.Close()
+
+	for _, topic := range topics[1:] {
+		topic.Close()
+	}
+
+	assert.Zero(t, server.TopicCount())
+}
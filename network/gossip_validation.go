@@ -0,0 +1,176 @@
+package network
+
+import (
+	"context"
+	"sync"
+
+	"github.com/armon/go-metrics"
+	pubsub "github.com/libp2p/go-libp2p-pubsub"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"google.golang.org/protobuf/proto"
+)
+
+// GossipValidationResult is the outcome of a gossip message validation
+type GossipValidationResult int
+
+const (
+	// GossipAccept accepts the message, so it is delivered and relayed
+	GossipAccept GossipValidationResult = iota
+
+	// GossipReject rejects the message as invalid, penalizing the peer which relayed it
+	GossipReject
+
+	// GossipIgnore drops the message without penalizing the peer (e.g. a stale message)
+	GossipIgnore
+)
+
+// GossipValidator decides the outcome of a gossip message, before it is delivered or relayed
+type GossipValidator func(obj interface{}, from peer.ID) GossipValidationResult
+
+// GossipValidationStats are the validation outcomes of the gossip messages relayed by a peer
+type GossipValidationStats struct {
+	Accepted uint64 // the number of accepted messages
+	Rejected uint64 // the number of rejected messages
+	Ignored  uint64 // the number of ignored messages
+}
+
+// maxGossipValidationRecords is the maximum number of peers whose validation outcomes are kept
+const maxGossipValidationRecords = 4096
+
+// gossipValidationTracker keeps track of the gossip validation outcomes per peer.
+// The number of records is bounded, so the peers which relayed no messages for the longest
+// are forgotten first, unless they are still connected
+type gossipValidationTracker struct {
+	sync.Mutex
+
+	stats           *boundedPeerRecords // peerID -> *GossipValidationStats, the connected peers are never evicted
+	sessionAccepted map[peer.ID]uint64  // the accepted messages of the connected peers, in the current session
+}
+
+// newGossipValidationTracker creates a new gossip validation tracker
+func newGossipValidationTracker(isConnected func(peer.ID) bool) *gossipValidationTracker {
+	return &gossipValidationTracker{
+		stats:           newBoundedPeerRecords(maxGossipValidationRecords, isConnected),
+		sessionAccepted: make(map[peer.ID]uint64),
+	}
+}
+
+// record counts the validation outcome of a message relayed by the peer [Thread safe]
+func (t *gossipValidationTracker) record(peerID peer.ID, result GossipValidationResult) {
+	t.Lock()
+	defer t.Unlock()
+
+	stats := &GossipValidationStats{}
+	if record, ok := t.stats.get(peerID); ok {
+		stats, _ = record.(*GossipValidationStats)
+	}
+
+	// The peer becomes the most recent one to relay a message
+	t.stats.add(peerID, stats)
+
+	switch result {
+	case GossipAccept:
+		stats.Accepted++
+		t.sessionAccepted[peerID]++
+	case GossipReject:
+		stats.Rejected++
+	case GossipIgnore:
+		stats.Ignored++
+	}
+}
+
+// get returns the validation outcomes of the messages relayed by the peer [Thread safe]
+func (t *gossipValidationTracker) get(peerID peer.ID) GossipValidationStats {
+	t.Lock()
+	defer t.Unlock()
+
+	if record, ok := t.stats.get(peerID); ok {
+		stats, _ := record.(*GossipValidationStats)
+
+		return *stats
+	}
+
+	return GossipValidationStats{}
+}
+
+// getSessionAccepted returns the number of accepted messages relayed by the peer
+// since it connected [Thread safe]
+func (t *gossipValidationTracker) getSessionAccepted(peerID peer.ID) uint64 {
+	t.Lock()
+	defer t.Unlock()
+
+	return t.sessionAccepted[peerID]
+}
+
+// endSession resets the accepted messages of the current session, once the peer disconnects [Thread safe]
+func (t *gossipValidationTracker) endSession(peerID peer.ID) {
+	t.Lock()
+	defer t.Unlock()
+
+	delete(t.sessionAccepted, peerID)
+}
+
+// remove removes the validation outcomes of the peer [Thread safe]
+func (t *gossipValidationTracker) remove(peerID peer.ID) {
+	t.Lock()
+	defer t.Unlock()
+
+	t.stats.remove(peerID)
+	delete(t.sessionAccepted, peerID)
+}
+
+// GossipValidationStats returns the validation outcomes of the gossip messages
+// relayed by the peer, across all the topics with a registered validator [Thread safe]
+func (s *Server) GossipValidationStats(peerID peer.ID) GossipValidationStats {
+	return s.gossipValidation.get(peerID)
+}
+
+// recordGossipValidation counts the validation outcome of a message relayed by the peer.
+// Rejected messages lower the peer reputation
+func (s *Server) recordGossipValidation(peerID peer.ID, result GossipValidationResult) {
+	s.gossipValidation.record(peerID, result)
+
+	if result != GossipReject {
+		return
+	}
+
+	penalty := s.penalties.penalize(peerID, s.clock.Now())
+
+	s.logger.Debug("Gossip message rejected", "peer", peerID, "penalty", penalty)
+
+	metrics.IncrCounter([]string{networkMetrics, "gossip_rejected_messages"}, 1)
+}
+
+// RegisterValidator sets the validator of the messages received on the topic.
+// Messages which can't be decoded are rejected without reaching the validator
+func (t *Topic) RegisterValidator(validator GossipValidator) error {
+	return t.ps.RegisterTopicValidator(
+		t.topic.String(),
+		func(_ context.Context, from peer.ID, msg *pubsub.Message) pubsub.ValidationResult {
+			result := GossipReject
+
+			if obj := t.createObj(); obj != nil && proto.Unmarshal(msg.Data, obj) == nil {
+				result = validator(obj, msg.GetFrom())
+			}
+
+			// Messages published by the node itself are validated as well, but not accounted
+			if from != t.localID && t.onValidated != nil {
+				t.onValidated(from, result)
+			}
+
+			return toPubSubResult(result)
+		},
+	)
+}
+
+// toPubSubResult converts the validation result to the pubsub one
+func toPubSubResult(result GossipValidationResult) pubsub.ValidationResult {
+	switch result {
+	case GossipAccept:
+		return pubsub.ValidationAccept
+	case GossipIgnore:
+		return pubsub.ValidationIgnore
+	default:
+		return pubsub.ValidationReject
+	}
+}
@@ -0,0 +1,93 @@
+package network
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	testproto "github.com/0xPolygon/polygon-edge/network/proto"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGossipValidationStats(t *testing.T) {
+	servers, createErr := createServers(3, nil)
+	require.NoError(t, createErr)
+
+	t.Cleanup(func() {
+		closeTestServers(t, servers)
+	})
+
+	server, spammer, honest := servers[0], servers[1], servers[2]
+	spammerID, honestID := spammer.host.ID(), honest.host.ID()
+
+	// The peers are only connected to the server, so it receives their messages directly
+	require.NoError(t, JoinAndWait(server, spammer, DefaultBufferTimeout, DefaultJoinTimeout))
+	require.NoError(t, JoinAndWait(server, honest, DefaultBufferTimeout, DefaultJoinTimeout))
+
+	const topicName = "validated-topic"
+
+	topics := make([]*Topic, len(servers))
+
+	for i, srv := range servers {
+		topic, err := srv.NewTopic(topicName, &testproto.GenericMessage{})
+		require.NoError(t, err)
+
+		require.NoError(t, topic.Subscribe(func(interface{}, peer.ID) {}))
+
+		topics[i] = topic
+	}
+
+	require.NoError(t, topics[0].RegisterValidator(func(obj interface{}, from peer.ID) GossipValidationResult {
+		if from == spammerID {
+			return GossipReject
+		}
+
+		return GossipAccept
+	}))
+
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultJoinTimeout)
+	defer cancel()
+
+	require.NoError(t, WaitForSubscribers(ctx, server, topicName, 2))
+
+	publish := func(topic *Topic, message string) {
+		require.NoError(t, topic.Publish(&testproto.GenericMessage{Message: message}))
+	}
+
+	require.Eventually(t, func() bool {
+		publish(topics[1], time.Now().String())
+		publish(topics[2], time.Now().String())
+
+		return server.GossipValidationStats(spammerID).Rejected >= 2 &&
+			server.GossipValidationStats(honestID).Accepted >= 2
+	}, 10*time.Second, 100*time.Millisecond)
+
+	spammerStats := server.GossipValidationStats(spammerID)
+	assert.Zero(t, spammerStats.Accepted)
+	assert.Positive(t, server.PeerPenalty(spammerID))
+
+	honestStats := server.GossipValidationStats(honestID)
+	assert.Zero(t, honestStats.Rejected)
+	assert.Zero(t, server.PeerPenalty(honestID))
+}
+
+func TestGossipValidationTracker_Capacity(t *testing.T) {
+	connectedID := peer.ID("peer-0")
+
+	tracker := newGossipValidationTracker(func(peerID peer.ID) bool {
+		return peerID == connectedID
+	})
+
+	for i := 0; i < maxGossipValidationRecords+10; i++ {
+		tracker.record(peer.ID(fmt.Sprintf("peer-%d", i)), GossipAccept)
+	}
+
+	// The peers which relayed no messages for the longest are dropped, except for the connected peers
+	assert.Equal(t, maxGossipValidationRecords, tracker.stats.len())
+	assert.Equal(t, uint64(1), tracker.get(connectedID).Accepted)
+	assert.Zero(t, tracker.get(peer.ID("peer-1")).Accepted)
+	assert.Equal(t, uint64(1), tracker.get(peer.ID(fmt.Sprintf("peer-%d", maxGossipValidationRecords+9))).Accepted)
+}
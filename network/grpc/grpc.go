@@ -5,30 +5,214 @@ import (
 	"errors"
 	"io"
 	"net"
+	"runtime/debug"
+	"time"
 
 	"google.golang.org/grpc/credentials/insecure"
 
 	manet "github.com/multiformats/go-multiaddr/net"
 
 	"github.com/0xPolygon/polygon-edge/validate"
+	"github.com/hashicorp/go-hclog"
 	"github.com/libp2p/go-libp2p/core/network"
 	"github.com/libp2p/go-libp2p/core/peer"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	grpcPeer "google.golang.org/grpc/peer"
+	"google.golang.org/grpc/stats"
+	"google.golang.org/grpc/status"
 )
 
 type GrpcStream struct {
 	ctx      context.Context
+	cancel   context.CancelFunc // cancels the context, once the listener is closed
 	streamCh chan network.Stream
 
 	grpcServer *grpc.Server
+
+	clientOpts []grpc.DialOption // the options of the client connections opened over the streams
+}
+
+// streamConfig is the configuration of the gRPC server and the clients of a GrpcStream
+type streamConfig struct {
+	serverOpts []grpc.ServerOption
+	clientOpts []grpc.DialOption
+	logger     hclog.Logger // the logger of the recovered handler panics
+}
+
+// StreamOption configures the gRPC server and the clients of a GrpcStream
+type StreamOption func(config *streamConfig)
+
+// WithLogger sets the logger of the handler panics recovered by the gRPC server
+func WithLogger(logger hclog.Logger) StreamOption {
+	return func(config *streamConfig) {
+		config.logger = logger
+	}
+}
+
+// WithMaxMsgSize limits the size of the messages sent and received
+// by both the gRPC server and the clients
+func WithMaxMsgSize(size int) StreamOption {
+	return func(config *streamConfig) {
+		config.serverOpts = append(config.serverOpts, grpc.MaxRecvMsgSize(size), grpc.MaxSendMsgSize(size))
+		config.clientOpts = append(config.clientOpts, grpc.WithDefaultCallOptions(
+			grpc.MaxCallRecvMsgSize(size),
+			grpc.MaxCallSendMsgSize(size),
+		))
+	}
+}
+
+// WithOversizedMsgHandler sets the callback invoked with the peers whose requests were
+// rejected by the gRPC server for exceeding the maximum message size. The requests are rejected
+// based on their length prefix, before the messages are read, so the callback runs before any parsing
+func WithOversizedMsgHandler(handler func(peerID peer.ID)) StreamOption {
+	return func(config *streamConfig) {
+		config.serverOpts = append(config.serverOpts, grpc.StatsHandler(&oversizedMsgStats{handler: handler}))
+	}
+}
+
+// oversizedMsgStats is the gRPC server stats handler reporting the oversized requests
+type oversizedMsgStats struct {
+	handler func(peerID peer.ID)
+}
+
+// TagRPC implements the stats.Handler interface
+func (o *oversizedMsgStats) TagRPC(ctx context.Context, _ *stats.RPCTagInfo) context.Context {
+	return ctx
+}
+
+// HandleRPC reports the peer of a call that failed due to an oversized message
+func (o *oversizedMsgStats) HandleRPC(ctx context.Context, rpcStats stats.RPCStats) {
+	end, ok := rpcStats.(*stats.End)
+	if !ok || status.Code(end.Error) != codes.ResourceExhausted {
+		return
+	}
+
+	contextPeer, ok := grpcPeer.FromContext(ctx)
+	if !ok {
+		return
+	}
+
+	if addr, ok := contextPeer.Addr.(*wrapLibp2pAddr); ok {
+		o.handler(addr.id)
+	}
+}
+
+// TagConn implements the stats.Handler interface
+func (o *oversizedMsgStats) TagConn(ctx context.Context, _ *stats.ConnTagInfo) context.Context {
+	return ctx
 }
 
-func NewGrpcStream() *GrpcStream {
+// HandleConn implements the stats.Handler interface
+func (o *oversizedMsgStats) HandleConn(context.Context, stats.ConnStats) {}
+
+// WithCallTimeout sets the deadline of every unary call, both on the gRPC server and the clients.
+// Earlier deadlines set by the caller are kept
+func WithCallTimeout(timeout time.Duration) StreamOption {
+	return func(config *streamConfig) {
+		config.serverOpts = append(config.serverOpts, grpc.ChainUnaryInterceptor(
+			func(
+				ctx context.Context,
+				req interface{},
+				_ *grpc.UnaryServerInfo,
+				handler grpc.UnaryHandler,
+			) (interface{}, error) {
+				ctx, cancel := context.WithTimeout(ctx, timeout)
+				defer cancel()
+
+				return handler(ctx, req)
+			},
+		))
+		config.clientOpts = append(config.clientOpts, grpc.WithChainUnaryInterceptor(
+			func(
+				ctx context.Context,
+				method string,
+				req, reply interface{},
+				cc *grpc.ClientConn,
+				invoker grpc.UnaryInvoker,
+				opts ...grpc.CallOption,
+			) error {
+				ctx, cancel := context.WithTimeout(ctx, timeout)
+				defer cancel()
+
+				return invoker(ctx, method, req, reply, cc, opts...)
+			},
+		))
+	}
+}
+
+func NewGrpcStream(opts ...StreamOption) *GrpcStream {
+	config := &streamConfig{
+		serverOpts: make([]grpc.ServerOption, 0),
+		clientOpts: make([]grpc.DialOption, 0),
+		logger:     hclog.NewNullLogger(),
+	}
+
+	for _, opt := range opts {
+		opt(config)
+	}
+
+	// The panics are recovered by the outermost interceptors, so they cover
+	// the interceptors of the options as well, while the peer data is wrapped
+	// by the innermost interceptor, so the handlers get the wrapped context regardless of the options
+	serverOpts := append([]grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(recoveryUnaryInterceptor(config.logger)),
+		grpc.ChainStreamInterceptor(recoveryStreamInterceptor(config.logger)),
+	}, config.serverOpts...)
+	serverOpts = append(serverOpts, grpc.ChainUnaryInterceptor(interceptor))
+
+	ctx, cancel := context.WithCancel(context.Background())
+
 	return &GrpcStream{
-		ctx:        context.Background(),
+		ctx:        ctx,
+		cancel:     cancel,
 		streamCh:   make(chan network.Stream),
-		grpcServer: grpc.NewServer(grpc.UnaryInterceptor(interceptor)),
+		grpcServer: grpc.NewServer(serverOpts...),
+		clientOpts: config.clientOpts,
+	}
+}
+
+// errInternal is the error returned to the callers of the handlers that panicked
+var errInternal = status.Error(codes.Internal, "internal error")
+
+// recoveryUnaryInterceptor recovers the panics of the unary handlers. The panic is logged,
+// and the call fails with codes.Internal, resetting the stream instead of crashing the node
+func recoveryUnaryInterceptor(logger hclog.Logger) grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context,
+		req interface{},
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (resp interface{}, err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				logger.Error("gRPC handler panicked", "method", info.FullMethod, "panic", r, "stack", string(debug.Stack()))
+
+				resp, err = nil, errInternal
+			}
+		}()
+
+		return handler(ctx, req)
+	}
+}
+
+// recoveryStreamInterceptor recovers the panics of the stream handlers, same as recoveryUnaryInterceptor
+func recoveryStreamInterceptor(logger hclog.Logger) grpc.StreamServerInterceptor {
+	return func(
+		srv interface{},
+		stream grpc.ServerStream,
+		info *grpc.StreamServerInfo,
+		handler grpc.StreamHandler,
+	) (err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				logger.Error("gRPC handler panicked", "method", info.FullMethod, "panic", r, "stack", string(debug.Stack()))
+
+				err = errInternal
+			}
+		}()
+
+		return handler(srv, stream)
 	}
 }
 
@@ -75,7 +259,7 @@ func interceptor(
 }
 
 func (g *GrpcStream) Client(stream network.Stream) (*grpc.ClientConn, error) {
-	return WrapClient(stream)
+	return WrapClient(stream, g.clientOpts...)
 }
 
 func (g *GrpcStream) Serve() {
@@ -88,12 +272,35 @@ func (g *GrpcStream) Handler() func(network.Stream) {
 	return func(stream network.Stream) {
 		select {
 		case <-g.ctx.Done():
-			return
+			// The server is stopped, and won't serve the stream
+			_ = stream.Reset()
 		case g.streamCh <- stream:
 		}
 	}
 }
 
+// GracefulStop stops the gRPC server from accepting new streams, and waits for the in-flight calls
+// to complete. Once the context is done, the remaining calls are cut off and the context error is returned
+func (g *GrpcStream) GracefulStop(ctx context.Context) error {
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		g.grpcServer.GracefulStop()
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		g.grpcServer.Stop()
+		<-done
+
+		return ctx.Err()
+	}
+}
+
 func (g *GrpcStream) RegisterService(sd *grpc.ServiceDesc, ss interface{}) {
 	g.grpcServer.RegisterService(sd, ss)
 }
@@ -118,18 +325,23 @@ func (g *GrpcStream) Addr() net.Addr {
 	return fakeLocalAddr()
 }
 
+// Close implements the net.Listener interface, unblocking the pending Accept calls
 func (g *GrpcStream) Close() error {
+	g.cancel()
+
 	return nil
 }
 
 // --- conn ---
 
-func WrapClient(s network.Stream) (*grpc.ClientConn, error) {
-	opts := grpc.WithContextDialer(func(ctx context.Context, peerIdStr string) (net.Conn, error) {
+func WrapClient(s network.Stream, opts ...grpc.DialOption) (*grpc.ClientConn, error) {
+	dialer := grpc.WithContextDialer(func(ctx context.Context, peerIdStr string) (net.Conn, error) {
 		return &streamConn{s}, nil
 	})
 
-	return grpc.Dial("", grpc.WithTransportCredentials(insecure.NewCredentials()), opts)
+	opts = append(opts, grpc.WithTransportCredentials(insecure.NewCredentials()), dialer)
+
+	return grpc.Dial("", opts...)
 }
 
 // streamConn represents a net.Conn wrapped to be compatible with net.conn
@@ -146,7 +358,9 @@ type wrapLibp2pAddr struct {
 func (c *streamConn) LocalAddr() net.Addr {
 	addr, err := manet.ToNetAddr(c.Stream.Conn().LocalMultiaddr())
 	if err != nil {
-		return fakeRemoteAddr()
+		// Relayed connections don't have a network address,
+		// but the peer ID should still be available
+		addr = fakeRemoteAddr()
 	}
 
 	return &wrapLibp2pAddr{Addr: addr, id: c.Stream.Conn().LocalPeer()}
@@ -156,7 +370,9 @@ func (c *streamConn) LocalAddr() net.Addr {
 func (c *streamConn) RemoteAddr() net.Addr {
 	addr, err := manet.ToNetAddr(c.Stream.Conn().RemoteMultiaddr())
 	if err != nil {
-		return fakeRemoteAddr()
+		// Relayed connections don't have a network address,
+		// but the peer ID should still be available
+		addr = fakeRemoteAddr()
 	}
 
 	return &wrapLibp2pAddr{Addr: addr, id: c.Stream.Conn().RemotePeer()}
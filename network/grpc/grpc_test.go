@@ -0,0 +1,73 @@
+package grpc
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestRecoveryInterceptors(t *testing.T) {
+	newLogger := func() (hclog.Logger, *bytes.Buffer) {
+		var buf bytes.Buffer
+
+		return hclog.New(&hclog.LoggerOptions{Output: &buf}), &buf
+	}
+
+	t.Run("unary handler panic", func(t *testing.T) {
+		logger, buf := newLogger()
+
+		resp, err := recoveryUnaryInterceptor(logger)(
+			context.Background(),
+			nil,
+			&grpc.UnaryServerInfo{FullMethod: "/test/Unary"},
+			func(context.Context, interface{}) (interface{}, error) {
+				panic("unary boom")
+			},
+		)
+
+		assert.Nil(t, resp)
+		assert.Equal(t, codes.Internal, status.Code(err))
+		assert.Contains(t, buf.String(), "unary boom")
+		assert.Contains(t, buf.String(), "/test/Unary")
+	})
+
+	t.Run("stream handler panic", func(t *testing.T) {
+		logger, buf := newLogger()
+
+		err := recoveryStreamInterceptor(logger)(
+			nil,
+			nil,
+			&grpc.StreamServerInfo{FullMethod: "/test/Stream"},
+			func(interface{}, grpc.ServerStream) error {
+				panic("stream boom")
+			},
+		)
+
+		assert.Equal(t, codes.Internal, status.Code(err))
+		assert.Contains(t, buf.String(), "stream boom")
+		assert.Contains(t, buf.String(), "/test/Stream")
+	})
+
+	t.Run("handler results are kept", func(t *testing.T) {
+		logger, buf := newLogger()
+
+		resp, err := recoveryUnaryInterceptor(logger)(
+			context.Background(),
+			nil,
+			&grpc.UnaryServerInfo{FullMethod: "/test/Unary"},
+			func(context.Context, interface{}) (interface{}, error) {
+				return "resp", nil
+			},
+		)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "resp", resp)
+		assert.Empty(t, buf.String())
+	})
+}
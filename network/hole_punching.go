@@ -0,0 +1,69 @@
+package network
+
+import (
+	"sync/atomic"
+
+	peerEvent "github.com/0xPolygon/polygon-edge/network/event"
+	"github.com/armon/go-metrics"
+	"github.com/libp2p/go-libp2p/core/network"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/libp2p/go-libp2p/p2p/protocol/holepunch"
+)
+
+// holePunchTracer passes the failed relayed connection upgrades (DCUtR)
+// to the networking server, which is only created after the libp2p host.
+// The successful upgrades are detected from the new direct connections,
+// since the hole punch can be won by the connection attempt of either peer
+type holePunchTracer struct {
+	server atomic.Pointer[Server]
+}
+
+// Trace handles a hole punching event of the libp2p host
+func (t *holePunchTracer) Trace(evt *holepunch.Event) {
+	server := t.server.Load()
+	if server == nil {
+		return
+	}
+
+	if outcome, ok := evt.Evt.(*holepunch.EndHolePunchEvt); ok && !outcome.Success {
+		server.logger.Debug("Unable to upgrade the relayed connection", "peer", evt.Remote, "err", outcome.Error)
+
+		metrics.IncrCounter([]string{networkMetrics, "relayed_conn_upgrade_failures"}, 1)
+	}
+}
+
+// checkConnUpgraded reports the new direct connection to the peer as an upgrade,
+// if the peer was only connected over relayed connections until now
+func (s *Server) checkConnUpgraded(net network.Network, conn network.Conn) {
+	if isRelayAddr(conn.RemoteMultiaddr()) {
+		return
+	}
+
+	relayed := false
+
+	for _, other := range net.ConnsToPeer(conn.RemotePeer()) {
+		if other == conn {
+			continue
+		}
+
+		if !isRelayAddr(other.RemoteMultiaddr()) {
+			return
+		}
+
+		relayed = true
+	}
+
+	if relayed {
+		s.handleConnUpgraded(conn.RemotePeer())
+	}
+}
+
+// handleConnUpgraded reports the direct connection to the peer, which replaces the relayed one.
+// The direct connection is preferred when describing the connection to the peer
+func (s *Server) handleConnUpgraded(peerID peer.ID) {
+	s.logger.Info("Relayed connection upgraded to a direct one", "peer", peerID)
+
+	metrics.IncrCounter([]string{networkMetrics, "relayed_conns_upgraded"}, 1)
+
+	s.emitEvent(peerID, peerEvent.PeerConnUpgraded)
+}
@@ -0,0 +1,206 @@
+package network
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/0xPolygon/polygon-edge/helper/tests"
+	"github.com/0xPolygon/polygon-edge/network/common"
+	peerEvent "github.com/0xPolygon/polygon-edge/network/event"
+	"github.com/libp2p/go-libp2p/core/event"
+	"github.com/libp2p/go-libp2p/core/host"
+	"github.com/libp2p/go-libp2p/core/network"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/libp2p/go-libp2p/p2p/host/eventbus"
+	"github.com/libp2p/go-libp2p/p2p/protocol/circuitv2/client"
+	"github.com/libp2p/go-libp2p/p2p/protocol/holepunch"
+	"github.com/libp2p/go-libp2p/p2p/protocol/identify"
+	"github.com/multiformats/go-multiaddr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// publicIDService reports a public observed address of the host,
+// which the hole punching service waits for before it starts
+type publicIDService struct {
+	identify.IDService
+}
+
+func (s publicIDService) OwnObservedAddrs() []multiaddr.Multiaddr {
+	return []multiaddr.Multiaddr{multiaddr.StringCast("/ip4/1.2.3.4/tcp/1478")}
+}
+
+// loopbackAddrFilter replaces the public addresses exchanged
+// during the hole punch with the loopback addresses of the host
+type loopbackAddrFilter struct {
+	host host.Host
+}
+
+func (f loopbackAddrFilter) FilterLocal(_ peer.ID, _ []multiaddr.Multiaddr) []multiaddr.Multiaddr {
+	return f.host.Addrs()
+}
+
+func (f loopbackAddrFilter) FilterRemote(_ peer.ID, addrs []multiaddr.Multiaddr) []multiaddr.Multiaddr {
+	return addrs
+}
+
+// newLoopbackHolePunching starts a hole punching service for the host of the server,
+// which punches holes over the loopback interface
+func newLoopbackHolePunching(t *testing.T, server *Server, opts ...holepunch.Option) *holepunch.Service {
+	t.Helper()
+
+	idHost, ok := server.host.(interface{ IDService() identify.IDService })
+	require.True(t, ok)
+
+	opts = append(opts, holepunch.WithAddrFilter(loopbackAddrFilter{host: server.host}))
+
+	service, err := holepunch.NewService(server.host, publicIDService{idHost.IDService()}, opts...)
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		assert.NoError(t, service.Close())
+	})
+
+	return service
+}
+
+func TestHolePunching_RelayedConnUpgraded(t *testing.T) {
+	servers, createErr := createServers(3, map[int]*CreateServerParams{
+		0: {ConfigCallback: func(c *Config) {
+			c.NoDiscover = true
+			c.EnableRelayService = true
+		}},
+		1: {ConfigCallback: func(c *Config) { c.NoDiscover = true }},
+		2: {ConfigCallback: func(c *Config) {
+			c.NoDiscover = true
+			c.EnableHolePunching = true
+		}},
+	})
+	require.NoError(t, createErr)
+
+	t.Cleanup(func() {
+		closeTestServers(t, servers)
+	})
+
+	relay, target, source := servers[0], servers[1], servers[2]
+	targetID := target.host.ID()
+
+	// The hosts only listen on the loopback interface, which the DCUtR service of libp2p
+	// never considers public, so the hole punch is driven by services started over them
+	newLoopbackHolePunching(t, source, holepunch.WithTracer(source.holePunching))
+	targetHolePunching := newLoopbackHolePunching(t, target)
+
+	// The target is behind a NAT, so it initiates the hole punch
+	emitter, err := target.host.EventBus().Emitter(new(event.EvtLocalReachabilityChanged), eventbus.Stateful)
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		assert.NoError(t, emitter.Close())
+	})
+
+	require.NoError(t, emitter.Emit(event.EvtLocalReachabilityChanged{Reachability: network.ReachabilityPrivate}))
+
+	// The target reserves a slot on the relay, so it can be reached through it
+	ctx, cancelFn := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancelFn()
+
+	_, err = tests.RetryUntilTimeout(ctx, func() (interface{}, bool) {
+		_, reserveErr := client.Reserve(ctx, target.host, *relay.AddrInfo())
+
+		return nil, reserveErr != nil
+	})
+	require.NoError(t, err)
+
+	upgradedCh := make(chan struct{}, 1)
+
+	require.NoError(t, source.Subscribe(ctx, func(evnt *peerEvent.PeerEvent) {
+		if evnt.Type == peerEvent.PeerConnUpgraded && evnt.PeerID == targetID {
+			upgradedCh <- struct{}{}
+		}
+	}))
+
+	relayAddr, err := common.AddrInfoToString(relay.AddrInfo())
+	require.NoError(t, err)
+
+	require.NoError(t, source.JoinPeer(fmt.Sprintf("%s/p2p-circuit/p2p/%s", relayAddr, targetID)))
+
+	waitCtx, cancelWait := context.WithTimeout(context.Background(), DefaultJoinTimeout)
+	defer cancelWait()
+
+	_, err = WaitUntilPeerConnectsTo(waitCtx, source, targetID)
+	require.NoError(t, err)
+
+	// The inbound relayed connection starts the hole punch on the target, unless it has already started
+	if err := targetHolePunching.DirectConnect(source.host.ID()); err != nil {
+		require.ErrorContains(t, err, "already")
+	}
+
+	select {
+	case <-upgradedCh:
+	case <-ctx.Done():
+		t.Fatal("connection upgrade not reported")
+	}
+
+	// The direct connection is described over the relayed one
+	characteristics := source.ConnCharacteristics(targetID)
+	assert.False(t, characteristics.Relayed)
+	assert.Equal(t, "tcp", characteristics.Transport)
+}
+
+func TestHolePunching_FailedUpgrade(t *testing.T) {
+	server, createErr := CreateServer(&CreateServerParams{ConfigCallback: func(c *Config) {
+		c.NoDiscover = true
+		c.EnableHolePunching = true
+	}})
+	require.NoError(t, createErr)
+
+	t.Cleanup(func() {
+		assert.NoError(t, server.Close())
+	})
+
+	randomPeers, err := generateRandomPeers(t, 1)
+	require.NoError(t, err)
+
+	eventCh := make(chan peerEvent.PeerEventType, 1)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	require.NoError(t, server.Subscribe(ctx, func(evnt *peerEvent.PeerEvent) {
+		if evnt.PeerID == randomPeers[0].peerID {
+			eventCh <- evnt.Type
+		}
+	}))
+
+	// Neither a failed direct dial, nor a failed hole punch upgrade the connection
+	server.holePunching.Trace(&holepunch.Event{
+		Remote: randomPeers[0].peerID,
+		Type:   holepunch.DirectDialEvtT,
+		Evt:    &holepunch.DirectDialEvt{Success: false, Error: "unreachable"},
+	})
+	server.holePunching.Trace(&holepunch.Event{
+		Remote: randomPeers[0].peerID,
+		Type:   holepunch.EndHolePunchEvtT,
+		Evt:    &holepunch.EndHolePunchEvt{Success: false, Error: "timeout"},
+	})
+
+	select {
+	case eventType := <-eventCh:
+		t.Fatalf("unexpected %s event", eventType)
+	case <-time.After(200 * time.Millisecond):
+	}
+
+	// Hole punching is off unless enabled
+	disabled, createErr := CreateServer(&CreateServerParams{ConfigCallback: func(c *Config) {
+		c.NoDiscover = true
+	}})
+	require.NoError(t, createErr)
+
+	t.Cleanup(func() {
+		assert.NoError(t, disabled.Close())
+	})
+
+	assert.Nil(t, disabled.holePunching)
+}
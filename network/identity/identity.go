@@ -5,8 +5,10 @@ import (
 	"errors"
 	"fmt"
 	"sync"
+	"time"
 
 	"github.com/0xPolygon/polygon-edge/network/event"
+	"github.com/0xPolygon/polygon-edge/network/grpc"
 	"github.com/hashicorp/go-hclog"
 
 	"github.com/0xPolygon/polygon-edge/network/proto"
@@ -16,9 +18,18 @@ import (
 
 const PeerID = "peerID"
 
+const (
+	// maxMetadataEntries is the maximum number of entries in the handshake metadata
+	maxMetadataEntries = 16
+
+	// maxMetadataSize is the maximum total size of the handshake metadata keys and values
+	maxMetadataSize = 1024
+)
+
 var (
 	ErrInvalidChainID   = errors.New("invalid chain ID")
 	ErrNoAvailableSlots = errors.New("no available Slots")
+	ErrInvalidMetadata  = errors.New("invalid handshake metadata")
 )
 
 // networkingServer defines the base communication interface between
@@ -52,6 +63,17 @@ type networkingServer interface {
 
 	// HasFreeConnectionSlot checks if there are available outbound connection slots [Thread safe]
 	HasFreeConnectionSlot(direction network.Direction) bool
+
+	// IsConnLimitExempt checks if the peer connection bypasses the connection slot limits [Thread safe]
+	IsConnLimitExempt(peerID peer.ID, direction network.Direction) bool
+
+	// MakeRoomForPeer frees a connection slot for the peer by evicting a lower-scoring one, if any [Thread safe]
+	MakeRoomForPeer(peerID peer.ID, direction network.Direction) bool
+
+	// REPUTATION //
+
+	// QuarantinePeer penalizes the peer and refuses connections with it for a while [Thread safe]
+	QuarantinePeer(peerID peer.ID, reason string)
 }
 
 // IdentityService is a networking service used to handle peer handshaking.
@@ -65,6 +87,21 @@ type IdentityService struct {
 
 	chainID int64   // The chain ID of the network
 	hostID  peer.ID // The base networking server's host peer ID
+
+	// The maximum time a connection can stay pending (half-open) before
+	// the handshake completes, after which it is closed. Unbounded if 0
+	handshakeTimeout time.Duration
+}
+
+// Option configures the optional behavior of the IdentityService
+type Option func(i *IdentityService)
+
+// WithHandshakeTimeout bounds the time a connection can stay pending before the handshake completes.
+// The handshake is unbounded by default
+func WithHandshakeTimeout(timeout time.Duration) Option {
+	return func(i *IdentityService) {
+		i.handshakeTimeout = timeout
+	}
 }
 
 // NewIdentityService returns a new instance of the IdentityService
@@ -73,13 +110,20 @@ func NewIdentityService(
 	logger hclog.Logger,
 	chainID int64,
 	hostID peer.ID,
+	opts ...Option,
 ) *IdentityService {
-	return &IdentityService{
+	identityService := &IdentityService{
 		logger:     logger.Named("identity"),
 		baseServer: server,
 		chainID:    chainID,
 		hostID:     hostID,
 	}
+
+	for _, opt := range opts {
+		opt(identityService)
+	}
+
+	return identityService
 }
 
 func (i *IdentityService) GetNotifyBundle() *network.NotifyBundle {
@@ -93,7 +137,9 @@ func (i *IdentityService) GetNotifyBundle() *network.NotifyBundle {
 				return
 			}
 
-			if !i.baseServer.HasFreeConnectionSlot(conn.Stat().Direction) {
+			if !i.baseServer.HasFreeConnectionSlot(conn.Stat().Direction) &&
+				!i.baseServer.IsConnLimitExempt(peerID, conn.Stat().Direction) &&
+				!i.baseServer.MakeRoomForPeer(peerID, conn.Stat().Direction) {
 				i.disconnectFromPeer(peerID, ErrNoAvailableSlots.Error())
 
 				return
@@ -171,12 +217,30 @@ func (i *IdentityService) handleConnected(peerID peer.ID, direction network.Dire
 	// Construct the response status
 	status := i.constructStatus(peerID)
 
+	// The connection is accounted as pending until the handshake is done,
+	// so a peer that never responds shouldn't hold it indefinitely
+	ctx := context.Background()
+
+	if i.handshakeTimeout > 0 {
+		var cancel context.CancelFunc
+
+		ctx, cancel = context.WithTimeout(ctx, i.handshakeTimeout)
+		defer cancel()
+	}
+
 	// Initiate the handshake
-	resp, err := clt.Hello(context.Background(), status)
+	resp, err := clt.Hello(ctx, status)
 	if err != nil {
 		return err
 	}
 
+	// Malformed metadata is a sign of a misbehaving peer, not just an incompatible one
+	if err := validateMetadata(peerID, resp.Metadata); err != nil {
+		i.baseServer.QuarantinePeer(peerID, err.Error())
+
+		return err
+	}
+
 	// Validate that the peers are working on the same chain
 	if status.Chain != resp.Chain {
 		return ErrInvalidChainID
@@ -192,7 +256,18 @@ func (i *IdentityService) handleConnected(peerID peer.ID, direction network.Dire
 
 // Hello is the initial message that bundles peer information
 // on first contact
-func (i *IdentityService) Hello(_ context.Context, req *proto.Status) (*proto.Status, error) {
+func (i *IdentityService) Hello(ctx context.Context, req *proto.Status) (*proto.Status, error) {
+	// The requester is known from the stream, so it can be held accountable for malformed metadata
+	if grpcContext, ok := ctx.(*grpc.Context); ok {
+		if err := validateMetadata(grpcContext.PeerID, req.Metadata); err != nil {
+			i.baseServer.QuarantinePeer(grpcContext.PeerID, err.Error())
+
+			go i.disconnectFromPeer(grpcContext.PeerID, err.Error())
+
+			return nil, err
+		}
+	}
+
 	// The peerID is the other node's peerID
 	// as this method is invoking a call such as "Hello, <peerID>!"
 	peerID, err := peer.Decode(req.Metadata[PeerID])
@@ -203,6 +278,39 @@ func (i *IdentityService) Hello(_ context.Context, req *proto.Status) (*proto.St
 	return i.constructStatus(peerID), nil
 }
 
+// validateMetadata checks that the handshake metadata of the peer is within the size limits,
+// and that the advertised peer ID, if any, is well-formed and matches the peer
+func validateMetadata(peerID peer.ID, metadata map[string]string) error {
+	if len(metadata) > maxMetadataEntries {
+		return fmt.Errorf("%w: %d entries", ErrInvalidMetadata, len(metadata))
+	}
+
+	size := 0
+	for key, value := range metadata {
+		size += len(key) + len(value)
+	}
+
+	if size > maxMetadataSize {
+		return fmt.Errorf("%w: %d bytes", ErrInvalidMetadata, size)
+	}
+
+	rawPeerID, ok := metadata[PeerID]
+	if !ok {
+		return nil
+	}
+
+	advertisedID, err := peer.Decode(rawPeerID)
+	if err != nil {
+		return fmt.Errorf("%w: unparseable peer ID, %v", ErrInvalidMetadata, err)
+	}
+
+	if advertisedID != peerID {
+		return fmt.Errorf("%w: peer ID mismatch", ErrInvalidMetadata)
+	}
+
+	return nil
+}
+
 // constructStatus constructs a status response of the current node
 func (i *IdentityService) constructStatus(peerID peer.ID) *proto.Status {
 	return &proto.Status{
@@ -2,6 +2,8 @@ package identity
 
 import (
 	"context"
+	"strconv"
+	"strings"
 	"testing"
 
 	"github.com/0xPolygon/polygon-edge/network/proto"
@@ -122,3 +124,73 @@ func TestHandshake_Errors(t *testing.T) {
 	// Make sure no peers have been  added to the base networking server
 	assert.Len(t, peersArray, 0)
 }
+
+// TestHandshake_MalformedMetadata tests that peers sending malformed
+// handshake metadata are quarantined, and not added as peers
+func TestHandshake_MalformedMetadata(t *testing.T) {
+	testTable := []struct {
+		name     string
+		metadata map[string]string
+	}{
+		{
+			"oversized metadata",
+			map[string]string{
+				"junk": strings.Repeat("a", maxMetadataSize+1),
+			},
+		},
+		{
+			"too many entries",
+			func() map[string]string {
+				metadata := make(map[string]string)
+				for i := 0; i <= maxMetadataEntries; i++ {
+					metadata[strconv.Itoa(i)] = ""
+				}
+
+				return metadata
+			}(),
+		},
+		{
+			"unparseable peer ID",
+			map[string]string{
+				PeerID: "not a peer ID",
+			},
+		},
+	}
+
+	for _, testCase := range testTable {
+		testCase := testCase
+
+		t.Run(testCase.name, func(t *testing.T) {
+			peersArray := make([]peer.ID, 0)
+			quarantined := make([]peer.ID, 0)
+
+			identityService := newIdentityService(
+				func(server *networkTesting.MockNetworkingServer) {
+					server.HookAddPeer(func(id peer.ID, direction network.Direction) {
+						peersArray = append(peersArray, id)
+					})
+
+					server.HookQuarantinePeer(func(id peer.ID, reason string) {
+						quarantined = append(quarantined, id)
+					})
+
+					server.GetMockIdentityClient().HookHello(func(
+						ctx context.Context,
+						in *proto.Status,
+						opts ...grpc.CallOption,
+					) (*proto.Status, error) {
+						return &proto.Status{
+							Metadata: testCase.metadata,
+						}, nil
+					})
+				},
+			)
+
+			connectErr := identityService.handleConnected("TestPeer", network.DirOutbound)
+
+			assert.ErrorIs(t, connectErr, ErrInvalidMetadata)
+			assert.Equal(t, []peer.ID{"TestPeer"}, quarantined)
+			assert.Len(t, peersArray, 0)
+		})
+	}
+}
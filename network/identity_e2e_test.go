@@ -2,11 +2,22 @@ package network
 
 import (
 	"context"
+	"io"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/0xPolygon/polygon-edge/chain"
+	"github.com/0xPolygon/polygon-edge/network/common"
+	"github.com/0xPolygon/polygon-edge/network/grpc"
+	"github.com/0xPolygon/polygon-edge/network/proto"
+	"github.com/libp2p/go-libp2p"
+	"github.com/libp2p/go-libp2p/core/network"
+	"github.com/libp2p/go-libp2p/core/protocol"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 func TestIdentityHandshake(t *testing.T) {
@@ -97,3 +108,98 @@ func TestIdentityHandshake(t *testing.T) {
 		})
 	}
 }
+
+func TestIdentityHandshake_HalfOpenConnections(t *testing.T) {
+	const (
+		numHalfOpen = 5
+		gracePeriod = time.Second
+	)
+
+	server, createErr := CreateServer(&CreateServerParams{ConfigCallback: func(c *Config) {
+		c.NoDiscover = true
+		c.HandshakeGracePeriod = gracePeriod
+	}})
+	require.NoError(t, createErr)
+
+	t.Cleanup(func() {
+		assert.NoError(t, server.Close())
+	})
+
+	// Each host connects, but never answers the identity handshake
+	for i := 0; i < numHalfOpen; i++ {
+		halfOpenHost, err := libp2p.New(libp2p.ListenAddrStrings("/ip4/127.0.0.1/tcp/0"))
+		require.NoError(t, err)
+
+		t.Cleanup(func() {
+			assert.NoError(t, halfOpenHost.Close())
+		})
+
+		halfOpenHost.SetStreamHandler(protocol.ID(common.IdentityProto), func(stream network.Stream) {
+			_, _ = io.Copy(io.Discard, stream)
+		})
+
+		require.NoError(t, halfOpenHost.Connect(context.Background(), *server.AddrInfo()))
+	}
+
+	// The half-open connections are accounted as pending right away
+	require.Eventually(t, func() bool {
+		return server.connectionCounts.GetPendingInboundConnCount() == numHalfOpen
+	}, gracePeriod/2, 10*time.Millisecond)
+
+	assert.Equal(t, int64(0), server.connectionCounts.GetInboundConnCount())
+
+	// Once the grace period passes, they are closed and no longer accounted
+	require.Eventually(t, func() bool {
+		return server.connectionCounts.GetPendingInboundConnCount() == 0
+	}, 3*gracePeriod, 50*time.Millisecond)
+
+	assert.Eventually(t, func() bool {
+		return len(server.host.Network().Peers()) == 0
+	}, gracePeriod, 50*time.Millisecond)
+	assert.Equal(t, int64(0), server.connectionCounts.GetInboundConnCount())
+}
+
+func TestIdentityHandshake_OversizedPayload(t *testing.T) {
+	const maxMsgSize = 1024
+
+	servers, createErr := createServers(2, map[int]*CreateServerParams{
+		0: {ConfigCallback: func(c *Config) {
+			c.NoDiscover = true
+			c.HandshakeMaxMsgSize = maxMsgSize
+		}},
+		1: {ConfigCallback: func(c *Config) { c.NoDiscover = true }},
+	})
+	require.NoError(t, createErr)
+
+	t.Cleanup(func() {
+		closeTestServers(t, servers)
+	})
+
+	server, malicious := servers[0], servers[1]
+
+	require.NoError(t, JoinAndWait(malicious, server, DefaultBufferTimeout, DefaultJoinTimeout))
+
+	// The malicious peer sends a handshake far over the limit, without any client-side limit
+	stream, err := malicious.host.NewStream(context.Background(), server.host.ID(), protocol.ID(common.IdentityProto))
+	require.NoError(t, err)
+
+	conn, err := grpc.WrapClient(stream)
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		_ = conn.Close()
+	})
+
+	_, err = proto.NewIdentityClient(conn).Hello(context.Background(), &proto.Status{
+		Metadata: map[string]string{"padding": strings.Repeat("x", 1024*maxMsgSize)},
+	})
+
+	// The payload is rejected by its length prefix, before it is read
+	require.Error(t, err)
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+
+	// The connection with the peer is dropped
+	assert.Eventually(t, func() bool {
+		return !server.IsConnected(malicious.host.ID()) && !server.hasPeer(malicious.host.ID())
+	}, 5*time.Second, 50*time.Millisecond)
+}
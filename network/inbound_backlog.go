@@ -0,0 +1,92 @@
+package network
+
+import (
+	"sync"
+
+	"github.com/libp2p/go-libp2p"
+	"github.com/libp2p/go-libp2p/core/network"
+	rcmgr "github.com/libp2p/go-libp2p/p2p/host/resource-manager"
+	"github.com/multiformats/go-multiaddr"
+)
+
+// backlogResourceManager is the libp2p resource manager of the networking server,
+// which ties the inbound backlog entries to the connection scopes. The scope of an accepted
+// connection is released once the connection closes, fails the upgrade or times out,
+// so such connections free their backlog slot right away, instead of holding it until they expire
+type backlogResourceManager struct {
+	network.ResourceManager
+
+	gater *connectionGater
+}
+
+// newResourceManager creates the libp2p default resource manager,
+// wrapped so it releases the inbound backlog entries of the gater
+func newResourceManager(gater *connectionGater) (network.ResourceManager, error) {
+	limits := rcmgr.DefaultLimits
+	libp2p.SetDefaultServiceLimits(&limits)
+
+	manager, err := rcmgr.NewResourceManager(rcmgr.NewFixedLimiter(limits.AutoScale()))
+	if err != nil {
+		return nil, err
+	}
+
+	return &backlogResourceManager{
+		ResourceManager: manager,
+		gater:           gater,
+	}, nil
+}
+
+// OpenConnection opens the connection scope, which releases the backlog entry of the inbound connection once done
+func (rm *backlogResourceManager) OpenConnection(
+	dir network.Direction,
+	usefd bool,
+	endpoint multiaddr.Multiaddr,
+) (network.ConnManagementScope, error) {
+	scope, err := rm.ResourceManager.OpenConnection(dir, usefd, endpoint)
+	if dir != network.DirInbound {
+		return scope, err
+	}
+
+	// The gater accepts the connection right before its scope is opened
+	entry := rm.gater.getBacklogEntry(endpoint)
+
+	if err != nil {
+		// The connection is closed right away
+		if entry != nil {
+			rm.gater.releaseBacklogEntry(endpoint, entry)
+		}
+
+		return nil, err
+	}
+
+	if entry == nil {
+		return scope, nil
+	}
+
+	return &backlogConnScope{
+		ConnManagementScope: scope,
+		gater:               rm.gater,
+		remoteAddr:          endpoint,
+		entry:               entry,
+	}, nil
+}
+
+// backlogConnScope is the scope of an accepted inbound connection, which releases
+// the backlog entry of the connection once done
+type backlogConnScope struct {
+	network.ConnManagementScope
+
+	gater      *connectionGater
+	remoteAddr multiaddr.Multiaddr
+	entry      *backlogEntry
+	once       sync.Once
+}
+
+// Done releases the backlog entry of the connection, and the connection scope
+func (s *backlogConnScope) Done() {
+	s.once.Do(func() {
+		s.gater.releaseBacklogEntry(s.remoteAddr, s.entry)
+	})
+
+	s.ConnManagementScope.Done()
+}
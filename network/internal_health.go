@@ -0,0 +1,64 @@
+package network
+
+import (
+	"sync/atomic"
+)
+
+// InternalHealth is a snapshot of the goroutines run by the networking server,
+// used for detecting leaks
+type InternalHealth struct {
+	Subscriptions   int64 // the event subscription loops
+	DialWorkers     int64 // the dials in progress
+	Watchers        int64 // the goroutines waiting for the server or a subscription to close
+	TopicReaders    int64 // the gossip topic read loops
+	MessageHandlers int64 // the gossip message handlers in progress
+}
+
+// goroutineCounter counts the running goroutines of a kind. A nil counter counts nothing
+type goroutineCounter struct {
+	count atomic.Int64
+}
+
+// start counts a goroutine as running, until the returned function is called.
+// It is called before the goroutine is spawned, so the goroutine is counted right away [Thread safe]
+func (c *goroutineCounter) start() func() {
+	if c == nil {
+		return func() {}
+	}
+
+	c.count.Add(1)
+
+	return func() {
+		c.count.Add(-1)
+	}
+}
+
+// get returns the number of running goroutines [Thread safe]
+func (c *goroutineCounter) get() int64 {
+	if c == nil {
+		return 0
+	}
+
+	return c.count.Load()
+}
+
+// internalHealthCounters keeps count of the goroutines run by the networking server, by kind
+type internalHealthCounters struct {
+	subscriptions   goroutineCounter
+	dialWorkers     goroutineCounter
+	watchers        goroutineCounter
+	topicReaders    goroutineCounter
+	messageHandlers goroutineCounter
+}
+
+// InternalHealth returns the number of goroutines the networking server currently runs, by kind.
+// Counts growing over time, without a matching growth of the peers or topics, indicate a leak [Thread safe]
+func (s *Server) InternalHealth() InternalHealth {
+	return InternalHealth{
+		Subscriptions:   s.health.subscriptions.get(),
+		DialWorkers:     s.health.dialWorkers.get(),
+		Watchers:        s.health.watchers.get(),
+		TopicReaders:    s.health.topicReaders.get(),
+		MessageHandlers: s.health.messageHandlers.get(),
+	}
+}
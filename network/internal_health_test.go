@@ -0,0 +1,102 @@
+package network
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	peerEvent "github.com/0xPolygon/polygon-edge/network/event"
+	testproto "github.com/0xPolygon/polygon-edge/network/proto"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGoroutineCounter(t *testing.T) {
+	var counter goroutineCounter
+
+	first, second := counter.start(), counter.start()
+	assert.Equal(t, int64(2), counter.get())
+
+	first()
+	second()
+	assert.Zero(t, counter.get())
+
+	// A nil counter counts nothing
+	var nilCounter *goroutineCounter
+
+	nilCounter.start()()
+	assert.Zero(t, nilCounter.get())
+}
+
+func TestInternalHealth(t *testing.T) {
+	servers, createErr := createServers(2, map[int]*CreateServerParams{
+		0: {ConfigCallback: func(c *Config) { c.NoDiscover = true }},
+		1: {ConfigCallback: func(c *Config) { c.NoDiscover = true }},
+	})
+	require.NoError(t, createErr)
+
+	server, target := servers[0], servers[1]
+
+	t.Cleanup(func() {
+		assert.NoError(t, target.Close())
+	})
+
+	baseline := server.InternalHealth()
+
+	// An event subscription runs until its context is done
+	ctx, cancelFn := context.WithCancel(context.Background())
+
+	require.NoError(t, server.Subscribe(ctx, func(*peerEvent.PeerEvent) {}))
+
+	expected := baseline
+	expected.Subscriptions++
+
+	assert.Equal(t, expected, server.InternalHealth())
+
+	cancelFn()
+
+	require.Eventually(t, func() bool {
+		return server.InternalHealth() == baseline
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// A topic read loop runs until the topic is closed
+	topic, err := server.NewTopic("internal-health", &testproto.GenericMessage{})
+	require.NoError(t, err)
+	require.NoError(t, topic.Subscribe(func(interface{}, peer.ID) {}))
+
+	expected = baseline
+	expected.TopicReaders++
+
+	assert.Equal(t, expected, server.InternalHealth())
+
+	topic.Close()
+
+	require.Eventually(t, func() bool {
+		return server.InternalHealth() == baseline
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// The dial worker is done once the peer is connected
+	require.NoError(t, JoinAndWait(server, target, DefaultBufferTimeout, DefaultJoinTimeout))
+
+	require.Eventually(t, func() bool {
+		return server.InternalHealth() == baseline
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// The channel subscription is watched until the server is closed
+	_, err = server.SubscribeCh(context.Background())
+	require.NoError(t, err)
+
+	expected = baseline
+	expected.Subscriptions++
+	expected.Watchers++
+
+	assert.Equal(t, expected, server.InternalHealth())
+
+	// Nothing is left running after the server is closed
+	require.NoError(t, server.Close())
+
+	require.Eventually(t, func() bool {
+		return server.InternalHealth() == InternalHealth{}
+	}, 5*time.Second, 10*time.Millisecond)
+}
@@ -2,11 +2,15 @@ package network
 
 import (
 	"encoding/hex"
+	"errors"
 
 	"github.com/0xPolygon/polygon-edge/secrets"
 	"github.com/libp2p/go-libp2p/core/crypto"
+	"github.com/libp2p/go-libp2p/core/peer"
 )
 
+var ErrNoNetworkKey = errors.New("no networking private key in the secrets manager")
+
 // ReadLibp2pKey reads the private networking key from the secrets manager
 func ReadLibp2pKey(manager secrets.SecretsManager) (crypto.PrivKey, error) {
 	libp2pKey, err := manager.GetSecret(secrets.NetworkKey)
@@ -46,3 +50,29 @@ func ParseLibp2pKey(key []byte) (crypto.PrivKey, error) {
 
 	return libp2pKey, nil
 }
+
+// PeerIDFromSecrets derives the node's peer ID from the networking key in the secrets manager,
+// without setting up the networking server. Returns ErrNoNetworkKey if the key is not present
+func PeerIDFromSecrets(manager secrets.SecretsManager) (peer.ID, error) {
+	if !manager.HasSecret(secrets.NetworkKey) {
+		return "", ErrNoNetworkKey
+	}
+
+	key, err := ReadLibp2pKey(manager)
+	if err != nil {
+		return "", err
+	}
+
+	return peer.IDFromPrivateKey(key)
+}
+
+// InitPeerIDInSecrets derives the node's peer ID like PeerIDFromSecrets, but generates
+// and stores a new networking key if one is not present in the secrets manager
+func InitPeerIDInSecrets(manager secrets.SecretsManager) (peer.ID, error) {
+	key, err := setupLibp2pKey(manager)
+	if err != nil {
+		return "", err
+	}
+
+	return peer.IDFromPrivateKey(key)
+}
@@ -0,0 +1,52 @@
+package network
+
+import (
+	"testing"
+
+	"github.com/0xPolygon/polygon-edge/secrets"
+	"github.com/0xPolygon/polygon-edge/secrets/local"
+	"github.com/hashicorp/go-hclog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPeerIDFromSecrets(t *testing.T) {
+	dataDir := t.TempDir()
+
+	manager, err := local.SecretsManagerFactory(
+		nil,
+		&secrets.SecretsManagerParams{
+			Logger: hclog.NewNullLogger(),
+			Extra: map[string]interface{}{
+				secrets.Path: dataDir,
+			},
+		},
+	)
+	require.NoError(t, err)
+
+	// The key is not generated implicitly
+	_, err = PeerIDFromSecrets(manager)
+	assert.ErrorIs(t, err, ErrNoNetworkKey)
+
+	peerID, err := InitPeerIDInSecrets(manager)
+	require.NoError(t, err)
+
+	storedID, err := PeerIDFromSecrets(manager)
+	require.NoError(t, err)
+	assert.Equal(t, peerID, storedID)
+
+	// The server started with the same key has the same ID
+	server, err := CreateServer(&CreateServerParams{
+		ConfigCallback: func(c *Config) {
+			c.NoDiscover = true
+			c.DataDir = dataDir
+		},
+	})
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		assert.NoError(t, server.Close())
+	})
+
+	assert.Equal(t, server.host.ID(), peerID)
+}
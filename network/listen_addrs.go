@@ -0,0 +1,35 @@
+package network
+
+import (
+	"fmt"
+
+	"github.com/libp2p/go-libp2p/core/host"
+	"github.com/multiformats/go-multiaddr"
+)
+
+// listenMultiaddrs returns the configured listen addresses,
+// or the one derived from the base address if none are set
+func listenMultiaddrs(config *Config) ([]multiaddr.Multiaddr, error) {
+	if len(config.ListenAddrs) > 0 {
+		return config.ListenAddrs, nil
+	}
+
+	listenAddr, err := multiaddr.NewMultiaddr(fmt.Sprintf("/ip4/%s/tcp/%d", config.Addr.IP.String(), config.Addr.Port))
+	if err != nil {
+		return nil, err
+	}
+
+	return []multiaddr.Multiaddr{listenAddr}, nil
+}
+
+// listenOn binds the host to the listen addresses one by one,
+// so the address that fails to bind is known, unlike when they are bound together
+func listenOn(h host.Host, listenAddrs []multiaddr.Multiaddr) error {
+	for _, listenAddr := range listenAddrs {
+		if err := h.Network().Listen(listenAddr); err != nil {
+			return fmt.Errorf("unable to listen on %s, %w", listenAddr, err)
+		}
+	}
+
+	return nil
+}
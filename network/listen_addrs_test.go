@@ -0,0 +1,93 @@
+package network
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/multiformats/go-multiaddr"
+	manet "github.com/multiformats/go-multiaddr/net"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListenAddrs_DualStack(t *testing.T) {
+	// Make sure the IPv6 loopback is available
+	probe, err := net.Listen("tcp6", "[::1]:0")
+	if err != nil {
+		t.Skip("IPv6 loopback not available")
+	}
+
+	_ = probe.Close()
+
+	servers, createErr := createServers(2, map[int]*CreateServerParams{
+		0: {ConfigCallback: func(c *Config) {
+			c.NoDiscover = true
+			c.ListenAddrs = []multiaddr.Multiaddr{
+				multiaddr.StringCast("/ip4/127.0.0.1/tcp/0"),
+				multiaddr.StringCast("/ip6/::1/tcp/0"),
+			}
+		}},
+		1: {ConfigCallback: func(c *Config) { c.NoDiscover = true }},
+	})
+	require.NoError(t, createErr)
+
+	t.Cleanup(func() {
+		closeTestServers(t, servers)
+	})
+
+	server, remote := servers[0], servers[1]
+
+	// Both addresses are bound and advertised
+	var ipv4Addrs, ipv6Addrs []multiaddr.Multiaddr
+
+	for _, addr := range server.AddrInfo().Addrs {
+		if _, err := addr.ValueForProtocol(multiaddr.P_IP4); err == nil {
+			ipv4Addrs = append(ipv4Addrs, addr)
+		}
+
+		if _, err := addr.ValueForProtocol(multiaddr.P_IP6); err == nil {
+			ipv6Addrs = append(ipv6Addrs, addr)
+		}
+	}
+
+	require.NotEmpty(t, ipv4Addrs)
+	require.NotEmpty(t, ipv6Addrs)
+
+	// The node is reachable over IPv6 alone
+	require.NoError(t, remote.joinPeer(&peer.AddrInfo{
+		ID:    server.host.ID(),
+		Addrs: ipv6Addrs,
+	}))
+
+	require.Eventually(t, func() bool {
+		return server.hasPeer(remote.host.ID())
+	}, DefaultJoinTimeout, 50*time.Millisecond)
+}
+
+func TestListenAddrs_BindFailure(t *testing.T) {
+	// The port is taken
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		_ = listener.Close()
+	})
+
+	takenAddr, err := manet.FromNetAddr(listener.Addr())
+	require.NoError(t, err)
+
+	server, err := CreateServer(&CreateServerParams{ConfigCallback: func(c *Config) {
+		c.NoDiscover = true
+		c.ListenAddrs = []multiaddr.Multiaddr{
+			multiaddr.StringCast("/ip4/127.0.0.1/tcp/0"),
+			takenAddr,
+		}
+	}})
+	require.Error(t, err)
+	assert.Nil(t, server)
+
+	// The error names the address which failed to bind
+	assert.Contains(t, err.Error(), takenAddr.String())
+}
@@ -0,0 +1,37 @@
+package network
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/libp2p/go-libp2p"
+	"github.com/libp2p/go-libp2p/p2p/muxer/mplex"
+	"github.com/libp2p/go-libp2p/p2p/muxer/yamux"
+)
+
+const (
+	MuxerYamux = "yamux"
+	MuxerMplex = "mplex"
+)
+
+var ErrUnknownMuxer = errors.New("unknown stream multiplexer")
+
+// muxerOptions returns the libp2p options enabling the stream multiplexers,
+// in the order of preference. No options are returned for an empty list,
+// so the libp2p default multiplexers are used
+func muxerOptions(muxers []string) ([]libp2p.Option, error) {
+	opts := make([]libp2p.Option, 0, len(muxers))
+
+	for _, muxer := range muxers {
+		switch muxer {
+		case MuxerYamux:
+			opts = append(opts, libp2p.Muxer(yamux.ID, yamux.DefaultTransport))
+		case MuxerMplex:
+			opts = append(opts, libp2p.Muxer(mplex.ID, mplex.DefaultTransport))
+		default:
+			return nil, fmt.Errorf("%w: %s", ErrUnknownMuxer, muxer)
+		}
+	}
+
+	return opts, nil
+}
@@ -0,0 +1,70 @@
+package network
+
+import (
+	"context"
+	"testing"
+
+	"github.com/libp2p/go-libp2p/p2p/muxer/mplex"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMuxers_Negotiation(t *testing.T) {
+	testTable := []struct {
+		name        string
+		dialer      []string
+		listener    []string
+		shouldDial  bool
+		expectedMux string
+	}{
+		{"no shared muxer", []string{MuxerYamux}, []string{MuxerMplex}, false, ""},
+		{"shared muxer", []string{MuxerYamux, MuxerMplex}, []string{MuxerMplex}, true, mplex.ID},
+	}
+
+	for _, testCase := range testTable {
+		testCase := testCase
+
+		t.Run(testCase.name, func(t *testing.T) {
+			servers, createErr := createServers(2, map[int]*CreateServerParams{
+				0: {ConfigCallback: func(c *Config) {
+					c.NoDiscover = true
+					c.Muxers = testCase.dialer
+				}},
+				1: {ConfigCallback: func(c *Config) {
+					c.NoDiscover = true
+					c.Muxers = testCase.listener
+				}},
+			})
+			require.NoError(t, createErr)
+
+			t.Cleanup(func() {
+				closeTestServers(t, servers)
+			})
+
+			ctx, cancel := context.WithTimeout(context.Background(), DefaultJoinTimeout)
+			defer cancel()
+
+			dialErr := servers[0].host.Connect(ctx, *servers[1].AddrInfo())
+			if !testCase.shouldDial {
+				assert.Error(t, dialErr)
+
+				return
+			}
+
+			require.NoError(t, dialErr)
+
+			conns := servers[0].host.Network().ConnsToPeer(servers[1].host.ID())
+			require.NotEmpty(t, conns)
+			assert.Equal(t, testCase.expectedMux, string(conns[0].ConnState().StreamMultiplexer))
+		})
+	}
+}
+
+func TestMuxers_Unknown(t *testing.T) {
+	_, createErr := CreateServer(&CreateServerParams{ConfigCallback: func(c *Config) {
+		c.NoDiscover = true
+		c.Muxers = []string{"spdy"}
+	}})
+
+	assert.ErrorIs(t, createErr, ErrUnknownMuxer)
+}
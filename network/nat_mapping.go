@@ -0,0 +1,88 @@
+package network
+
+import (
+	"net"
+	"sync"
+
+	"github.com/libp2p/go-libp2p/core/network"
+	basichost "github.com/libp2p/go-libp2p/p2p/host/basic"
+)
+
+// NATMappingStatus is the state of the UPnP / NAT-PMP port mapping of the listen port
+type NATMappingStatus struct {
+	Mapped       bool   // flag indicating if the NAT device maps an external port to the listen port
+	ExternalAddr net.IP // the external address of the NAT device, nil if unknown
+	ExternalPort int    // the external port mapped to the listen port, 0 if not mapped
+}
+
+// natMappingSource reports the current port mapping of the NAT device
+type natMappingSource interface {
+	mappingStatus() NATMappingStatus
+}
+
+// libp2pNATMapping is the natMappingSource backed by the libp2p NAT manager
+type libp2pNATMapping struct {
+	lock    sync.RWMutex
+	manager basichost.NATManager // the NAT manager of the host, nil until the host is created
+}
+
+// newNATManager is the libp2p NAT manager constructor,
+// which keeps a reference to the created manager to report its mappings
+func (m *libp2pNATMapping) newNATManager(net network.Network) basichost.NATManager {
+	manager := basichost.NewNATManager(net)
+
+	m.lock.Lock()
+	m.manager = manager
+	m.lock.Unlock()
+
+	return manager
+}
+
+// mappingStatus returns the first TCP mapping with an assigned external port [Thread safe]
+func (m *libp2pNATMapping) mappingStatus() NATMappingStatus {
+	m.lock.RLock()
+	manager := m.manager
+	m.lock.RUnlock()
+
+	if manager == nil {
+		return NATMappingStatus{}
+	}
+
+	// The NAT device is nil until it is discovered, or if none is found
+	nat := manager.NAT()
+	if nat == nil {
+		return NATMappingStatus{}
+	}
+
+	for _, mapping := range nat.Mappings() {
+		if mapping.Protocol() != "tcp" || mapping.ExternalPort() == 0 {
+			continue
+		}
+
+		status := NATMappingStatus{
+			Mapped:       true,
+			ExternalPort: mapping.ExternalPort(),
+		}
+
+		// The external address lookup can fail, even though the port is mapped
+		if addr, err := mapping.ExternalAddr(); err == nil {
+			if tcpAddr, ok := addr.(*net.TCPAddr); ok {
+				status.ExternalAddr = tcpAddr.IP
+			}
+		}
+
+		return status
+	}
+
+	return NATMappingStatus{}
+}
+
+// NATMappingStatus returns the state of the NAT port mapping of the listen port.
+// Nothing is mapped if the port mapping is turned off [Thread safe]
+func (s *Server) NATMappingStatus() NATMappingStatus {
+	if s.natMapping == nil {
+		return NATMappingStatus{}
+	}
+
+	return s.natMapping.mappingStatus()
+}
@@ -0,0 +1,57 @@
+package network
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stubNATMapping is a natMappingSource reporting a fixed mapping
+type stubNATMapping struct {
+	status NATMappingStatus
+}
+
+func (m *stubNATMapping) mappingStatus() NATMappingStatus {
+	return m.status
+}
+
+func TestNATMappingStatus(t *testing.T) {
+	server, createErr := CreateServer(&CreateServerParams{ConfigCallback: func(c *Config) {
+		c.NoDiscover = true
+	}})
+	require.NoError(t, createErr)
+
+	t.Cleanup(func() {
+		require.NoError(t, server.Close())
+	})
+
+	// Nothing is mapped while the port mapping is turned off
+	assert.Equal(t, NATMappingStatus{}, server.NATMappingStatus())
+
+	stub := &stubNATMapping{}
+	server.natMapping = stub
+
+	// The NAT device hasn't mapped the port (yet)
+	assert.False(t, server.NATMappingStatus().Mapped)
+
+	// The mapping reported by the NAT device is passed through
+	stub.status = NATMappingStatus{
+		Mapped:       true,
+		ExternalAddr: net.ParseIP("203.0.113.7"),
+		ExternalPort: 31478,
+	}
+
+	status := server.NATMappingStatus()
+	assert.True(t, status.Mapped)
+	assert.True(t, net.ParseIP("203.0.113.7").Equal(status.ExternalAddr))
+	assert.Equal(t, 31478, status.ExternalPort)
+}
+
+func TestNATMappingStatus_NoManager(t *testing.T) {
+	// The NAT manager isn't created before the host is
+	mapping := &libp2pNATMapping{}
+
+	assert.Equal(t, NATMappingStatus{}, mapping.mappingStatus())
+}
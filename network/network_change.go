@@ -0,0 +1,122 @@
+package network
+
+import (
+	"context"
+	"net"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/armon/go-metrics"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/libp2p/go-libp2p/p2p/protocol/ping"
+)
+
+// networkChangePingTimeout is the maximum time a peer has to respond to a ping
+// after a network change, before its connection is considered dead
+const networkChangePingTimeout = 10 * time.Second
+
+// interfaceAddrsFingerprint returns a string identifying the set of local interface addresses
+func interfaceAddrsFingerprint(addrs []net.Addr) string {
+	values := make([]string, 0, len(addrs))
+	for _, addr := range addrs {
+		values = append(values, addr.String())
+	}
+
+	sort.Strings(values)
+
+	return strings.Join(values, ",")
+}
+
+// watchNetworkChanges periodically checks the local interface addresses,
+// and reevaluates the node addresses and connections once they change
+func (s *Server) watchNetworkChanges() {
+	addrs, err := s.interfaceAddrs()
+	if err != nil {
+		s.logger.Error("Unable to list the network interface addresses", "err", err)
+
+		return
+	}
+
+	fingerprint := interfaceAddrsFingerprint(addrs)
+
+	for {
+		select {
+		case <-s.clock.After(s.config.NetworkChangeCheckInterval):
+		case <-s.closeCh:
+			return
+		}
+
+		addrs, err := s.interfaceAddrs()
+		if err != nil {
+			s.logger.Debug("Unable to list the network interface addresses", "err", err)
+
+			continue
+		}
+
+		if current := interfaceAddrsFingerprint(addrs); current != fingerprint {
+			fingerprint = current
+
+			s.handleNetworkChange()
+		}
+	}
+}
+
+// handleNetworkChange refreshes the advertised addresses, drops the connections
+// which didn't survive the network change, and looks for peers again
+func (s *Server) handleNetworkChange() {
+	s.logger.Info("Network change detected, reevaluating the connections")
+
+	metrics.IncrCounter([]string{networkMetrics, "network_changes"}, 1)
+
+	s.refreshAddrs()
+
+	s.verifyPeerConns()
+
+	// Peers learn the new addresses from the identify push,
+	// while the node learns about the peers reachable from the new network
+	if s.discoverer != nil {
+		s.discoverer.Refresh()
+	}
+}
+
+// refreshAddrs updates the advertised addresses to the current host addresses [Thread safe]
+func (s *Server) refreshAddrs() {
+	addrs := s.host.Addrs()
+
+	s.addrsLock.Lock()
+	defer s.addrsLock.Unlock()
+
+	s.addrs = addrs
+}
+
+// verifyPeerConns pings all the connected peers, and disconnects
+// from the ones that don't respond [BLOCKING]
+func (s *Server) verifyPeerConns() {
+	var wg sync.WaitGroup
+
+	for _, connInfo := range s.Peers() {
+		wg.Add(1)
+
+		go func(peerID peer.ID) {
+			defer wg.Done()
+
+			ctx, cancel := context.WithTimeout(context.Background(), networkChangePingTimeout)
+			defer cancel()
+
+			result := <-ping.Ping(ctx, s.host, peerID)
+			if result.Error == nil {
+				return
+			}
+
+			s.logger.Debug("Peer did not respond to ping after a network change", "peer", peerID, "err", result.Error)
+
+			metrics.IncrCounter([]string{networkMetrics, "network_change_dead_conns"}, 1)
+
+			s.DisconnectFromPeer(peerID, "connection lost in a network change")
+		}(connInfo.Info.ID)
+	}
+
+	wg.Wait()
+}
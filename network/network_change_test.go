@@ -0,0 +1,87 @@
+package network
+
+import (
+	"context"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/libp2p/go-libp2p/p2p/protocol/ping"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stubInterfaces is a list of the local network interface addresses, which can be changed
+type stubInterfaces struct {
+	lock  sync.Mutex
+	addrs []net.Addr
+}
+
+func (s *stubInterfaces) set(ips ...string) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.addrs = make([]net.Addr, 0, len(ips))
+	for _, ip := range ips {
+		s.addrs = append(s.addrs, &net.IPNet{IP: net.ParseIP(ip), Mask: net.CIDRMask(24, 32)})
+	}
+}
+
+func (s *stubInterfaces) list() ([]net.Addr, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return s.addrs, nil
+}
+
+func TestNetworkChange(t *testing.T) {
+	interfaces := &stubInterfaces{}
+	interfaces.set("127.0.0.1", "192.168.1.10")
+
+	servers, createErr := createServers(3, map[int]*CreateServerParams{
+		0: {
+			ConfigCallback: func(c *Config) {
+				c.NoDiscover = true
+				c.NetworkChangeCheckInterval = 50 * time.Millisecond
+			},
+			ServerCallback: func(server *Server) {
+				server.interfaceAddrs = interfaces.list
+			},
+		},
+	})
+	require.NoError(t, createErr)
+
+	t.Cleanup(func() {
+		closeTestServers(t, servers)
+	})
+
+	alive, dead := servers[1].AddrInfo().ID, servers[2].AddrInfo().ID
+
+	require.NoError(t, JoinAndWait(servers[0], servers[1], DefaultBufferTimeout, DefaultJoinTimeout))
+	require.NoError(t, JoinAndWait(servers[0], servers[2], DefaultBufferTimeout, DefaultJoinTimeout))
+
+	// The connection to the last peer doesn't survive the network change
+	servers[2].host.RemoveStreamHandler(ping.ID)
+
+	// The advertised addresses went stale
+	servers[0].addrsLock.Lock()
+	servers[0].addrs = nil
+	servers[0].addrsLock.Unlock()
+
+	// Nothing is reevaluated while the network stays the same
+	time.Sleep(200 * time.Millisecond)
+	assert.Empty(t, servers[0].AddrInfo().Addrs)
+	assert.True(t, servers[0].IsConnected(dead))
+
+	interfaces.set("127.0.0.1", "10.0.0.10")
+
+	disconnectCtx, disconnectFn := context.WithTimeout(context.Background(), DefaultJoinTimeout)
+	defer disconnectFn()
+
+	_, disconnectErr := WaitUntilPeerDisconnectsFrom(disconnectCtx, servers[0], dead)
+	require.NoError(t, disconnectErr)
+
+	assert.True(t, servers[0].IsConnected(alive))
+	assert.ElementsMatch(t, servers[0].host.Addrs(), servers[0].AddrInfo().Addrs)
+}
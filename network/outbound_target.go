@@ -0,0 +1,96 @@
+package network
+
+import (
+	"sync"
+	"time"
+
+	peerEvent "github.com/0xPolygon/polygon-edge/network/event"
+	"github.com/libp2p/go-libp2p/core/peer"
+)
+
+// DefaultOutboundTargetDebounce is the default time the outbound peer count
+// needs to stay below the target, before the target is considered lost
+const DefaultOutboundTargetDebounce = 5 * time.Second
+
+// outboundTargetState keeps track of whether the outbound peer target is reached
+type outboundTargetState struct {
+	sync.Mutex
+
+	reached   bool        // flag indicating if the target is currently reached
+	lostTimer *time.Timer // the pending confirmation of the target loss, if any
+}
+
+// outboundTargetDebounce returns the configured outbound target debounce, or the default one
+func (s *Server) outboundTargetDebounce() time.Duration {
+	if s.config.OutboundTargetDebounce > 0 {
+		return s.config.OutboundTargetDebounce
+	}
+
+	return DefaultOutboundTargetDebounce
+}
+
+// checkOutboundTarget emits an event once the outbound peer count reaches the target.
+// Drops below the target are only reported if they last longer than the debounce time,
+// so the events are not spammed when the count hovers around the target
+func (s *Server) checkOutboundTarget(peerID peer.ID) {
+	target := s.config.TargetOutboundPeers
+	if target <= 0 {
+		return
+	}
+
+	reached := s.connectionCounts.GetOutboundConnCount() >= target
+
+	s.outboundTarget.Lock()
+
+	if reached {
+		if s.outboundTarget.lostTimer != nil {
+			// The count recovered before the loss was confirmed
+			s.outboundTarget.lostTimer.Stop()
+			s.outboundTarget.lostTimer = nil
+		}
+
+		if s.outboundTarget.reached {
+			s.outboundTarget.Unlock()
+
+			return
+		}
+
+		s.outboundTarget.reached = true
+		s.outboundTarget.Unlock()
+
+		s.logger.Info("Outbound peer target reached", "target", target)
+		s.emitEvent(peerID, peerEvent.OutboundTargetReached)
+
+		return
+	}
+
+	if s.outboundTarget.reached && s.outboundTarget.lostTimer == nil {
+		s.outboundTarget.lostTimer = time.AfterFunc(s.outboundTargetDebounce(), func() {
+			s.confirmOutboundTargetLost(peerID)
+		})
+	}
+
+	s.outboundTarget.Unlock()
+}
+
+// confirmOutboundTargetLost emits an event if the outbound
+// peer count is still below the target after the debounce time
+func (s *Server) confirmOutboundTargetLost(peerID peer.ID) {
+	target := s.config.TargetOutboundPeers
+
+	s.outboundTarget.Lock()
+
+	s.outboundTarget.lostTimer = nil
+
+	if !s.outboundTarget.reached || s.connectionCounts.GetOutboundConnCount() >= target {
+		s.outboundTarget.Unlock()
+
+		return
+	}
+
+	s.outboundTarget.reached = false
+	s.outboundTarget.Unlock()
+
+	s.logger.Info("Outbound peer target lost", "target", target)
+	s.emitEvent(peerID, peerEvent.OutboundTargetLost)
+}
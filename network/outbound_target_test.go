@@ -0,0 +1,97 @@
+package network
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	peerEvent "github.com/0xPolygon/polygon-edge/network/event"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOutboundTargetEvents(t *testing.T) {
+	const (
+		numPeers = 3
+		target   = 2
+		debounce = 500 * time.Millisecond
+	)
+
+	params := map[int]*CreateServerParams{
+		0: {ConfigCallback: func(c *Config) {
+			c.NoDiscover = true
+			c.TargetOutboundPeers = target
+			c.OutboundTargetDebounce = debounce
+		}},
+	}
+	for i := 1; i <= numPeers; i++ {
+		params[i] = &CreateServerParams{ConfigCallback: func(c *Config) {
+			c.NoDiscover = true
+		}}
+	}
+
+	servers, createErr := createServers(numPeers+1, params)
+	require.NoError(t, createErr)
+
+	t.Cleanup(func() {
+		closeTestServers(t, servers)
+	})
+
+	server, peers := servers[0], servers[1:]
+
+	var (
+		eventsLock sync.Mutex
+		events     = make(map[peerEvent.PeerEventType]int)
+	)
+
+	countEvents := func(eventType peerEvent.PeerEventType) int {
+		eventsLock.Lock()
+		defer eventsLock.Unlock()
+
+		return events[eventType]
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	require.NoError(t, server.Subscribe(ctx, func(evnt *peerEvent.PeerEvent) {
+		eventsLock.Lock()
+		defer eventsLock.Unlock()
+
+		events[evnt.Type]++
+	}))
+
+	// The server dials all the peers, so they are outbound peers
+	for i, peerServer := range peers {
+		require.NoError(t, JoinAndWait(server, peerServer, DefaultBufferTimeout, DefaultJoinTimeout))
+
+		if i+1 < target {
+			assert.Zero(t, countEvents(peerEvent.OutboundTargetReached))
+		}
+	}
+
+	require.Eventually(t, func() bool {
+		return countEvents(peerEvent.OutboundTargetReached) == 1
+	}, DefaultJoinTimeout, 50*time.Millisecond)
+
+	// Dropping to the target keeps it reached
+	server.DisconnectFromPeer(peers[0].host.ID(), "test")
+	require.Eventually(t, func() bool {
+		return server.connectionCounts.GetOutboundConnCount() == target
+	}, DefaultJoinTimeout, 50*time.Millisecond)
+
+	// Dropping below the target is only reported after the debounce
+	server.DisconnectFromPeer(peers[1].host.ID(), "test")
+	require.Eventually(t, func() bool {
+		return server.connectionCounts.GetOutboundConnCount() == target-1
+	}, DefaultJoinTimeout, 50*time.Millisecond)
+
+	assert.Zero(t, countEvents(peerEvent.OutboundTargetLost))
+
+	require.Eventually(t, func() bool {
+		return countEvents(peerEvent.OutboundTargetLost) == 1
+	}, 4*debounce, 50*time.Millisecond)
+
+	assert.Equal(t, 1, countEvents(peerEvent.OutboundTargetReached))
+}
@@ -0,0 +1,157 @@
+package network
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/multiformats/go-multiaddr"
+)
+
+// DefaultMaxPeerAddrs is the default maximum number of addresses stored per peer
+const DefaultMaxPeerAddrs = 10
+
+// peerAddrTracker keeps track of the last time a connection
+// to a specific peer address was successful
+type peerAddrTracker struct {
+	sync.Mutex
+
+	lastSuccess map[peer.ID]map[string]time.Time // peerID -> address -> time of the last successful connection
+}
+
+// newPeerAddrTracker creates a new peer address tracker
+func newPeerAddrTracker() *peerAddrTracker {
+	return &peerAddrTracker{
+		lastSuccess: make(map[peer.ID]map[string]time.Time),
+	}
+}
+
+// markSuccess records a successful connection to the peer address at the given time [Thread safe]
+func (t *peerAddrTracker) markSuccess(peerID peer.ID, addr multiaddr.Multiaddr, now time.Time) {
+	t.Lock()
+	defer t.Unlock()
+
+	addrs, ok := t.lastSuccess[peerID]
+	if !ok {
+		addrs = make(map[string]time.Time)
+		t.lastSuccess[peerID] = addrs
+	}
+
+	addrs[addr.String()] = now
+}
+
+// remove removes all the records of the peer [Thread safe]
+func (t *peerAddrTracker) remove(peerID peer.ID) {
+	t.Lock()
+	defer t.Unlock()
+
+	delete(t.lastSuccess, peerID)
+}
+
+// retain removes the records of the peer addresses which are not in the specified set,
+// e.g. the ones which are no longer in the peer store [Thread safe]
+func (t *peerAddrTracker) retain(peerID peer.ID, addrs []multiaddr.Multiaddr) {
+	t.Lock()
+	defer t.Unlock()
+
+	successes, ok := t.lastSuccess[peerID]
+	if !ok {
+		return
+	}
+
+	kept := make(map[string]struct{}, len(addrs))
+	for _, addr := range addrs {
+		kept[addr.String()] = struct{}{}
+	}
+
+	for addr := range successes {
+		if _, ok := kept[addr]; !ok {
+			delete(successes, addr)
+		}
+	}
+
+	if len(successes) == 0 {
+		delete(t.lastSuccess, peerID)
+	}
+}
+
+// limit returns at most max addresses of the peer, preferring the ones
+// with the most recent successful connection. The order of addresses
+// that were never successful is preserved. Records of the addresses
+// that are dropped are removed [Thread safe]
+func (t *peerAddrTracker) limit(
+	peerID peer.ID,
+	addrs []multiaddr.Multiaddr,
+	max int,
+) []multiaddr.Multiaddr {
+	t.Lock()
+	defer t.Unlock()
+
+	if len(addrs) <= max {
+		return addrs
+	}
+
+	successes := t.lastSuccess[peerID]
+
+	sorted := make([]multiaddr.Multiaddr, len(addrs))
+	copy(sorted, addrs)
+
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return successes[sorted[i].String()].After(successes[sorted[j].String()])
+	})
+
+	kept := sorted[:max]
+
+	for _, dropped := range sorted[max:] {
+		delete(successes, dropped.String())
+	}
+
+	return kept
+}
+
+// maxPeerAddrs returns the configured maximum number of addresses per peer, or the default one
+func (s *Server) maxPeerAddrs() int {
+	if s.config.MaxPeerAddrs > 0 {
+		return s.config.MaxPeerAddrs
+	}
+
+	return DefaultMaxPeerAddrs
+}
+
+// mergeAddrs returns the union of the address sets, preserving their order
+func mergeAddrs(existing, added []multiaddr.Multiaddr) []multiaddr.Multiaddr {
+	merged := make([]multiaddr.Multiaddr, 0, len(existing)+len(added))
+	seen := make(map[string]struct{}, len(existing)+len(added))
+
+	for _, addrs := range [][]multiaddr.Multiaddr{existing, added} {
+		for _, addr := range addrs {
+			if _, ok := seen[addr.String()]; ok {
+				continue
+			}
+
+			seen[addr.String()] = struct{}{}
+			merged = append(merged, addr)
+		}
+	}
+
+	return merged
+}
+
+// excludeAddrs returns the addresses which are not in the excluded set, preserving their order
+func excludeAddrs(addrs, excluded []multiaddr.Multiaddr) []multiaddr.Multiaddr {
+	skip := make(map[string]struct{}, len(excluded))
+	for _, addr := range excluded {
+		skip[addr.String()] = struct{}{}
+	}
+
+	remaining := make([]multiaddr.Multiaddr, 0, len(addrs))
+
+	for _, addr := range addrs {
+		if _, ok := skip[addr.String()]; !ok {
+			remaining = append(remaining, addr)
+		}
+	}
+
+	return remaining
+}
@@ -0,0 +1,149 @@
+package network
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/libp2p/go-libp2p/core/peerstore"
+	"github.com/multiformats/go-multiaddr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// generateTestAddrs generates the specified number of unique addresses
+func generateTestAddrs(t *testing.T, count int) []multiaddr.Multiaddr {
+	t.Helper()
+
+	addrs := make([]multiaddr.Multiaddr, count)
+
+	for i := 0; i < count; i++ {
+		addr, err := multiaddr.NewMultiaddr(fmt.Sprintf("/ip4/10.0.%d.%d/tcp/1478", i/256, i%256))
+		require.NoError(t, err)
+
+		addrs[i] = addr
+	}
+
+	return addrs
+}
+
+func TestAddToPeerStore_MaxPeerAddrs(t *testing.T) {
+	const maxPeerAddrs = 5
+
+	server, createErr := CreateServer(&CreateServerParams{ConfigCallback: func(c *Config) {
+		c.NoDiscover = true
+		c.MaxPeerAddrs = maxPeerAddrs
+	}})
+	require.NoError(t, createErr)
+
+	t.Cleanup(func() {
+		assert.NoError(t, server.Close())
+	})
+
+	randomPeers, err := generateRandomPeers(t, 1)
+	require.NoError(t, err)
+
+	peerID := randomPeers[0].peerID
+	addrs := generateTestAddrs(t, 100)
+
+	// Mark one of the last addresses as successful, so it is kept
+	server.peerAddrs.markSuccess(peerID, addrs[90], time.Now())
+
+	server.AddToPeerStore(&peer.AddrInfo{
+		ID:    peerID,
+		Addrs: addrs,
+	})
+
+	storedAddrs := server.host.Peerstore().Addrs(peerID)
+	assert.Len(t, storedAddrs, maxPeerAddrs)
+	assert.Contains(t, storedAddrs, addrs[90])
+
+	// Subsequent additions are limited as well
+	server.AddToPeerStore(&peer.AddrInfo{
+		ID:    peerID,
+		Addrs: generateTestAddrs(t, 200)[100:],
+	})
+
+	storedAddrs = server.host.Peerstore().Addrs(peerID)
+	assert.Len(t, storedAddrs, maxPeerAddrs)
+	assert.Contains(t, storedAddrs, addrs[90])
+}
+
+func TestAddToPeerStore_KeepsAddrTTLs(t *testing.T) {
+	const maxPeerAddrs = 5
+
+	server, createErr := CreateServer(&CreateServerParams{ConfigCallback: func(c *Config) {
+		c.NoDiscover = true
+		c.MaxPeerAddrs = maxPeerAddrs
+	}})
+	require.NoError(t, createErr)
+
+	t.Cleanup(func() {
+		assert.NoError(t, server.Close())
+	})
+
+	randomPeers, err := generateRandomPeers(t, 1)
+	require.NoError(t, err)
+
+	peerID := randomPeers[0].peerID
+	addrs := generateTestAddrs(t, 20)
+	store := server.host.Peerstore()
+
+	store.AddAddrs(peerID, addrs[:1], peerstore.PermanentAddrTTL)
+
+	// The limiting drops the excess addresses, but keeps the TTLs of the kept ones
+	server.AddToPeerStore(&peer.AddrInfo{
+		ID:    peerID,
+		Addrs: addrs,
+	})
+
+	require.Len(t, store.Addrs(peerID), maxPeerAddrs)
+
+	// Expiring the addresses with the regular TTL leaves the permanent one
+	store.UpdateAddrs(peerID, peerstore.AddressTTL, 0)
+
+	assert.Equal(t, addrs[:1], store.Addrs(peerID))
+}
+
+func TestPeerAddrTracker_Limit(t *testing.T) {
+	tracker := newPeerAddrTracker()
+	peerID := peer.ID("RandomPeer")
+	addrs := generateTestAddrs(t, 4)
+
+	now := time.Now()
+
+	tracker.markSuccess(peerID, addrs[3], now)
+	tracker.markSuccess(peerID, addrs[2], now.Add(time.Second))
+
+	// The most recently successful address comes first,
+	// and the order of unsuccessful addresses is preserved
+	assert.Equal(t, []multiaddr.Multiaddr{addrs[2], addrs[3], addrs[0]}, tracker.limit(peerID, addrs, 3))
+	assert.Equal(t, addrs, tracker.limit(peerID, addrs, 4))
+
+	// Records of dropped addresses are removed
+	assert.Equal(t, []multiaddr.Multiaddr{addrs[2]}, tracker.limit(peerID, addrs, 1))
+	assert.Len(t, tracker.lastSuccess[peerID], 1)
+
+	tracker.remove(peerID)
+	assert.Empty(t, tracker.lastSuccess)
+}
+
+func TestPeerAddrTracker_Retain(t *testing.T) {
+	tracker := newPeerAddrTracker()
+	peerID := peer.ID("RandomPeer")
+	addrs := generateTestAddrs(t, 3)
+
+	for _, addr := range addrs {
+		tracker.markSuccess(peerID, addr, time.Now())
+	}
+
+	// Only the records of the retained addresses are kept
+	tracker.retain(peerID, addrs[1:2])
+	assert.Len(t, tracker.lastSuccess[peerID], 1)
+	assert.Contains(t, tracker.lastSuccess[peerID], addrs[1].String())
+
+	// The peer is dropped once none of its addresses are retained
+	tracker.retain(peerID, nil)
+	assert.Empty(t, tracker.lastSuccess)
+}
@@ -0,0 +1,48 @@
+package network
+
+import (
+	"time"
+
+	"github.com/armon/go-metrics"
+	"github.com/libp2p/go-libp2p/core/network"
+	"github.com/libp2p/go-libp2p/core/peer"
+)
+
+// BanPeer disconnects from the peer after a goodbye, and refuses any further connections with it
+// for the specified duration, or until it is unbanned if the duration is not positive.
+// Unlike a quarantine, the ban is not escalated and is only lifted by the operator or the TTL [Thread safe]
+func (s *Server) BanPeer(peerID peer.ID, duration time.Duration, reason string) {
+	var until time.Time
+	if duration > 0 {
+		until = s.clock.Now().Add(duration)
+	}
+
+	s.gater.banPeer(peerID, until, reason)
+
+	// Abort any connection attempt to the peer
+	s.CancelDials(peerID)
+
+	s.logger.Warn("Peer banned", "peer", peerID, "reason", reason, "duration", duration)
+
+	metrics.IncrCounter([]string{networkMetrics, "banned_peers"}, 1)
+
+	if s.host.Network().Connectedness(peerID) == network.Connected {
+		s.logDisconnect(peerID, "peer banned: "+reason)
+
+		s.sendGoodbye(peerID, GoodbyeReasonDisconnect)
+
+		s.closePeer(peerID)
+	}
+}
+
+// UnbanPeer lifts the ban of the peer [Thread safe]
+func (s *Server) UnbanPeer(peerID peer.ID) {
+	s.gater.unbanPeer(peerID)
+
+	s.logger.Info("Peer unbanned", "peer", peerID)
+}
+
+// IsBanned checks if the peer is currently banned [Thread safe]
+func (s *Server) IsBanned(peerID peer.ID) bool {
+	return s.gater.isPeerBanned(peerID)
+}
@@ -0,0 +1,163 @@
+package network
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBanPeer(t *testing.T) {
+	servers, createErr := createServers(2, map[int]*CreateServerParams{
+		0: {ConfigCallback: func(c *Config) { c.NoDiscover = true }},
+		1: {ConfigCallback: func(c *Config) { c.NoDiscover = true }},
+	})
+	require.NoError(t, createErr)
+
+	t.Cleanup(func() {
+		closeTestServers(t, servers)
+	})
+
+	server, banned := servers[0], servers[1]
+	bannedID := banned.host.ID()
+
+	require.NoError(t, JoinAndWait(server, banned, DefaultBufferTimeout, DefaultJoinTimeout))
+
+	server.BanPeer(bannedID, time.Minute, "spamming")
+
+	// The open connection is torn down
+	require.Eventually(t, func() bool {
+		return !server.IsConnected(bannedID) && !server.hasPeer(bannedID)
+	}, 5*time.Second, 50*time.Millisecond)
+
+	assert.True(t, server.IsBanned(bannedID))
+	assert.True(t, server.ExplainPeer(bannedID).Banned)
+
+	// The banned peer is sent a goodbye first
+	goodbyeReason, saidGoodbye := banned.getGoodbyeReason(server.host.ID())
+	assert.True(t, saidGoodbye)
+	assert.Equal(t, GoodbyeReasonDisconnect, goodbyeReason)
+
+	reason, remaining, backedOff := server.PeerBackoffInfo(bannedID)
+	assert.True(t, backedOff)
+	assert.Equal(t, "banned: spamming", reason)
+	assert.LessOrEqual(t, remaining, time.Minute)
+
+	// Inbound connections from the banned peer are refused
+	smallTimeout := 5 * time.Second
+
+	assert.Error(t, JoinAndWait(banned, server, smallTimeout, smallTimeout))
+	assert.False(t, server.hasPeer(bannedID))
+
+	// The banned peer is not dialed
+	require.NoError(t, server.joinPeer(banned.AddrInfo()))
+	assert.Never(t, func() bool {
+		return server.IsConnected(bannedID)
+	}, time.Second, 50*time.Millisecond)
+
+	// The peer can connect again once the ban is lifted
+	server.UnbanPeer(bannedID)
+	assert.False(t, server.IsBanned(bannedID))
+
+	require.NoError(t, JoinAndWait(server, banned, DefaultBufferTimeout, DefaultJoinTimeout))
+}
+
+func TestConnectionGater_PeerBanExpiry(t *testing.T) {
+	clock := newFakeClock()
+
+	config := DefaultConfig()
+	config.Clock = clock
+
+	gater := newConnectionGater(config)
+
+	peers, err := generateRandomPeers(t, 2)
+	require.NoError(t, err)
+
+	temporary, indefinite := peers[0].peerID, peers[1].peerID
+
+	gater.banPeer(temporary, clock.Now().Add(time.Minute), "temporary")
+	gater.banPeer(indefinite, time.Time{}, "indefinite")
+
+	assert.False(t, gater.InterceptPeerDial(temporary))
+	assert.False(t, gater.InterceptPeerDial(indefinite))
+
+	clock.Advance(2 * time.Minute)
+
+	// Only the temporary ban expires
+	assert.False(t, gater.isPeerBanned(temporary))
+	assert.True(t, gater.InterceptPeerDial(temporary))
+	assert.True(t, gater.isPeerBanned(indefinite))
+	assert.False(t, gater.InterceptPeerDial(indefinite))
+
+	gater.unbanPeer(indefinite)
+	assert.False(t, gater.isPeerBanned(indefinite))
+}
+
+func TestConnectionGater_BanAndQuarantine(t *testing.T) {
+	clock := newFakeClock()
+
+	config := DefaultConfig()
+	config.Clock = clock
+
+	gater := newConnectionGater(config)
+
+	peers, err := generateRandomPeers(t, 1)
+	require.NoError(t, err)
+
+	peerID := peers[0].peerID
+
+	// A ban doesn't replace the quarantine
+	gater.quarantinePeer(peerID, clock.Now().Add(time.Hour), "violation")
+	gater.banPeer(peerID, time.Time{}, "operator request")
+
+	assert.True(t, gater.isPeerBanned(peerID))
+	assert.True(t, gater.isQuarantined(peerID))
+	assert.False(t, gater.InterceptPeerDial(peerID))
+
+	// Unbanning doesn't lift the quarantine
+	gater.unbanPeer(peerID)
+
+	assert.False(t, gater.isPeerBanned(peerID))
+	assert.True(t, gater.isQuarantined(peerID))
+	assert.False(t, gater.InterceptPeerDial(peerID))
+
+	// Neither does a shorter ban running out
+	gater.banPeer(peerID, clock.Now().Add(time.Minute), "operator request")
+	clock.Advance(2 * time.Minute)
+
+	assert.False(t, gater.isPeerBanned(peerID))
+	assert.True(t, gater.isQuarantined(peerID))
+	assert.False(t, gater.InterceptPeerDial(peerID))
+
+	// A later quarantine doesn't lift a ban either
+	gater.banPeer(peerID, time.Time{}, "operator request")
+	gater.quarantinePeer(peerID, clock.Now().Add(time.Minute), "violation")
+	clock.Advance(2 * time.Hour)
+
+	assert.True(t, gater.isPeerBanned(peerID))
+	assert.False(t, gater.isQuarantined(peerID))
+	assert.False(t, gater.InterceptPeerDial(peerID))
+}
+
+func TestPeerBackoffInfo_IndefiniteBan(t *testing.T) {
+	server, createErr := CreateServer(&CreateServerParams{ConfigCallback: func(c *Config) {
+		c.NoDiscover = true
+	}})
+	require.NoError(t, createErr)
+
+	t.Cleanup(func() {
+		assert.NoError(t, server.Close())
+	})
+
+	peerID := peer.ID("banned")
+
+	server.BanPeer(peerID, 0, "operator request")
+
+	reason, remaining, backedOff := server.PeerBackoffInfo(peerID)
+	assert.True(t, backedOff)
+	assert.Equal(t, "banned: operator request", reason)
+	assert.Equal(t, time.Duration(math.MaxInt64), remaining)
+}
@@ -0,0 +1,144 @@
+package network
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/multiformats/go-multiaddr"
+)
+
+// dialFailure is the record of the last failed dial to a peer
+type dialFailure struct {
+	err error     // the reason the dial failed
+	at  time.Time // the time the dial failed
+}
+
+// PeerDiagnosis aggregates the node state which prevents,
+// or previously prevented, a connection with a peer
+type PeerDiagnosis struct {
+	PeerID    peer.ID // the diagnosed peer
+	Connected bool    // flag indicating if the node is connected to the peer
+
+	NotAllowlisted  bool                  // flag indicating if the peer is not allowlisted, in the allowlist-only mode
+	Quarantined     bool                  // flag indicating if the peer is quarantined for violating the protocol
+	Banned          bool                  // flag indicating if the peer is banned by the operator
+	GoodbyeBackoff  bool                  // flag indicating if the peer said goodbye recently, so it is not redialed
+	DialRateLimited time.Duration         // the time until the peer can be dialed again, zero if not limited
+	DialBackoff     time.Duration         // the time until the peer is dialed again after failed dials, zero if none
+	KnownAddrs      []multiaddr.Multiaddr // the peer addresses in the peer store
+	BannedAddrs     []multiaddr.Multiaddr // the known peer addresses with a banned IP
+	NoRoutableAddrs bool                  // flag indicating if none of the known peer addresses are routable
+
+	LastDialError error     // the error of the last failed dial, if any
+	LastDialAt    time.Time // the time of the last failed dial, if any
+}
+
+// Blocked checks if any of the diagnosed states prevents dialing the peer
+func (d PeerDiagnosis) Blocked() bool {
+	allAddrsBanned := len(d.KnownAddrs) > 0 && len(d.BannedAddrs) == len(d.KnownAddrs)
+
+	return d.NotAllowlisted ||
+		d.Quarantined ||
+		d.Banned ||
+		d.GoodbyeBackoff ||
+		d.DialRateLimited > 0 ||
+		d.DialBackoff > 0 ||
+		d.NoRoutableAddrs ||
+		allAddrsBanned
+}
+
+// ExplainPeer reports the gating and backoff state of the peer,
+// along with the last dial error, to explain why the node is not connected to it [Thread safe]
+func (s *Server) ExplainPeer(peerID peer.ID) PeerDiagnosis {
+	diagnosis := PeerDiagnosis{
+		PeerID:          peerID,
+		Connected:       s.IsConnected(peerID),
+		NotAllowlisted:  !s.gater.isAllowed(peerID),
+		Quarantined:     s.IsQuarantined(peerID),
+		Banned:          s.IsBanned(peerID),
+		GoodbyeBackoff:  s.isInGoodbyeBackoff(peerID),
+		DialRateLimited: s.dialRate.retryAfter(peerID, s.clock.Now()),
+		DialBackoff:     s.dialBackoffRemaining(peerID),
+		KnownAddrs:      s.host.Peerstore().Addrs(peerID),
+		BannedAddrs:     make([]multiaddr.Multiaddr, 0),
+	}
+
+	for _, addr := range diagnosis.KnownAddrs {
+		if s.gater.isAddrBanned(addr) {
+			diagnosis.BannedAddrs = append(diagnosis.BannedAddrs, addr)
+		}
+	}
+
+	diagnosis.NoRoutableAddrs = !s.hasRoutableAddr(&peer.AddrInfo{ID: peerID, Addrs: diagnosis.KnownAddrs})
+
+	if value, ok := s.lastDialFailures.Load(peerID); ok {
+		failure, _ := value.(*dialFailure)
+
+		diagnosis.LastDialError = failure.err
+		diagnosis.LastDialAt = failure.at
+	}
+
+	return diagnosis
+}
+
+// PeerBackoffInfo reports why the peer is not dialed right now, and for how long it won't be.
+// When multiple backoffs apply, the one lasting the longest is reported.
+// An indefinite ban is reported with the maximum duration.
+// Returns false if the peer is not in any backoff [Thread safe]
+func (s *Server) PeerBackoffInfo(peerID peer.ID) (string, time.Duration, bool) {
+	var (
+		reason    string
+		remaining time.Duration
+	)
+
+	consider := func(candidateReason string, candidateRemaining time.Duration) {
+		if candidateRemaining > remaining {
+			reason, remaining = candidateReason, candidateRemaining
+		}
+	}
+
+	now := s.clock.Now()
+
+	if record, ok := s.gater.getQuarantine(peerID); ok {
+		consider(fmt.Sprintf("quarantined for a protocol violation: %s", record.reason), record.until.Sub(now))
+	}
+
+	if record, ok := s.gater.getPeerBan(peerID); ok {
+		banRemaining := time.Duration(math.MaxInt64)
+		if !record.until.IsZero() {
+			banRemaining = record.until.Sub(now)
+		}
+
+		consider(fmt.Sprintf("banned: %s", record.reason), banRemaining)
+	}
+
+	if record, ok := s.getGoodbyeBackoff(peerID); ok {
+		consider(fmt.Sprintf("said goodbye: %s", record.reason), record.until.Sub(now))
+	}
+
+	consider("exceeded the total connect deadline", s.connectDeadlineBackoffRemaining(peerID))
+
+	if failures, backoff := s.dialBackoff.get(peerID, now); backoff > 0 {
+		consider(fmt.Sprintf("%d consecutive failed dials", failures), backoff)
+	}
+
+	if retryAfter := s.dialRate.retryAfter(peerID, now); retryAfter > 0 {
+		rateReason := "dialed too often"
+
+		if value, ok := s.lastDialFailures.Load(peerID); ok {
+			failure, _ := value.(*dialFailure)
+			rateReason = fmt.Sprintf("dialed too often, the last dial failed: %v", failure.err)
+		}
+
+		consider(rateReason, retryAfter)
+	}
+
+	return reason, remaining, remaining > 0
+}
+
+// recordDialFailure keeps the error of the last failed dial to the peer
+func (s *Server) recordDialFailure(peerID peer.ID, err error) {
+	s.lastDialFailures.Store(peerID, &dialFailure{err: err, at: s.clock.Now()})
+}
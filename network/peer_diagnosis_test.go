@@ -0,0 +1,154 @@
+package network
+
+import (
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/libp2p/go-libp2p/core/peerstore"
+	"github.com/multiformats/go-multiaddr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExplainPeer(t *testing.T) {
+	server, createErr := CreateServer(&CreateServerParams{ConfigCallback: func(c *Config) {
+		c.NoDiscover = true
+		c.NatAddr = net.ParseIP("1.2.3.4")
+		c.AllowlistOnly = true
+	}})
+	require.NoError(t, createErr)
+
+	t.Cleanup(func() {
+		require.NoError(t, server.Close())
+	})
+
+	randomPeers, err := generateRandomPeers(t, 2)
+	require.NoError(t, err)
+
+	peerID, otherID := randomPeers[0].peerID, randomPeers[1].peerID
+	peerAddr := multiaddr.StringCast("/ip4/127.0.0.1/tcp/1478")
+
+	server.host.Peerstore().AddAddrs(peerID, []multiaddr.Multiaddr{peerAddr}, peerstore.PermanentAddrTTL)
+
+	// A peer nothing is known about is only blocked by the allowlist
+	diagnosis := server.ExplainPeer(otherID)
+	assert.True(t, diagnosis.NotAllowlisted)
+	assert.False(t, diagnosis.Quarantined)
+	assert.False(t, diagnosis.NoRoutableAddrs)
+	assert.NoError(t, diagnosis.LastDialError)
+
+	// Put the peer into several blocking states
+	dialErr := errors.New("connection refused")
+
+	server.BanIP(net.ParseIP("127.0.0.1"), time.Minute)
+	server.QuarantinePeer(peerID, "test")
+	server.goodbyes.Store(peerID, &goodbyeRecord{reason: GoodbyeReasonShutdown, until: time.Now().Add(time.Minute)})
+	server.recordDialFailure(peerID, dialErr)
+
+	for i := 0; i < DefaultMaxDialsPerPeer; i++ {
+		server.dialRate.allow(peerID, time.Now())
+	}
+
+	diagnosis = server.ExplainPeer(peerID)
+
+	assert.Equal(t, peerID, diagnosis.PeerID)
+	assert.False(t, diagnosis.Connected)
+	assert.True(t, diagnosis.Blocked())
+	assert.True(t, diagnosis.NotAllowlisted)
+	assert.True(t, diagnosis.Quarantined)
+	assert.True(t, diagnosis.GoodbyeBackoff)
+	assert.Positive(t, diagnosis.DialRateLimited)
+	assert.True(t, diagnosis.NoRoutableAddrs)
+	assert.Equal(t, []multiaddr.Multiaddr{peerAddr}, diagnosis.BannedAddrs)
+	assert.ErrorIs(t, diagnosis.LastDialError, dialErr)
+	assert.False(t, diagnosis.LastDialAt.IsZero())
+
+	// The dial failures are forgotten along with the peer
+	server.RemoveFromPeerStore(&peer.AddrInfo{ID: peerID})
+
+	assert.NoError(t, server.ExplainPeer(peerID).LastDialError)
+}
+
+func TestExplainPeer_DialFailureClearedOnConnect(t *testing.T) {
+	servers, createErr := createServers(2, map[int]*CreateServerParams{
+		0: {ConfigCallback: func(c *Config) { c.NoDiscover = true }},
+		1: {ConfigCallback: func(c *Config) { c.NoDiscover = true }},
+	})
+	require.NoError(t, createErr)
+
+	t.Cleanup(func() {
+		closeTestServers(t, servers)
+	})
+
+	server, remote := servers[0], servers[1]
+
+	server.recordDialFailure(remote.host.ID(), errors.New("connection refused"))
+	require.Error(t, server.ExplainPeer(remote.host.ID()).LastDialError)
+
+	// The failure is stale once the peer connects
+	require.NoError(t, JoinAndWait(server, remote, DefaultBufferTimeout, DefaultJoinTimeout))
+
+	assert.NoError(t, server.ExplainPeer(remote.host.ID()).LastDialError)
+}
+
+func TestPeerBackoffInfo(t *testing.T) {
+	const quarantineDuration = time.Hour
+
+	server, createErr := CreateServer(&CreateServerParams{ConfigCallback: func(c *Config) {
+		c.NoDiscover = true
+		c.QuarantineDuration = quarantineDuration
+	}})
+	require.NoError(t, createErr)
+
+	t.Cleanup(func() {
+		require.NoError(t, server.Close())
+	})
+
+	randomPeers, err := generateRandomPeers(t, 1)
+	require.NoError(t, err)
+
+	peerID := randomPeers[0].peerID
+
+	// A peer nothing is known about is not in backoff
+	_, _, ok := server.PeerBackoffInfo(peerID)
+	assert.False(t, ok)
+
+	// Failed dials put the peer into the dial rate backoff
+	server.recordDialFailure(peerID, errors.New("handshake failed"))
+
+	for i := 0; i < DefaultMaxDialsPerPeer; i++ {
+		server.dialRate.allow(peerID, time.Now())
+	}
+
+	reason, remaining, ok := server.PeerBackoffInfo(peerID)
+	require.True(t, ok)
+
+	assert.Equal(t, "dialed too often, the last dial failed: handshake failed", reason)
+	assert.LessOrEqual(t, remaining, DefaultDialRateWindow)
+	assert.InDelta(t, DefaultDialRateWindow, remaining, float64(time.Second))
+
+	// A longer backoff takes precedence
+	goodbyeBackoff := DefaultDialRateWindow + time.Minute
+
+	server.goodbyes.Store(peerID, &goodbyeRecord{
+		reason: GoodbyeReasonShutdown,
+		until:  time.Now().Add(goodbyeBackoff),
+	})
+
+	reason, remaining, ok = server.PeerBackoffInfo(peerID)
+	require.True(t, ok)
+
+	assert.Equal(t, "said goodbye: shutdown", reason)
+	assert.InDelta(t, goodbyeBackoff, remaining, float64(time.Second))
+
+	server.QuarantinePeer(peerID, "invalid block")
+
+	reason, remaining, ok = server.PeerBackoffInfo(peerID)
+	require.True(t, ok)
+
+	assert.Equal(t, "quarantined for a protocol violation: invalid block", reason)
+	assert.InDelta(t, quarantineDuration, remaining, float64(time.Second))
+}
@@ -0,0 +1,112 @@
+package network
+
+import (
+	"sync"
+	"time"
+
+	"github.com/libp2p/go-libp2p/core/network"
+	"github.com/libp2p/go-libp2p/core/peer"
+)
+
+// maxPeerHistoryEntries is the maximum number of history entries kept per peer
+const maxPeerHistoryEntries = 32
+
+// PeerHistoryOutcome is the kind of a peer connection history entry
+type PeerHistoryOutcome int
+
+const (
+	// PeerHistoryConnected is a completed peer connection
+	PeerHistoryConnected PeerHistoryOutcome = iota
+
+	// PeerHistoryDialFailed is a failed dial to the peer
+	PeerHistoryDialFailed
+
+	// PeerHistoryDisconnected is a peer disconnect
+	PeerHistoryDisconnected
+)
+
+// String returns the string representation of the history outcome
+func (o PeerHistoryOutcome) String() string {
+	switch o {
+	case PeerHistoryConnected:
+		return "connected"
+	case PeerHistoryDialFailed:
+		return "dial-failed"
+	case PeerHistoryDisconnected:
+		return "disconnected"
+	default:
+		return "unknown"
+	}
+}
+
+// PeerHistoryEntry is a single event in the connection history of a peer
+type PeerHistoryEntry struct {
+	At        time.Time          // the time of the event
+	Outcome   PeerHistoryOutcome // the kind of the event
+	Direction network.Direction  // the direction of the connection, if the peer connected
+	Err       error              // the reason of the failure, if the dial failed
+	Local     bool               // whether the disconnect was initiated by this node
+}
+
+// peerHistoryTracker keeps the recent connection history of the peers,
+// bounded to the latest entries per peer
+type peerHistoryTracker struct {
+	sync.Mutex
+
+	entries map[peer.ID][]PeerHistoryEntry
+}
+
+// newPeerHistoryTracker creates a new peer history tracker
+func newPeerHistoryTracker() *peerHistoryTracker {
+	return &peerHistoryTracker{
+		entries: make(map[peer.ID][]PeerHistoryEntry),
+	}
+}
+
+// record appends the entry to the peer history, dropping the oldest entries over the cap [Thread safe]
+func (t *peerHistoryTracker) record(peerID peer.ID, entry PeerHistoryEntry) {
+	t.Lock()
+	defer t.Unlock()
+
+	entries := append(t.entries[peerID], entry)
+	if len(entries) > maxPeerHistoryEntries {
+		entries = append([]PeerHistoryEntry(nil), entries[len(entries)-maxPeerHistoryEntries:]...)
+	}
+
+	t.entries[peerID] = entries
+}
+
+// get returns a copy of the peer history, from the oldest to the latest entry [Thread safe]
+func (t *peerHistoryTracker) get(peerID peer.ID) []PeerHistoryEntry {
+	t.Lock()
+	defer t.Unlock()
+
+	return append([]PeerHistoryEntry(nil), t.entries[peerID]...)
+}
+
+// latest returns the latest history entry of the peer, if any [Thread safe]
+func (t *peerHistoryTracker) latest(peerID peer.ID) (PeerHistoryEntry, bool) {
+	t.Lock()
+	defer t.Unlock()
+
+	entries := t.entries[peerID]
+	if len(entries) == 0 {
+		return PeerHistoryEntry{}, false
+	}
+
+	return entries[len(entries)-1], true
+}
+
+// remove removes the peer history [Thread safe]
+func (t *peerHistoryTracker) remove(peerID peer.ID) {
+	t.Lock()
+	defer t.Unlock()
+
+	delete(t.entries, peerID)
+}
+
+// PeerHistory returns the recent connects, failed dials and disconnects of the peer,
+// from the oldest to the latest one [Thread safe]
+func (s *Server) PeerHistory(peerID peer.ID) []PeerHistoryEntry {
+	return s.peerHistory.get(peerID)
+}
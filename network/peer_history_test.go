@@ -0,0 +1,84 @@
+package network
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/libp2p/go-libp2p/core/network"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPeerHistoryTracker_Cap(t *testing.T) {
+	tracker := newPeerHistoryTracker()
+	peerID := peer.ID("RandomPeer")
+	start := time.Now()
+
+	const total = 2 * maxPeerHistoryEntries
+
+	for i := 0; i < total; i++ {
+		tracker.record(peerID, PeerHistoryEntry{
+			At:      start.Add(time.Duration(i) * time.Second),
+			Outcome: PeerHistoryDialFailed,
+			Err:     errors.New("dial failed"),
+		})
+	}
+
+	history := tracker.get(peerID)
+	require.Len(t, history, maxPeerHistoryEntries)
+
+	// Only the latest entries are kept, from the oldest to the latest
+	assert.Equal(t, start.Add((total-maxPeerHistoryEntries)*time.Second), history[0].At)
+	assert.Equal(t, start.Add((total-1)*time.Second), history[len(history)-1].At)
+
+	tracker.remove(peerID)
+	assert.Empty(t, tracker.get(peerID))
+}
+
+func TestPeerHistory_ConnectDisconnectCycles(t *testing.T) {
+	servers, createErr := createServers(2, map[int]*CreateServerParams{
+		0: {ConfigCallback: func(c *Config) { c.NoDiscover = true }},
+		1: {ConfigCallback: func(c *Config) { c.NoDiscover = true }},
+	})
+	require.NoError(t, createErr)
+
+	t.Cleanup(func() {
+		closeTestServers(t, servers)
+	})
+
+	server, remote := servers[0], servers[1]
+	remoteID := remote.host.ID()
+
+	const cycles = 3
+
+	for i := 0; i < cycles; i++ {
+		require.NoError(t, JoinAndWait(server, remote, DefaultBufferTimeout, DefaultJoinTimeout))
+
+		server.DisconnectFromPeer(remoteID, "Bye")
+
+		disconnectCtx, disconnectFn := context.WithTimeout(context.Background(), DefaultJoinTimeout)
+		_, disconnectErr := WaitUntilPeerDisconnectsFrom(disconnectCtx, server, remoteID)
+
+		disconnectFn()
+		require.NoError(t, disconnectErr)
+	}
+
+	history := server.PeerHistory(remoteID)
+	require.Len(t, history, 2*cycles)
+
+	for i, entry := range history {
+		if i%2 == 0 {
+			assert.Equal(t, PeerHistoryConnected, entry.Outcome)
+			assert.Equal(t, network.DirOutbound, entry.Direction)
+		} else {
+			assert.Equal(t, PeerHistoryDisconnected, entry.Outcome)
+		}
+
+		if i > 0 {
+			assert.False(t, entry.At.Before(history[i-1].At))
+		}
+	}
+}
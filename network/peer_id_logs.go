@@ -0,0 +1,28 @@
+package network
+
+import (
+	"github.com/libp2p/go-libp2p/core/peer"
+)
+
+// shortPeerIDChars is the number of characters kept from each end of a peer ID in short form
+const shortPeerIDChars = 6
+
+// shortPeerID returns the short form of the peer ID, made of its first and last few characters
+func shortPeerID(id peer.ID) string {
+	full := id.String()
+	if len(full) <= 2*shortPeerIDChars {
+		return full
+	}
+
+	return full[:shortPeerIDChars] + "..." + full[len(full)-shortPeerIDChars:]
+}
+
+// peerLogMsg returns the log message about the peer, naming the peer in short form
+// if the short peer ID logs are turned on. The full ID is kept in the structured log fields
+func (s *Server) peerLogMsg(msg string, id peer.ID) string {
+	if !s.config.ShortPeerIDLogs {
+		return msg
+	}
+
+	return msg + " " + shortPeerID(id)
+}
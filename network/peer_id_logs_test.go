@@ -0,0 +1,88 @@
+package network
+
+import (
+	"bytes"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// syncBuffer is a log output which can be read while the servers log concurrently
+type syncBuffer struct {
+	lock sync.Mutex
+	buf  bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	return b.buf.String()
+}
+
+func TestShortPeerID(t *testing.T) {
+	randomPeers, err := generateRandomPeers(t, 1)
+	require.NoError(t, err)
+
+	full := randomPeers[0].peerID.String()
+	short := shortPeerID(randomPeers[0].peerID)
+
+	assert.Equal(t, full[:shortPeerIDChars]+"..."+full[len(full)-shortPeerIDChars:], short)
+
+	// IDs that are short already are kept as they are
+	assert.Equal(t, peer.ID("A").String(), shortPeerID(peer.ID("A")))
+}
+
+func TestShortPeerIDLogs(t *testing.T) {
+	for _, enabled := range []bool{true, false} {
+		output := &syncBuffer{}
+
+		servers, createErr := createServers(2, map[int]*CreateServerParams{
+			0: {
+				ConfigCallback: func(c *Config) {
+					c.NoDiscover = true
+					c.ShortPeerIDLogs = enabled
+				},
+				Logger: hclog.New(&hclog.LoggerOptions{Output: output, Level: hclog.Info}),
+			},
+		})
+		require.NoError(t, createErr)
+
+		peerID := servers[1].AddrInfo().ID
+
+		require.NoError(t, JoinAndWait(servers[0], servers[1], DefaultBufferTimeout, DefaultJoinTimeout))
+
+		closeTestServers(t, servers)
+
+		var connectedLine string
+
+		for _, line := range strings.Split(output.String(), "\n") {
+			if strings.Contains(line, "Peer connected") {
+				connectedLine = line
+			}
+		}
+
+		require.NotEmpty(t, connectedLine)
+
+		// The full ID is always kept in the structured fields
+		assert.Contains(t, connectedLine, "id="+peerID.String())
+
+		if enabled {
+			assert.Contains(t, connectedLine, "Peer connected "+shortPeerID(peerID)+":")
+		} else {
+			assert.NotContains(t, connectedLine, shortPeerID(peerID))
+		}
+	}
+}
@@ -0,0 +1,247 @@
+package network
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/libp2p/go-libp2p/p2p/protocol/ping"
+)
+
+const (
+	// pingTimeout is the maximum time a peer has to respond to a ping
+	pingTimeout = 10 * time.Second
+
+	// maxMissedPings is the number of consecutive pings a peer can miss before being disconnected
+	maxMissedPings = 3
+)
+
+// idleTracker keeps track of the last activity of the connected peers,
+// based on the traffic exchanged with them, and of the pings sent to them
+type idleTracker struct {
+	sync.Mutex
+
+	lastTraffic  map[peer.ID]int64     // peerID -> total bytes exchanged at the last check
+	lastActivity map[peer.ID]time.Time // peerID -> time the traffic last changed
+	lastPing     map[peer.ID]time.Time // peerID -> time the last ping was sent
+	missedPings  map[peer.ID]int       // peerID -> number of consecutive failed pings
+	pinging      map[peer.ID]struct{}  // peers with a ping in flight
+	roles        map[peer.ID]PeerRole  // peerID -> role, regular if not set
+}
+
+// newIdleTracker creates a new peer idle tracker
+func newIdleTracker() *idleTracker {
+	return &idleTracker{
+		lastTraffic:  make(map[peer.ID]int64),
+		lastActivity: make(map[peer.ID]time.Time),
+		lastPing:     make(map[peer.ID]time.Time),
+		missedPings:  make(map[peer.ID]int),
+		pinging:      make(map[peer.ID]struct{}),
+		roles:        make(map[peer.ID]PeerRole),
+	}
+}
+
+// observe records the total traffic exchanged with the peer, and returns
+// the time elapsed since the traffic last changed [Thread safe]
+func (t *idleTracker) observe(peerID peer.ID, traffic int64, now time.Time) time.Duration {
+	t.Lock()
+	defer t.Unlock()
+
+	lastTraffic, ok := t.lastTraffic[peerID]
+	if !ok || lastTraffic != traffic {
+		t.lastTraffic[peerID] = traffic
+		t.lastActivity[peerID] = now
+	}
+
+	return now.Sub(t.lastActivity[peerID])
+}
+
+// pingDue reports if the interval since the last ping to the peer has passed.
+// A peer that missed its last ping is always due, until it responds [Thread safe]
+func (t *idleTracker) pingDue(peerID peer.ID, interval time.Duration, now time.Time) bool {
+	t.Lock()
+	defer t.Unlock()
+
+	if t.missedPings[peerID] > 0 {
+		return true
+	}
+
+	if interval <= 0 {
+		return false
+	}
+
+	lastPing, ok := t.lastPing[peerID]
+
+	return !ok || now.Sub(lastPing) >= interval
+}
+
+// startPing marks the ping to the peer as in flight.
+// Returns false if a ping is already in flight [Thread safe]
+func (t *idleTracker) startPing(peerID peer.ID, now time.Time) bool {
+	t.Lock()
+	defer t.Unlock()
+
+	if _, ok := t.pinging[peerID]; ok {
+		return false
+	}
+
+	t.lastPing[peerID] = now
+	t.pinging[peerID] = struct{}{}
+
+	return true
+}
+
+// finishPing marks the ping to the peer as done, and returns the number of
+// consecutive pings the peer missed. A successful ping resets the peer idle time [Thread safe]
+func (t *idleTracker) finishPing(peerID peer.ID, success bool, now time.Time) int {
+	t.Lock()
+	defer t.Unlock()
+
+	delete(t.pinging, peerID)
+
+	if !success {
+		t.missedPings[peerID]++
+
+		return t.missedPings[peerID]
+	}
+
+	delete(t.missedPings, peerID)
+	t.lastActivity[peerID] = now
+
+	return 0
+}
+
+// setRole sets the role of the peer [Thread safe]
+func (t *idleTracker) setRole(peerID peer.ID, role PeerRole) {
+	t.Lock()
+	defer t.Unlock()
+
+	if role == PeerRoleRegular {
+		delete(t.roles, peerID)
+
+		return
+	}
+
+	t.roles[peerID] = role
+}
+
+// role returns the role of the peer [Thread safe]
+func (t *idleTracker) role(peerID peer.ID) PeerRole {
+	t.Lock()
+	defer t.Unlock()
+
+	return t.roles[peerID]
+}
+
+// remove removes all the records of the disconnected peer, including its role [Thread safe]
+func (t *idleTracker) remove(peerID peer.ID) {
+	t.Lock()
+	defer t.Unlock()
+
+	t.removeRecords(peerID)
+	delete(t.roles, peerID)
+}
+
+// retain removes the activity and ping records of the peers that are not in the set [Thread safe]
+func (t *idleTracker) retain(peers map[peer.ID]struct{}) {
+	t.Lock()
+	defer t.Unlock()
+
+	for peerID := range t.lastTraffic {
+		if _, ok := peers[peerID]; !ok {
+			t.removeRecords(peerID)
+		}
+	}
+
+	for peerID := range t.lastPing {
+		if _, ok := peers[peerID]; !ok {
+			t.removeRecords(peerID)
+		}
+	}
+}
+
+// removeRecords removes the activity and ping records of the peer. Must be called with the lock held
+func (t *idleTracker) removeRecords(peerID peer.ID) {
+	delete(t.lastTraffic, peerID)
+	delete(t.lastActivity, peerID)
+	delete(t.lastPing, peerID)
+	delete(t.missedPings, peerID)
+}
+
+// idleCheckInterval returns the interval of the idle and keepalive checks, which is
+// the shortest of half the idle timeout and the configured ping intervals.
+// Returns 0 if both the idle timeout and the keepalive pings are disabled
+func (s *Server) idleCheckInterval() time.Duration {
+	checkInterval := s.config.PeerIdleTimeout / 2
+
+	for _, interval := range s.config.PingIntervals {
+		if interval > 0 && (checkInterval <= 0 || interval < checkInterval) {
+			checkInterval = interval
+		}
+	}
+
+	return checkInterval
+}
+
+// runIdleChecks periodically pings the connected peers that exchanged no traffic
+// within the idle timeout, and the peers whose keepalive ping interval (by role) has passed.
+// Peers are only disconnected after missing several consecutive pings,
+// so quiet but alive peers are kept
+func (s *Server) runIdleChecks(checkInterval time.Duration) {
+	for {
+		select {
+		case <-s.clock.After(checkInterval):
+		case <-s.closeCh:
+			return
+		}
+
+		now := s.clock.Now()
+		connected := make(map[peer.ID]struct{})
+
+		for _, connInfo := range s.Peers() {
+			peerID := connInfo.Info.ID
+			connected[peerID] = struct{}{}
+
+			if s.IsPinned(peerID) {
+				// Pinned peers are never dropped voluntarily
+				continue
+			}
+
+			stats := s.bandwidthCounter.GetBandwidthForPeer(peerID)
+			idleTime := s.idlePeers.observe(peerID, stats.TotalIn+stats.TotalOut, now)
+
+			idle := s.config.PeerIdleTimeout > 0 && idleTime >= s.config.PeerIdleTimeout
+			if !idle && !s.idlePeers.pingDue(peerID, s.pingInterval(peerID), now) {
+				continue
+			}
+
+			if s.idlePeers.startPing(peerID, now) {
+				go s.pingPeer(peerID)
+			}
+		}
+
+		s.idlePeers.retain(connected)
+	}
+}
+
+// pingPeer pings the peer, and disconnects from it once it missed maxMissedPings consecutive pings
+func (s *Server) pingPeer(peerID peer.ID) {
+	ctx, cancel := withClockTimeout(context.Background(), s.clock, pingTimeout)
+	defer cancel()
+
+	result := <-ping.Ping(ctx, s.host, peerID)
+
+	missed := s.idlePeers.finishPing(peerID, result.Error == nil, s.clock.Now())
+	if missed == 0 {
+		s.logger.Debug("Peer responded to ping", "peer", peerID, "rtt", result.RTT)
+
+		return
+	}
+
+	s.logger.Debug("Peer did not respond to ping", "peer", peerID, "missed", missed, "err", result.Error)
+
+	if missed >= maxMissedPings {
+		s.DisconnectFromPeer(peerID, "peer not responding to pings")
+	}
+}
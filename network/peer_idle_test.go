@@ -0,0 +1,77 @@
+package network
+
+import (
+	"testing"
+	"time"
+
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/libp2p/go-libp2p/p2p/protocol/ping"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIdleTracker(t *testing.T) {
+	tracker := newIdleTracker()
+	peerID := peer.ID("RandomPeer")
+	start := time.Now()
+
+	assert.Zero(t, tracker.observe(peerID, 100, start))
+	assert.Equal(t, time.Second, tracker.observe(peerID, 100, start.Add(time.Second)))
+
+	// New traffic resets the idle time
+	assert.Zero(t, tracker.observe(peerID, 200, start.Add(2*time.Second)))
+
+	// Only a single ping is in flight per peer
+	assert.True(t, tracker.startPing(peerID, start))
+	assert.False(t, tracker.startPing(peerID, start))
+
+	// The missed pings are counted until the peer responds
+	assert.Equal(t, 1, tracker.finishPing(peerID, false, start))
+	assert.True(t, tracker.startPing(peerID, start))
+	assert.Equal(t, 2, tracker.finishPing(peerID, false, start))
+	assert.True(t, tracker.startPing(peerID, start))
+	assert.Zero(t, tracker.finishPing(peerID, true, start.Add(3*time.Second)))
+
+	// A successful ping resets the peer idle time
+	assert.Equal(t, time.Second, tracker.observe(peerID, 200, start.Add(4*time.Second)))
+
+	tracker.retain(map[peer.ID]struct{}{})
+	assert.Empty(t, tracker.lastActivity)
+	assert.Empty(t, tracker.lastPing)
+}
+
+func TestPeerIdleTimeout_Ping(t *testing.T) {
+	const idleTimeout = time.Second
+
+	servers, createErr := createServers(3, map[int]*CreateServerParams{
+		0: {ConfigCallback: func(c *Config) {
+			c.NoDiscover = true
+			c.PeerIdleTimeout = idleTimeout
+		}},
+		1: {ConfigCallback: func(c *Config) { c.NoDiscover = true }},
+		2: {ConfigCallback: func(c *Config) { c.NoDiscover = true }},
+	})
+	require.NoError(t, createErr)
+
+	t.Cleanup(func() {
+		closeTestServers(t, servers)
+	})
+
+	server, responsive, dead := servers[0], servers[1], servers[2]
+
+	require.NoError(t, JoinAndWait(server, responsive, DefaultBufferTimeout, DefaultJoinTimeout))
+	require.NoError(t, JoinAndWait(server, dead, DefaultBufferTimeout, DefaultJoinTimeout))
+
+	// The dead peer keeps the connection open, but doesn't respond to pings
+	dead.host.RemoveStreamHandler(ping.ID)
+
+	// The idle and dead peer is dropped
+	require.Eventually(t, func() bool {
+		return !server.hasPeer(dead.host.ID())
+	}, 5*idleTimeout, 100*time.Millisecond)
+
+	// The idle but responsive peer survives multiple idle timeouts
+	time.Sleep(2 * idleTimeout)
+
+	assert.True(t, server.hasPeer(responsive.host.ID()))
+}
@@ -0,0 +1,64 @@
+package network
+
+import (
+	"time"
+
+	"github.com/libp2p/go-libp2p/core/peer"
+)
+
+// PeerRole defines how critical the liveness of a peer is,
+// which determines how often the peer is pinged
+type PeerRole int
+
+const (
+	// PeerRoleRegular is the role of the bulk peers
+	PeerRoleRegular PeerRole = iota
+
+	// PeerRoleCritical is the role of the peers whose failure has to be detected quickly (validators)
+	PeerRoleCritical
+)
+
+// String returns the string representation of the peer role
+func (r PeerRole) String() string {
+	switch r {
+	case PeerRoleRegular:
+		return "regular"
+	case PeerRoleCritical:
+		return "critical"
+	default:
+		return "unknown"
+	}
+}
+
+const (
+	// DefaultRegularPingInterval is the recommended keepalive ping interval of the regular peers
+	DefaultRegularPingInterval = 30 * time.Second
+
+	// DefaultCriticalPingInterval is the recommended keepalive ping interval of the critical peers
+	DefaultCriticalPingInterval = 5 * time.Second
+)
+
+// DefaultPingIntervals returns the recommended keepalive ping intervals per peer role.
+// The keepalive pings are disabled unless Config.PingIntervals is set
+func DefaultPingIntervals() map[PeerRole]time.Duration {
+	return map[PeerRole]time.Duration{
+		PeerRoleRegular:  DefaultRegularPingInterval,
+		PeerRoleCritical: DefaultCriticalPingInterval,
+	}
+}
+
+// SetPeerRole sets the role of the peer, which determines its keepalive ping interval.
+// The role is forgotten once the peer disconnects [Thread safe]
+func (s *Server) SetPeerRole(peerID peer.ID, role PeerRole) {
+	s.idlePeers.setRole(peerID, role)
+}
+
+// PeerRole returns the role of the peer, regular if not set [Thread safe]
+func (s *Server) PeerRole(peerID peer.ID) PeerRole {
+	return s.idlePeers.role(peerID)
+}
+
+// pingInterval returns the keepalive ping interval of the peer, disabled if 0
+func (s *Server) pingInterval(peerID peer.ID) time.Duration {
+	return s.config.PingIntervals[s.idlePeers.role(peerID)]
+}
@@ -0,0 +1,156 @@
+package network
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	peerEvent "github.com/0xPolygon/polygon-edge/network/event"
+	"github.com/libp2p/go-libp2p/core/network"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/libp2p/go-libp2p/p2p/protocol/ping"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeepAliveTracker(t *testing.T) {
+	tracker := newIdleTracker()
+	peerID := peer.ID("RandomPeer")
+	start := time.Now()
+
+	assert.Equal(t, PeerRoleRegular, tracker.role(peerID))
+
+	tracker.setRole(peerID, PeerRoleCritical)
+	assert.Equal(t, PeerRoleCritical, tracker.role(peerID))
+
+	// The first ping is due right away, the next one waits for the interval
+	assert.True(t, tracker.pingDue(peerID, time.Second, start))
+	assert.True(t, tracker.startPing(peerID, start))
+	tracker.finishPing(peerID, true, start)
+
+	assert.False(t, tracker.pingDue(peerID, time.Second, start.Add(time.Second/2)))
+	assert.True(t, tracker.pingDue(peerID, time.Second, start.Add(time.Second)))
+
+	// A peer that missed a ping is pinged on every check
+	assert.True(t, tracker.startPing(peerID, start.Add(time.Second)))
+	tracker.finishPing(peerID, false, start.Add(time.Second))
+	assert.True(t, tracker.pingDue(peerID, time.Second, start.Add(time.Second)))
+	assert.True(t, tracker.pingDue(peerID, 0, start.Add(time.Second)))
+
+	// The role is kept while the peer is connected, and forgotten once it disconnects
+	tracker.retain(map[peer.ID]struct{}{peerID: {}})
+	assert.Equal(t, PeerRoleCritical, tracker.role(peerID))
+
+	tracker.remove(peerID)
+	assert.Equal(t, PeerRoleRegular, tracker.role(peerID))
+	assert.Empty(t, tracker.roles)
+	assert.Empty(t, tracker.missedPings)
+}
+
+func TestKeepAlivePings_PerRoleInterval(t *testing.T) {
+	servers, createErr := createServers(3, map[int]*CreateServerParams{
+		0: {ConfigCallback: func(c *Config) {
+			c.NoDiscover = true
+			c.PingIntervals = map[PeerRole]time.Duration{
+				PeerRoleRegular:  time.Second,
+				PeerRoleCritical: 100 * time.Millisecond,
+			}
+		}},
+		1: {ConfigCallback: func(c *Config) {
+			c.NoDiscover = true
+			c.PingIntervals = nil
+		}},
+		2: {ConfigCallback: func(c *Config) {
+			c.NoDiscover = true
+			c.PingIntervals = nil
+		}},
+	})
+	require.NoError(t, createErr)
+
+	t.Cleanup(func() {
+		closeTestServers(t, servers)
+	})
+
+	server, critical, regular := servers[0], servers[1], servers[2]
+
+	server.SetPeerRole(critical.host.ID(), PeerRoleCritical)
+	assert.Equal(t, PeerRoleCritical, server.PeerRole(critical.host.ID()))
+	assert.Equal(t, PeerRoleRegular, server.PeerRole(regular.host.ID()))
+
+	// Count the pings received by each peer
+	countPings := func(s *Server) *atomic.Int64 {
+		var pings atomic.Int64
+
+		service := &ping.PingService{Host: s.host}
+
+		s.host.SetStreamHandler(ping.ID, func(stream network.Stream) {
+			pings.Add(1)
+			service.PingHandler(stream)
+		})
+
+		return &pings
+	}
+
+	criticalPings, regularPings := countPings(critical), countPings(regular)
+
+	require.NoError(t, JoinAndWait(server, critical, DefaultBufferTimeout, DefaultJoinTimeout))
+	require.NoError(t, JoinAndWait(server, regular, DefaultBufferTimeout, DefaultJoinTimeout))
+
+	time.Sleep(2500 * time.Millisecond)
+
+	assert.GreaterOrEqual(t, regularPings.Load(), int64(1))
+	assert.Greater(t, criticalPings.Load(), 4*regularPings.Load())
+}
+
+func TestKeepAlivePings_DisconnectAfterMissedPings(t *testing.T) {
+	servers, createErr := createServers(2, map[int]*CreateServerParams{
+		0: {ConfigCallback: func(c *Config) {
+			c.NoDiscover = true
+			c.PingIntervals = map[PeerRole]time.Duration{
+				PeerRoleCritical: 100 * time.Millisecond,
+			}
+		}},
+		1: {ConfigCallback: func(c *Config) { c.NoDiscover = true }},
+	})
+	require.NoError(t, createErr)
+
+	t.Cleanup(func() {
+		closeTestServers(t, servers)
+	})
+
+	server, dead := servers[0], servers[1]
+
+	// The dead peer keeps the connection open, but fails every ping
+	var pings atomic.Int64
+
+	dead.host.SetStreamHandler(ping.ID, func(stream network.Stream) {
+		pings.Add(1)
+		_ = stream.Reset()
+	})
+
+	// Only the critical peers are pinged
+	require.NoError(t, JoinAndWait(server, dead, DefaultBufferTimeout, DefaultJoinTimeout))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	events, err := server.SubscribeCh(ctx)
+	require.NoError(t, err)
+
+	server.SetPeerRole(dead.host.ID(), PeerRoleCritical)
+
+	// The dead peer may be redialed later, so wait for the disconnect event
+	for disconnected := false; !disconnected; {
+		select {
+		case evnt := <-events:
+			disconnected = evnt.PeerID == dead.host.ID() && evnt.Type == peerEvent.PeerDisconnected
+		case <-ctx.Done():
+			t.Fatal("dead peer not disconnected")
+		}
+	}
+
+	// The peer is only dropped once it missed several consecutive pings
+	assert.Equal(t, int64(maxMissedPings), pings.Load())
+	assert.Equal(t, PeerRoleRegular, server.PeerRole(dead.host.ID()))
+}
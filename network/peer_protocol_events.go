@@ -0,0 +1,67 @@
+package network
+
+import (
+	peerEvent "github.com/0xPolygon/polygon-edge/network/event"
+	"github.com/libp2p/go-libp2p/core/event"
+	"github.com/libp2p/go-libp2p/core/protocol"
+)
+
+// watchPeerProtocols emits a PeerProtocolsUpdated event whenever a connected peer
+// pushes a changed protocol list (e.g. once it starts serving a protocol after the handshake)
+func (s *Server) watchPeerProtocols() error {
+	sub, err := s.host.EventBus().Subscribe(new(event.EvtPeerProtocolsUpdated))
+	if err != nil {
+		return err
+	}
+
+	done := s.health.subscriptions.start()
+
+	go func() {
+		defer done()
+		defer sub.Close()
+
+		for {
+			select {
+			case <-s.closeCh:
+				return
+			case evnt, ok := <-sub.Out():
+				if !ok {
+					return
+				}
+
+				updated, ok := evnt.(event.EvtPeerProtocolsUpdated)
+				if !ok || !s.hasPeer(updated.Peer) {
+					continue
+				}
+
+				if len(updated.Added) == 0 && len(updated.Removed) == 0 {
+					continue
+				}
+
+				s.emitProtocolsUpdated(updated)
+			}
+		}
+	}()
+
+	return nil
+}
+
+// emitProtocolsUpdated emits the PeerProtocolsUpdated event of the peer
+func (s *Server) emitProtocolsUpdated(updated event.EvtPeerProtocolsUpdated) {
+	s.logger.Debug(
+		s.peerLogMsg("Peer protocols updated", updated.Peer),
+		"id", updated.Peer,
+		"added", updated.Added,
+		"removed", updated.Removed,
+	)
+
+	// POTENTIALLY BLOCKING
+	if err := s.emitterPeerEvent.Emit(peerEvent.PeerEvent{
+		PeerID:           updated.Peer,
+		Type:             peerEvent.PeerProtocolsUpdated,
+		AddedProtocols:   protocol.ConvertToStrings(updated.Added),
+		RemovedProtocols: protocol.ConvertToStrings(updated.Removed),
+	}); err != nil {
+		s.logger.Info("failed to emit event", "peer", updated.Peer, "type", peerEvent.PeerProtocolsUpdated, "err", err)
+	}
+}
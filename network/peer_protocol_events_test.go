@@ -0,0 +1,78 @@
+package network
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	peerEvent "github.com/0xPolygon/polygon-edge/network/event"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPeerProtocolsUpdated(t *testing.T) {
+	const lateProto = "/late-test/0.1"
+
+	servers, createErr := createServers(2, map[int]*CreateServerParams{
+		0: {ConfigCallback: func(c *Config) { c.NoDiscover = true }},
+		1: {ConfigCallback: func(c *Config) { c.NoDiscover = true }},
+	})
+	require.NoError(t, createErr)
+
+	t.Cleanup(func() {
+		closeTestServers(t, servers)
+	})
+
+	server, remote := servers[0], servers[1]
+
+	require.NoError(t, JoinAndWait(server, remote, DefaultBufferTimeout, DefaultJoinTimeout))
+
+	updatesCh := make(chan *peerEvent.PeerEvent, 16)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	require.NoError(t, server.Subscribe(ctx, func(evnt *peerEvent.PeerEvent) {
+		if evnt.Type == peerEvent.PeerProtocolsUpdated {
+			updatesCh <- evnt
+		}
+	}))
+
+	// waitUpdate waits for the update of the remote peer mentioning the late protocol
+	waitUpdate := func() *peerEvent.PeerEvent {
+		timeout := time.After(10 * time.Second)
+
+		for {
+			select {
+			case evnt := <-updatesCh:
+				if evnt.PeerID != remote.host.ID() {
+					continue
+				}
+
+				for _, protos := range [][]string{evnt.AddedProtocols, evnt.RemovedProtocols} {
+					for _, proto := range protos {
+						if proto == lateProto {
+							return evnt
+						}
+					}
+				}
+			case <-timeout:
+				t.Fatal("peer protocols update not emitted")
+			}
+		}
+	}
+
+	// The peer starts serving a protocol after the handshake
+	remote.RegisterProtocol(lateProto, holdProtocol{})
+
+	added := waitUpdate()
+	assert.Contains(t, added.AddedProtocols, lateProto)
+	assert.NotContains(t, added.RemovedProtocols, lateProto)
+
+	// ... and stops serving it
+	remote.UnregisterProtocol(lateProto)
+
+	removed := waitUpdate()
+	assert.Contains(t, removed.RemovedProtocols, lateProto)
+	assert.NotContains(t, removed.AddedProtocols, lateProto)
+}
@@ -0,0 +1,109 @@
+package network
+
+import (
+	"github.com/hashicorp/golang-lru/simplelru"
+	"github.com/libp2p/go-libp2p/core/peer"
+)
+
+// boundedPeerRecords keeps a record per peer, bounded to a capacity, backed by an LRU cache.
+// Once full, the least recently added records are evicted first, except the records
+// of the pinned peers (e.g. the connected ones), which are kept even over capacity.
+// It isn't thread safe, so the owner has to guard it with its own lock
+type boundedPeerRecords struct {
+	records  *simplelru.LRU
+	size     int                // the current size of the cache, grown over capacity only by pinned records
+	capacity int                // the maximum number of records kept, exceeded only by pinned records
+	isPinned func(peer.ID) bool // reports if the record of the peer is never evicted
+}
+
+// newBoundedPeerRecords creates a new bounded store of peer records.
+// No record is pinned if isPinned is nil
+func newBoundedPeerRecords(capacity int, isPinned func(peer.ID) bool) *boundedPeerRecords {
+	if isPinned == nil {
+		isPinned = func(peer.ID) bool { return false }
+	}
+
+	// The size is positive, so the cache can't fail to be created
+	records, _ := simplelru.NewLRU(capacity, nil)
+
+	return &boundedPeerRecords{
+		records:  records,
+		size:     capacity,
+		capacity: capacity,
+		isPinned: isPinned,
+	}
+}
+
+// add saves the record of the peer as the most recent one, and returns the number
+// of records evicted to make room for it
+func (r *boundedPeerRecords) add(peerID peer.ID, record interface{}) int {
+	if r.records.Contains(peerID) {
+		r.records.Add(peerID, record)
+
+		return 0
+	}
+
+	evicted := r.evictOldest()
+
+	if r.records.Len() >= r.size {
+		// Only the pinned records are left, so the cache grows over capacity
+		r.size = r.records.Len() + 1
+		r.records.Resize(r.size)
+	}
+
+	r.records.Add(peerID, record)
+
+	return evicted
+}
+
+// evictOldest drops the least recently added records until there is room for a new one.
+// The pinned records are skipped, and become the most recent ones, so each is checked once
+func (r *boundedPeerRecords) evictOldest() int {
+	evicted, skipped := 0, 0
+
+	for r.records.Len() >= r.capacity && skipped < r.records.Len() {
+		key, _, _ := r.records.GetOldest()
+		peerID, _ := key.(peer.ID)
+
+		if r.isPinned(peerID) {
+			r.records.Get(peerID)
+
+			skipped++
+
+			continue
+		}
+
+		r.records.Remove(peerID)
+
+		evicted++
+	}
+
+	return evicted
+}
+
+// get returns the record of the peer, if any, without changing its recency
+func (r *boundedPeerRecords) get(peerID peer.ID) (interface{}, bool) {
+	return r.records.Peek(peerID)
+}
+
+// oldest returns the least recently added record, if any
+func (r *boundedPeerRecords) oldest() (peer.ID, interface{}, bool) {
+	key, record, ok := r.records.GetOldest()
+	if !ok {
+		return "", nil, false
+	}
+
+	peerID, _ := key.(peer.ID)
+
+	return peerID, record, true
+}
+
+// remove drops the record of the peer
+func (r *boundedPeerRecords) remove(peerID peer.ID) {
+	r.records.Remove(peerID)
+}
+
+// len returns the number of records kept
+func (r *boundedPeerRecords) len() int {
+	return r.records.Len()
+}
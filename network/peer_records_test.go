@@ -0,0 +1,61 @@
+package network
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBoundedPeerRecords(t *testing.T) {
+	const capacity = 4
+
+	pinned := map[peer.ID]bool{}
+	records := newBoundedPeerRecords(capacity, func(peerID peer.ID) bool {
+		return pinned[peerID]
+	})
+
+	peerID := func(i int) peer.ID {
+		return peer.ID(fmt.Sprintf("peer-%d", i))
+	}
+
+	for i := 0; i < capacity; i++ {
+		assert.Zero(t, records.add(peerID(i), i))
+	}
+
+	// Updating a record makes it the most recent one, without evicting any
+	assert.Zero(t, records.add(peerID(0), 10))
+
+	// The least recently added record is evicted first, skipping the pinned ones
+	pinned[peerID(1)] = true
+
+	assert.Equal(t, 1, records.add(peerID(4), 4))
+	assert.Equal(t, capacity, records.len())
+
+	_, ok := records.get(peerID(2))
+	assert.False(t, ok)
+
+	record, ok := records.get(peerID(0))
+	assert.True(t, ok)
+	assert.Equal(t, 10, record)
+
+	// The pinned record was skipped, so it became the most recent one
+	oldestID, _, ok := records.oldest()
+	assert.True(t, ok)
+	assert.Equal(t, peerID(3), oldestID)
+
+	// The pinned records are kept even over capacity
+	for i := 0; i < 5; i++ {
+		pinned[peerID(i)] = true
+	}
+
+	assert.Zero(t, records.add(peerID(5), 5))
+	assert.Equal(t, capacity+1, records.len())
+
+	records.remove(peerID(5))
+	assert.Equal(t, capacity, records.len())
+
+	_, ok = records.get(peerID(5))
+	assert.False(t, ok)
+}
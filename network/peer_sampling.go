@@ -0,0 +1,44 @@
+package network
+
+import (
+	"crypto/rand"
+	"math/big"
+
+	"github.com/libp2p/go-libp2p/core/peer"
+)
+
+// RandomPeers returns up to k connected peers, sampled uniformly at random
+// from a consistent snapshot of the peer set [Thread safe]
+func (s *Server) RandomPeers(k int) []peer.ID {
+	if k <= 0 {
+		return []peer.ID{}
+	}
+
+	s.peersLock.Lock()
+
+	peerIDs := make([]peer.ID, 0, len(s.peers))
+	for peerID := range s.peers {
+		peerIDs = append(peerIDs, peerID)
+	}
+
+	s.peersLock.Unlock()
+
+	return sampleRandom(peerIDs, k)
+}
+
+// sampleRandom returns a uniformly random sample of up to size peers, reordering the passed in slice
+func sampleRandom(peerIDs []peer.ID, size int) []peer.ID {
+	// Partial Fisher-Yates shuffle, only the sampled part is shuffled
+	for i := 0; i < len(peerIDs) && i < size; i++ {
+		randNum, _ := rand.Int(rand.Reader, big.NewInt(int64(len(peerIDs)-i)))
+		j := i + int(randNum.Int64())
+
+		peerIDs[i], peerIDs[j] = peerIDs[j], peerIDs[i]
+	}
+
+	if len(peerIDs) > size {
+		peerIDs = peerIDs[:size]
+	}
+
+	return peerIDs
+}
@@ -0,0 +1,76 @@
+package network
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/stretchr/testify/assert"
+)
+
+// newSamplingServer creates a server with the given number of (fake) connected peers
+func newSamplingServer(numPeers int) *Server {
+	server := &Server{peers: make(map[peer.ID]*PeerConnInfo, numPeers)}
+
+	for i := 0; i < numPeers; i++ {
+		peerID := peer.ID(fmt.Sprintf("peer-%d", i))
+		server.peers[peerID] = &PeerConnInfo{Info: peer.AddrInfo{ID: peerID}}
+	}
+
+	return server
+}
+
+func TestRandomPeers_SampleSize(t *testing.T) {
+	const numPeers = 5
+
+	server := newSamplingServer(numPeers)
+
+	for _, k := range []int{-1, 0, 1, 3, numPeers, 2 * numPeers} {
+		sample := server.RandomPeers(k)
+
+		expected := k
+		if expected < 0 {
+			expected = 0
+		} else if expected > numPeers {
+			expected = numPeers
+		}
+
+		assert.Len(t, sample, expected, "k=%d", k)
+
+		// The sampled peers are distinct and connected
+		seen := make(map[peer.ID]struct{}, len(sample))
+
+		for _, peerID := range sample {
+			assert.Contains(t, server.peers, peerID)
+			assert.NotContains(t, seen, peerID)
+
+			seen[peerID] = struct{}{}
+		}
+	}
+}
+
+func TestRandomPeers_Uniform(t *testing.T) {
+	const (
+		numPeers = 5
+		k        = 2
+		rounds   = 10000
+	)
+
+	server := newSamplingServer(numPeers)
+	counts := make(map[peer.ID]int, numPeers)
+
+	for i := 0; i < rounds; i++ {
+		for _, peerID := range server.RandomPeers(k) {
+			counts[peerID]++
+		}
+	}
+
+	// Every peer is expected to be sampled in k out of numPeers rounds
+	expected := float64(rounds*k) / numPeers
+
+	assert.Len(t, counts, numPeers)
+
+	for peerID, count := range counts {
+		assert.InEpsilon(t, expected, float64(count), 0.1, "peer %s", peerID)
+	}
+}
@@ -0,0 +1,182 @@
+package network
+
+import (
+	"math"
+
+	"github.com/armon/go-metrics"
+	"github.com/libp2p/go-libp2p/core/network"
+	"github.com/libp2p/go-libp2p/core/peer"
+)
+
+// PeerScorer scores the peers by their value to the node, the higher the better.
+// Once the inbound slots are exhausted, a connecting peer outscoring the lowest-scoring
+// connected inbound peer takes over its slot. The lowest-scoring peers are the first ones pruned or trimmed as well
+type PeerScorer interface {
+	// Score returns the current score of the peer, which may or may not be connected [Thread safe]
+	Score(peerID peer.ID) float64
+}
+
+const (
+	// connAgeScorePerMinute is the score of every minute the peer has been connected for
+	connAgeScorePerMinute = 1.0
+
+	// maxConnAgeScore is the maximum score of the connection age
+	maxConnAgeScore = 60.0
+
+	// acceptedMessageScore is the score of every accepted gossip message relayed by the peer
+	acceptedMessageScore = 0.1
+
+	// maxAcceptedMessagesScore is the maximum score of the accepted gossip messages
+	maxAcceptedMessagesScore = 50.0
+
+	// failedDialScore is the score of every recent failed dial to the peer
+	failedDialScore = -5.0
+
+	// sharedTopicScore is the score of every gossip topic the peer shares with the node
+	sharedTopicScore = 10.0
+)
+
+// defaultPeerScorer scores the peers by their connection age, the gossip messages
+// they relayed in the current session which were accepted, the gossip topics they share
+// with the node, and the recent failed dials to them
+type defaultPeerScorer struct {
+	server *Server
+}
+
+// NewDefaultPeerScorer returns the default peer scorer of the server,
+// e.g. to be combined with the custom scoring criteria
+func NewDefaultPeerScorer(server *Server) PeerScorer {
+	return &defaultPeerScorer{server: server}
+}
+
+// Score returns the current score of the peer [Thread safe]
+func (d *defaultPeerScorer) Score(peerID peer.ID) float64 {
+	age := d.server.peerUptime.connectedFor(peerID, d.server.clock.Now())
+	ageScore := math.Min(age.Minutes()*connAgeScorePerMinute, maxConnAgeScore)
+
+	// Only the current connection session counts, so a reconnecting peer starts over
+	accepted := d.server.gossipValidation.getSessionAccepted(peerID)
+	messagesScore := math.Min(float64(accepted)*acceptedMessageScore, maxAcceptedMessagesScore)
+
+	failedDials := 0
+
+	for _, entry := range d.server.peerHistory.get(peerID) {
+		if entry.Outcome == PeerHistoryDialFailed {
+			failedDials++
+		}
+	}
+
+	// Peers sharing the node's topics are more useful for message propagation
+	topicsScore := float64(sharedTopicCount(d.server.topicMembership, peerID)) * sharedTopicScore
+
+	return ageScore + messagesScore + topicsScore + float64(failedDials)*failedDialScore
+}
+
+// peerScorer returns the configured peer scorer, or the default one
+func (s *Server) peerScorer() PeerScorer {
+	if s.config.PeerScorer != nil {
+		return s.config.PeerScorer
+	}
+
+	return s.defaultScorer
+}
+
+// PeerScore returns the current score of the peer, as given by the peer scorer [Thread safe]
+func (s *Server) PeerScore(peerID peer.ID) float64 {
+	return s.peerScorer().Score(peerID)
+}
+
+// MakeRoomForPeer disconnects from the lowest-scoring, unprotected inbound peer,
+// if the connecting peer outscores it. Returns true if a slot was freed for the peer.
+// The inbound peers are added only once the evicted peer is gone, so they don't exceed the limit.
+// Only the inbound slots are contested, as the outbound peers are chosen by the node itself [Thread safe]
+func (s *Server) MakeRoomForPeer(peerID peer.ID, direction network.Direction) bool {
+	if direction != network.DirInbound {
+		return false
+	}
+
+	s.evictionLock.Lock()
+	defer s.evictionLock.Unlock()
+
+	scorer := s.peerScorer()
+	score := scorer.Score(peerID)
+
+	var (
+		evicted      peer.ID
+		evictedScore = math.Inf(1)
+	)
+
+	for _, candidate := range s.peersByDirection(direction) {
+		if _, ok := s.evicting[candidate]; ok {
+			continue
+		}
+
+		if candidate == peerID || s.IsProtected(candidate) || !s.IsConnected(candidate) {
+			continue
+		}
+
+		if candidateScore := scorer.Score(candidate); candidateScore < evictedScore {
+			evicted, evictedScore = candidate, candidateScore
+		}
+	}
+
+	if evicted == "" || evictedScore >= score {
+		return false
+	}
+
+	s.logger.Info(
+		"Evicting the lowest-scoring peer for a higher-scoring one",
+		"evicted", evicted,
+		"evicted_score", evictedScore,
+		"peer", peerID,
+		"score", score,
+	)
+
+	metrics.IncrCounter([]string{networkMetrics, "evicted_peers"}, 1)
+
+	// The eviction is requested from the connection notifiee, which must not block on the goodbye
+	s.evicting[evicted] = make(chan struct{})
+
+	go func() {
+		s.DisconnectFromPeer(evicted, "evicted by a higher-scoring peer")
+
+		// The peer may have disconnected on its own before the eviction,
+		// in which case it won't be removed again
+		if !s.hasPeer(evicted) {
+			s.completeEviction(evicted)
+		}
+	}()
+
+	return true
+}
+
+// completeEviction marks the eviction of the peer as completed, if the peer was evicted [Thread safe]
+func (s *Server) completeEviction(peerID peer.ID) {
+	s.evictionLock.Lock()
+	defer s.evictionLock.Unlock()
+
+	if done, ok := s.evicting[peerID]; ok {
+		delete(s.evicting, peerID)
+		close(done)
+	}
+}
+
+// awaitEvictions waits for the pending evictions to complete, or the server to close [Thread safe]
+func (s *Server) awaitEvictions() {
+	s.evictionLock.Lock()
+
+	pending := make([]chan struct{}, 0, len(s.evicting))
+	for _, done := range s.evicting {
+		pending = append(pending, done)
+	}
+
+	s.evictionLock.Unlock()
+
+	for _, done := range pending {
+		select {
+		case <-done:
+		case <-s.closeCh:
+			return
+		}
+	}
+}
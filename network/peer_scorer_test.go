@@ -0,0 +1,175 @@
+package network
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/0xPolygon/polygon-edge/network/common"
+	peerEvent "github.com/0xPolygon/polygon-edge/network/event"
+	"github.com/libp2p/go-libp2p/core/network"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// staticPeerScorer scores the peers by a fixed table, 0 if not listed
+type staticPeerScorer struct {
+	sync.Mutex
+
+	scores map[peer.ID]float64
+}
+
+func (s *staticPeerScorer) set(peerID peer.ID, score float64) {
+	s.Lock()
+	defer s.Unlock()
+
+	s.scores[peerID] = score
+}
+
+func (s *staticPeerScorer) Score(peerID peer.ID) float64 {
+	s.Lock()
+	defer s.Unlock()
+
+	return s.scores[peerID]
+}
+
+func TestDefaultPeerScorer(t *testing.T) {
+	clock := newFakeClock()
+
+	server, createErr := CreateServer(&CreateServerParams{ConfigCallback: func(c *Config) {
+		c.NoDiscover = true
+		c.Clock = clock
+	}})
+	require.NoError(t, createErr)
+
+	t.Cleanup(func() {
+		assert.NoError(t, server.Close())
+	})
+
+	peers, err := generateRandomPeers(t, 1)
+	require.NoError(t, err)
+
+	peerID := peers[0].peerID
+
+	// An unknown peer is neutral
+	assert.Zero(t, server.PeerScore(peerID))
+
+	// The connection age counts
+	server.peerUptime.connected(peerID, clock.Now())
+	clock.Advance(10 * time.Minute)
+
+	assert.InDelta(t, 10, server.PeerScore(peerID), 0.001)
+
+	// ... up to an hour
+	clock.Advance(2 * time.Hour)
+
+	assert.InDelta(t, maxConnAgeScore, server.PeerScore(peerID), 0.001)
+
+	// The accepted gossip messages count, the other ones don't
+	for i := 0; i < 20; i++ {
+		server.gossipValidation.record(peerID, GossipAccept)
+		server.gossipValidation.record(peerID, GossipIgnore)
+	}
+
+	assert.InDelta(t, maxConnAgeScore+2, server.PeerScore(peerID), 0.001)
+
+	// The failed dials count against the peer
+	server.peerHistory.record(peerID, PeerHistoryEntry{At: clock.Now(), Outcome: PeerHistoryDialFailed})
+
+	assert.InDelta(t, maxConnAgeScore+2+failedDialScore, server.PeerScore(peerID), 0.001)
+
+	// Once disconnected, neither the connection age nor the session messages count anymore
+	server.peerUptime.disconnected(peerID, clock.Now())
+	server.gossipValidation.endSession(peerID)
+
+	assert.InDelta(t, failedDialScore, server.PeerScore(peerID), 0.001)
+	assert.Equal(t, uint64(20), server.GossipValidationStats(peerID).Accepted)
+
+	// A reconnecting peer starts over
+	server.peerUptime.connected(peerID, clock.Now())
+	server.gossipValidation.record(peerID, GossipAccept)
+
+	assert.InDelta(t, acceptedMessageScore+failedDialScore, server.PeerScore(peerID), 0.001)
+
+	// The gossip topics shared with the node count
+	server.topicMembership = mockTopicMembership{
+		"txs":    {peerID},
+		"blocks": {peerID},
+		"votes":  {},
+	}
+
+	assert.InDelta(t, 2*sharedTopicScore+acceptedMessageScore+failedDialScore, server.PeerScore(peerID), 0.001)
+}
+
+func TestMakeRoomForPeer_EvictsLowestScoring(t *testing.T) {
+	scorer := &staticPeerScorer{scores: make(map[peer.ID]float64)}
+
+	servers, createErr := createServers(4, map[int]*CreateServerParams{
+		0: {ConfigCallback: func(c *Config) {
+			c.NoDiscover = true
+			c.MaxInboundPeers = 1
+			c.MaxOutboundPeers = 1
+			c.PeerScorer = scorer
+		}},
+		1: {ConfigCallback: func(c *Config) { c.NoDiscover = true }},
+		2: {ConfigCallback: func(c *Config) { c.NoDiscover = true }},
+		3: {ConfigCallback: func(c *Config) { c.NoDiscover = true }},
+	})
+	require.NoError(t, createErr)
+
+	t.Cleanup(func() {
+		closeTestServers(t, servers)
+	})
+
+	server, low, lower, high := servers[0], servers[1], servers[2], servers[3]
+
+	scorer.set(low.host.ID(), 1)
+	scorer.set(lower.host.ID(), 0)
+	scorer.set(high.host.ID(), 2)
+
+	// The low-scoring peer takes the only inbound slot
+	require.NoError(t, JoinAndWait(low, server, DefaultBufferTimeout, DefaultJoinTimeout))
+	require.False(t, server.HasFreeConnectionSlot(network.DirInbound))
+
+	// A peer scoring even lower is refused
+	smallTimeout := 5 * time.Second
+	require.Error(t, JoinAndWait(lower, server, smallTimeout, smallTimeout))
+	assert.True(t, server.hasPeer(low.host.ID()))
+
+	// The evicted peer is slow to take the goodbye, so its disconnect overlaps the handshake of the new peer
+	low.host.SetStreamHandler(common.GoodbyeProto, func(stream network.Stream) {
+		time.Sleep(time.Second)
+
+		_ = stream.Close()
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// The higher-scoring peer is added only after the evicted one is gone
+	inboundCh := make(chan int64, 1)
+
+	require.NoError(t, server.Subscribe(ctx, func(evnt *peerEvent.PeerEvent) {
+		if evnt.Type == peerEvent.PeerConnected && evnt.PeerID == high.host.ID() {
+			inboundCh <- server.connectionCounts.GetInboundConnCount()
+		}
+	}))
+
+	// A higher-scoring peer evicts the low-scoring one
+	require.NoError(t, JoinAndWait(high, server, DefaultBufferTimeout, DefaultJoinTimeout))
+
+	select {
+	case inbound := <-inboundCh:
+		assert.Equal(t, int64(1), inbound)
+	case <-time.After(5 * time.Second):
+		t.Fatal("higher-scoring peer not added")
+	}
+
+	require.Eventually(t, func() bool {
+		return !server.hasPeer(low.host.ID())
+	}, 5*time.Second, 50*time.Millisecond)
+
+	assert.Equal(t, []peer.ID{high.host.ID()}, server.peersByDirection(network.DirInbound))
+}
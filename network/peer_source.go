@@ -0,0 +1,143 @@
+package network
+
+import (
+	"sync"
+
+	"github.com/libp2p/go-libp2p/core/peer"
+)
+
+// maxPeerSources is the maximum number of peer source records kept
+const maxPeerSources = 4096
+
+// PeerSource defines how the node learned about a peer
+type PeerSource int
+
+const (
+	// PeerSourceUnknown is the source of the peers the node has no record of
+	PeerSourceUnknown PeerSource = iota
+
+	// PeerSourceBootnode is the source of the configured bootnodes
+	PeerSourceBootnode
+
+	// PeerSourceDiscovery is the source of the peers found by the discovery service
+	PeerSourceDiscovery
+
+	// PeerSourceInbound is the source of the peers that connected to the node first
+	PeerSourceInbound
+
+	// PeerSourceJoin is the source of the peers joined manually
+	PeerSourceJoin
+
+	// PeerSourcePeerstore is the source of the peers redialed from the peer store
+	PeerSourcePeerstore
+
+	// PeerSourcePex is the source of the peers shared by other peers in a peer exchange
+	PeerSourcePex
+)
+
+// String returns the string representation of the peer source
+func (s PeerSource) String() string {
+	switch s {
+	case PeerSourceBootnode:
+		return "bootnode"
+	case PeerSourceDiscovery:
+		return "discovery"
+	case PeerSourceInbound:
+		return "inbound"
+	case PeerSourceJoin:
+		return "join"
+	case PeerSourcePeerstore:
+		return "peerstore"
+	case PeerSourcePex:
+		return "pex"
+	default:
+		return "unknown"
+	}
+}
+
+// peerSourceTracker keeps how the node learned about the peers.
+// The number of records is bounded, so the least recently recorded peers
+// are forgotten first, unless they are still connected
+type peerSourceTracker struct {
+	sync.Mutex
+
+	records *boundedPeerRecords // peerID -> source, the records of the connected peers are never evicted
+}
+
+// newPeerSourceTracker creates a new peer source tracker
+func newPeerSourceTracker(isConnected func(peer.ID) bool) *peerSourceTracker {
+	return &peerSourceTracker{
+		records: newBoundedPeerRecords(maxPeerSources, isConnected),
+	}
+}
+
+// record saves the source of the peer, unless one is already recorded [Thread safe]
+func (t *peerSourceTracker) record(peerID peer.ID, source PeerSource) {
+	t.Lock()
+	defer t.Unlock()
+
+	if _, ok := t.records.get(peerID); ok {
+		return
+	}
+
+	t.records.add(peerID, source)
+}
+
+// get returns the source of the peer, unknown if none is recorded [Thread safe]
+func (t *peerSourceTracker) get(peerID peer.ID) PeerSource {
+	t.Lock()
+	defer t.Unlock()
+
+	record, ok := t.records.get(peerID)
+	if !ok {
+		return PeerSourceUnknown
+	}
+
+	source, _ := record.(PeerSource)
+
+	return source
+}
+
+// remove removes the source of the peer [Thread safe]
+func (t *peerSourceTracker) remove(peerID peer.ID) {
+	t.Lock()
+	defer t.Unlock()
+
+	t.records.remove(peerID)
+}
+
+// recordPeerSource saves how the node learned about the peer.
+// Only the first source is kept, since later ones only rediscover the peer [Thread safe]
+func (s *Server) recordPeerSource(peerID peer.ID, source PeerSource) {
+	if source == PeerSourceUnknown {
+		return
+	}
+
+	s.peerSources.record(peerID, source)
+}
+
+// getPeerSource returns how the node learned about the peer [Thread safe]
+func (s *Server) getPeerSource(peerID peer.ID) PeerSource {
+	return s.peerSources.get(peerID)
+}
+
+// discoveredPeerSource returns the source of a peer found by the discovery service,
+// which also covers the bootnodes, since they seed the routing table
+func (s *Server) discoveredPeerSource(peerID peer.ID) PeerSource {
+	if s.bootnodes.isBootnode(peerID) {
+		return PeerSourceBootnode
+	}
+
+	return PeerSourceDiscovery
+}
+
+// PeerSourceBreakdown returns the number of connected peers per source [Thread safe]
+func (s *Server) PeerSourceBreakdown() map[PeerSource]int {
+	breakdown := make(map[PeerSource]int)
+
+	for _, connInfo := range s.Peers() {
+		breakdown[connInfo.Source]++
+	}
+
+	return breakdown
+}
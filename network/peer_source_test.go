@@ -0,0 +1,88 @@
+package network
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/0xPolygon/polygon-edge/network/common"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPeerSource(t *testing.T) {
+	const numPeers = 5
+
+	params := make(map[int]*CreateServerParams)
+	for i := 0; i <= numPeers; i++ {
+		params[i] = &CreateServerParams{ConfigCallback: func(c *Config) {
+			c.NoDiscover = true
+		}}
+	}
+
+	servers, createErr := createServers(numPeers+1, params)
+	require.NoError(t, createErr)
+
+	t.Cleanup(func() {
+		closeTestServers(t, servers)
+	})
+
+	server := servers[0]
+	joined, inbound, bootnode, discovered, stored := servers[1], servers[2], servers[3], servers[4], servers[5]
+
+	// Mark the bootnode before any connection is made
+	server.bootnodes.bootnodeArr = append(server.bootnodes.bootnodeArr, bootnode.AddrInfo())
+	server.bootnodes.bootnodesMap[bootnode.host.ID()] = bootnode.AddrInfo()
+
+	require.NoError(t, JoinAndWait(server, joined, DefaultBufferTimeout, DefaultJoinTimeout))
+	require.NoError(t, JoinAndWait(inbound, server, DefaultBufferTimeout, DefaultJoinTimeout))
+
+	server.TemporaryDialPeer(bootnode.AddrInfo())
+	server.TemporaryDialPeer(discovered.AddrInfo())
+
+	server.AddToPeerStore(stored.AddrInfo())
+	server.addToDialQueue(server.GetPeerInfo(stored.host.ID()), common.PriorityRandomDial, PeerSourcePeerstore)
+
+	require.Eventually(t, func() bool {
+		return len(server.Peers()) == numPeers
+	}, DefaultJoinTimeout, 50*time.Millisecond)
+
+	expected := map[peer.ID]PeerSource{
+		joined.host.ID():     PeerSourceJoin,
+		inbound.host.ID():    PeerSourceInbound,
+		bootnode.host.ID():   PeerSourceBootnode,
+		discovered.host.ID(): PeerSourceDiscovery,
+		stored.host.ID():     PeerSourcePeerstore,
+	}
+
+	for _, connInfo := range server.Peers() {
+		assert.Equal(t, expected[connInfo.Info.ID], connInfo.Source, connInfo.Info.ID)
+	}
+
+	assert.Equal(t, map[PeerSource]int{
+		PeerSourceJoin:      1,
+		PeerSourceInbound:   1,
+		PeerSourceBootnode:  1,
+		PeerSourceDiscovery: 1,
+		PeerSourcePeerstore: 1,
+	}, server.PeerSourceBreakdown())
+}
+
+func TestPeerSourceTracker_Capacity(t *testing.T) {
+	connectedID := peer.ID("peer-0")
+
+	tracker := newPeerSourceTracker(func(peerID peer.ID) bool {
+		return peerID == connectedID
+	})
+
+	for i := 0; i < maxPeerSources+10; i++ {
+		tracker.record(peer.ID(fmt.Sprintf("peer-%d", i)), PeerSourceDiscovery)
+	}
+
+	// The least recently recorded sources are dropped, except for the connected peers
+	assert.Equal(t, maxPeerSources, tracker.records.len())
+	assert.Equal(t, PeerSourceDiscovery, tracker.get(connectedID))
+	assert.Equal(t, PeerSourceUnknown, tracker.get(peer.ID("peer-1")))
+	assert.Equal(t, PeerSourceDiscovery, tracker.get(peer.ID(fmt.Sprintf("peer-%d", maxPeerSources+9))))
+}
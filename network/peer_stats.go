@@ -0,0 +1,28 @@
+package network
+
+// PeerStats is a snapshot of the peer connection counts of the networking server
+type PeerStats struct {
+	Total           int64 // the number of connected peers
+	Inbound         int64 // the number of connected inbound peers
+	Outbound        int64 // the number of connected outbound peers
+	Bootnodes       int64 // the number of connected bootnodes
+	PendingInbound  int64 // the number of pending inbound connections
+	PendingOutbound int64 // the number of pending outbound connections
+}
+
+// PeerStats returns the peer connection counts, broken down by direction and bootnode status.
+// The connected counts are taken under the peer map lock, so they are consistent
+// with each other with respect to the peers being added and removed [Thread safe]
+func (s *Server) PeerStats() PeerStats {
+	s.peersLock.Lock()
+	defer s.peersLock.Unlock()
+
+	return PeerStats{
+		Total:           int64(len(s.peers)),
+		Inbound:         s.connectionCounts.GetInboundConnCount(),
+		Outbound:        s.connectionCounts.GetOutboundConnCount(),
+		Bootnodes:       s.bootnodes.getBootnodeConnCount(),
+		PendingInbound:  s.connectionCounts.GetPendingInboundConnCount(),
+		PendingOutbound: s.connectionCounts.GetPendingOutboundConnCount(),
+	}
+}
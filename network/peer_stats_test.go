@@ -0,0 +1,43 @@
+package network
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPeerStats(t *testing.T) {
+	servers, createErr := createServers(3, map[int]*CreateServerParams{
+		0: {ConfigCallback: func(c *Config) { c.NoDiscover = true }},
+		1: {ConfigCallback: func(c *Config) { c.NoDiscover = true }},
+		2: {ConfigCallback: func(c *Config) { c.NoDiscover = true }},
+	})
+	require.NoError(t, createErr)
+
+	t.Cleanup(func() {
+		closeTestServers(t, servers)
+	})
+
+	server := servers[0]
+
+	assert.Equal(t, PeerStats{}, server.PeerStats())
+
+	// server dials the first peer, and is dialed by the second one
+	require.NoError(t, JoinAndWait(server, servers[1], DefaultBufferTimeout, DefaultJoinTimeout))
+	require.NoError(t, JoinAndWait(servers[2], server, DefaultBufferTimeout, DefaultJoinTimeout))
+
+	connectCtx, connectFn := context.WithTimeout(context.Background(), DefaultJoinTimeout)
+	defer connectFn()
+
+	connected, connectErr := WaitUntilPeerConnectsTo(connectCtx, server, servers[2].AddrInfo().ID)
+	require.NoError(t, connectErr)
+	require.True(t, connected)
+
+	assert.Equal(t, PeerStats{
+		Total:    2,
+		Inbound:  1,
+		Outbound: 1,
+	}, server.PeerStats())
+}
@@ -0,0 +1,163 @@
+package network
+
+import (
+	"sync"
+	"time"
+
+	"github.com/0xPolygon/polygon-edge/network/common"
+	"github.com/armon/go-metrics"
+	"github.com/libp2p/go-libp2p/core/network"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/libp2p/go-libp2p/core/protocol"
+)
+
+// streamIdleExemptProtocols are the connection housekeeping protocols,
+// whose streams don't count as the peer contributing to the node
+var streamIdleExemptProtocols = map[protocol.ID]struct{}{
+	common.IdentityProto: {},
+	common.GoodbyeProto:  {},
+}
+
+// streamActivityTracker keeps track of the last protocol stream opened with each connected peer,
+// in either direction. Only the streams of the server protocols are accounted
+type streamActivityTracker struct {
+	sync.Mutex
+
+	lastStream map[peer.ID]time.Time // peerID -> time the last protocol stream was opened
+}
+
+// newStreamActivityTracker creates a new stream activity tracker
+func newStreamActivityTracker() *streamActivityTracker {
+	return &streamActivityTracker{
+		lastStream: make(map[peer.ID]time.Time),
+	}
+}
+
+// record marks the protocol stream as opened with the peer.
+// The streams of the housekeeping protocols are ignored [Thread safe]
+func (t *streamActivityTracker) record(peerID peer.ID, proto protocol.ID, now time.Time) {
+	if _, ok := streamIdleExemptProtocols[proto]; ok || proto == "" {
+		return
+	}
+
+	t.touch(peerID, now)
+}
+
+// touch marks the peer as having protocol stream activity [Thread safe]
+func (t *streamActivityTracker) touch(peerID peer.ID, now time.Time) {
+	t.Lock()
+	defer t.Unlock()
+
+	t.lastStream[peerID] = now
+}
+
+// idleFor returns the time elapsed since the last protocol stream with the peer,
+// or the connection time if no protocol stream was opened yet [Thread safe]
+func (t *streamActivityTracker) idleFor(peerID peer.ID, connectedFor time.Duration, now time.Time) time.Duration {
+	t.Lock()
+	defer t.Unlock()
+
+	lastStream, ok := t.lastStream[peerID]
+	if !ok {
+		return connectedFor
+	}
+
+	return now.Sub(lastStream)
+}
+
+// remove removes the stream activity record of the peer [Thread safe]
+func (t *streamActivityTracker) remove(peerID peer.ID) {
+	t.Lock()
+	defer t.Unlock()
+
+	delete(t.lastStream, peerID)
+}
+
+// hasProtocolStreams checks if any of the protocol streams open on the connection
+// is not a housekeeping one, e.g. a long-lived stream still in use [Thread safe]
+func (t *openStreamTracker) hasProtocolStreams(conn network.Conn) bool {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	for stream := range t.streams[conn] {
+		if _, ok := streamIdleExemptProtocols[stream.Protocol()]; !ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// gossipTopicPeers returns the peers subscribed to any of the topics joined by the node.
+// Their gossip streams are opened by pubsub directly, so they bypass the stream accounting
+func (s *Server) gossipTopicPeers() map[peer.ID]struct{} {
+	peers := make(map[peer.ID]struct{})
+
+	if s.ps == nil {
+		return peers
+	}
+
+	for _, topic := range s.SubscribedTopics() {
+		for _, peerID := range s.ps.ListPeers(topic) {
+			peers[peerID] = struct{}{}
+		}
+	}
+
+	return peers
+}
+
+// runStreamIdleChecks periodically looks for the peers that neither opened nor accepted
+// any protocol stream within the stream idle timeout, and disconnects from them,
+// so the connection slots are not occupied by peers not contributing to the node.
+// The peers gossiping on the topics of the node count as active.
+// The protected (e.g. pinned) peers and the bootnodes are exempt
+func (s *Server) runStreamIdleChecks() {
+	checkInterval := s.config.PeerStreamIdleTimeout / 2
+
+	for {
+		select {
+		case <-s.clock.After(checkInterval):
+		case <-s.closeCh:
+			return
+		}
+
+		now := s.clock.Now()
+		gossipPeers := s.gossipTopicPeers()
+
+		for _, connInfo := range s.Peers() {
+			peerID := connInfo.Info.ID
+
+			if connInfo.IsBootnode || s.IsProtected(peerID) {
+				continue
+			}
+
+			// The shared gossip topics are ongoing activity
+			if _, ok := gossipPeers[peerID]; ok {
+				s.streamActivity.touch(peerID, now)
+			}
+
+			// The streams still open are ongoing activity
+			for _, conn := range s.host.Network().ConnsToPeer(peerID) {
+				if s.openStreams.hasProtocolStreams(conn) {
+					s.streamActivity.touch(peerID, now)
+				}
+			}
+
+			connectedFor := s.peerUptime.connectedFor(peerID, now)
+			if connectedFor == 0 {
+				continue
+			}
+
+			idleTime := s.streamActivity.idleFor(peerID, connectedFor, now)
+			if idleTime < s.config.PeerStreamIdleTimeout {
+				continue
+			}
+
+			s.logger.Debug("Peer opened no protocol streams", "peer", peerID, "idle", idleTime)
+
+			metrics.IncrCounter([]string{networkMetrics, "stream_idle_disconnects"}, 1)
+
+			s.DisconnectFromPeer(peerID, "no protocol streams")
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package network
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	testproto "github.com/0xPolygon/polygon-edge/network/proto"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPeerStreamIdleTimeout(t *testing.T) {
+	const (
+		idleTimeout = 2 * time.Second
+		holdProto   = "/hold/0.1"
+		topicName   = "stream-idle"
+	)
+
+	servers, createErr := createServers(5, map[int]*CreateServerParams{
+		0: {ConfigCallback: func(c *Config) {
+			c.NoDiscover = true
+			c.PeerStreamIdleTimeout = idleTimeout
+		}},
+		1: {ConfigCallback: func(c *Config) { c.NoDiscover = true }},
+		2: {ConfigCallback: func(c *Config) { c.NoDiscover = true }},
+		3: {ConfigCallback: func(c *Config) { c.NoDiscover = true }},
+		4: {ConfigCallback: func(c *Config) { c.NoDiscover = true }},
+	})
+	require.NoError(t, createErr)
+
+	t.Cleanup(func() {
+		closeTestServers(t, servers)
+	})
+
+	server, freeloader, active, protected, gossiper := servers[0], servers[1], servers[2], servers[3], servers[4]
+
+	server.RegisterProtocol(holdProto, holdProtocol{})
+
+	// The gossip peer only shares a topic with the server, over the pubsub stream
+	for _, srv := range []*Server{server, gossiper} {
+		topic, topicErr := srv.NewTopic(topicName, &testproto.GenericMessage{})
+		require.NoError(t, topicErr)
+
+		require.NoError(t, topic.Subscribe(func(_ interface{}, _ peer.ID) {}))
+	}
+
+	// Each peer starts its activity right after joining, before the timeout passes
+	require.NoError(t, JoinAndWait(gossiper, server, DefaultBufferTimeout, DefaultJoinTimeout))
+
+	// The active peer keeps a protocol stream open
+	require.NoError(t, JoinAndWait(active, server, DefaultBufferTimeout, DefaultJoinTimeout))
+
+	stream, streamErr := active.NewStream(holdProto, server.host.ID())
+	require.NoError(t, streamErr)
+
+	// The stream is negotiated lazily, on the first write
+	_, writeErr := stream.Write([]byte{0})
+	require.NoError(t, writeErr)
+
+	require.NoError(t, JoinAndWait(protected, server, DefaultBufferTimeout, DefaultJoinTimeout))
+	server.ProtectPeer(protected.host.ID())
+
+	require.NoError(t, JoinAndWait(freeloader, server, DefaultBufferTimeout, DefaultJoinTimeout))
+
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultJoinTimeout)
+	defer cancel()
+
+	require.NoError(t, WaitForSubscribers(ctx, server, topicName, 1))
+
+	// The peer that opens no streams is dropped after the timeout
+	require.Eventually(t, func() bool {
+		return !server.hasPeer(freeloader.host.ID())
+	}, 5*idleTimeout, 100*time.Millisecond)
+
+	// The active, the protected and the gossip peers survive multiple timeouts
+	time.Sleep(2 * idleTimeout)
+
+	assert.True(t, server.hasPeer(active.host.ID()))
+	assert.True(t, server.hasPeer(protected.host.ID()))
+	assert.True(t, server.hasPeer(gossiper.host.ID()))
+}
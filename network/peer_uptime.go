@@ -0,0 +1,152 @@
+package network
+
+import (
+	"sync"
+	"time"
+
+	"github.com/libp2p/go-libp2p/core/peer"
+)
+
+// maxUptimeIntervals is the maximum number of connection intervals kept per peer
+const maxUptimeIntervals = 64
+
+// maxUptimePeers is the maximum number of peers whose connection intervals are kept
+const maxUptimePeers = 4096
+
+// uptimeInterval is a period in which the peer was connected
+type uptimeInterval struct {
+	start time.Time // the time the peer connected
+	end   time.Time // the time the peer disconnected, zero if it is still connected
+}
+
+// peerUptimeTracker keeps the recent connection intervals of the peers,
+// bounded to the latest intervals per peer, and to the most recently seen peers
+type peerUptimeTracker struct {
+	sync.Mutex
+
+	intervals *boundedPeerRecords // peerID -> []uptimeInterval, the connected peers are never evicted
+}
+
+// newPeerUptimeTracker creates a new peer uptime tracker
+func newPeerUptimeTracker() *peerUptimeTracker {
+	t := &peerUptimeTracker{}
+	t.intervals = newBoundedPeerRecords(maxUptimePeers, t.isConnected)
+
+	return t
+}
+
+// get returns the connection intervals of the peer. The lock has to be held
+func (t *peerUptimeTracker) get(peerID peer.ID) []uptimeInterval {
+	record, ok := t.intervals.get(peerID)
+	if !ok {
+		return nil
+	}
+
+	intervals, _ := record.([]uptimeInterval)
+
+	return intervals
+}
+
+// isConnected checks if the latest connection interval of the peer is still open.
+// The lock has to be held
+func (t *peerUptimeTracker) isConnected(peerID peer.ID) bool {
+	intervals := t.get(peerID)
+
+	return len(intervals) > 0 && intervals[len(intervals)-1].end.IsZero()
+}
+
+// connected opens a connection interval of the peer, dropping the oldest intervals over the cap.
+// No-op if the peer is already connected [Thread safe]
+func (t *peerUptimeTracker) connected(peerID peer.ID, now time.Time) {
+	t.Lock()
+	defer t.Unlock()
+
+	if t.isConnected(peerID) {
+		return
+	}
+
+	intervals := t.get(peerID)
+
+	intervals = append(intervals, uptimeInterval{start: now})
+	if len(intervals) > maxUptimeIntervals {
+		intervals = append([]uptimeInterval(nil), intervals[len(intervals)-maxUptimeIntervals:]...)
+	}
+
+	t.intervals.add(peerID, intervals)
+}
+
+// disconnected closes the open connection interval of the peer, if any [Thread safe]
+func (t *peerUptimeTracker) disconnected(peerID peer.ID, now time.Time) {
+	t.Lock()
+	defer t.Unlock()
+
+	if !t.isConnected(peerID) {
+		return
+	}
+
+	intervals := t.get(peerID)
+	intervals[len(intervals)-1].end = now
+
+	// The peer becomes the most recently disconnected one
+	t.intervals.add(peerID, intervals)
+}
+
+// remove drops the connection intervals of the peer [Thread safe]
+func (t *peerUptimeTracker) remove(peerID peer.ID) {
+	t.Lock()
+	defer t.Unlock()
+
+	t.intervals.remove(peerID)
+}
+
+// connectedFor returns the time the peer has been connected for, 0 if it is not connected [Thread safe]
+func (t *peerUptimeTracker) connectedFor(peerID peer.ID, now time.Time) time.Duration {
+	t.Lock()
+	defer t.Unlock()
+
+	if !t.isConnected(peerID) {
+		return 0
+	}
+
+	intervals := t.get(peerID)
+
+	return now.Sub(intervals[len(intervals)-1].start)
+}
+
+// uptime returns the fraction of the window, ending now, in which the peer was connected [Thread safe]
+func (t *peerUptimeTracker) uptime(peerID peer.ID, window time.Duration, now time.Time) float64 {
+	if window <= 0 {
+		return 0
+	}
+
+	t.Lock()
+	defer t.Unlock()
+
+	windowStart := now.Add(-window)
+
+	var connected time.Duration
+
+	for _, interval := range t.get(peerID) {
+		start, end := interval.start, interval.end
+		if end.IsZero() || end.After(now) {
+			end = now
+		}
+
+		if start.Before(windowStart) {
+			start = windowStart
+		}
+
+		if end.After(start) {
+			connected += end.Sub(start)
+		}
+	}
+
+	return float64(connected) / float64(window)
+}
+
+// PeerUptime returns the fraction (0 to 1) of the latest time window in which the peer was connected.
+// Only the recent connection intervals are kept, so the uptime of a peer reconnecting often
+// is underestimated over long windows [Thread safe]
+func (s *Server) PeerUptime(peerID peer.ID, window time.Duration) float64 {
+	return s.peerUptime.uptime(peerID, window, s.clock.Now())
+}
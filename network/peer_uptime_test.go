@@ -0,0 +1,138 @@
+package network
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPeerUptimeTracker(t *testing.T) {
+	var (
+		tracker = newPeerUptimeTracker()
+		peerID  = peer.ID("peer")
+		start   = time.Now()
+	)
+
+	at := func(minutes int) time.Time {
+		return start.Add(time.Duration(minutes) * time.Minute)
+	}
+
+	// Connected for 10 minutes, then for 20, then again since the 50th minute
+	tracker.connected(peerID, at(0))
+	tracker.disconnected(peerID, at(10))
+	tracker.connected(peerID, at(20))
+	tracker.disconnected(peerID, at(40))
+	tracker.connected(peerID, at(50))
+
+	// A repeated connect doesn't restart the interval
+	tracker.connected(peerID, at(55))
+
+	testTable := []struct {
+		name     string
+		window   time.Duration
+		now      time.Time
+		expected float64
+	}{
+		{"whole history", time.Hour, at(60), 40.0 / 60},
+		{"window cutting an interval", 30 * time.Minute, at(60), 20.0 / 30},
+		{"window within the open interval", 5 * time.Minute, at(60), 1},
+		{"window before the history", time.Hour, at(0), 0},
+		{"window over the history start", 2 * time.Hour, at(60), 40.0 / 120},
+		{"empty window", 0, at(60), 0},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			assert.InDelta(t, testCase.expected, tracker.uptime(peerID, testCase.window, testCase.now), 1e-9)
+		})
+	}
+
+	assert.Zero(t, tracker.uptime(peer.ID("unknown"), time.Hour, at(60)))
+}
+
+func TestPeerUptimeTracker_Cap(t *testing.T) {
+	var (
+		tracker = newPeerUptimeTracker()
+		peerID  = peer.ID("peer")
+		now     = time.Now()
+	)
+
+	for i := 0; i < 2*maxUptimeIntervals; i++ {
+		tracker.connected(peerID, now)
+		now = now.Add(time.Minute)
+		tracker.disconnected(peerID, now)
+		now = now.Add(time.Minute)
+	}
+
+	assert.Len(t, tracker.get(peerID), maxUptimeIntervals)
+
+	// Only the kept intervals count towards the uptime
+	window := time.Duration(4*maxUptimeIntervals) * time.Minute
+	assert.InDelta(t, float64(maxUptimeIntervals)/float64(4*maxUptimeIntervals), tracker.uptime(peerID, window, now), 1e-9)
+}
+
+func TestPeerUptimeTracker_PeerCapacity(t *testing.T) {
+	var (
+		tracker     = newPeerUptimeTracker()
+		connectedID = peer.ID("peer-0")
+		start       = time.Now()
+	)
+
+	tracker.connected(connectedID, start)
+
+	for i := 1; i < maxUptimePeers+10; i++ {
+		peerID := peer.ID(fmt.Sprintf("peer-%d", i))
+		at := start.Add(time.Duration(i) * time.Minute)
+
+		tracker.connected(peerID, at)
+		tracker.disconnected(peerID, at.Add(time.Second))
+	}
+
+	// The peers which disconnected the longest ago are dropped, except for the connected peers
+	assert.Equal(t, maxUptimePeers, tracker.intervals.len())
+	assert.NotEmpty(t, tracker.get(connectedID))
+	assert.Empty(t, tracker.get(peer.ID("peer-1")))
+	assert.NotEmpty(t, tracker.get(peer.ID(fmt.Sprintf("peer-%d", maxUptimePeers+9))))
+
+	tracker.remove(connectedID)
+	assert.Empty(t, tracker.get(connectedID))
+}
+
+func TestPeerUptime(t *testing.T) {
+	clock := newFakeClock()
+
+	servers, createErr := createServers(2, map[int]*CreateServerParams{
+		0: {ConfigCallback: func(c *Config) {
+			c.NoDiscover = true
+			c.Clock = clock
+		}},
+		1: {ConfigCallback: func(c *Config) { c.NoDiscover = true }},
+	})
+	require.NoError(t, createErr)
+
+	t.Cleanup(func() {
+		closeTestServers(t, servers)
+	})
+
+	server, remote := servers[0], servers[1]
+	remoteID := remote.host.ID()
+
+	require.NoError(t, JoinAndWait(server, remote, DefaultBufferTimeout, DefaultJoinTimeout))
+
+	clock.Advance(30 * time.Minute)
+
+	server.DisconnectFromPeer(remoteID, "test")
+
+	require.Eventually(t, func() bool {
+		return !server.hasPeer(remoteID)
+	}, 5*time.Second, 50*time.Millisecond)
+
+	clock.Advance(30 * time.Minute)
+
+	assert.InDelta(t, 0.5, server.PeerUptime(remoteID, time.Hour), 1e-9)
+	assert.Zero(t, server.PeerUptime(remoteID, 30*time.Minute))
+}
@@ -0,0 +1,102 @@
+package network
+
+import (
+	"sort"
+
+	"github.com/libp2p/go-libp2p/core/peer"
+)
+
+// topicMembership exposes the gossip topic membership of peers,
+// and is implemented by the pubsub service
+type topicMembership interface {
+	// GetTopics returns the topics the node is subscribed to
+	GetTopics() []string
+
+	// ListPeers returns the peers subscribed to the topic
+	ListPeers(topic string) []peer.ID
+}
+
+// sharedTopicCount returns the number of topics the peer shares with the node
+func sharedTopicCount(membership topicMembership, peerID peer.ID) int {
+	if membership == nil {
+		return 0
+	}
+
+	count := 0
+
+	for _, topic := range membership.GetTopics() {
+		for _, subscriber := range membership.ListPeers(topic) {
+			if subscriber == peerID {
+				count++
+
+				break
+			}
+		}
+	}
+
+	return count
+}
+
+// rankPeersByValue sorts the peers from the most to the least valuable one, as scored by the peer scorer,
+// so the pruned, trimmed and evicted peers are valued the same way
+func (s *Server) rankPeersByValue(peers []peer.ID) []peer.ID {
+	scorer := s.peerScorer()
+
+	scores := make(map[peer.ID]float64, len(peers))
+	for _, peerID := range peers {
+		scores[peerID] = scorer.Score(peerID)
+	}
+
+	ranked := make([]peer.ID, len(peers))
+	copy(ranked, peers)
+
+	sort.SliceStable(ranked, func(i, j int) bool {
+		return scores[ranked[i]] > scores[ranked[j]]
+	})
+
+	return ranked
+}
+
+// ProtectPeer exempts the peer from being pruned or trimmed as a low-value peer [Thread safe]
+func (s *Server) ProtectPeer(peerID peer.ID) {
+	s.protectedPeers.Store(peerID, struct{}{})
+}
+
+// UnprotectPeer reverts the peer protection [Thread safe]
+func (s *Server) UnprotectPeer(peerID peer.ID) {
+	s.protectedPeers.Delete(peerID)
+}
+
+// IsProtected checks if the peer is exempt from being pruned or trimmed [Thread safe]
+func (s *Server) IsProtected(peerID peer.ID) bool {
+	_, ok := s.protectedPeers.Load(peerID)
+
+	return ok
+}
+
+// PruneLowValuePeers disconnects from the specified number of the least valuable, unprotected peers,
+// in order to free up connection slots. Returns the IDs of the pruned peers
+func (s *Server) PruneLowValuePeers(count int) []peer.ID {
+	if count <= 0 {
+		return nil
+	}
+
+	peers := make([]peer.ID, 0)
+	for _, connInfo := range s.Peers() {
+		if !s.IsProtected(connInfo.Info.ID) {
+			peers = append(peers, connInfo.Info.ID)
+		}
+	}
+
+	ranked := s.rankPeersByValue(peers)
+	if count > len(ranked) {
+		count = len(ranked)
+	}
+
+	pruned := ranked[len(ranked)-count:]
+	for _, peerID := range pruned {
+		s.DisconnectFromPeer(peerID, "low peer value")
+	}
+
+	return pruned
+}
@@ -0,0 +1,119 @@
+package network
+
+import (
+	"testing"
+	"time"
+
+	"github.com/libp2p/go-libp2p/core/network"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mockTopicMembership is a static topic -> peers membership
+type mockTopicMembership map[string][]peer.ID
+
+func (m mockTopicMembership) GetTopics() []string {
+	topics := make([]string, 0, len(m))
+	for topic := range m {
+		topics = append(topics, topic)
+	}
+
+	return topics
+}
+
+func (m mockTopicMembership) ListPeers(topic string) []peer.ID {
+	return m[topic]
+}
+
+func TestRankPeersByValue(t *testing.T) {
+	scorer := &staticPeerScorer{scores: map[peer.ID]float64{"b": 1, "c": 3, "d": 1}}
+
+	server, createErr := CreateServer(&CreateServerParams{ConfigCallback: func(c *Config) {
+		c.NoDiscover = true
+		c.PeerScorer = scorer
+	}})
+	require.NoError(t, createErr)
+
+	t.Cleanup(func() {
+		assert.NoError(t, server.Close())
+	})
+
+	peers := []peer.ID{"a", "b", "c", "d"}
+
+	// The peers are ranked by the configured scorer, same as the evicted ones
+	assert.Equal(t, []peer.ID{"c", "b", "d", "a"}, server.rankPeersByValue(peers))
+
+	scorer.set("a", 5)
+
+	assert.Equal(t, []peer.ID{"a", "c", "b", "d"}, server.rankPeersByValue(peers))
+}
+
+func TestPruneLowValuePeers(t *testing.T) {
+	server, createErr := CreateServer(&CreateServerParams{ConfigCallback: func(c *Config) {
+		c.NoDiscover = true
+	}})
+	require.NoError(t, createErr)
+
+	t.Cleanup(func() {
+		assert.NoError(t, server.Close())
+	})
+
+	randomPeers, err := generateRandomPeers(t, 4)
+	require.NoError(t, err)
+
+	for _, randomPeer := range randomPeers {
+		server.AddPeer(randomPeer.peerID, randomPeer.direction)
+	}
+
+	// Peer 0 shares all topics, peer 1 shares two,
+	// peer 2 shares one, and peer 3 doesn't share any
+	server.topicMembership = mockTopicMembership{
+		"txs":    {randomPeers[0].peerID, randomPeers[1].peerID, randomPeers[2].peerID},
+		"blocks": {randomPeers[0].peerID, randomPeers[1].peerID},
+		"votes":  {randomPeers[0].peerID},
+	}
+
+	pruned := server.PruneLowValuePeers(2)
+
+	assert.ElementsMatch(t, []peer.ID{randomPeers[2].peerID, randomPeers[3].peerID}, pruned)
+	assert.Empty(t, server.PruneLowValuePeers(0))
+	assert.Len(t, server.PruneLowValuePeers(10), 4)
+}
+
+func TestMakeRoomForPeer_PrefersSharedTopics(t *testing.T) {
+	servers, createErr := createServers(3, map[int]*CreateServerParams{
+		0: {ConfigCallback: func(c *Config) {
+			c.NoDiscover = true
+			c.MaxInboundPeers = 1
+			c.MaxOutboundPeers = 1
+		}},
+		1: {ConfigCallback: func(c *Config) { c.NoDiscover = true }},
+		2: {ConfigCallback: func(c *Config) { c.NoDiscover = true }},
+	})
+	require.NoError(t, createErr)
+
+	t.Cleanup(func() {
+		closeTestServers(t, servers)
+	})
+
+	server, unrelated, subscriber := servers[0], servers[1], servers[2]
+
+	// Only the subscriber shares a gossip topic with the node
+	server.topicMembership = mockTopicMembership{
+		"txs": {subscriber.host.ID()},
+	}
+
+	// The peer sharing no topics takes the only inbound slot
+	require.NoError(t, JoinAndWait(unrelated, server, DefaultBufferTimeout, DefaultJoinTimeout))
+	require.False(t, server.HasFreeConnectionSlot(network.DirInbound))
+
+	// The peer sharing a topic outscores it, and takes over its slot
+	require.NoError(t, JoinAndWait(subscriber, server, DefaultBufferTimeout, DefaultJoinTimeout))
+
+	require.Eventually(t, func() bool {
+		return !server.hasPeer(unrelated.host.ID())
+	}, 5*time.Second, 50*time.Millisecond)
+
+	assert.Equal(t, []peer.ID{subscriber.host.ID()}, server.peersByDirection(network.DirInbound))
+}
@@ -0,0 +1,152 @@
+package network
+
+import (
+	"sync"
+
+	"github.com/armon/go-metrics"
+	"github.com/libp2p/go-libp2p/core/event"
+	"github.com/libp2p/go-libp2p/core/peer"
+)
+
+const (
+	// unknownAgentVersion is reported for the peers which didn't advertise their software version
+	unknownAgentVersion = "unknown"
+
+	// minVersionDiversityPeers is the minimum number of peers with a known version
+	// for which the version diversity is checked
+	minVersionDiversityPeers = 3
+)
+
+// versionDiversityState is the state of the peer software version diversity check
+type versionDiversityState struct {
+	sync.Mutex
+
+	dominated bool // flag indicating if a single version currently exceeds the dominance threshold
+}
+
+// peerAgentVersion returns the software (agent) version the peer advertised over libp2p identify
+func (s *Server) peerAgentVersion(peerID peer.ID) string {
+	value, err := s.host.Peerstore().Get(peerID, "AgentVersion")
+	if err != nil {
+		return unknownAgentVersion
+	}
+
+	version, _ := value.(string)
+	if version == "" {
+		return unknownAgentVersion
+	}
+
+	return version
+}
+
+// PeerVersionDistribution returns the number of connected peers per software (agent) version.
+// The peers which didn't advertise their version yet are counted as unknown [Thread safe]
+func (s *Server) PeerVersionDistribution() map[string]int {
+	distribution := make(map[string]int)
+
+	for _, peerInfo := range s.Peers() {
+		distribution[s.peerAgentVersion(peerInfo.Info.ID)]++
+	}
+
+	return distribution
+}
+
+// dominantVersion returns the most common known version in the distribution,
+// its share among the known versions, and the number of peers with a known version
+func dominantVersion(distribution map[string]int) (string, float64, int) {
+	var (
+		dominant string
+		top      int
+		known    int
+	)
+
+	for version, count := range distribution {
+		if version == unknownAgentVersion {
+			continue
+		}
+
+		known += count
+
+		// Ties are broken by the version name, so the result is deterministic
+		if count > top || (count == top && version < dominant) {
+			dominant, top = version, count
+		}
+	}
+
+	if known == 0 {
+		return "", 0, 0
+	}
+
+	return dominant, float64(top) / float64(known), known
+}
+
+// checkVersionDiversity logs a warning once the share of the most common peer software
+// version exceeds the configured threshold, and returns true if it currently does
+func (s *Server) checkVersionDiversity() bool {
+	threshold := s.config.VersionDominanceThreshold
+	if threshold <= 0 {
+		return false
+	}
+
+	version, share, known := dominantVersion(s.PeerVersionDistribution())
+	dominated := known >= minVersionDiversityPeers && share > threshold
+
+	metrics.SetGauge([]string{networkMetrics, "peer_version_dominant_share"}, float32(share))
+
+	s.versionDiversity.Lock()
+	wasDominated := s.versionDiversity.dominated
+	s.versionDiversity.dominated = dominated
+	s.versionDiversity.Unlock()
+
+	// Only the transitions are logged, to avoid flooding the log on every peer change
+	switch {
+	case dominated && !wasDominated:
+		s.logger.Warn(
+			"Most peers run the same software version, a bug in it threatens the network",
+			"version", version,
+			"share", share,
+			"peers", known,
+		)
+	case !dominated && wasDominated:
+		s.logger.Info("Peer software versions are diverse again", "share", share, "peers", known)
+	}
+
+	return dominated
+}
+
+// watchPeerVersions checks the version diversity whenever a peer advertises its version,
+// which can happen after the peer is already connected
+func (s *Server) watchPeerVersions() error {
+	if s.config.VersionDominanceThreshold <= 0 {
+		return nil
+	}
+
+	sub, err := s.host.EventBus().Subscribe(new(event.EvtPeerIdentificationCompleted))
+	if err != nil {
+		return err
+	}
+
+	done := s.health.subscriptions.start()
+
+	go func() {
+		defer done()
+		defer sub.Close()
+
+		for {
+			select {
+			case <-s.closeCh:
+				return
+			case evnt, ok := <-sub.Out():
+				if !ok {
+					return
+				}
+
+				if completed, ok := evnt.(event.EvtPeerIdentificationCompleted); ok && s.hasPeer(completed.Peer) {
+					s.checkVersionDiversity()
+				}
+			}
+		}
+	}()
+
+	return nil
+}
@@ -0,0 +1,116 @@
+package network
+
+import (
+	"bytes"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// lockedBuffer is a buffer safe for the concurrent log writes
+type lockedBuffer struct {
+	lock sync.Mutex
+	buf  bytes.Buffer
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	return b.buf.Write(p)
+}
+
+func (b *lockedBuffer) String() string {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	return b.buf.String()
+}
+
+func TestDominantVersion(t *testing.T) {
+	version, share, known := dominantVersion(map[string]int{
+		"edge/1.0":          3,
+		"edge/2.0":          1,
+		unknownAgentVersion: 4,
+	})
+
+	// The unknown versions are not accounted
+	assert.Equal(t, "edge/1.0", version)
+	assert.Equal(t, 0.75, share)
+	assert.Equal(t, 4, known)
+
+	version, share, known = dominantVersion(map[string]int{unknownAgentVersion: 2})
+
+	assert.Empty(t, version)
+	assert.Zero(t, share)
+	assert.Zero(t, known)
+}
+
+func TestPeerVersionDistribution(t *testing.T) {
+	logs := &lockedBuffer{}
+
+	versions := []string{"", "edge/1.0", "edge/1.0", "edge/2.0"}
+	params := make(map[int]*CreateServerParams, len(versions))
+
+	for i, version := range versions {
+		version := version
+
+		params[i] = &CreateServerParams{ConfigCallback: func(c *Config) {
+			c.NoDiscover = true
+			c.AgentVersion = version
+		}}
+	}
+
+	params[0].Logger = hclog.New(&hclog.LoggerOptions{Output: logs, Level: hclog.Info})
+	params[0].ConfigCallback = func(c *Config) {
+		c.NoDiscover = true
+		c.VersionDominanceThreshold = 0.6
+	}
+
+	servers, createErr := createServers(len(versions), params)
+	require.NoError(t, createErr)
+
+	t.Cleanup(func() {
+		closeTestServers(t, servers)
+	})
+
+	server := servers[0]
+
+	for _, peerServer := range servers[1:] {
+		require.NoError(t, JoinAndWait(server, peerServer, DefaultBufferTimeout, DefaultJoinTimeout))
+	}
+
+	// The versions are learned once the peers are identified
+	expected := map[string]int{"edge/1.0": 2, "edge/2.0": 1}
+
+	require.Eventually(t, func() bool {
+		return assert.ObjectsAreEqual(expected, server.PeerVersionDistribution())
+	}, 5*time.Second, 50*time.Millisecond)
+
+	// Two thirds of the peers run the same version, which is above the threshold
+	assert.True(t, server.checkVersionDiversity())
+
+	warning := "Most peers run the same software version"
+
+	require.Eventually(t, func() bool {
+		return strings.Contains(logs.String(), warning)
+	}, 5*time.Second, 50*time.Millisecond)
+
+	// The warning is logged only once the threshold is exceeded, not on every check
+	server.checkVersionDiversity()
+	assert.Equal(t, 1, strings.Count(logs.String(), warning))
+
+	// Once a peer with the dominant version disconnects, the versions are diverse again
+	server.DisconnectFromPeer(servers[1].host.ID(), "Bye")
+
+	require.Eventually(t, func() bool {
+		return strings.Contains(logs.String(), "Peer software versions are diverse again")
+	}, 5*time.Second, 50*time.Millisecond)
+
+	assert.False(t, server.checkVersionDiversity())
+}
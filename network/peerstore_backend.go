@@ -0,0 +1,42 @@
+package network
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	leveldb "github.com/ipfs/go-ds-leveldb"
+	"github.com/libp2p/go-libp2p/core/peerstore"
+	"github.com/libp2p/go-libp2p/p2p/host/peerstore/pstoreds"
+	"github.com/libp2p/go-libp2p/p2p/host/peerstore/pstoremem"
+)
+
+// newPersistentPeerstore creates a peerstore backed by the on-disk datastore at the path,
+// so the known peers survive restarts. The returned datastore has to be closed
+// once the host (which closes the peerstore) is closed
+func newPersistentPeerstore(path string) (peerstore.Peerstore, io.Closer, error) {
+	store, err := leveldb.NewDatastore(path, nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("unable to open peerstore datastore, %w", err)
+	}
+
+	ps, err := pstoreds.NewPeerstore(context.Background(), store, pstoreds.DefaultOpts())
+	if err != nil {
+		_ = store.Close()
+
+		return nil, nil, fmt.Errorf("unable to create peerstore, %w", err)
+	}
+
+	return ps, store, nil
+}
+
+// newClockedPeerstore creates an in-memory peerstore, which expires the addresses
+// based on the clock instead of the system time
+func newClockedPeerstore(clock Clock) (peerstore.Peerstore, error) {
+	ps, err := pstoremem.NewPeerstore(pstoremem.WithClock(clock))
+	if err != nil {
+		return nil, fmt.Errorf("unable to create peerstore, %w", err)
+	}
+
+	return ps, nil
+}
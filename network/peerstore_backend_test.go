@@ -0,0 +1,77 @@
+package network
+
+import (
+	"net"
+	"path/filepath"
+	"testing"
+
+	"github.com/libp2p/go-libp2p/core/peerstore"
+	"github.com/multiformats/go-multiaddr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPersistentPeerstore(t *testing.T) {
+	datastorePath := filepath.Join(t.TempDir(), "peerstore")
+
+	randomPeers, err := generateRandomPeers(t, 1)
+	require.NoError(t, err)
+
+	peerID := randomPeers[0].peerID
+	peerAddr := multiaddr.StringCast("/ip4/10.1.2.3/tcp/1478")
+
+	newServer := func(path string) *Server {
+		server, createErr := CreateServer(&CreateServerParams{ConfigCallback: func(c *Config) {
+			c.NoDiscover = true
+			c.PeerstoreDatastorePath = path
+		}})
+		require.NoError(t, createErr)
+
+		return server
+	}
+
+	// Add the peer before the restart
+	server := newServer(datastorePath)
+	server.host.Peerstore().AddAddrs(peerID, []multiaddr.Multiaddr{peerAddr}, peerstore.PermanentAddrTTL)
+
+	require.NoError(t, server.Close())
+
+	// The peer is known after the peerstore is reconstructed from the datastore
+	server = newServer(datastorePath)
+
+	t.Cleanup(func() {
+		require.NoError(t, server.Close())
+	})
+
+	assert.Equal(t, []multiaddr.Multiaddr{peerAddr}, server.host.Peerstore().Addrs(peerID))
+	assert.Contains(t, server.host.Peerstore().PeersWithAddrs(), peerID)
+
+	// The in-memory peerstore doesn't survive restarts
+	inMemory := newServer("")
+
+	t.Cleanup(func() {
+		require.NoError(t, inMemory.Close())
+	})
+
+	assert.Empty(t, inMemory.host.Peerstore().Addrs(peerID))
+	assert.NotContains(t, inMemory.host.Peerstore().PeersWithAddrs(), peerID)
+}
+
+func TestPersistentPeerstore_ClosedOnSetupFailure(t *testing.T) {
+	datastorePath := filepath.Join(t.TempDir(), "peerstore")
+
+	// No TCP port is bound for the NAT address, so the setup fails after the host is created
+	server, err := CreateServer(&CreateServerParams{ConfigCallback: func(c *Config) {
+		c.NoDiscover = true
+		c.PeerstoreDatastorePath = datastorePath
+		c.NatAddr = net.ParseIP("10.0.0.1")
+		c.ListenAddrs = []multiaddr.Multiaddr{multiaddr.StringCast("/ip4/127.0.0.1/udp/0/quic-v1")}
+	}})
+	require.ErrorIs(t, err, ErrNoListenPort)
+	assert.Nil(t, server)
+
+	// The datastore is released, so it can be opened again
+	_, datastore, err := newPersistentPeerstore(datastorePath)
+	require.NoError(t, err)
+	require.NoError(t, datastore.Close())
+}
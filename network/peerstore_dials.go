@@ -0,0 +1,66 @@
+package network
+
+import (
+	"time"
+
+	"github.com/0xPolygon/polygon-edge/network/common"
+	"github.com/armon/go-metrics"
+	"github.com/libp2p/go-libp2p/core/peer"
+)
+
+// peerstoreDialBatch is the number of random peer store peers queued
+// per minimum peer connections check
+const peerstoreDialBatch = 3
+
+// localDisconnectRedialDelay is the time a peer disconnected by this node
+// is not redialed from the peer store
+const localDisconnectRedialDelay = 5 * time.Minute
+
+// isPeerstoreDialCandidate checks if the peer known from the peer store is worth redialing
+func (s *Server) isPeerstoreDialCandidate(peerID peer.ID) bool {
+	return peerID != s.host.ID() &&
+		!s.IsConnected(peerID) &&
+		!s.IsBanned(peerID) &&
+		!s.IsQuarantined(peerID) &&
+		!s.isRecentlyDisconnectedLocally(peerID)
+}
+
+// isRecentlyDisconnectedLocally checks if this node disconnected from the peer recently,
+// in which case the peer store dials should not undo the disconnect
+func (s *Server) isRecentlyDisconnectedLocally(peerID peer.ID) bool {
+	entry, ok := s.peerHistory.latest(peerID)
+
+	return ok &&
+		entry.Outcome == PeerHistoryDisconnected &&
+		entry.Local &&
+		s.clock.Now().Sub(entry.At) < localDisconnectRedialDelay
+}
+
+// dialRandomPeerstorePeers adds a few random peers with known addresses from the peer store
+// to the dial queue. Nodes without discovery or bootnodes (e.g. static private networks)
+// rely on it to recover the peers they lost
+func (s *Server) dialRandomPeerstorePeers() {
+	candidates := make([]peer.ID, 0)
+
+	for _, peerID := range s.host.Peerstore().PeersWithAddrs() {
+		if s.isPeerstoreDialCandidate(peerID) {
+			candidates = append(candidates, peerID)
+		}
+	}
+
+	queued := 0
+
+	for _, peerID := range sampleRandom(candidates, peerstoreDialBatch) {
+		if s.addToDialQueue(s.GetPeerInfo(peerID), common.PriorityRandomDial, PeerSourcePeerstore) {
+			queued++
+		}
+	}
+
+	if queued == 0 {
+		return
+	}
+
+	s.logger.Debug("Below the minimum peer connections, dialing the known peers", "queued", queued)
+
+	metrics.IncrCounter([]string{networkMetrics, "peerstore_dials"}, float32(queued))
+}
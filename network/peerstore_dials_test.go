@@ -0,0 +1,81 @@
+package network
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	peerEvent "github.com/0xPolygon/polygon-edge/network/event"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeepAlive_DialsPeerstorePeers(t *testing.T) {
+	clock := newFakeClock()
+
+	servers, createErr := createServers(3, map[int]*CreateServerParams{
+		0: {ConfigCallback: func(c *Config) {
+			c.NoDiscover = true
+			c.DialFallbackInterval = -1
+			c.Clock = clock
+		}},
+		1: {ConfigCallback: func(c *Config) { c.NoDiscover = true }},
+		2: {ConfigCallback: func(c *Config) { c.NoDiscover = true }},
+	})
+	require.NoError(t, createErr)
+
+	t.Cleanup(func() {
+		closeTestServers(t, servers)
+	})
+
+	server, known, banned := servers[0], servers[1], servers[2]
+
+	// The server learns the addresses of the peers, and then loses them
+	for _, remote := range []*Server{known, banned} {
+		require.NoError(t, JoinAndWait(remote, server, DefaultBufferTimeout, DefaultJoinTimeout))
+	}
+
+	server.BanPeer(banned.host.ID(), time.Hour, "test")
+	server.DisconnectFromPeer(known.host.ID(), "test")
+
+	require.Eventually(t, func() bool {
+		return server.numPeers() == 0
+	}, DefaultJoinTimeout, 10*time.Millisecond)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	queuedCh := make(chan peer.ID, 16)
+
+	require.NoError(t, server.Subscribe(ctx, func(evnt *peerEvent.PeerEvent) {
+		if evnt.Type == peerEvent.PeerAddedToDialQueue {
+			queuedCh <- evnt.PeerID
+		}
+	}))
+
+	clock.Advance(peerConnectionsCheckInterval)
+
+	// The peer disconnected by the server is not redialed right away
+	select {
+	case peerID := <-queuedCh:
+		t.Fatalf("peer %s redialed right after the disconnect", peerID)
+	case <-time.After(200 * time.Millisecond):
+	}
+
+	clock.Advance(localDisconnectRedialDelay)
+
+	// Only the peer that is not banned is redialed
+	select {
+	case peerID := <-queuedCh:
+		assert.Equal(t, known.host.ID(), peerID)
+	case <-time.After(5 * time.Second):
+		t.Fatal("peer store peer not dialed below the minimum peer connections")
+	}
+
+	select {
+	case peerID := <-queuedCh:
+		assert.NotEqual(t, banned.host.ID(), peerID)
+	case <-time.After(200 * time.Millisecond):
+	}
+}
@@ -0,0 +1,77 @@
+package network
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/libp2p/go-libp2p/core/peer"
+)
+
+// DefaultMaxPendingJoins is the default maximum number of join requests waiting to be dialed
+const DefaultMaxPendingJoins = 256
+
+var ErrTooManyPendingJoins = errors.New("too many pending joins")
+
+// pendingJoins keeps track of the join requests waiting in the dial queue,
+// so a flood of join requests can't grow the dial queue unboundedly
+type pendingJoins struct {
+	sync.Mutex
+
+	maxJoins int
+	peers    map[peer.ID]struct{} // peers with a join request waiting to be dialed
+}
+
+// newPendingJoins creates a new pending join tracker
+func newPendingJoins(maxJoins int) *pendingJoins {
+	if maxJoins <= 0 {
+		maxJoins = DefaultMaxPendingJoins
+	}
+
+	return &pendingJoins{
+		maxJoins: maxJoins,
+		peers:    make(map[peer.ID]struct{}),
+	}
+}
+
+// add marks the join request for the peer as pending. Repeated requests
+// for the same peer are merged in the dial queue, so they are not counted twice [Thread safe]
+func (p *pendingJoins) add(peerID peer.ID) error {
+	p.Lock()
+	defer p.Unlock()
+
+	if _, ok := p.peers[peerID]; ok {
+		return nil
+	}
+
+	if len(p.peers) >= p.maxJoins {
+		return ErrTooManyPendingJoins
+	}
+
+	p.peers[peerID] = struct{}{}
+
+	return nil
+}
+
+// done marks the join request for the peer as taken out of the dial queue [Thread safe]
+func (p *pendingJoins) done(peerID peer.ID) {
+	p.Lock()
+	defer p.Unlock()
+
+	delete(p.peers, peerID)
+}
+
+// deleteDialTask drops the queued dial task of the peer, along with its pending join request.
+// Any dial task taken out of the dial queue has to free its join request, or it would
+// count against the pending join limit forever [Thread safe]
+func (s *Server) deleteDialTask(peerID peer.ID) {
+	s.dialQueue.DeleteTask(peerID)
+	s.joins.done(peerID)
+}
+
+// count returns the number of pending join requests [Thread safe]
+func (p *pendingJoins) count() int {
+	p.Lock()
+	defer p.Unlock()
+
+	return len(p.peers)
+}
@@ -0,0 +1,212 @@
+package network
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/0xPolygon/polygon-edge/network/common"
+	peerEvent "github.com/0xPolygon/polygon-edge/network/event"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/multiformats/go-multiaddr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPendingJoins(t *testing.T) {
+	joins := newPendingJoins(2)
+
+	require.NoError(t, joins.add(peer.ID("A")))
+	require.NoError(t, joins.add(peer.ID("B")))
+
+	// Repeated requests for the same peer are not counted twice
+	require.NoError(t, joins.add(peer.ID("A")))
+	assert.ErrorIs(t, joins.add(peer.ID("C")), ErrTooManyPendingJoins)
+
+	joins.done(peer.ID("A"))
+
+	require.NoError(t, joins.add(peer.ID("C")))
+	assert.Equal(t, 2, joins.count())
+}
+
+func TestJoinPeer_TooManyPendingJoins(t *testing.T) {
+	const maxPendingJoins = 2
+
+	server, createErr := CreateServer(&CreateServerParams{ConfigCallback: func(c *Config) {
+		c.NoDiscover = true
+		c.MaxOutboundPeers = 1
+		c.MaxPendingJoins = maxPendingJoins
+	}})
+	require.NoError(t, createErr)
+
+	t.Cleanup(func() {
+		require.NoError(t, server.Close())
+	})
+
+	require.Eventually(t, func() bool {
+		return server.dialSlots.Load() != nil
+	}, DefaultJoinTimeout, 10*time.Millisecond)
+
+	// Hold the only dial slot, so the join requests pile up in the dial queue
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	require.False(t, server.getDialSlots().Take(ctx))
+
+	randomPeers, err := generateRandomPeers(t, maxPendingJoins+3)
+	require.NoError(t, err)
+
+	joinPeer := func(index int) error {
+		return server.joinPeer(&peer.AddrInfo{
+			ID:    randomPeers[index].peerID,
+			Addrs: generateTestAddrs(t, 1),
+		})
+	}
+
+	// The first request is taken out of the queue, and waits for the dial slot
+	require.NoError(t, joinPeer(0))
+	require.Eventually(t, func() bool {
+		return server.joins.count() == 0
+	}, DefaultJoinTimeout, 10*time.Millisecond)
+
+	for i := 1; i <= maxPendingJoins; i++ {
+		require.NoError(t, joinPeer(i))
+	}
+
+	// The excess requests are rejected, instead of growing the queue
+	assert.ErrorIs(t, joinPeer(maxPendingJoins+1), ErrTooManyPendingJoins)
+	assert.ErrorIs(t, joinPeer(maxPendingJoins+2), ErrTooManyPendingJoins)
+	assert.Equal(t, maxPendingJoins, server.joins.count())
+}
+
+func TestJoinPeer_DeletedTasksFreePendingJoins(t *testing.T) {
+	const maxPendingJoins = 3
+
+	server, createErr := CreateServer(&CreateServerParams{ConfigCallback: func(c *Config) {
+		c.NoDiscover = true
+		c.MaxOutboundPeers = 1
+		c.MaxPendingJoins = maxPendingJoins
+	}})
+	require.NoError(t, createErr)
+
+	t.Cleanup(func() {
+		require.NoError(t, server.Close())
+	})
+
+	require.Eventually(t, func() bool {
+		return server.dialSlots.Load() != nil
+	}, DefaultJoinTimeout, 10*time.Millisecond)
+
+	// Hold the only dial slot, so the join requests pile up in the dial queue
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	require.False(t, server.getDialSlots().Take(ctx))
+
+	randomPeers, err := generateRandomPeers(t, 2*maxPendingJoins+2)
+	require.NoError(t, err)
+
+	joinPeer := func(index int) error {
+		return server.joinPeer(&peer.AddrInfo{
+			ID:    randomPeers[index].peerID,
+			Addrs: generateTestAddrs(t, 1),
+		})
+	}
+
+	// The first request is taken out of the queue, and waits for the dial slot
+	require.NoError(t, joinPeer(0))
+	require.Eventually(t, func() bool {
+		return server.joins.count() == 0
+	}, DefaultJoinTimeout, 10*time.Millisecond)
+
+	for i := 1; i <= maxPendingJoins; i++ {
+		require.NoError(t, joinPeer(i))
+	}
+
+	require.ErrorIs(t, joinPeer(maxPendingJoins+1), ErrTooManyPendingJoins)
+
+	// Dropping the queued dials frees their join requests, whichever way they are dropped
+	server.CancelDials(randomPeers[1].peerID)
+	server.QuarantinePeer(randomPeers[2].peerID, "test")
+	server.BanPeer(randomPeers[3].peerID, time.Minute, "test")
+
+	assert.Equal(t, 0, server.joins.count())
+	assert.Equal(t, 0, server.dialQueue.Len())
+
+	for i := maxPendingJoins + 1; i <= 2*maxPendingJoins; i++ {
+		require.NoError(t, joinPeer(i))
+	}
+
+	assert.ErrorIs(t, joinPeer(2*maxPendingJoins+1), ErrTooManyPendingJoins)
+}
+
+func TestJoinPeer_AheadOfRequestedDials(t *testing.T) {
+	const requestedDials = 10
+
+	servers, createErr := createServers(2, map[int]*CreateServerParams{
+		0: {ConfigCallback: func(c *Config) {
+			c.NoDiscover = true
+			c.MaxOutboundPeers = 1
+		}},
+		1: {ConfigCallback: func(c *Config) { c.NoDiscover = true }},
+	})
+	require.NoError(t, createErr)
+
+	t.Cleanup(func() {
+		closeTestServers(t, servers)
+	})
+
+	server, target := servers[0], servers[1]
+
+	require.Eventually(t, func() bool {
+		return server.dialSlots.Load() != nil
+	}, DefaultJoinTimeout, 10*time.Millisecond)
+
+	// Hold the only dial slot, so the requested dials pile up in the dial queue
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultJoinTimeout)
+	defer cancel()
+
+	require.False(t, server.getDialSlots().Take(ctx))
+
+	randomPeers, err := generateRandomPeers(t, requestedDials)
+	require.NoError(t, err)
+
+	requested := make(map[peer.ID]struct{}, requestedDials)
+
+	for _, randomPeer := range randomPeers {
+		requested[randomPeer.peerID] = struct{}{}
+
+		require.NoError(t, server.requestDial(&peer.AddrInfo{
+			ID:    randomPeer.peerID,
+			Addrs: []multiaddr.Multiaddr{multiaddr.StringCast("/ip4/127.0.0.1/tcp/1")},
+		}, common.PriorityRequestedDial))
+	}
+
+	events, err := server.SubscribeCh(ctx)
+	require.NoError(t, err)
+
+	targetAddrs, err := peer.AddrInfoToP2pAddrs(target.AddrInfo())
+	require.NoError(t, err)
+
+	require.NoError(t, server.JoinPeer(targetAddrs[0].String()))
+
+	server.getDialSlots().Release()
+
+	// At most the requested dial already waiting for the slot goes before the join
+	failedDials := 0
+
+	for connected := false; !connected; {
+		select {
+		case evnt := <-events:
+			if _, ok := requested[evnt.PeerID]; ok && evnt.Type == peerEvent.PeerFailedToConnect {
+				failedDials++
+			}
+
+			connected = evnt.PeerID == target.host.ID() && evnt.Type == peerEvent.PeerConnected
+		case <-ctx.Done():
+			t.Fatal("join not completed")
+		}
+	}
+
+	assert.LessOrEqual(t, failedDials, 1)
+}
@@ -0,0 +1,123 @@
+package network
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/0xPolygon/polygon-edge/network/common"
+	peerEvent "github.com/0xPolygon/polygon-edge/network/event"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/multiformats/go-multiaddr"
+)
+
+// pinnedRedialDelay is the delay before a failed dial to a pinned peer is retried
+const pinnedRedialDelay = 5 * time.Second
+
+var ErrUnknownPeer = errors.New("peer is not known")
+
+// PinConnection guarantees a long-lived connection with the already known peer.
+// The peer is protected from pruning and trimming, is never dropped for being idle,
+// and is redialed with priority whenever it disconnects [Thread safe]
+func (s *Server) PinConnection(peerID peer.ID) error {
+	peerInfo := s.GetPeerInfo(peerID)
+	if len(peerInfo.Addrs) == 0 {
+		return ErrUnknownPeer
+	}
+
+	// Keep the addresses, since the peer store only keeps them for a while after disconnecting
+	s.pinnedPeers.Store(peerID, peerInfo)
+	s.ProtectPeer(peerID)
+
+	s.logger.Info("Connection pinned", "peer", peerID)
+
+	if !s.IsConnected(peerID) {
+		s.redialPinnedPeer(peerID)
+	}
+
+	return nil
+}
+
+// UnpinConnection reverts the connection pinning of the peer [Thread safe]
+func (s *Server) UnpinConnection(peerID peer.ID) {
+	if _, ok := s.pinnedPeers.LoadAndDelete(peerID); ok {
+		s.UnprotectPeer(peerID)
+
+		s.logger.Info("Connection unpinned", "peer", peerID)
+	}
+}
+
+// IsPinned checks if the connection with the peer is pinned [Thread safe]
+func (s *Server) IsPinned(peerID peer.ID) bool {
+	_, ok := s.pinnedPeers.Load(peerID)
+
+	return ok
+}
+
+// getPinnedPeerInfo returns the addresses of the pinned peer,
+// including the ones learned after the peer was pinned
+func (s *Server) getPinnedPeerInfo(peerID peer.ID) (*peer.AddrInfo, bool) {
+	value, ok := s.pinnedPeers.Load(peerID)
+	if !ok {
+		return nil, false
+	}
+
+	pinnedInfo, _ := value.(*peer.AddrInfo)
+	peerInfo := s.GetPeerInfo(peerID)
+
+	for _, addr := range pinnedInfo.Addrs {
+		if !multiaddr.Contains(peerInfo.Addrs, addr) {
+			peerInfo.Addrs = append(peerInfo.Addrs, addr)
+		}
+	}
+
+	return peerInfo, true
+}
+
+// redialPinnedPeer adds the pinned peer to the dial queue with the highest priority
+func (s *Server) redialPinnedPeer(peerID peer.ID) {
+	select {
+	case <-s.closeCh:
+		// The networking server is shutting down
+		return
+	default:
+	}
+
+	peerInfo, ok := s.getPinnedPeerInfo(peerID)
+	if !ok {
+		return
+	}
+
+	// Pinning overrides any backoff from a previous goodbye
+	s.goodbyes.Delete(peerID)
+
+	s.addToDialQueue(peerInfo, common.PriorityPinnedDial, s.getPeerSource(peerID))
+}
+
+// watchPinnedDials retries the failed dials to the pinned peers, until they are connected or unpinned
+func (s *Server) watchPinnedDials() error {
+	ctx, cancel := context.WithCancel(context.Background())
+	watchDone := s.health.watchers.start()
+
+	go func() {
+		defer watchDone()
+
+		<-s.closeCh
+		cancel()
+	}()
+
+	return s.Subscribe(ctx, func(evnt *peerEvent.PeerEvent) {
+		if evnt.Type != peerEvent.PeerFailedToConnect || !s.IsPinned(evnt.PeerID) {
+			return
+		}
+
+		peerInfo, ok := s.getPinnedPeerInfo(evnt.PeerID)
+		if !ok {
+			return
+		}
+
+		s.logger.Debug("Retrying dial to pinned peer", "peer", evnt.PeerID, "delay", pinnedRedialDelay)
+
+		s.deferDial(peerInfo, common.PriorityPinnedDial, pinnedRedialDelay)
+	})
+}
@@ -0,0 +1,112 @@
+package network
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/0xPolygon/polygon-edge/network/common"
+	"github.com/0xPolygon/polygon-edge/network/dial"
+	peerEvent "github.com/0xPolygon/polygon-edge/network/event"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPinConnection_Redial(t *testing.T) {
+	servers, createErr := createServers(2, map[int]*CreateServerParams{
+		0: {ConfigCallback: func(c *Config) { c.NoDiscover = true }},
+		1: {ConfigCallback: func(c *Config) { c.NoDiscover = true }},
+	})
+	require.NoError(t, createErr)
+
+	t.Cleanup(func() {
+		closeTestServers(t, servers)
+	})
+
+	server, pinned := servers[0], servers[1]
+	pinnedID := pinned.host.ID()
+
+	assert.ErrorIs(t, server.PinConnection(peer.ID("UnknownPeer")), ErrUnknownPeer)
+
+	require.NoError(t, JoinAndWait(server, pinned, DefaultBufferTimeout, DefaultJoinTimeout))
+	require.NoError(t, server.PinConnection(pinnedID))
+
+	assert.True(t, server.IsPinned(pinnedID))
+	assert.True(t, server.IsProtected(pinnedID))
+
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultJoinTimeout)
+	defer cancel()
+
+	eventCh, err := server.SubscribeCh(ctx)
+	require.NoError(t, err)
+
+	// Even a deliberate disconnect is reverted
+	server.DisconnectFromPeer(pinnedID, "test")
+
+	expectedEvents := []peerEvent.PeerEventType{peerEvent.PeerDisconnected, peerEvent.PeerConnected}
+
+	for len(expectedEvents) > 0 {
+		select {
+		case evnt := <-eventCh:
+			if evnt.PeerID == pinnedID && evnt.Type == expectedEvents[0] {
+				expectedEvents = expectedEvents[1:]
+			}
+		case <-ctx.Done():
+			t.Fatalf("pinned peer not reconnected, missing events %v", expectedEvents)
+		}
+	}
+
+	cancel()
+
+	assert.True(t, server.hasPeer(pinnedID))
+
+	// Unpinned peers are not redialed
+	server.UnpinConnection(pinnedID)
+
+	assert.False(t, server.IsPinned(pinnedID))
+	assert.False(t, server.IsProtected(pinnedID))
+
+	require.NoError(t, DisconnectAndWait(server, pinnedID, DefaultLeaveTimeout))
+
+	assert.Never(t, func() bool {
+		return server.IsConnected(pinnedID)
+	}, time.Second, 50*time.Millisecond)
+}
+
+func TestPinConnection_DialPriority(t *testing.T) {
+	randomPeers, err := generateRandomPeers(t, 2)
+	require.NoError(t, err)
+
+	otherInfo := &peer.AddrInfo{ID: randomPeers[0].peerID, Addrs: generateTestAddrs(t, 1)}
+	pinnedInfo := &peer.AddrInfo{ID: randomPeers[1].peerID, Addrs: generateTestAddrs(t, 1)}
+
+	var queued []*dial.DialTask
+
+	server, createErr := CreateServer(&CreateServerParams{
+		ConfigCallback: func(c *Config) {
+			c.NoDiscover = true
+		},
+		ServerCallback: func(server *Server) {
+			// The dial loop is not running yet, so the dial queue can be inspected
+			server.addToDialQueue(otherInfo, common.PriorityRequestedDial, PeerSourceJoin)
+
+			server.AddToPeerStore(pinnedInfo)
+			require.NoError(t, server.PinConnection(pinnedInfo.ID))
+
+			for task := server.dialQueue.PopTask(); task != nil; task = server.dialQueue.PopTask() {
+				queued = append(queued, task)
+			}
+		},
+	})
+	require.NoError(t, createErr)
+
+	t.Cleanup(func() {
+		require.NoError(t, server.Close())
+	})
+
+	// The pinned peer is dialed ahead of even the requested dials
+	require.Len(t, queued, 2)
+	assert.Equal(t, pinnedInfo.ID, queued[0].GetAddrInfo().ID)
+	assert.Equal(t, common.PriorityPinnedDial, queued[0].GetPriority())
+}
@@ -0,0 +1,31 @@
+package network
+
+import (
+	"github.com/armon/go-metrics"
+	"github.com/libp2p/go-libp2p/core/network"
+	"github.com/libp2p/go-libp2p/core/peer"
+)
+
+// RegisterProtocolWithAuth registers the protocol, serving only the streams
+// of the peers for which the auth callback returns true.
+// The streams of the other peers are reset before reaching the protocol handler
+func (s *Server) RegisterProtocolWithAuth(id string, p Protocol, authFn func(peer.ID) bool) {
+	s.protocolsLock.Lock()
+	defer s.protocolsLock.Unlock()
+
+	s.protocols[id] = p
+	s.wrapAuthorizedStream(id, p.Handler(), authFn)
+}
+
+// resetUnauthorizedStream resets the stream of a peer not authorized to use the protocol
+func (s *Server) resetUnauthorizedStream(stream network.Stream) {
+	s.logger.Debug(
+		"Resetting stream, peer not authorized for the protocol",
+		"protocol", stream.Protocol(),
+		"peer", stream.Conn().RemotePeer(),
+	)
+
+	metrics.IncrCounter([]string{networkMetrics, "streams_reset_unauthorized"}, 1)
+
+	_ = stream.Reset()
+}
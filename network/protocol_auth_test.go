@@ -0,0 +1,66 @@
+package network
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/libp2p/go-libp2p/core/network"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/libp2p/go-libp2p/core/protocol"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegisterProtocolWithAuth(t *testing.T) {
+	const authProto = "/auth-test/0.1"
+
+	servers, createErr := createServers(3, map[int]*CreateServerParams{
+		0: {ConfigCallback: func(c *Config) { c.NoDiscover = true }},
+		1: {ConfigCallback: func(c *Config) { c.NoDiscover = true }},
+		2: {ConfigCallback: func(c *Config) { c.NoDiscover = true }},
+	})
+	require.NoError(t, createErr)
+
+	t.Cleanup(func() {
+		closeTestServers(t, servers)
+	})
+
+	server, authorized, unauthorized := servers[0], servers[1], servers[2]
+	authorizedID := authorized.host.ID()
+
+	server.RegisterProtocolWithAuth(authProto, holdProtocol{}, func(peerID peer.ID) bool {
+		return peerID == authorizedID
+	})
+
+	require.NoError(t, JoinAndWait(authorized, server, DefaultBufferTimeout, DefaultJoinTimeout))
+	require.NoError(t, JoinAndWait(unauthorized, server, DefaultBufferTimeout, DefaultJoinTimeout))
+
+	openStream := func(client *Server) network.Stream {
+		stream, err := client.host.NewStream(context.Background(), server.host.ID(), protocol.ID(authProto))
+		require.NoError(t, err)
+
+		t.Cleanup(func() {
+			_ = stream.Reset()
+		})
+
+		// Streams are negotiated lazily, so make sure the server handles them
+		_, err = stream.Write([]byte("hello"))
+		require.NoError(t, err)
+
+		return stream
+	}
+
+	authorizedStream := openStream(authorized)
+	unauthorizedStream := openStream(unauthorized)
+
+	// The stream of the unauthorized peer is reset
+	require.Eventually(t, func() bool {
+		return isStreamReset(unauthorizedStream)
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// The stream of the authorized peer is served
+	assert.False(t, isStreamReset(authorizedStream))
+	assert.Contains(t, server.ConnProtocols(authorizedID), protocol.ID(authProto))
+	assert.NotContains(t, server.ConnProtocols(unauthorized.host.ID()), protocol.ID(authProto))
+}
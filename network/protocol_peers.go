@@ -0,0 +1,136 @@
+package network
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/libp2p/go-libp2p/core/protocol"
+)
+
+const (
+	// defaultPeerWeight is the selection weight of the peers without a latency measurement
+	defaultPeerWeight = 10
+
+	// maxPeerWeight is the selection weight of the peers at or below the reference latency
+	maxPeerWeight = 100
+
+	// referenceLatency is the latency at which a peer gets the maximum selection weight.
+	// The weight of slower peers drops in inverse proportion to their latency
+	referenceLatency = 10 * time.Millisecond
+)
+
+// peerWeight returns the selection weight of a peer with the given latency and
+// reputation penalty. A zero latency means the latency has not been measured yet
+func peerWeight(latency time.Duration, penalty int) int {
+	weight := defaultPeerWeight
+
+	if latency > 0 {
+		weight = int(maxPeerWeight * int64(referenceLatency) / int64(latency))
+	}
+
+	weight /= 1 + penalty
+
+	if weight > maxPeerWeight {
+		return maxPeerWeight
+	}
+
+	if weight < 1 {
+		return 1
+	}
+
+	return weight
+}
+
+// protocolCursors keeps the smooth weighted round-robin state of each protocol,
+// so the requests are spread across the peers in proportion to their weights,
+// without bursts towards a single peer
+type protocolCursors struct {
+	sync.Mutex
+
+	// protocol -> peerID -> current weight of the peer
+	cursors map[string]map[peer.ID]int
+}
+
+// newProtocolCursors creates a new protocol round-robin state
+func newProtocolCursors() *protocolCursors {
+	return &protocolCursors{
+		cursors: make(map[string]map[peer.ID]int),
+	}
+}
+
+// next selects the next peer from the candidates, given as peerID -> weight [Thread safe]
+func (c *protocolCursors) next(protoID string, candidates map[peer.ID]int) (peer.ID, bool) {
+	c.Lock()
+	defer c.Unlock()
+
+	if len(candidates) == 0 {
+		delete(c.cursors, protoID)
+
+		return "", false
+	}
+
+	current, ok := c.cursors[protoID]
+	if !ok {
+		current = make(map[peer.ID]int)
+		c.cursors[protoID] = current
+	}
+
+	// Forget the peers that are no longer candidates
+	for peerID := range current {
+		if _, ok := candidates[peerID]; !ok {
+			delete(current, peerID)
+		}
+	}
+
+	// Iterate in a fixed order, so the ties are resolved deterministically
+	peerIDs := make([]peer.ID, 0, len(candidates))
+	for peerID := range candidates {
+		peerIDs = append(peerIDs, peerID)
+	}
+
+	sort.Slice(peerIDs, func(i, j int) bool {
+		return peerIDs[i] < peerIDs[j]
+	})
+
+	var (
+		selected    peer.ID
+		totalWeight int
+	)
+
+	for _, peerID := range peerIDs {
+		weight := candidates[peerID]
+
+		current[peerID] += weight
+		totalWeight += weight
+
+		if selected == "" || current[peerID] > current[selected] {
+			selected = peerID
+		}
+	}
+
+	current[selected] -= totalWeight
+
+	return selected, true
+}
+
+// NextPeerForProtocol selects the next connected peer supporting the protocol, in a
+// round-robin fashion weighted by the peer latency and reputation, so the better peers
+// get proportionally more requests. Returns false if no connected peer supports the protocol [Thread safe]
+func (s *Server) NextPeerForProtocol(protoID string) (peer.ID, bool) {
+	candidates := make(map[peer.ID]int)
+
+	for _, connInfo := range s.Peers() {
+		peerID := connInfo.Info.ID
+
+		supported, err := s.host.Peerstore().SupportsProtocols(peerID, protocol.ID(protoID))
+		if err != nil || len(supported) == 0 {
+			continue
+		}
+
+		candidates[peerID] = peerWeight(s.host.Peerstore().LatencyEWMA(peerID), s.PeerPenalty(peerID))
+	}
+
+	return s.protocolCursors.next(protoID, candidates)
+}
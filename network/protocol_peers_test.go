@@ -0,0 +1,102 @@
+package network
+
+import (
+	"testing"
+	"time"
+
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/libp2p/go-libp2p/core/protocol"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPeerWeight(t *testing.T) {
+	assert.Equal(t, defaultPeerWeight, peerWeight(0, 0))
+	assert.Equal(t, maxPeerWeight, peerWeight(time.Millisecond, 0))
+	assert.Equal(t, maxPeerWeight/2, peerWeight(2*referenceLatency, 0))
+	assert.Equal(t, 1, peerWeight(time.Hour, 0))
+
+	// Penalized peers get proportionally fewer requests
+	assert.Equal(t, maxPeerWeight/3, peerWeight(referenceLatency, 2))
+}
+
+func TestProtocolCursors_Smooth(t *testing.T) {
+	cursors := newProtocolCursors()
+	candidates := map[peer.ID]int{"A": 2, "B": 1}
+
+	selected := make([]peer.ID, 0)
+
+	for i := 0; i < 6; i++ {
+		peerID, ok := cursors.next("proto", candidates)
+		require.True(t, ok)
+
+		selected = append(selected, peerID)
+	}
+
+	// The heavier peer doesn't get its share in a single burst
+	assert.Equal(t, []peer.ID{"A", "B", "A", "A", "B", "A"}, selected)
+
+	_, ok := cursors.next("proto", map[peer.ID]int{})
+	assert.False(t, ok)
+}
+
+func TestNextPeerForProtocol(t *testing.T) {
+	const (
+		numPeers = 4
+		protoID  = "/test-weighted/0.1"
+		rounds   = 10
+	)
+
+	params := make(map[int]*CreateServerParams)
+	for i := 0; i <= numPeers; i++ {
+		params[i] = &CreateServerParams{ConfigCallback: func(c *Config) {
+			c.NoDiscover = true
+		}}
+	}
+
+	servers, createErr := createServers(numPeers+1, params)
+	require.NoError(t, createErr)
+
+	t.Cleanup(func() {
+		closeTestServers(t, servers)
+	})
+
+	server, peers := servers[0], servers[1:]
+
+	for _, peerServer := range peers {
+		require.NoError(t, JoinAndWait(server, peerServer, DefaultBufferTimeout, DefaultJoinTimeout))
+	}
+
+	_, ok := server.NextPeerForProtocol(protoID)
+	require.False(t, ok)
+
+	// The last peer doesn't support the protocol
+	latencies := []time.Duration{referenceLatency, 2 * referenceLatency, 5 * referenceLatency}
+	expectedWeights := make(map[peer.ID]int)
+	totalWeight := 0
+
+	for i, latency := range latencies {
+		peerID := peers[i].host.ID()
+
+		require.NoError(t, server.host.Peerstore().AddProtocols(peerID, protocol.ID(protoID)))
+		server.host.Peerstore().RecordLatency(peerID, latency)
+
+		expectedWeights[peerID] = peerWeight(latency, 0)
+		totalWeight += expectedWeights[peerID]
+	}
+
+	selections := make(map[peer.ID]int)
+
+	for i := 0; i < rounds*totalWeight; i++ {
+		peerID, ok := server.NextPeerForProtocol(protoID)
+		require.True(t, ok)
+
+		selections[peerID]++
+	}
+
+	for peerID, weight := range expectedWeights {
+		assert.Equal(t, rounds*weight, selections[peerID], peerID)
+	}
+
+	assert.Zero(t, selections[peers[numPeers-1].host.ID()])
+}
@@ -0,0 +1,112 @@
+package network
+
+import (
+	"sync"
+	"sync/atomic"
+
+	"github.com/armon/go-metrics"
+	"github.com/libp2p/go-libp2p/core/network"
+)
+
+// TrafficStats is the traffic exchanged over the streams of a single protocol
+type TrafficStats struct {
+	Sent     uint64 // the number of bytes sent
+	Received uint64 // the number of bytes received
+}
+
+// protocolTraffic is the running traffic counter of a single protocol
+type protocolTraffic struct {
+	proto    string
+	sent     atomic.Uint64
+	received atomic.Uint64
+}
+
+// protocolTrafficTracker keeps count of the traffic exchanged over the server protocol streams,
+// both the served and the opened ones, per protocol ID
+type protocolTrafficTracker struct {
+	sync.Mutex
+
+	protocols map[string]*protocolTraffic
+}
+
+// newProtocolTrafficTracker creates a new protocol traffic tracker
+func newProtocolTrafficTracker() *protocolTrafficTracker {
+	return &protocolTrafficTracker{
+		protocols: make(map[string]*protocolTraffic),
+	}
+}
+
+// counter returns the traffic counter of the protocol, creating it if needed [Thread safe]
+func (t *protocolTrafficTracker) counter(proto string) *protocolTraffic {
+	t.Lock()
+	defer t.Unlock()
+
+	traffic, ok := t.protocols[proto]
+	if !ok {
+		traffic = &protocolTraffic{proto: proto}
+		t.protocols[proto] = traffic
+	}
+
+	return traffic
+}
+
+// wrap wraps the stream so its traffic is counted for its protocol
+func (t *protocolTrafficTracker) wrap(proto string, stream network.Stream) network.Stream {
+	return &trafficCountingStream{
+		Stream:  stream,
+		traffic: t.counter(proto),
+	}
+}
+
+// stats returns a snapshot of the traffic per protocol [Thread safe]
+func (t *protocolTrafficTracker) stats() map[string]TrafficStats {
+	t.Lock()
+	defer t.Unlock()
+
+	stats := make(map[string]TrafficStats, len(t.protocols))
+	for proto, traffic := range t.protocols {
+		stats[proto] = TrafficStats{
+			Sent:     traffic.sent.Load(),
+			Received: traffic.received.Load(),
+		}
+	}
+
+	return stats
+}
+
+// trafficCountingStream is a stream whose reads and writes are counted
+// in the traffic of its protocol
+type trafficCountingStream struct {
+	network.Stream
+
+	traffic *protocolTraffic
+}
+
+// Read reads the data from the stream, counting the bytes received
+func (cs *trafficCountingStream) Read(p []byte) (int, error) {
+	n, err := cs.Stream.Read(p)
+	if n > 0 {
+		cs.traffic.received.Add(uint64(n))
+		metrics.IncrCounter([]string{networkMetrics, "protocol_traffic", cs.traffic.proto, "received"}, float32(n))
+	}
+
+	return n, err
+}
+
+// Write writes the data to the stream, counting the bytes sent
+func (cs *trafficCountingStream) Write(p []byte) (int, error) {
+	n, err := cs.Stream.Write(p)
+	if n > 0 {
+		cs.traffic.sent.Add(uint64(n))
+		metrics.IncrCounter([]string{networkMetrics, "protocol_traffic", cs.traffic.proto, "sent"}, float32(n))
+	}
+
+	return n, err
+}
+
+// ProtocolTrafficStats returns the number of bytes sent and received over the streams
+// of each server protocol, since the server was created. The streams opened directly
+// by the libp2p services (e.g. the gossip ones) are not accounted [Thread safe]
+func (s *Server) ProtocolTrafficStats() map[string]TrafficStats {
+	return s.protocolTraffic.stats()
+}
@@ -0,0 +1,67 @@
+package network
+
+import (
+	"io"
+	"testing"
+	"time"
+
+	"github.com/libp2p/go-libp2p/core/network"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProtocolTrafficStats(t *testing.T) {
+	const (
+		protoA = "/traffic-a/0.1"
+		protoB = "/traffic-b/0.1"
+	)
+
+	sizes := map[string]int{
+		protoA: 3000,
+		protoB: 500,
+	}
+
+	servers, createErr := createServers(2, map[int]*CreateServerParams{
+		0: {ConfigCallback: func(c *Config) { c.NoDiscover = true }},
+		1: {ConfigCallback: func(c *Config) { c.NoDiscover = true }},
+	})
+	require.NoError(t, createErr)
+
+	t.Cleanup(func() {
+		closeTestServers(t, servers)
+	})
+
+	sender, receiver := servers[0], servers[1]
+
+	for proto := range sizes {
+		receiver.wrapStream(proto, func(stream network.Stream) {
+			_, _ = io.Copy(io.Discard, stream)
+			_ = stream.Close()
+		})
+	}
+
+	require.NoError(t, JoinAndWait(sender, receiver, DefaultBufferTimeout, DefaultJoinTimeout))
+
+	for proto, size := range sizes {
+		stream, err := sender.NewStream(proto, receiver.host.ID())
+		require.NoError(t, err)
+
+		n, err := stream.Write(make([]byte, size))
+		require.NoError(t, err)
+		require.Equal(t, size, n)
+
+		require.NoError(t, stream.Close())
+	}
+
+	// The receiver counts the bytes once they are read by the handlers
+	require.Eventually(t, func() bool {
+		stats := receiver.ProtocolTrafficStats()
+
+		return stats[protoA].Received == uint64(sizes[protoA]) && stats[protoB].Received == uint64(sizes[protoB])
+	}, 5*time.Second, 10*time.Millisecond)
+
+	senderStats := sender.ProtocolTrafficStats()
+
+	assert.Equal(t, TrafficStats{Sent: uint64(sizes[protoA])}, senderStats[protoA])
+	assert.Equal(t, TrafficStats{Sent: uint64(sizes[protoB])}, senderStats[protoB])
+}
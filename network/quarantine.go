@@ -0,0 +1,176 @@
+package network
+
+import (
+	"sync"
+	"time"
+
+	"github.com/armon/go-metrics"
+	"github.com/libp2p/go-libp2p/core/host"
+	"github.com/libp2p/go-libp2p/core/network"
+	"github.com/libp2p/go-libp2p/core/peer"
+)
+
+const (
+	// DefaultQuarantineDuration is the default time a misbehaving peer is quarantined for
+	DefaultQuarantineDuration = 5 * time.Minute
+
+	// maxQuarantineMultiplier caps the quarantine extension of the repeat offenders
+	maxQuarantineMultiplier = 8
+
+	// DefaultPenaltyMaxAge is the default time without violations after which the peer penalty is forgotten
+	DefaultPenaltyMaxAge = 24 * time.Hour
+
+	// DefaultMaxPenaltyRecords is the default maximum number of penalty records kept
+	DefaultMaxPenaltyRecords = 4096
+)
+
+// penaltyRecord is the reputation penalty of a misbehaving peer
+type penaltyRecord struct {
+	penalty       int       // the number of protocol violations of the peer
+	lastViolation time.Time // the time of the latest violation
+}
+
+// peerPenalties keeps track of the reputation penalties of the misbehaving peers.
+// The number of records is bounded, so the least recently penalized peers
+// are forgotten first, unless they are still connected
+type peerPenalties struct {
+	sync.Mutex
+
+	records *boundedPeerRecords // peerID -> *penaltyRecord, the records of the connected peers are never evicted
+	maxAge  time.Duration       // the time without violations after which the record is dropped
+}
+
+// newPeerPenalties creates a new peer penalty tracker.
+// The default capacity and max age are used if they are not positive
+func newPeerPenalties(capacity int, maxAge time.Duration, isConnected func(peer.ID) bool) *peerPenalties {
+	if capacity <= 0 {
+		capacity = DefaultMaxPenaltyRecords
+	}
+
+	if maxAge <= 0 {
+		maxAge = DefaultPenaltyMaxAge
+	}
+
+	return &peerPenalties{
+		records: newBoundedPeerRecords(capacity, isConnected),
+		maxAge:  maxAge,
+	}
+}
+
+// hostConnectedness returns a check if the host is connected to a peer
+func hostConnectedness(h host.Host) func(peer.ID) bool {
+	return func(peerID peer.ID) bool {
+		return h.Network().Connectedness(peerID) == network.Connected
+	}
+}
+
+// penalize increases the penalty of the peer, and returns the new penalty [Thread safe]
+func (p *peerPenalties) penalize(peerID peer.ID, now time.Time) int {
+	p.Lock()
+	defer p.Unlock()
+
+	// Forget the peers that have behaved for long enough, the least recently penalized come first
+	for {
+		id, record, ok := p.records.oldest()
+		if !ok {
+			break
+		}
+
+		if oldest, _ := record.(*penaltyRecord); now.Sub(oldest.lastViolation) <= p.maxAge {
+			break
+		}
+
+		p.records.remove(id)
+	}
+
+	record := p.getLocked(peerID)
+	if record == nil {
+		record = &penaltyRecord{}
+	}
+
+	record.penalty++
+	record.lastViolation = now
+
+	// The peer becomes the most recently penalized one
+	if evicted := p.records.add(peerID, record); evicted > 0 {
+		metrics.IncrCounter([]string{networkMetrics, "penalty_records_evicted"}, float32(evicted))
+	}
+
+	return record.penalty
+}
+
+// get returns the current penalty of the peer [Thread safe]
+func (p *peerPenalties) get(peerID peer.ID, now time.Time) int {
+	p.Lock()
+	defer p.Unlock()
+
+	record := p.getLocked(peerID)
+	if record == nil || now.Sub(record.lastViolation) > p.maxAge {
+		return 0
+	}
+
+	return record.penalty
+}
+
+// getLocked returns the penalty record of the peer, nil if there is none. The lock has to be held
+func (p *peerPenalties) getLocked(peerID peer.ID) *penaltyRecord {
+	record, ok := p.records.get(peerID)
+	if !ok {
+		return nil
+	}
+
+	penalty, _ := record.(*penaltyRecord)
+
+	return penalty
+}
+
+// size returns the number of penalty records kept [Thread safe]
+func (p *peerPenalties) size() int {
+	p.Lock()
+	defer p.Unlock()
+
+	return p.records.len()
+}
+
+// quarantineDuration returns the configured quarantine duration, or the default one
+func (s *Server) quarantineDuration() time.Duration {
+	if s.config.QuarantineDuration > 0 {
+		return s.config.QuarantineDuration
+	}
+
+	return DefaultQuarantineDuration
+}
+
+// QuarantinePeer penalizes the peer for a protocol violation, and refuses any connections
+// with it for the quarantine duration. Repeat offenders are quarantined for longer [Thread safe]
+func (s *Server) QuarantinePeer(peerID peer.ID, reason string) {
+	now := s.clock.Now()
+	penalty := s.penalties.penalize(peerID, now)
+
+	multiplier := penalty
+	if multiplier > maxQuarantineMultiplier {
+		multiplier = maxQuarantineMultiplier
+	}
+
+	duration := s.quarantineDuration() * time.Duration(multiplier)
+
+	s.gater.quarantinePeer(peerID, now.Add(duration), reason)
+
+	// Drop any pending dial to the peer
+	s.deleteDialTask(peerID)
+
+	s.logger.Warn("Peer quarantined", "peer", peerID, "reason", reason, "penalty", penalty, "duration", duration)
+
+	metrics.IncrCounter([]string{networkMetrics, "quarantined_peers"}, 1)
+}
+
+// IsQuarantined checks if the peer is currently quarantined [Thread safe]
+func (s *Server) IsQuarantined(peerID peer.ID) bool {
+	return s.gater.isQuarantined(peerID)
+}
+
+// PeerPenalty returns the reputation penalty of the peer,
+// which is the number of its recent protocol violations [Thread safe]
+func (s *Server) PeerPenalty(peerID peer.ID) int {
+	return s.penalties.get(peerID, s.clock.Now())
+}
@@ -0,0 +1,126 @@
+package network
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/0xPolygon/polygon-edge/network/common"
+	"github.com/0xPolygon/polygon-edge/network/grpc"
+	"github.com/0xPolygon/polygon-edge/network/identity"
+	"github.com/0xPolygon/polygon-edge/network/proto"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// malformedIdentityService is an identity service responding with oversized handshake metadata
+type malformedIdentityService struct {
+	proto.UnimplementedIdentityServer
+
+	chainID int64
+}
+
+func (m *malformedIdentityService) Hello(_ context.Context, _ *proto.Status) (*proto.Status, error) {
+	return &proto.Status{
+		Metadata: map[string]string{
+			identity.PeerID: strings.Repeat("a", 4096),
+		},
+		Chain: m.chainID,
+	}, nil
+}
+
+func TestPeerPenalties(t *testing.T) {
+	penalties := newPeerPenalties(0, 0, nil)
+	peerID := peer.ID("RandomPeer")
+	now := time.Now()
+
+	assert.Equal(t, 1, penalties.penalize(peerID, now))
+	assert.Equal(t, 2, penalties.penalize(peerID, now.Add(time.Minute)))
+	assert.Equal(t, 2, penalties.get(peerID, time.Now()))
+
+	// The penalty is forgotten after a period without violations
+	assert.Equal(t, 1, penalties.penalize(peerID, now.Add(2*DefaultPenaltyMaxAge)))
+}
+
+func TestPeerPenalties_Capacity(t *testing.T) {
+	const capacity = 16
+
+	connectedID := peer.ID("ConnectedPeer")
+	penalties := newPeerPenalties(capacity, 0, func(peerID peer.ID) bool {
+		return peerID == connectedID
+	})
+
+	now := time.Now()
+
+	// The connected peer is penalized first, so it is the oldest record
+	penalties.penalize(connectedID, now)
+
+	const total = 1000
+
+	for i := 0; i < total; i++ {
+		penalties.penalize(peer.ID(fmt.Sprintf("Peer%d", i)), now.Add(time.Duration(i+1)*time.Second))
+
+		assert.LessOrEqual(t, penalties.size(), capacity)
+	}
+
+	// The connected peer is never evicted
+	assert.Equal(t, 1, penalties.get(connectedID, time.Now()))
+
+	// The most recently penalized peers are kept, while the old ones are evicted
+	for i := total - capacity + 1; i < total; i++ {
+		assert.Equal(t, 1, penalties.get(peer.ID(fmt.Sprintf("Peer%d", i)), time.Now()))
+	}
+
+	assert.Equal(t, 0, penalties.get(peer.ID("Peer0"), time.Now()))
+}
+
+func TestQuarantinePeer_MalformedHandshake(t *testing.T) {
+	servers, createErr := createServers(2, map[int]*CreateServerParams{
+		0: {ConfigCallback: func(c *Config) { c.NoDiscover = true }},
+		1: {ConfigCallback: func(c *Config) { c.NoDiscover = true }},
+	})
+	require.NoError(t, createErr)
+
+	t.Cleanup(func() {
+		closeTestServers(t, servers)
+	})
+
+	server, malicious := servers[0], servers[1]
+	maliciousID := malicious.host.ID()
+
+	// Replace the identity service of the malicious peer
+	grpcStream := grpc.NewGrpcStream()
+	proto.RegisterIdentityServer(grpcStream.GrpcServer(), &malformedIdentityService{
+		chainID: malicious.config.Chain.Params.ChainID,
+	})
+	grpcStream.Serve()
+	malicious.RegisterProtocol(common.IdentityProto, grpcStream)
+
+	require.NoError(t, server.joinPeer(malicious.AddrInfo()))
+
+	require.Eventually(t, func() bool {
+		return server.IsQuarantined(maliciousID) && !server.IsConnected(maliciousID)
+	}, DefaultJoinTimeout, 50*time.Millisecond)
+
+	assert.Equal(t, 1, server.PeerPenalty(maliciousID))
+	assert.False(t, server.hasPeer(maliciousID))
+
+	// The quarantined peer is not redialed
+	require.NoError(t, server.joinPeer(malicious.AddrInfo()))
+	assert.Never(t, func() bool {
+		return server.IsConnected(maliciousID)
+	}, time.Second, 50*time.Millisecond)
+
+	// The quarantined peer can't connect either
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultJoinTimeout)
+	defer cancel()
+
+	_ = malicious.host.Connect(ctx, *server.AddrInfo())
+
+	assert.Never(t, func() bool {
+		return server.IsConnected(maliciousID)
+	}, time.Second, 50*time.Millisecond)
+}
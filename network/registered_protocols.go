@@ -0,0 +1,32 @@
+package network
+
+import (
+	"sort"
+
+	"github.com/libp2p/go-libp2p/core/protocol"
+)
+
+// UnregisterProtocol stops serving the protocol. The streams already open are not affected [Thread safe]
+func (s *Server) UnregisterProtocol(id string) {
+	s.protocolsLock.Lock()
+	defer s.protocolsLock.Unlock()
+
+	delete(s.protocols, id)
+	s.host.RemoveStreamHandler(protocol.ID(id))
+}
+
+// RegisteredProtocols returns the sorted IDs of the protocols currently registered on the server.
+// Only the protocols registered as such are listed, not the bare stream handlers (e.g. ping, goodbye) [Thread safe]
+func (s *Server) RegisteredProtocols() []string {
+	s.protocolsLock.Lock()
+	defer s.protocolsLock.Unlock()
+
+	protocols := make([]string, 0, len(s.protocols))
+	for id := range s.protocols {
+		protocols = append(protocols, id)
+	}
+
+	sort.Strings(protocols)
+
+	return protocols
+}
@@ -0,0 +1,50 @@
+package network
+
+import (
+	"testing"
+
+	"github.com/0xPolygon/polygon-edge/network/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegisteredProtocols(t *testing.T) {
+	const (
+		protoA = "/test-a/0.1"
+		protoB = "/test-b/0.1"
+	)
+
+	servers, createErr := createServers(2, map[int]*CreateServerParams{
+		0: {ConfigCallback: func(c *Config) { c.NoDiscover = true }},
+		1: {ConfigCallback: func(c *Config) { c.NoDiscover = true }},
+	})
+	require.NoError(t, createErr)
+
+	t.Cleanup(func() {
+		closeTestServers(t, servers)
+	})
+
+	server, remote := servers[0], servers[1]
+
+	assert.Equal(t, []string{common.IdentityProto}, server.RegisteredProtocols())
+
+	server.RegisterProtocol(protoB, holdProtocol{})
+	server.RegisterProtocol(protoA, holdProtocol{})
+
+	assert.Equal(t, []string{common.IdentityProto, protoA, protoB}, server.RegisteredProtocols())
+
+	server.UnregisterProtocol(protoA)
+
+	assert.Equal(t, []string{common.IdentityProto, protoB}, server.RegisteredProtocols())
+
+	// The unregistered protocol is no longer served
+	require.NoError(t, JoinAndWait(remote, server, DefaultBufferTimeout, DefaultJoinTimeout))
+
+	_, err := remote.NewStream(protoA, server.host.ID())
+	assert.Error(t, err)
+
+	stream, err := remote.NewStream(protoB, server.host.ID())
+	require.NoError(t, err)
+
+	_ = stream.Close()
+}
@@ -0,0 +1,101 @@
+package network
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	helperCommon "github.com/0xPolygon/polygon-edge/helper/common"
+	"github.com/0xPolygon/polygon-edge/network/common"
+	"github.com/libp2p/go-libp2p/core/peer"
+)
+
+const (
+	// lastPeersFile is the file in the data directory holding the peers connected at the last shutdown
+	lastPeersFile = "last_peers.json"
+
+	// maxRestoredPeers is the maximum number of peers redialed after a restart
+	maxRestoredPeers = 256
+)
+
+// lastPeersPath returns the path of the file holding the peers connected at the last shutdown.
+// The peers are not persisted unless turned on, or if the node has no data directory
+func (s *Server) lastPeersPath() (string, bool) {
+	if !s.config.RestoreLastPeers || s.config.DataDir == "" {
+		return "", false
+	}
+
+	return filepath.Join(s.config.DataDir, lastPeersFile), true
+}
+
+// saveConnectedPeers persists the currently connected peers,
+// so they are redialed first on the next start
+func (s *Server) saveConnectedPeers() {
+	path, ok := s.lastPeersPath()
+	if !ok {
+		return
+	}
+
+	peers := make([]*peer.AddrInfo, 0)
+
+	for _, connInfo := range s.Peers() {
+		if peerInfo := s.GetPeerInfo(connInfo.Info.ID); len(peerInfo.Addrs) > 0 {
+			peers = append(peers, peerInfo)
+		}
+	}
+
+	data, err := json.Marshal(peers)
+	if err != nil {
+		s.logger.Warn("Unable to encode the connected peers", "err", err)
+
+		return
+	}
+
+	if err := helperCommon.SaveFileSafe(path, data, 0660); err != nil {
+		s.logger.Warn("Unable to save the connected peers", "path", path, "err", err)
+	}
+}
+
+// restoreLastPeers queues the peers connected at the last shutdown for dialing,
+// ahead of the peers found by the discovery, to minimize the reconnection gap after a restart
+func (s *Server) restoreLastPeers() {
+	path, ok := s.lastPeersPath()
+	if !ok {
+		return
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return
+	}
+
+	if err != nil {
+		s.logger.Warn("Unable to read the last connected peers", "path", path, "err", err)
+
+		return
+	}
+
+	var peers []*peer.AddrInfo
+	if err := json.Unmarshal(data, &peers); err != nil {
+		s.logger.Warn("Unable to decode the last connected peers", "path", path, "err", err)
+
+		return
+	}
+
+	if len(peers) > maxRestoredPeers {
+		peers = peers[:maxRestoredPeers]
+	}
+
+	s.logger.Info("Redialing the peers connected before the restart", "count", len(peers))
+
+	for _, peerInfo := range peers {
+		if peerInfo == nil || peerInfo.ID == s.host.ID() {
+			continue
+		}
+
+		s.AddToPeerStore(peerInfo)
+		s.addToDialQueue(peerInfo, common.PriorityRestoredDial, PeerSourcePeerstore)
+	}
+}
@@ -0,0 +1,112 @@
+package network
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/0xPolygon/polygon-edge/network/common"
+	"github.com/0xPolygon/polygon-edge/network/dial"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRestoreLastPeers(t *testing.T) {
+	dataDir := t.TempDir()
+
+	servers, createErr := createServers(3, map[int]*CreateServerParams{
+		0: {ConfigCallback: func(c *Config) {
+			c.NoDiscover = true
+			c.DataDir = dataDir
+			c.RestoreLastPeers = true
+		}},
+		1: {ConfigCallback: func(c *Config) { c.NoDiscover = true }},
+		2: {ConfigCallback: func(c *Config) { c.NoDiscover = true }},
+	})
+	require.NoError(t, createErr)
+
+	t.Cleanup(func() {
+		closeTestServers(t, servers[1:])
+	})
+
+	server, peers := servers[0], servers[1:]
+	serverID := server.host.ID()
+
+	for _, peerServer := range peers {
+		require.NoError(t, JoinAndWait(server, peerServer, DefaultBufferTimeout, DefaultJoinTimeout))
+	}
+
+	// The connected peers are saved on shutdown
+	require.NoError(t, server.Close())
+
+	randomPeers, err := generateRandomPeers(t, 1)
+	require.NoError(t, err)
+
+	discoveredInfo := &peer.AddrInfo{ID: randomPeers[0].peerID, Addrs: generateTestAddrs(t, 1)}
+	queued := make([]*dial.DialTask, 0)
+
+	restarted, createErr := CreateServer(&CreateServerParams{
+		ConfigCallback: func(c *Config) {
+			c.NoDiscover = true
+			c.DataDir = dataDir
+			c.RestoreLastPeers = true
+		},
+		ServerCallback: func(server *Server) {
+			// The dial loop is not running yet, so the dial queue can be inspected
+			server.addToDialQueue(discoveredInfo, common.PriorityRandomDial, PeerSourceDiscovery)
+			server.restoreLastPeers()
+
+			for task := server.dialQueue.PopTask(); task != nil; task = server.dialQueue.PopTask() {
+				queued = append(queued, task)
+			}
+		},
+	})
+	require.NoError(t, createErr)
+
+	t.Cleanup(func() {
+		require.NoError(t, restarted.Close())
+	})
+
+	require.Equal(t, serverID, restarted.host.ID())
+
+	// The restored peers are dialed ahead of the discovered one
+	require.Len(t, queued, 3)
+	assert.ElementsMatch(t,
+		[]peer.ID{peers[0].host.ID(), peers[1].host.ID()},
+		[]peer.ID{queued[0].GetAddrInfo().ID, queued[1].GetAddrInfo().ID},
+	)
+	assert.Equal(t, common.PriorityRestoredDial, queued[0].GetPriority())
+	assert.Equal(t, discoveredInfo.ID, queued[2].GetAddrInfo().ID)
+
+	// The peers are reconnected on start
+	require.Eventually(t, func() bool {
+		return restarted.IsConnected(peers[0].host.ID()) && restarted.IsConnected(peers[1].host.ID())
+	}, DefaultJoinTimeout, 50*time.Millisecond)
+}
+
+func TestRestoreLastPeers_Disabled(t *testing.T) {
+	dataDir := t.TempDir()
+
+	servers, createErr := createServers(2, map[int]*CreateServerParams{
+		0: {ConfigCallback: func(c *Config) {
+			c.NoDiscover = true
+			c.DataDir = dataDir
+		}},
+		1: {ConfigCallback: func(c *Config) { c.NoDiscover = true }},
+	})
+	require.NoError(t, createErr)
+
+	t.Cleanup(func() {
+		closeTestServers(t, servers[1:])
+	})
+
+	require.NoError(t, JoinAndWait(servers[0], servers[1], DefaultBufferTimeout, DefaultJoinTimeout))
+	require.NoError(t, servers[0].Close())
+
+	// Nothing is written to the data directory unless turned on
+	_, err := os.Stat(filepath.Join(dataDir, lastPeersFile))
+	assert.ErrorIs(t, err, fs.ErrNotExist)
+}
@@ -0,0 +1,98 @@
+package network
+
+import (
+	"errors"
+	"net"
+
+	"github.com/armon/go-metrics"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/multiformats/go-multiaddr"
+	madns "github.com/multiformats/go-multiaddr-dns"
+	manet "github.com/multiformats/go-multiaddr/net"
+)
+
+var ErrNoRoutableAddrs = errors.New("no routable peer addresses")
+
+// RoutableAddrFilter reports if the peer address can yield a useful connection
+type RoutableAddrFilter func(addr multiaddr.Multiaddr) bool
+
+// isPublicAddr is the default routable address filter of public nodes.
+// It rejects the loopback, link-local and private addresses,
+// while the DNS addresses are considered routable
+func isPublicAddr(addr multiaddr.Multiaddr) bool {
+	return madns.Matches(addr) || manet.IsPublicAddr(addr)
+}
+
+// isPublicIP checks if the IP address is routable on the public internet
+func isPublicIP(ip net.IP) bool {
+	if ip == nil {
+		return false
+	}
+
+	addr, err := manet.FromIP(ip)
+
+	return err == nil && manet.IsPublicAddr(addr)
+}
+
+// hasPublicListenAddr checks if the node listens on any public address
+func hasPublicListenAddr(config *Config) bool {
+	if len(config.ListenAddrs) == 0 {
+		return config.Addr != nil && isPublicIP(config.Addr.IP)
+	}
+
+	for _, addr := range config.ListenAddrs {
+		if manet.IsPublicAddr(addr) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// newRoutableAddrFilter returns the routable address filter for the node.
+// Nodes without a public address (e.g. in local networks) dial any address,
+// unless a filter is configured explicitly
+func newRoutableAddrFilter(config *Config) RoutableAddrFilter {
+	if config.RoutableAddrFilter != nil {
+		return config.RoutableAddrFilter
+	}
+
+	public := config.DNS != nil || isPublicIP(config.NatAddr) || hasPublicListenAddr(config)
+
+	if !public {
+		return nil
+	}
+
+	return isPublicAddr
+}
+
+// hasRoutableAddr checks if any of the peer addresses is routable for the node.
+// Peers without known addresses are not judged, as the peer store may hold their addresses
+func (s *Server) hasRoutableAddr(addrInfo *peer.AddrInfo) bool {
+	if s.routableAddr == nil || len(addrInfo.Addrs) == 0 {
+		return true
+	}
+
+	for _, addr := range addrInfo.Addrs {
+		if s.routableAddr(addr) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// skipUnroutablePeer records that the dial to the peer was skipped,
+// since none of its addresses are routable
+func (s *Server) skipUnroutablePeer(addrInfo *peer.AddrInfo) {
+	s.unroutableSkipped.Add(1)
+	metrics.IncrCounter([]string{networkMetrics, "unroutable_peers_skipped"}, 1)
+
+	s.logger.Debug("Skipping dial to peer with no routable addresses", "addr", addrInfo)
+}
+
+// UnroutablePeersSkipped returns the number of dials skipped
+// because the peer had no routable addresses [Thread safe]
+func (s *Server) UnroutablePeersSkipped() int64 {
+	return s.unroutableSkipped.Load()
+}
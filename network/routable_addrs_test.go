@@ -0,0 +1,84 @@
+package network
+
+import (
+	"net"
+	"testing"
+
+	"github.com/0xPolygon/polygon-edge/network/common"
+	"github.com/0xPolygon/polygon-edge/network/dial"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/multiformats/go-multiaddr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAddToDialQueue_UnroutablePeers(t *testing.T) {
+	randomPeers, err := generateRandomPeers(t, 2)
+	require.NoError(t, err)
+
+	loopbackInfo := &peer.AddrInfo{
+		ID:    randomPeers[0].peerID,
+		Addrs: []multiaddr.Multiaddr{multiaddr.StringCast("/ip4/127.0.0.1/tcp/1478")},
+	}
+	publicInfo := &peer.AddrInfo{
+		ID: randomPeers[1].peerID,
+		Addrs: []multiaddr.Multiaddr{
+			multiaddr.StringCast("/ip4/169.254.0.1/tcp/1478"),
+			multiaddr.StringCast("/ip4/8.8.8.8/tcp/1478"),
+		},
+	}
+
+	queued := make([]*dial.DialTask, 0)
+
+	server, createErr := CreateServer(&CreateServerParams{
+		ConfigCallback: func(c *Config) {
+			c.NoDiscover = true
+			c.NatAddr = net.ParseIP("1.2.3.4")
+		},
+		ServerCallback: func(server *Server) {
+			// The dial loop is not running yet, so the dial queue can be inspected
+			assert.False(t, server.addToDialQueue(loopbackInfo, common.PriorityRandomDial, PeerSourceDiscovery))
+			assert.True(t, server.addToDialQueue(publicInfo, common.PriorityRandomDial, PeerSourceDiscovery))
+
+			for task := server.dialQueue.PopTask(); task != nil; task = server.dialQueue.PopTask() {
+				queued = append(queued, task)
+			}
+		},
+	})
+	require.NoError(t, createErr)
+
+	t.Cleanup(func() {
+		require.NoError(t, server.Close())
+	})
+
+	require.Len(t, queued, 1)
+	assert.Equal(t, publicInfo.ID, queued[0].GetAddrInfo().ID)
+	assert.Equal(t, int64(1), server.UnroutablePeersSkipped())
+
+	// Explicit joins are rejected as well, without holding a pending join
+	assert.ErrorIs(t, server.joinPeer(loopbackInfo), ErrNoRoutableAddrs)
+	assert.Zero(t, server.joins.count())
+}
+
+func TestNewRoutableAddrFilter(t *testing.T) {
+	loopback := multiaddr.StringCast("/ip4/127.0.0.1/tcp/1478")
+
+	// Local nodes dial any address
+	assert.Nil(t, newRoutableAddrFilter(DefaultConfig()))
+
+	config := DefaultConfig()
+	config.DNS = multiaddr.StringCast("/dns4/example.com/tcp/1478")
+
+	filter := newRoutableAddrFilter(config)
+	require.NotNil(t, filter)
+	assert.False(t, filter(loopback))
+	assert.True(t, filter(multiaddr.StringCast("/dns4/example.com/tcp/1478")))
+
+	// The configured filter is always used
+	config = DefaultConfig()
+	config.RoutableAddrFilter = func(multiaddr.Multiaddr) bool { return true }
+
+	filter = newRoutableAddrFilter(config)
+	require.NotNil(t, filter)
+	assert.True(t, filter(loopback))
+}
@@ -0,0 +1,137 @@
+package network
+
+import (
+	"sync"
+	"time"
+
+	"github.com/libp2p/go-libp2p/core/crypto"
+	"github.com/libp2p/go-libp2p/core/network"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/libp2p/go-libp2p/core/protocol"
+)
+
+// maxSecuritySessions is the maximum number of peer session records kept.
+// The records outlive the connections, so the least recently established ones are dropped first
+const maxSecuritySessions = 4096
+
+// SecurityParams describes the security context of a connection to a peer
+type SecurityParams struct {
+	PeerID        peer.ID       // the ID of the remote peer
+	PublicKey     crypto.PubKey // the identity key the remote peer authenticated with
+	Security      protocol.ID   // the negotiated security protocol (e.g. noise)
+	Muxer         protocol.ID   // the negotiated stream multiplexer
+	EstablishedAt time.Time     // the time the secure session was established
+}
+
+// KeyChanged checks if the remote identity key differs from the one in the other parameters
+func (p SecurityParams) KeyChanged(other SecurityParams) bool {
+	if p.PublicKey == nil || other.PublicKey == nil {
+		return p.PublicKey != other.PublicKey
+	}
+
+	return !p.PublicKey.Equals(other.PublicKey)
+}
+
+// SecurityChangeHandler is notified when a connection to a peer is re-established
+// with a new secure session. Every re-handshake rotates the session keys, so protocols
+// caching the security context of the peer should refresh it from the current parameters
+type SecurityChangeHandler func(previous, current SecurityParams)
+
+// newSecurityParams extracts the security parameters of the connection
+func newSecurityParams(conn network.Conn) SecurityParams {
+	state := conn.ConnState()
+
+	return SecurityParams{
+		PeerID:        conn.RemotePeer(),
+		PublicKey:     conn.RemotePublicKey(),
+		Security:      state.Security,
+		Muxer:         state.StreamMultiplexer,
+		EstablishedAt: conn.Stat().Opened,
+	}
+}
+
+// securityTracker keeps the security parameters of the last session with each peer
+type securityTracker struct {
+	sync.Mutex
+
+	sessions *boundedPeerRecords     // peerID -> parameters of the last established session
+	handlers []SecurityChangeHandler // the handlers notified of the re-established sessions
+}
+
+// newSecurityTracker creates a new security parameters tracker
+func newSecurityTracker() *securityTracker {
+	return &securityTracker{
+		sessions: newBoundedPeerRecords(maxSecuritySessions, nil),
+	}
+}
+
+// addHandler registers the handler notified of the re-established sessions [Thread safe]
+func (t *securityTracker) addHandler(handler SecurityChangeHandler) {
+	t.Lock()
+	defer t.Unlock()
+
+	t.handlers = append(t.handlers, handler)
+}
+
+// update records the parameters of a newly established session, and notifies
+// the handlers if a session with the peer was established before [Thread safe]
+func (t *securityTracker) update(current SecurityParams) {
+	t.Lock()
+
+	previous, existed := t.getLocked(current.PeerID)
+
+	t.sessions.add(current.PeerID, current)
+
+	handlers := make([]SecurityChangeHandler, len(t.handlers))
+	copy(handlers, t.handlers)
+
+	t.Unlock()
+
+	if !existed {
+		return
+	}
+
+	for _, handler := range handlers {
+		handler(previous, current)
+	}
+}
+
+// get returns the parameters of the last session with the peer [Thread safe]
+func (t *securityTracker) get(peerID peer.ID) (SecurityParams, bool) {
+	t.Lock()
+	defer t.Unlock()
+
+	return t.getLocked(peerID)
+}
+
+// getLocked returns the parameters of the last session with the peer. The lock has to be held
+func (t *securityTracker) getLocked(peerID peer.ID) (SecurityParams, bool) {
+	record, ok := t.sessions.get(peerID)
+	if !ok {
+		return SecurityParams{}, false
+	}
+
+	params, _ := record.(SecurityParams)
+
+	return params, true
+}
+
+// remove removes the session record of the peer [Thread safe]
+func (t *securityTracker) remove(peerID peer.ID) {
+	t.Lock()
+	defer t.Unlock()
+
+	t.sessions.remove(peerID)
+}
+
+// RegisterSecurityChangeHandler registers the handler notified when a connection to a peer
+// is re-established with new security parameters. The handler is called synchronously
+// from the connection notifications, so it should not block [Thread safe]
+func (s *Server) RegisterSecurityChangeHandler(handler SecurityChangeHandler) {
+	s.securitySessions.addHandler(handler)
+}
+
+// GetSecurityParams returns the security parameters of the last session with the peer [Thread safe]
+func (s *Server) GetSecurityParams(peerID peer.ID) (SecurityParams, bool) {
+	return s.securitySessions.get(peerID)
+}
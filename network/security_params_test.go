@@ -0,0 +1,134 @@
+package network
+
+import (
+	"crypto/rand"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/libp2p/go-libp2p/core/crypto"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSecurityTracker_KeyChange(t *testing.T) {
+	tracker := newSecurityTracker()
+	peerID := peer.ID("RandomPeer")
+
+	oldKey, _, err := crypto.GenerateEd25519Key(rand.Reader)
+	require.NoError(t, err)
+
+	newKey, _, err := crypto.GenerateEd25519Key(rand.Reader)
+	require.NoError(t, err)
+
+	notices := make([][2]SecurityParams, 0)
+	tracker.addHandler(func(previous, current SecurityParams) {
+		notices = append(notices, [2]SecurityParams{previous, current})
+	})
+
+	// The first session is not a re-establishment
+	tracker.update(SecurityParams{PeerID: peerID, PublicKey: oldKey.GetPublic()})
+	assert.Empty(t, notices)
+
+	tracker.update(SecurityParams{PeerID: peerID, PublicKey: newKey.GetPublic()})
+	require.Len(t, notices, 1)
+
+	previous, current := notices[0][0], notices[0][1]
+	assert.True(t, current.KeyChanged(previous))
+	assert.True(t, current.PublicKey.Equals(newKey.GetPublic()))
+
+	params, ok := tracker.get(peerID)
+	require.True(t, ok)
+	assert.Equal(t, current, params)
+
+	tracker.remove(peerID)
+
+	_, ok = tracker.get(peerID)
+	assert.False(t, ok)
+}
+
+func TestSecurityTracker_Capacity(t *testing.T) {
+	tracker := newSecurityTracker()
+	start := time.Now()
+
+	for i := 0; i < maxSecuritySessions+10; i++ {
+		tracker.update(SecurityParams{
+			PeerID:        peer.ID(fmt.Sprintf("peer-%d", i)),
+			EstablishedAt: start.Add(time.Duration(i) * time.Second),
+		})
+	}
+
+	// The least recently established sessions are dropped
+	assert.Equal(t, maxSecuritySessions, tracker.sessions.len())
+
+	_, ok := tracker.get(peer.ID("peer-0"))
+	assert.False(t, ok)
+
+	_, ok = tracker.get(peer.ID(fmt.Sprintf("peer-%d", maxSecuritySessions+9)))
+	assert.True(t, ok)
+}
+
+func TestSecurityChangeHandler_Reconnect(t *testing.T) {
+	servers, createErr := createServers(2, map[int]*CreateServerParams{
+		0: {ConfigCallback: func(c *Config) { c.NoDiscover = true }},
+		1: {ConfigCallback: func(c *Config) { c.NoDiscover = true }},
+	})
+	require.NoError(t, createErr)
+
+	t.Cleanup(func() {
+		closeTestServers(t, servers)
+	})
+
+	server, peerServer := servers[0], servers[1]
+	peerID := peerServer.host.ID()
+
+	var (
+		noticesLock sync.Mutex
+		notices     = make([][2]SecurityParams, 0)
+	)
+
+	server.RegisterSecurityChangeHandler(func(previous, current SecurityParams) {
+		noticesLock.Lock()
+		defer noticesLock.Unlock()
+
+		notices = append(notices, [2]SecurityParams{previous, current})
+	})
+
+	getNotices := func() [][2]SecurityParams {
+		noticesLock.Lock()
+		defer noticesLock.Unlock()
+
+		return append([][2]SecurityParams{}, notices...)
+	}
+
+	require.NoError(t, JoinAndWait(server, peerServer, DefaultBufferTimeout, DefaultJoinTimeout))
+
+	initial, ok := server.GetSecurityParams(peerID)
+	require.True(t, ok)
+	assert.NotEmpty(t, initial.Security)
+	assert.Empty(t, getNotices())
+
+	// Force a new handshake with the peer
+	require.NoError(t, server.host.Network().ClosePeer(peerID))
+	require.Eventually(t, func() bool {
+		return !server.hasPeer(peerID)
+	}, DefaultJoinTimeout, 50*time.Millisecond)
+
+	require.NoError(t, JoinAndWait(server, peerServer, DefaultBufferTimeout, DefaultJoinTimeout))
+
+	require.Eventually(t, func() bool {
+		return len(getNotices()) == 1
+	}, DefaultJoinTimeout, 50*time.Millisecond)
+
+	previous, current := getNotices()[0][0], getNotices()[0][1]
+	assert.Equal(t, initial, previous)
+	assert.Equal(t, peerID, current.PeerID)
+	assert.True(t, current.EstablishedAt.After(previous.EstablishedAt))
+	assert.False(t, current.KeyChanged(previous))
+
+	updated, ok := server.GetSecurityParams(peerID)
+	require.True(t, ok)
+	assert.Equal(t, current, updated)
+}
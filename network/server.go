@@ -4,7 +4,11 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
+	"net"
+	"strconv"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/0xPolygon/polygon-edge/network/common"
@@ -12,6 +16,7 @@ import (
 	"github.com/0xPolygon/polygon-edge/network/discovery"
 	"github.com/armon/go-metrics"
 	"github.com/libp2p/go-libp2p"
+	"github.com/libp2p/go-libp2p/p2p/protocol/holepunch"
 	"github.com/libp2p/go-libp2p/p2p/security/noise"
 	rawGrpc "google.golang.org/grpc"
 
@@ -22,6 +27,7 @@ import (
 	"github.com/libp2p/go-libp2p/core/crypto"
 	"github.com/libp2p/go-libp2p/core/event"
 	"github.com/libp2p/go-libp2p/core/host"
+	libp2pMetrics "github.com/libp2p/go-libp2p/core/metrics"
 	"github.com/libp2p/go-libp2p/core/network"
 	"github.com/libp2p/go-libp2p/core/peer"
 	"github.com/libp2p/go-libp2p/core/protocol"
@@ -41,6 +47,9 @@ const (
 
 	// networkMetrics is a prefix used for network-related metrics
 	networkMetrics = "network"
+
+	// peerConnectionsCheckInterval is the time between the checks of the minimum peer connections
+	peerConnectionsCheckInterval = 10 * time.Second
 )
 
 const (
@@ -56,6 +65,13 @@ const (
 var (
 	ErrNoBootnodes  = errors.New("no bootnodes specified")
 	ErrMinBootnodes = errors.New("minimum 1 bootnode is required")
+
+	ErrDiscoveryDisabled = errors.New("discovery is disabled")
+	ErrPubSubDisabled    = errors.New("pubsub is disabled")
+
+	ErrBootnodeNotAllowlisted = errors.New("bootnode is not allowlisted")
+
+	ErrNoListenPort = errors.New("no bound TCP listen port")
 )
 
 type Server struct {
@@ -72,7 +88,9 @@ type Server struct {
 
 	dialQueue *dial.DialQueue // queue used to asynchronously connect to peers
 
-	discovery *discovery.DiscoveryService // service used for discovering other peers
+	discovery        *discovery.DiscoveryService // the Kademlia discovery service, nil if another backend is used
+	discoveryStarted chan struct{}               // the channel closed once the discovery service starts crawling
+	discoverer       Discoverer                  // the running discovery backend, nil if the discovery is off
 
 	protocols     map[string]Protocol // supported protocols
 	protocolsLock sync.Mutex          // lock for the supported protocols map
@@ -81,13 +99,116 @@ type Server struct {
 
 	ps *pubsub.PubSub // reference to the networking PubSub service
 
+	topicMembership topicMembership // gossip topic membership of peers, used for ranking them
+
+	joinedTopics     map[string]*Topic // the currently joined gossip topics; name -> topic
+	joinedTopicsLock sync.RWMutex      // lock for the joined topics map
+
 	emitterPeerEvent event.Emitter // event emitter for listeners
 
 	connectionCounts *ConnectionInfo
 
 	temporaryDials sync.Map // map of temporary connections; peerID -> bool
 
+	goodbyes sync.Map // map of peers that said goodbye; peerID -> *goodbyeRecord
+
+	localDisconnects sync.Map // map of peers disconnected by this node; peerID -> struct{}
+
 	bootnodes *bootnodesWrapper // reference of all bootnodes for the node
+
+	bandwidthCounter *libp2pMetrics.BandwidthCounter // counter of the traffic sent and received by the node
+
+	protocolTraffic *protocolTrafficTracker // counter of the traffic per server protocol
+
+	trafficBaseline atomic.Pointer[libp2pMetrics.Stats] // the traffic totals on start
+
+	peerAddrs *peerAddrTracker // tracker of the successful peer addresses
+
+	dialRate    *dialRateLimiter // limiter of the dials to a single peer
+	dialBackoff *dialBackoff     // tracker of the backoffs after consecutive failed dials per peer
+
+	peerSources *peerSourceTracker // tracker of the ways the node learned about peers
+
+	lastDialFailures sync.Map // map of the last failed dials to peers; peerID -> *dialFailure
+
+	joins *pendingJoins // tracker of the join requests waiting to be dialed
+
+	dialRamp *dialRamp // limiter of the concurrent dials during the startup warm-up
+
+	dialSlots atomic.Pointer[ResizableSlots] // the outbound dial slots, resized when the limits change
+
+	dialQueueWait dialQueueWaitStats // the statistics of the time the dials wait in the dial queue
+
+	health internalHealthCounters // the counts of the running goroutines, by kind
+
+	dialing sync.WaitGroup // the running dial loop, which drains its in-flight dials before exiting
+
+	inFlightDials *inFlightDials // tracker of the dials in progress, which can be canceled per peer
+
+	disconnectLogs *disconnectLogLimiter // coalesces the log lines of the identical disconnects of a peer
+
+	defaultScorer PeerScorer                // the peer scorer used if none is configured
+	evictionLock  sync.Mutex                // serializes the evictions, so the same peer isn't evicted twice
+	evicting      map[peer.ID]chan struct{} // the peers being disconnected after an eviction, closed once they are removed
+
+	streamHandlers *streamHandlerTracker // tracker of the running protocol stream handlers, drained on shutdown
+
+	readyWaiters *readyWaiters // tracker of the dials waiting for the peers to become ready
+	slowConnects sync.Map      // map of the peers which exceeded the total connect deadline; peerID -> time.Time
+
+	protectedPeers sync.Map // map of peers exempt from pruning and trimming; peerID -> struct{}
+
+	pinnedPeers sync.Map // map of peers that are always reconnected; peerID -> *peer.AddrInfo
+
+	trimming atomic.Bool // flag indicating if the excess connections are being trimmed
+
+	idlePeers *idleTracker // tracker of the peer activity, pings and roles, used for detecting dead peers
+
+	securitySessions *securityTracker // tracker of the security parameters of the peer sessions
+
+	outboundTarget outboundTargetState // the state of the outbound peer target
+
+	gater *connectionGater // the gater deciding which peer connections are allowed
+
+	penalties *peerPenalties // tracker of the reputation penalties of misbehaving peers
+
+	gossipValidation *gossipValidationTracker // tracker of the gossip validation outcomes per peer
+
+	connProtocols *connProtocolTracker // tracker of the protocols used on the peer connections
+
+	streamActivity *streamActivityTracker // tracker of the protocol stream activity per peer
+
+	peerHistory *peerHistoryTracker // tracker of the recent connection history per peer
+	peerUptime  *peerUptimeTracker  // tracker of the recent connection intervals per peer
+
+	gossipFlush *gossipFlushTracker // tracker of the outbound gossip queues, nil if the pubsub is turned off
+
+	gossipReceipt gossipReceiptState // the state of the gossip receipt, used for detecting isolation
+
+	versionDiversity versionDiversityState // the state of the peer software version diversity check
+
+	protocolCursors *protocolCursors // the round-robin state of the peer selection per protocol
+
+	reachability reachabilityState // the result of the last public reachability check
+
+	connLimits connLimitsState // the configured connection limits and the node reachability
+
+	routableAddr      RoutableAddrFilter // the filter of the peer addresses worth dialing, all are if not set
+	unroutableSkipped atomic.Int64       // the number of dials skipped due to no routable peer addresses
+
+	openStreams *openStreamTracker // tracker of the streams open node-wide
+
+	peerstoreDatastore io.Closer // the datastore backing the peerstore, nil if the peerstore is in-memory
+
+	natMapping natMappingSource // the source of the NAT port mapping status, nil if the port mapping is turned off
+
+	clock Clock // the source of time for the timeouts and backoffs
+
+	connStates *connStateTracker // tracker of the detailed connection states, nil if they are not traced
+
+	addrsLock      sync.RWMutex               // lock for the advertised addresses, refreshed on network changes
+	holePunching   *holePunchTracer           // the tracer of the relayed connection upgrades, nil if they are turned off
+	interfaceAddrs func() ([]net.Addr, error) // lists the local network interface addresses
 }
 
 // NewServer returns a new instance of the networking server
@@ -99,14 +220,22 @@ func NewServer(logger hclog.Logger, config *Config) (*Server, error) {
 		return nil, err
 	}
 
-	listenAddr, err := multiaddr.NewMultiaddr(fmt.Sprintf("/ip4/%s/tcp/%d", config.Addr.IP.String(), config.Addr.Port))
+	listenAddrs, err := listenMultiaddrs(config)
 	if err != nil {
 		return nil, err
 	}
 
+	// The advertised port is only known once the host is bound,
+	// in case a random port (0) or custom listen addresses are requested
+	var advertisedPort atomic.Int64
+
+	if len(config.ListenAddrs) == 0 {
+		advertisedPort.Store(int64(config.Addr.Port))
+	}
+
 	addrsFactory := func(addrs []multiaddr.Multiaddr) []multiaddr.Multiaddr {
 		if config.NatAddr != nil {
-			addr, _ := multiaddr.NewMultiaddr(fmt.Sprintf("/ip4/%s/tcp/%d", config.NatAddr.String(), config.Addr.Port))
+			addr, _ := multiaddr.NewMultiaddr(fmt.Sprintf("/ip4/%s/tcp/%d", config.NatAddr.String(), advertisedPort.Load()))
 
 			if addr != nil {
 				addrs = []multiaddr.Multiaddr{addr}
@@ -118,17 +247,137 @@ func NewServer(logger hclog.Logger, config *Config) (*Server, error) {
 		return addrs
 	}
 
-	host, err := libp2p.New(
+	bandwidthCounter := libp2pMetrics.NewBandwidthCounter()
+	gater := newConnectionGater(config)
+
+	resourceManager, err := newResourceManager(gater)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create resource manager, %w", err)
+	}
+
+	resolver, err := newMultiaddrResolver(config)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create DNS resolver, %w", err)
+	}
+
+	opts := []libp2p.Option{
 		// Use noise as the encryption protocol
 		libp2p.Security(noise.ID, noise.New),
-		libp2p.ListenAddrs(listenAddr),
+		// The listen addresses are bound once the host is created.
+		// The relay transport is kept, which is otherwise turned off along with the default listen addresses
+		libp2p.EnableRelay(),
+		libp2p.NoListenAddrs,
 		libp2p.AddrsFactory(addrsFactory),
 		libp2p.Identity(key),
-	)
+		libp2p.BandwidthReporter(bandwidthCounter),
+		libp2p.ConnectionGater(gater),
+		libp2p.ResourceManager(resourceManager),
+		libp2p.MultiaddrResolver(resolver),
+	}
+
+	muxerOpts, err := muxerOptions(config.Muxers)
 	if err != nil {
+		return nil, fmt.Errorf("unable to set up stream multiplexers, %w", err)
+	}
+
+	opts = append(opts, muxerOpts...)
+
+	if config.AgentVersion != "" {
+		opts = append(opts, libp2p.UserAgent(config.AgentVersion))
+	}
+
+	// The in-memory peerstore is used, unless a datastore path is set
+	var peerstoreDatastore io.Closer
+
+	if config.PeerstoreDatastorePath != "" {
+		ps, datastore, err := newPersistentPeerstore(config.PeerstoreDatastorePath)
+		if err != nil {
+			return nil, err
+		}
+
+		opts = append(opts, libp2p.Peerstore(ps))
+		peerstoreDatastore = datastore
+	} else if config.Clock != nil {
+		// The address TTLs follow the configured clock
+		ps, err := newClockedPeerstore(config.Clock)
+		if err != nil {
+			return nil, err
+		}
+
+		opts = append(opts, libp2p.Peerstore(ps))
+	}
+
+	if config.EnableRelayService {
+		// The relay service is only started for publicly reachable nodes,
+		// so the node operator is trusted on the reachability
+		opts = append(opts,
+			libp2p.EnableRelayService(),
+			libp2p.ForceReachabilityPublic(),
+		)
+	}
+
+	// Relayed connections are upgraded to direct ones, if the peers can reach each other.
+	// Only nodes behind a NAT, reached over relayed connections, need it, so it is opt-in.
+	// The outcomes are traced, so the upgrades are reported
+	var holePunching *holePunchTracer
+
+	if config.EnableHolePunching {
+		holePunching = &holePunchTracer{}
+		opts = append(opts, libp2p.EnableHolePunching(holepunch.WithTracer(holePunching)))
+	}
+
+	// The NAT manager is kept to report the port mapping status
+	var natMapping *libp2pNATMapping
+
+	if config.EnableNATPortMap {
+		natMapping = &libp2pNATMapping{}
+		opts = append(opts, libp2p.NATManager(natMapping.newNATManager))
+	}
+
+	host, err := libp2p.New(opts...)
+	if err != nil {
+		if peerstoreDatastore != nil {
+			_ = peerstoreDatastore.Close()
+		}
+
 		return nil, fmt.Errorf("failed to create libp2p stack: %w", err)
 	}
 
+	// The host and the peerstore datastore are released if the server fails to be set up.
+	// The cleanup is cancelled once the server is returned
+	cleanup := func() {
+		_ = host.Close()
+
+		if peerstoreDatastore != nil {
+			_ = peerstoreDatastore.Close()
+		}
+	}
+
+	defer func() {
+		if cleanup != nil {
+			cleanup()
+		}
+	}()
+
+	if err := listenOn(host, listenAddrs); err != nil {
+		return nil, err
+	}
+
+	if advertisedPort.Load() == 0 {
+		// Custom listen addresses without a TCP one only need a port for the NAT address
+		boundPort, err := boundTCPPort(host)
+		if err != nil && (len(config.ListenAddrs) == 0 || config.NatAddr != nil) {
+			return nil, err
+		}
+
+		advertisedPort.Store(int64(boundPort))
+	}
+
+	// All streams (including pubsub ones) share a single outbound token bucket, if set
+	if limiter := newBandwidthLimiter(config.MaxOutboundBandwidth); limiter != nil {
+		host = newThrottledHost(host, limiter)
+	}
+
 	emitter, err := host.EventBus().Emitter(new(peerEvent.PeerEvent))
 	if err != nil {
 		return nil, err
@@ -140,11 +389,42 @@ func NewServer(logger hclog.Logger, config *Config) (*Server, error) {
 		host:             host,
 		addrs:            host.Addrs(),
 		peers:            make(map[peer.ID]*PeerConnInfo),
-		dialQueue:        dial.NewDialQueue(),
+		dialQueue:        dial.NewDialQueue(dial.WithClock(configuredClock(config))),
 		closeCh:          make(chan struct{}),
+		discoveryStarted: make(chan struct{}),
+		joinedTopics:     make(map[string]*Topic),
 		emitterPeerEvent: emitter,
 		protocols:        map[string]Protocol{},
 		secretsManager:   config.SecretsManager,
+		bandwidthCounter: bandwidthCounter,
+		protocolTraffic:  newProtocolTrafficTracker(),
+		peerAddrs:        newPeerAddrTracker(),
+		dialRate:         newDialRateLimiter(config.MaxDialsPerPeer, config.DialRateWindow),
+		dialBackoff:      newDialBackoff(config.DialBackoffBase, config.DialBackoffMax),
+		joins:            newPendingJoins(config.MaxPendingJoins),
+		dialRamp:         newDialRamp(config.DialRampInitial, config.DialRampWindow, configuredClock(config)),
+		idlePeers:        newIdleTracker(),
+		readyWaiters:     newReadyWaiters(),
+		inFlightDials:    newInFlightDials(),
+		disconnectLogs:   newDisconnectLogLimiter(config.DisconnectLogWindow),
+		streamHandlers:   newStreamHandlerTracker(),
+		securitySessions: newSecurityTracker(),
+		penalties:        newPeerPenalties(config.MaxPenaltyRecords, config.PenaltyMaxAge, hostConnectedness(host)),
+		peerSources:      newPeerSourceTracker(hostConnectedness(host)),
+		gossipValidation: newGossipValidationTracker(hostConnectedness(host)),
+		evicting:         make(map[peer.ID]chan struct{}),
+		connProtocols:    newConnProtocolTracker(),
+		peerHistory:      newPeerHistoryTracker(),
+		peerUptime:       newPeerUptimeTracker(),
+		streamActivity:   newStreamActivityTracker(),
+		protocolCursors:  newProtocolCursors(),
+		openStreams:      newOpenStreamTracker(),
+		gater:            gater,
+		routableAddr:     newRoutableAddrFilter(config),
+		connLimits: connLimitsState{
+			maxInbound:  config.MaxInboundPeers,
+			maxOutbound: config.MaxOutboundPeers,
+		},
 		bootnodes: &bootnodesWrapper{
 			bootnodeArr:       make([]*peer.AddrInfo, 0),
 			bootnodesMap:      make(map[peer.ID]*peer.AddrInfo),
@@ -154,32 +434,142 @@ func NewServer(logger hclog.Logger, config *Config) (*Server, error) {
 			config.MaxInboundPeers,
 			config.MaxOutboundPeers,
 		),
+		peerstoreDatastore: peerstoreDatastore,
+		clock:              configuredClock(config),
+		interfaceAddrs:     net.InterfaceAddrs,
+		holePunching:       holePunching,
+	}
+
+	srv.recordGossipReceipt()
+	srv.defaultScorer = NewDefaultPeerScorer(srv)
+	srv.dialQueue.SetPriorityAging(config.DialPriorityAging)
+
+	if holePunching != nil {
+		holePunching.server.Store(srv)
 	}
 
+	// A nil pointer is not stored, to keep the interface comparable to nil
+	if natMapping != nil {
+		srv.natMapping = natMapping
+	}
+
+	if config.TraceConnStates {
+		connStateEmitter, err := host.EventBus().Emitter(new(ConnStateEvent))
+		if err != nil {
+			return nil, err
+		}
+
+		srv.connStates = newConnStateTracker(connStateEmitter, logger)
+	}
+
+	// Node roles that don't gossip skip the pubsub queues and scoring entirely
+	if config.DisablePubSub {
+		cleanup = nil
+
+		return srv, nil
+	}
+
+	srv.gossipFlush = newGossipFlushTracker(host.ID())
+
 	// start gossip protocol
 	ps, err := pubsub.NewGossipSub(
 		context.Background(),
-		host, pubsub.WithPeerOutboundQueueSize(peerOutboundBufferSize),
+		&gossipHost{Host: host, flush: srv.gossipFlush},
+		pubsub.WithPeerOutboundQueueSize(peerOutboundBufferSize),
 		pubsub.WithValidateQueueSize(validateBufferSize),
+		pubsub.WithEventTracer(srv.gossipFlush),
 	)
 	if err != nil {
 		return nil, err
 	}
 
 	srv.ps = ps
+	srv.topicMembership = ps
+
+	cleanup = nil
 
 	return srv, nil
 }
 
+// boundTCPPort returns the TCP port the host is actually listening on
+func boundTCPPort(h host.Host) (int, error) {
+	for _, addr := range h.Network().ListenAddresses() {
+		rawPort, err := addr.ValueForProtocol(multiaddr.P_TCP)
+		if err != nil {
+			continue
+		}
+
+		port, err := strconv.Atoi(rawPort)
+		if err != nil {
+			return 0, fmt.Errorf("invalid listen port %s, %w", rawPort, err)
+		}
+
+		return port, nil
+	}
+
+	return 0, ErrNoListenPort
+}
+
+// BanIP disconnects from all the peers connected from the IP, and refuses
+// any further connections with it for the specified duration,
+// or until it is unbanned if the duration is not positive [Thread safe]
+func (s *Server) BanIP(ip net.IP, duration time.Duration) {
+	var until time.Time
+	if duration > 0 {
+		until = s.clock.Now().Add(duration)
+	}
+
+	s.gater.banIP(ip, until)
+
+	s.logger.Info("IP banned", "ip", ip, "duration", duration)
+
+	bannedPeers := make(map[peer.ID]struct{})
+
+	for _, conn := range s.host.Network().Conns() {
+		if s.gater.isAddrBanned(conn.RemoteMultiaddr()) {
+			bannedPeers[conn.RemotePeer()] = struct{}{}
+		}
+	}
+
+	for peerID := range bannedPeers {
+		s.DisconnectFromPeer(peerID, "IP banned")
+	}
+}
+
+// UnbanIP lifts the ban of the IP [Thread safe]
+func (s *Server) UnbanIP(ip net.IP) {
+	s.gater.unbanIP(ip)
+}
+
+// InboundBacklog returns the number of accepted inbound connections
+// which haven't finished the security handshake yet [Thread safe]
+func (s *Server) InboundBacklog() int {
+	return s.gater.inboundBacklog()
+}
+
 // HasFreeConnectionSlot checks if there are free connection slots in the specified direction [Thread safe]
 func (s *Server) HasFreeConnectionSlot(direction network.Direction) bool {
 	return s.connectionCounts.HasFreeConnectionSlot(direction)
 }
 
+// IsConnLimitExempt checks if the connection of the peer in the specified direction bypasses
+// the connection slot limits. Unless configured otherwise, the bootnodes connecting inbound
+// are accepted at inbound capacity, as they commonly connect back to the node [Thread safe]
+func (s *Server) IsConnLimitExempt(peerID peer.ID, direction network.Direction) bool {
+	if direction != network.DirInbound || s.config.LimitBootnodeInbound {
+		return false
+	}
+
+	return s.bootnodes.isBootnode(peerID)
+}
+
 // PeerConnInfo holds the connection information about the peer
 type PeerConnInfo struct {
-	Info peer.AddrInfo
+	Info       peer.AddrInfo
+	IsBootnode bool       // flag indicating if the peer is one of the set bootnodes
+	Source     PeerSource // the way the node learned about the peer
 
+	direction       network.Direction // the direction the peer is accounted in
 	connDirections  map[network.Direction]bool
 	protocolStreams map[string]*rawGrpc.ClientConn
 }
@@ -249,10 +639,26 @@ func (s *Server) Start() error {
 
 	s.logger.Info("LibP2P server running", "addr", addr)
 
+	s.recordTrafficBaseline()
+
+	// Set up the goodbye handler, so peers can announce deliberate disconnects
+	s.setupGoodbye()
+
+	// Set up the dial-back handler, so peers can check their public reachability
+	s.setupDialBack()
+
+	// Set up the peer exchange, so peers can share their connected peers
+	if s.config.EnablePex {
+		s.setupPex()
+	}
+
 	if setupErr := s.setupIdentity(); setupErr != nil {
 		return fmt.Errorf("unable to setup identity, %w", setupErr)
 	}
 
+	// Queue the peers connected before a restart ahead of the discovered ones
+	s.restoreLastPeers()
+
 	// Set up the peer discovery mechanism if needed
 	if !s.config.NoDiscover {
 		// Parse the bootnode data
@@ -260,18 +666,100 @@ func (s *Server) Start() error {
 			return fmt.Errorf("unable to parse bootnode data, %w", setupErr)
 		}
 
-		// Setup and start the discovery service
-		if setupErr := s.setupDiscovery(); setupErr != nil {
+		// Setup and start the discovery backend
+		discoverer := s.discoveryBackend()
+		if setupErr := discoverer.Start(s.feedDiscoveredPeer); setupErr != nil {
 			return fmt.Errorf("unable to setup discovery, %w", setupErr)
 		}
+
+		s.discoverer = discoverer
 	}
 
+	s.dialing.Add(1)
+
 	go s.runDial()
 	go s.keepAliveMinimumPeerConnections()
 
+	if s.config.DialFallbackInterval > 0 {
+		go s.runDialFallback()
+	}
+
+	if s.config.ReachabilityCheckInterval > 0 {
+		go s.runReachabilityChecks()
+	}
+
+	if checkInterval := s.idleCheckInterval(); checkInterval > 0 {
+		go s.runIdleChecks(checkInterval)
+	}
+
+	if s.config.PeerStreamIdleTimeout > 0 {
+		go s.runStreamIdleChecks()
+	}
+
+	if s.ps != nil && s.config.GossipSilenceThreshold > 0 {
+		go s.runGossipSilenceChecks()
+	}
+
+	if s.config.NetworkChangeCheckInterval > 0 {
+		go s.watchNetworkChanges()
+	}
+
+	if s.config.EnablePex {
+		go s.runPeerExchanges()
+	}
+
+	if err := s.watchPinnedDials(); err != nil {
+		return fmt.Errorf("unable to watch pinned peer dials, %w", err)
+	}
+
+	if err := s.watchReachability(); err != nil {
+		return fmt.Errorf("unable to watch node reachability, %w", err)
+	}
+
+	if err := s.watchPeerVersions(); err != nil {
+		return fmt.Errorf("unable to watch peer versions, %w", err)
+	}
+
+	if err := s.watchPeerProtocols(); err != nil {
+		return fmt.Errorf("unable to watch peer protocols, %w", err)
+	}
+
+	if s.discovery != nil {
+		if err := s.watchLocalAddrs(); err != nil {
+			return fmt.Errorf("unable to watch local addresses, %w", err)
+		}
+	}
+
 	// watch for disconnected peers
 	s.host.Network().Notify(&network.NotifyBundle{
+		ConnectedF: func(net network.Network, conn network.Conn) {
+			s.setConnState(conn.RemotePeer(), ConnStateConnected)
+
+			// Remember the dialed address, so it is preferred when limiting peer addresses
+			if conn.Stat().Direction == network.DirOutbound {
+				s.peerAddrs.markSuccess(conn.RemotePeer(), conn.RemoteMultiaddr(), s.clock.Now())
+			}
+
+			// Notify the protocols caching the security context of the peer
+			s.securitySessions.update(newSecurityParams(conn))
+
+			s.checkConnUpgraded(net, conn)
+		},
 		DisconnectedF: func(net network.Network, conn network.Conn) {
+			s.openStreams.connClosed(conn)
+
+			// A peer with other open connections is still connected
+			if len(net.ConnsToPeer(conn.RemotePeer())) > 0 {
+				s.refreshPeerDirections(conn.RemotePeer())
+
+				return
+			}
+
+			s.connProtocols.remove(conn.RemotePeer())
+			s.streamActivity.remove(conn.RemotePeer())
+			s.setConnState(conn.RemotePeer(), ConnStateClosed)
+			s.readyWaiters.notify(conn.RemotePeer(), false)
+
 			// Update the local connection metrics
 			s.removePeer(conn.RemotePeer())
 		},
@@ -307,6 +795,10 @@ func (s *Server) setupBootnodes() error {
 			continue
 		}
 
+		if !s.gater.isAllowed(bootnode.ID) {
+			return fmt.Errorf("%w: %s", ErrBootnodeNotAllowlisted, bootnode.ID)
+		}
+
 		bootnodesArr = append(bootnodesArr, bootnode)
 		bootnodesMap[bootnode.ID] = bootnode
 	}
@@ -320,6 +812,11 @@ func (s *Server) setupBootnodes() error {
 		bootnodeConnCount: 0,
 	}
 
+	// The bootnodes are parsed once, so their addresses must not expire from the peer store
+	for _, bootnode := range bootnodesArr {
+		s.storeBootnodeAddrs(bootnode.ID)
+	}
+
 	return nil
 }
 
@@ -328,25 +825,26 @@ func (s *Server) setupBootnodes() error {
 func (s *Server) keepAliveMinimumPeerConnections() {
 	for {
 		select {
-		case <-time.After(10 * time.Second):
+		case <-s.clock.After(peerConnectionsCheckInterval):
 		case <-s.closeCh:
 			return
 		}
 
 		if s.numPeers() < MinimumPeerConnections {
 			if s.config.NoDiscover || !s.bootnodes.hasBootnodes() {
-				// dial unconnected peer
-				randPeer := s.GetRandomPeer()
-				if randPeer != nil && !s.IsConnected(*randPeer) {
-					s.addToDialQueue(s.GetPeerInfo(*randPeer), common.PriorityRandomDial)
-				}
+				// dial random unconnected peers known from the peer store
+				s.dialRandomPeerstorePeers()
 			} else {
 				// dial random unconnected bootnode
 				if randomNode := s.GetRandomBootnode(); randomNode != nil {
-					s.addToDialQueue(randomNode, common.PriorityRandomDial)
+					s.addToDialQueue(randomNode, common.PriorityRandomDial, PeerSourceBootnode)
 				}
 			}
 		}
+
+		if !s.config.NoDiscover {
+			s.maintainBootnodeConnections()
+		}
 	}
 }
 
@@ -354,18 +852,37 @@ func (s *Server) keepAliveMinimumPeerConnections() {
 // Essentially, the networking server monitors for any open connection slots
 // and attempts to fill them as soon as they open up
 func (s *Server) runDial() {
-	slots := NewSlots(s.connectionCounts.maxOutboundConnectionCount)
+	defer s.dialing.Done()
+
+	s.dialSlots.Store(NewResizableSlots(s.connectionCounts.maxOutboundConnCount()))
+
+	startedAt := s.clock.Now()
+	s.dialRamp.start(startedAt)
+
 	ctx, cancel := context.WithCancel(context.Background())
 
+	// Once the loop exits, the in-flight dials are aborted, and waited for
+	var dials sync.WaitGroup
+
+	defer dials.Wait()
 	defer cancel()
 
+	// Closing the server aborts the waits for a dial slot as well
+	go func() {
+		select {
+		case <-s.closeCh:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
 	if err := s.Subscribe(ctx, func(event *peerEvent.PeerEvent) {
 		// Return back slot on PeerFailedToConnect or PeerDisconnected
 		switch event.Type {
 		case
 			peerEvent.PeerFailedToConnect,
 			peerEvent.PeerDisconnected:
-			slots.Release()
+			s.getDialSlots().Release()
 			s.logger.Debug("slot released", "event", event.Type, "peerID", event.PeerID)
 		}
 	}); err != nil {
@@ -380,6 +897,11 @@ func (s *Server) runDial() {
 		return
 	}
 
+	// Give the node connectivity a chance to come up before the first dials
+	if !s.waitStartupDialDelay() {
+		return
+	}
+
 	for {
 		if closed := s.dialQueue.Wait(ctx); closed {
 			// The dial queue is closed, no further dial tasks are incoming
@@ -394,23 +916,128 @@ func (s *Server) runDial() {
 
 			peerInfo := tt.GetAddrInfo()
 
+			s.joins.done(peerInfo.ID)
+
 			if s.IsConnected(peerInfo.ID) {
 				continue
 			}
 
-			s.logger.Debug("Waiting for a dialing slot", "addr", peerInfo, "local", s.host.ID())
+			if !s.gater.isAllowed(peerInfo.ID) {
+				s.logger.Debug("Skipping dial, peer is not allowlisted", "addr", peerInfo)
+
+				continue
+			}
+
+			if s.IsQuarantined(peerInfo.ID) {
+				s.logger.Debug("Skipping dial, peer is quarantined", "addr", peerInfo)
+
+				continue
+			}
+
+			if s.IsBanned(peerInfo.ID) {
+				s.logger.Debug("Skipping dial, peer is banned", "addr", peerInfo)
+
+				continue
+			}
+
+			if s.isInGoodbyeBackoff(peerInfo.ID) {
+				s.logger.Debug("Skipping dial, peer said goodbye recently", "addr", peerInfo)
+
+				continue
+			}
+
+			if s.connectDeadlineBackoffRemaining(peerInfo.ID) > 0 {
+				s.logger.Debug("Skipping dial, peer exceeded the connect deadline recently", "addr", peerInfo)
+
+				continue
+			}
+
+			if tt.GetPriority() != common.PriorityPinnedDial && s.dialBackoffRemaining(peerInfo.ID) > 0 {
+				s.logger.Debug("Skipping dial, peer is backed off after failed dials", "addr", peerInfo)
+
+				continue
+			}
+
+			if ok, retryAfter := s.dialRate.allow(peerInfo.ID, s.clock.Now()); !ok {
+				s.logger.Debug("Deferring dial, peer was dialed too often", "addr", peerInfo, "retry", retryAfter)
+
+				s.deferDial(peerInfo, tt.GetPriority(), retryAfter)
+
+				continue
+			}
+
+			s.logger.Debug(s.peerLogMsg("Waiting for a dialing slot", peerInfo.ID), "addr", peerInfo, "local", s.host.ID())
+
+			// The dial can be canceled from here on, while waiting for a slot as well
+			dialCtx, cancelDial := context.WithCancel(ctx)
+			removeDial := s.inFlightDials.add(peerInfo.ID, cancelDial)
+
+			if closed := s.getDialSlots().Take(ctx); closed {
+				removeDial()
+				cancelDial()
 
-			if closed := slots.Take(ctx); closed {
 				return
 			}
 
+			// Right after start, fewer dials are made concurrently
+			if !s.dialRamp.acquire(ctx, s.maxConcurrentDials()) {
+				removeDial()
+				cancelDial()
+
+				return
+			}
+
+			if dialCtx.Err() != nil {
+				s.logger.Debug("Skipping dial, the dial was canceled", "addr", peerInfo)
+
+				removeDial()
+				s.dialRamp.release()
+				s.getDialSlots().Release()
+
+				continue
+			}
+
+			s.recordDialQueueWait(tt)
+
+			dialDone := s.health.dialWorkers.start()
+
+			dials.Add(1)
+
 			// the connection process is async because it involves connection (here) +
 			// the handshake done in the identity service.
 			go func() {
-				s.logger.Debug("Dialing peer", "addr", peerInfo, "local", s.host.ID())
+				defer dials.Done()
+				defer dialDone()
+				defer s.dialRamp.release()
+				defer cancelDial()
+				defer removeDial()
+
+				s.logger.Debug(s.peerLogMsg("Dialing peer", peerInfo.ID), "addr", peerInfo, "local", s.host.ID())
 
-				if err := s.host.Connect(ctx, *peerInfo); err != nil {
-					s.logger.Debug("failed to dial", "addr", peerInfo, "err", err.Error())
+				s.setConnState(peerInfo.ID, ConnStateDialing)
+
+				if err := s.connectPeer(dialCtx, *peerInfo); err != nil {
+					s.logger.Debug(s.peerLogMsg("failed to dial", peerInfo.ID), "addr", peerInfo, "err", err.Error())
+
+					// A connection the peer opened in the meantime is kept
+					s.setConnStateFrom(peerInfo.ID, ConnStateDialing, ConnStateClosed)
+
+					s.recordDialFailure(peerInfo.ID, err)
+
+					switch {
+					case dialCtx.Err() != nil && ctx.Err() == nil:
+						// The dial was canceled on purpose, which tells nothing about the peer
+					case s.inStartupGrace(startedAt):
+						s.forgiveDialFailure(peerInfo.ID)
+					default:
+						s.backOffFailedDial(peerInfo.ID)
+					}
+
+					s.peerHistory.record(peerInfo.ID, PeerHistoryEntry{
+						At:      s.clock.Now(),
+						Outcome: PeerHistoryDialFailed,
+						Err:     err,
+					})
 
 					s.emitEvent(peerInfo.ID, peerEvent.PeerFailedToConnect)
 				}
@@ -470,18 +1097,59 @@ func (s *Server) GetProtocols(peerID peer.ID) ([]string, error) {
 // and updates relevant counters and metrics. It is called from the
 // disconnection callback of the libp2p network bundle (when the connection is closed)
 func (s *Server) removePeer(peerID peer.ID) {
-	s.logger.Info("Peer disconnected", "id", peerID)
+	s.logger.Info(s.peerLogMsg("Peer disconnected", peerID), "id", peerID)
+
+	_, deliberate := s.localDisconnects.LoadAndDelete(peerID)
 
 	// Remove the peer from the peers map
 	connectionInfo := s.removePeerInfo(peerID)
+	s.completeEviction(peerID)
+
 	if connectionInfo == nil {
 		// The peer wasn't present in the local peers info table
 		// so no action should be taken further
 		return
 	}
 
+	s.peerHistory.record(peerID, PeerHistoryEntry{At: s.clock.Now(), Outcome: PeerHistoryDisconnected, Local: deliberate})
+	s.peerUptime.disconnected(peerID, s.clock.Now())
+	s.gossipValidation.endSession(peerID)
+	s.peerAddrs.retain(peerID, s.host.Peerstore().Addrs(peerID))
+	s.idlePeers.remove(peerID)
+
 	// Emit the event alerting listeners
 	s.emitEvent(peerID, peerEvent.PeerDisconnected)
+
+	s.checkOutboundTarget(peerID)
+	s.checkVersionDiversity()
+
+	// Pinned peers are always redialed, while bootnodes
+	// that were disconnected by this node are not
+	if s.IsPinned(peerID) {
+		s.redialPinnedPeer(peerID)
+	} else if connectionInfo.IsBootnode && !deliberate {
+		s.redialBootnode(peerID)
+	}
+}
+
+// redialBootnode adds the disconnected bootnode back to the dial queue,
+// in case fewer bootnode connections than the minimum are active. Bootnodes are regular peers,
+// so they are redialed instead of waiting for the peer count to drop
+func (s *Server) redialBootnode(peerID peer.ID) {
+	select {
+	case <-s.closeCh:
+		// The networking server is shutting down
+		return
+	default:
+	}
+
+	if s.bootnodes.getBootnodeConnCount() >= s.minBootnodeConnections() {
+		return
+	}
+
+	if bootnode, ok := s.bootnodes.bootnodesMap[peerID]; ok {
+		s.addToDialQueue(bootnode, common.PriorityRandomDial, PeerSourceBootnode)
+	}
 }
 
 // removePeerInfo removes (pops) peer connection info from the networking
@@ -505,13 +1173,9 @@ func (s *Server) removePeerInfo(peerID peer.ID) *PeerConnInfo {
 	delete(s.peers, peerID)
 
 	// Update connection counters
-	for connDirection, active := range connectionInfo.connDirections {
-		if active {
-			s.connectionCounts.UpdateConnCountByDirection(-1, connDirection)
-			s.updateConnCountMetrics(connDirection)
-			s.updateBootnodeConnCount(peerID, -1)
-		}
-	}
+	s.connectionCounts.UpdateConnCountByDirection(-1, connectionInfo.direction)
+	s.updateConnCountMetrics(connectionInfo.direction)
+	s.updateBootnodeConnCount(peerID, -1)
 
 	metrics.SetGauge([]string{networkMetrics, "peers"}, float32(len(s.peers)))
 
@@ -529,16 +1193,29 @@ func (s *Server) updateBootnodeConnCount(peerID peer.ID, delta int64) {
 	}
 
 	s.bootnodes.increaseBootnodeConnCount(delta)
+
+	metrics.SetGauge([]string{networkMetrics, "bootnode_connections"}, float32(s.bootnodes.getBootnodeConnCount()))
 }
 
-// DisconnectFromPeer disconnects the networking server from the specified peer
+// DisconnectFromPeer disconnects the networking server from the specified peer.
+// The peer is sent a goodbye message before the connection is closed
 func (s *Server) DisconnectFromPeer(peer peer.ID, reason string) {
 	if s.host.Network().Connectedness(peer) == network.Connected {
-		s.logger.Info("Closing connection", "id", peer, "reason", reason)
+		s.logDisconnect(peer, reason)
 
-		if err := s.host.Network().ClosePeer(peer); err != nil {
-			s.logger.Error("Unable to gracefully close connection", "id", peer, "err", err)
-		}
+		s.sendGoodbye(peer, GoodbyeReasonDisconnect)
+
+		s.closePeer(peer)
+	}
+}
+
+// closePeer closes the connections with the peer right away, without a goodbye
+func (s *Server) closePeer(peer peer.ID) {
+	s.localDisconnects.Store(peer, struct{}{})
+	s.setConnState(peer, ConnStateDisconnecting)
+
+	if err := s.host.Network().ClosePeer(peer); err != nil {
+		s.logger.Error("Unable to gracefully close connection", "id", peer, "err", err)
 	}
 }
 
@@ -548,7 +1225,8 @@ var (
 	DefaultBufferTimeout = DefaultJoinTimeout + time.Second*5
 )
 
-// JoinPeer attempts to add a new peer to the networking server
+// JoinPeer attempts to add a new peer to the networking server.
+// Relayed addresses (<relay addr>/p2p/<relay>/p2p-circuit/p2p/<peer>) are supported as well
 func (s *Server) JoinPeer(rawPeerMultiaddr string) error {
 	// Parse the raw string to a MultiAddr format
 	parsedMultiaddr, err := multiaddr.NewMultiaddr(rawPeerMultiaddr)
@@ -563,33 +1241,69 @@ func (s *Server) JoinPeer(rawPeerMultiaddr string) error {
 	}
 
 	// Mark the peer as ripe for dialing (async)
-	s.joinPeer(peerInfo)
+	return s.joinPeer(peerInfo)
+}
 
-	return nil
+// DialByID resolves the addresses of the peer using the discovery service,
+// and creates a new dial task for it (for async joining)
+func (s *Server) DialByID(ctx context.Context, peerID peer.ID) error {
+	if s.config.NoDiscover || s.discovery == nil {
+		return ErrDiscoveryDisabled
+	}
+
+	peerInfo, err := s.discovery.FindPeer(ctx, peerID)
+	if err != nil {
+		return fmt.Errorf("unable to find peer %s, %w", peerID, err)
+	}
+
+	s.AddToPeerStore(peerInfo)
+
+	return s.requestDial(peerInfo, common.PriorityRequestedDial)
 }
 
-// joinPeer creates a new dial task for the peer (for async joining)
-func (s *Server) joinPeer(peerInfo *peer.AddrInfo) {
+// joinPeer creates a new dial task for the peer (for async joining), dialed ahead of
+// all the other dials but the pinned ones. Returns an error if too many join requests
+// are already waiting to be dialed
+func (s *Server) joinPeer(peerInfo *peer.AddrInfo) error {
+	return s.requestDial(peerInfo, common.PriorityJoinDial)
+}
+
+// requestDial creates a new dial task with the priority for the explicitly requested peer.
+// Returns an error if too many join requests are already waiting to be dialed
+func (s *Server) requestDial(peerInfo *peer.AddrInfo, priority common.DialPriority) error {
+	if err := s.joins.add(peerInfo.ID); err != nil {
+		s.logger.Warn("Rejecting join request", "addr", peerInfo, "err", err)
+
+		return err
+	}
+
 	s.logger.Info("Join request", "addr", peerInfo)
 
+	// An explicit join request overrides any backoff from a previous goodbye, slow connect or failed dials
+	s.goodbyes.Delete(peerInfo.ID)
+	s.slowConnects.Delete(peerInfo.ID)
+	s.dialBackoff.reset(peerInfo.ID)
+
 	// This method can be completely refactored to support some kind of active
 	// feedback information on the dial status, and not just asynchronous updates.
 	// For this feature to work, the networking server requires a flexible event subscription
 	// manager that is configurable and cancelable at any point in time
-	s.addToDialQueue(peerInfo, common.PriorityRequestedDial)
-}
-
-func (s *Server) Close() error {
-	err := s.host.Close()
-	s.dialQueue.Close()
+	if !s.addToDialQueue(peerInfo, priority, PeerSourceJoin) {
+		s.joins.done(peerInfo.ID)
 
-	if !s.config.NoDiscover {
-		s.discovery.Close()
+		return ErrNoRoutableAddrs
 	}
 
-	close(s.closeCh)
+	return nil
+}
+
+// Close gracefully stops the networking server, giving the in-flight work
+// the default shutdown timeout to complete
+func (s *Server) Close() error {
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultShutdownTimeout)
+	defer cancel()
 
-	return err
+	return s.Shutdown(ctx)
 }
 
 // NewProtoConnection opens up a new stream on the set protocol to the peer,
@@ -611,8 +1325,48 @@ func (s *Server) NewProtoConnection(protocol string, peerID peer.ID) (*rawGrpc.C
 	return p.Client(stream)
 }
 
+// NewStream opens up a new stream on the set protocol to the peer.
+// Streams are allowed over relayed (transient) connections as well.
+// The streams opened to a single peer at the same time are limited
 func (s *Server) NewStream(proto string, id peer.ID) (network.Stream, error) {
-	return s.host.NewStream(context.Background(), id, protocol.ID(proto))
+	return s.newStream(withRelayedConns(context.Background()), proto, id)
+}
+
+// newStream opens up a new stream on the set protocol to the peer, within the context.
+// The stream is accounted in the open streams, same as the ones opened with NewStream
+func (s *Server) newStream(ctx context.Context, proto string, id peer.ID) (network.Stream, error) {
+	if !s.openStreams.reserveOutbound(id, s.config.MaxOutboundStreamsPerPeer) {
+		metrics.IncrCounter([]string{networkMetrics, "streams_rejected_over_peer_limit"}, 1)
+
+		return nil, ErrTooManyPeerStreams
+	}
+
+	stream, err := s.host.NewStream(ctx, id, protocol.ID(proto))
+	if err != nil {
+		s.openStreams.unreserveOutbound(id)
+
+		return nil, err
+	}
+
+	counted, ok := s.openStreams.open(stream, s.config.MaxTotalStreams, true)
+	if !ok {
+		s.openStreams.unreserveOutbound(id)
+
+		_ = stream.Reset()
+
+		return nil, ErrTooManyStreams
+	}
+
+	s.connProtocols.record(id, stream.Protocol())
+	s.streamActivity.record(id, stream.Protocol(), s.clock.Now())
+
+	return s.protocolTraffic.wrap(proto, counted), nil
+}
+
+// withRelayedConns returns a context which allows opening
+// streams over relayed (transient) connections
+func withRelayedConns(ctx context.Context) context.Context {
+	return network.WithUseTransient(ctx, "relayed peer connection")
 }
 
 type Protocol interface {
@@ -629,24 +1383,110 @@ func (s *Server) RegisterProtocol(id string, p Protocol) {
 }
 
 func (s *Server) wrapStream(id string, handle func(network.Stream)) {
+	s.wrapAuthorizedStream(id, handle, nil)
+}
+
+// wrapAuthorizedStream sets the protocol stream handler, which serves only
+// the streams of the peers authorized by the auth callback, or of any peer if it is not set
+func (s *Server) wrapAuthorizedStream(id string, handle func(network.Stream), authFn func(peer.ID) bool) {
 	s.host.SetStreamHandler(protocol.ID(id), func(stream network.Stream) {
+		// The node is shutting down
+		if !s.streamHandlers.enter() {
+			_ = stream.Reset()
+
+			return
+		}
+
+		defer s.streamHandlers.exit()
+
+		if !s.isStreamAllowed(stream) {
+			s.resetExcessStream(stream)
+
+			return
+		}
+
 		peerID := stream.Conn().RemotePeer()
+
+		if authFn != nil && !authFn(peerID) {
+			s.resetUnauthorizedStream(stream)
+
+			return
+		}
+
+		counted, ok := s.openStreams.open(stream, s.config.MaxTotalStreams, false)
+		if !ok {
+			s.resetStreamOverTotalLimit(stream)
+
+			return
+		}
+
 		s.logger.Debug("open stream", "protocol", id, "peer", peerID)
 
-		handle(stream)
+		s.connProtocols.record(peerID, stream.Protocol())
+		s.streamActivity.record(peerID, stream.Protocol(), s.clock.Now())
+
+		defer s.recoverStreamPanic(id, counted)
+
+		handle(s.protocolTraffic.wrap(id, counted))
 	})
 }
 
 func (s *Server) AddrInfo() *peer.AddrInfo {
+	s.addrsLock.RLock()
+	defer s.addrsLock.RUnlock()
+
 	return &peer.AddrInfo{
 		ID:    s.host.ID(),
 		Addrs: s.addrs,
 	}
 }
 
-func (s *Server) addToDialQueue(addr *peer.AddrInfo, priority common.DialPriority) {
+// deferDial adds the peer back to the dial queue after the delay,
+// unless a dial to the peer is already deferred
+func (s *Server) deferDial(addr *peer.AddrInfo, priority common.DialPriority, delay time.Duration) {
+	if !s.dialRate.markDeferred(addr.ID) {
+		return
+	}
+
+	go func() {
+		defer s.dialRate.clearDeferred(addr.ID)
+
+		select {
+		case <-s.clock.After(delay):
+		case <-s.closeCh:
+			return
+		}
+
+		if !s.IsConnected(addr.ID) {
+			s.addToDialQueue(addr, priority, s.getPeerSource(addr.ID))
+		}
+	}()
+}
+
+// addToDialQueue creates a new dial task for the peer, and records how the node learned about it.
+// Peers with no routable addresses are skipped, so they don't take up the outbound slots.
+// Peers backed off after consecutive failed dials are skipped as well, unless they are pinned.
+// Returns false if the peer is skipped
+func (s *Server) addToDialQueue(addr *peer.AddrInfo, priority common.DialPriority, source PeerSource) bool {
+	if !s.hasRoutableAddr(addr) {
+		s.skipUnroutablePeer(addr)
+
+		return false
+	}
+
+	if priority != common.PriorityPinnedDial {
+		if remaining := s.dialBackoffRemaining(addr.ID); remaining > 0 {
+			s.logger.Debug("Not queueing the dial, peer is backed off after failed dials", "addr", addr, "remaining", remaining)
+
+			return false
+		}
+	}
+
+	s.recordPeerSource(addr.ID, source)
 	s.dialQueue.AddTask(addr, priority)
 	s.emitEvent(addr.ID, peerEvent.PeerAddedToDialQueue)
+
+	return true
 }
 
 func (s *Server) emitEvent(peerID peer.ID, peerEventType peerEvent.PeerEventType) {
@@ -666,7 +1506,10 @@ func (s *Server) Subscribe(ctx context.Context, handler func(evnt *peerEvent.Pee
 		return err
 	}
 
+	done := s.health.subscriptions.start()
+
 	go func() {
+		defer done()
 		defer sub.Close()
 
 		for {
@@ -693,7 +1536,20 @@ func (s *Server) SubscribeCh(ctx context.Context) (<-chan *peerEvent.PeerEvent,
 	ch := make(chan *peerEvent.PeerEvent)
 	ctx, cancel := context.WithCancel(ctx)
 
+	// The channel is closed only once no event is being sent on it
+	var (
+		chLock sync.RWMutex
+		closed bool
+	)
+
 	err := s.Subscribe(ctx, func(evnt *peerEvent.PeerEvent) {
+		chLock.RLock()
+		defer chLock.RUnlock()
+
+		if closed {
+			return
+		}
+
 		select {
 		case <-ctx.Done():
 			return
@@ -703,6 +1559,11 @@ func (s *Server) SubscribeCh(ctx context.Context) (<-chan *peerEvent.PeerEvent,
 
 	cleanup := func() {
 		cancel()
+
+		chLock.Lock()
+		defer chLock.Unlock()
+
+		closed = true
 		close(ch)
 	}
 
@@ -712,7 +1573,11 @@ func (s *Server) SubscribeCh(ctx context.Context) (<-chan *peerEvent.PeerEvent,
 		return nil, err
 	}
 
+	watchDone := s.health.watchers.start()
+
 	go func() {
+		defer watchDone()
+
 		<-s.closeCh
 
 		cleanup()
@@ -9,14 +9,30 @@ import (
 
 	"github.com/0xPolygon/polygon-edge/network/common"
 	"github.com/0xPolygon/polygon-edge/network/discovery"
+	peerEvent "github.com/0xPolygon/polygon-edge/network/event"
 	"github.com/0xPolygon/polygon-edge/network/grpc"
 	"github.com/0xPolygon/polygon-edge/network/proto"
+	"github.com/armon/go-metrics"
 	kb "github.com/libp2p/go-libp2p-kbucket"
 	"github.com/libp2p/go-libp2p/core/peer"
 	"github.com/libp2p/go-libp2p/core/peerstore"
 	rawGrpc "google.golang.org/grpc"
 )
 
+const (
+	// DefaultDiscoveryMaxMsgSize is the default maximum size of a discovery request or response
+	DefaultDiscoveryMaxMsgSize = 64 * 1024
+
+	// DefaultDiscoveryCallTimeout is the default maximum time spent on a single discovery call
+	DefaultDiscoveryCallTimeout = 10 * time.Second
+
+	// DefaultDiscoveryStartTimeout is the default maximum time the crawling waits for a bootnode connection on start
+	DefaultDiscoveryStartTimeout = 30 * time.Second
+
+	// DefaultDiscoveryMaxQueries is the default maximum number of concurrent outbound discovery queries
+	DefaultDiscoveryMaxQueries = 8
+)
+
 // GetRandomBootnode fetches a random bootnode that's currently
 // NOT connected, if any
 func (s *Server) GetRandomBootnode() *peer.AddrInfo {
@@ -42,6 +58,23 @@ func (s *Server) GetBootnodeConnCount() int64 {
 	return s.bootnodes.getBootnodeConnCount()
 }
 
+// ConnectedBootnodes returns the IDs of the bootnodes with an active connection [Thread safe]
+func (s *Server) ConnectedBootnodes() []peer.ID {
+	if s.bootnodes == nil {
+		return nil
+	}
+
+	connected := make([]peer.ID, 0, s.bootnodes.getBootnodeCount())
+
+	for _, bootnode := range s.bootnodes.getBootnodes() {
+		if s.hasPeer(bootnode.ID) {
+			connected = append(connected, bootnode.ID)
+		}
+	}
+
+	return connected
+}
+
 // getProtoStream returns an active protocol stream if present, otherwise
 // it returns nil
 func (s *Server) getProtoStream(protocol string, peerID peer.ID) *rawGrpc.ClientConn {
@@ -129,14 +162,50 @@ func (s *Server) CloseProtocolStream(protocol string, peerID peer.ID) error {
 	return connectionInfo.removeProtocolStream(protocol)
 }
 
-// AddToPeerStore adds peer information to the node's peer store
+// AddToPeerStore adds peer information to the node's peer store.
+// The number of stored addresses per peer is limited, and the addresses
+// with the most recent successful connection are kept
 func (s *Server) AddToPeerStore(peerInfo *peer.AddrInfo) {
-	s.host.Peerstore().AddAddr(peerInfo.ID, peerInfo.Addrs[0], peerstore.AddressTTL)
+	store := s.host.Peerstore()
+
+	addrs := mergeAddrs(store.Addrs(peerInfo.ID), peerInfo.Addrs)
+	keptAddrs := s.peerAddrs.limit(peerInfo.ID, addrs, s.maxPeerAddrs())
+
+	if len(keptAddrs) < len(addrs) {
+		s.logger.Debug("Limiting the number of peer addresses", "id", peerInfo.ID, "addrs", len(addrs))
+
+		// Only the dropped addresses are removed, so the kept ones retain their TTLs
+		store.SetAddrs(peerInfo.ID, excludeAddrs(addrs, keptAddrs), 0)
+		s.storeBootnodeAddrs(peerInfo.ID)
+	}
+
+	// Adding an address never lowers its TTL, e.g. a permanent one
+	store.AddAddrs(peerInfo.ID, keptAddrs, peerstore.AddressTTL)
+}
+
+// storeBootnodeAddrs stores the configured addresses of the bootnode in the peer store
+// with a permanent TTL, so the bootnode stays dialable for as long as it is referenced.
+// The bootnode addresses are exempt from the address limit. No-op for other peers
+func (s *Server) storeBootnodeAddrs(peerID peer.ID) {
+	if bootnode, ok := s.bootnodes.getBootnode(peerID); ok {
+		s.host.Peerstore().AddAddrs(bootnode.ID, bootnode.Addrs, peerstore.PermanentAddrTTL)
+	}
 }
 
-// RemoveFromPeerStore removes peer information from the node's peer store
+// RemoveFromPeerStore removes peer information from the node's peer store.
+// The configured bootnode addresses are kept
 func (s *Server) RemoveFromPeerStore(peerInfo *peer.AddrInfo) {
 	s.host.Peerstore().RemovePeer(peerInfo.ID)
+	s.storeBootnodeAddrs(peerInfo.ID)
+	s.peerAddrs.remove(peerInfo.ID)
+	s.dialRate.remove(peerInfo.ID)
+	s.dialBackoff.reset(peerInfo.ID)
+	s.securitySessions.remove(peerInfo.ID)
+	s.peerSources.remove(peerInfo.ID)
+	s.lastDialFailures.Delete(peerInfo.ID)
+	s.gossipValidation.remove(peerInfo.ID)
+	s.peerHistory.remove(peerInfo.ID)
+	s.peerUptime.remove(peerInfo.ID)
 }
 
 // GetPeerInfo fetches the information of a peer
@@ -209,13 +278,13 @@ func (s *Server) setupDiscovery() error {
 		// spawn routine because PeerAdded is called from event handler and s.addToDialQueue emits event again
 		go func() {
 			info := s.host.Peerstore().PeerInfo(p)
-			s.addToDialQueue(&info, common.PriorityRandomDial)
+			s.addToDialQueue(&info, common.PriorityRandomDial, s.discoveredPeerSource(p))
 		}()
 	}
 
 	// Set the PeerRemoved event handler
 	routingTable.PeerRemoved = func(p peer.ID) {
-		s.dialQueue.DeleteTask(p)
+		s.deleteDialTask(p)
 	}
 
 	// Create an instance of the discovery service
@@ -223,6 +292,7 @@ func (s *Server) setupDiscovery() error {
 		s,
 		routingTable,
 		s.logger,
+		s.discoveryMaxQueries(),
 	)
 
 	// Register a network event handler
@@ -237,23 +307,133 @@ func (s *Server) setupDiscovery() error {
 	// and instantiates connections to them
 	discoveryService.ConnectToBootnodes(s.bootnodes.getBootnodes())
 
-	// Start the discovery service
-	discoveryService.Start()
-
 	// Set the discovery service reference
 	s.discovery = discoveryService
 
+	// Crawling before any bootnode is connected yields nothing,
+	// so the discovery service is started once a bootnode connects
+	watchDone := s.health.watchers.start()
+
+	go func() {
+		defer watchDone()
+
+		if s.waitForBootnodeConn() {
+			discoveryService.Start()
+			close(s.discoveryStarted)
+		}
+	}()
+
 	return nil
 }
 
+// discoveryMaxQueries returns the configured discovery query concurrency limit or the default one
+func (s *Server) discoveryMaxQueries() int {
+	if s.config.DiscoveryMaxQueries > 0 {
+		return s.config.DiscoveryMaxQueries
+	}
+
+	return DefaultDiscoveryMaxQueries
+}
+
+// discoveryStartTimeout returns the configured discovery start timeout or the default one
+func (s *Server) discoveryStartTimeout() time.Duration {
+	if s.config.DiscoveryStartTimeout > 0 {
+		return s.config.DiscoveryStartTimeout
+	}
+
+	return DefaultDiscoveryStartTimeout
+}
+
+// waitForBootnodeConn waits until a bootnode connection is established, or the discovery
+// start timeout passes. Returns false if the networking server closed in the meantime
+func (s *Server) waitForBootnodeConn() bool {
+	if !s.bootnodes.hasBootnodes() {
+		return true
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.discoveryStartTimeout())
+	defer cancel()
+
+	connectedCh := make(chan struct{}, 1)
+
+	if err := s.Subscribe(ctx, func(evnt *peerEvent.PeerEvent) {
+		if evnt.Type == peerEvent.PeerConnected && s.bootnodes.isBootnode(evnt.PeerID) {
+			select {
+			case connectedCh <- struct{}{}:
+			default:
+			}
+		}
+	}); err != nil {
+		s.logger.Error("Cannot subscribe to network events, starting discovery right away", "err", err)
+
+		return true
+	}
+
+	// The bootnode could have connected before the subscription
+	if s.GetBootnodeConnCount() > 0 {
+		return true
+	}
+
+	select {
+	case <-connectedCh:
+		return true
+	case <-ctx.Done():
+		s.logger.Warn("No bootnode connected in time, starting discovery anyway")
+
+		return true
+	case <-s.closeCh:
+		return false
+	}
+}
+
 func (s *Server) TemporaryDialPeer(peerAddrInfo *peer.AddrInfo) {
 	s.logger.Debug("creating new temporary dial to peer", "peer", peerAddrInfo.ID)
-	s.addToDialQueue(peerAddrInfo, common.PriorityRandomDial)
+	s.addToDialQueue(peerAddrInfo, common.PriorityRandomDial, s.discoveredPeerSource(peerAddrInfo.ID))
+}
+
+// SetDiscoveryServeEnabled sets if the discovery queries of other peers are answered.
+// When disabled, the queries get empty responses, while the node keeps discovering peers itself
+func (s *Server) SetDiscoveryServeEnabled(enabled bool) {
+	if s.discovery == nil {
+		return
+	}
+
+	s.discovery.SetServeEnabled(enabled)
+
+	s.logger.Info("Discovery serving updated", "enabled", enabled)
+
+	serving := float32(0)
+	if enabled {
+		serving = 1
+	}
+
+	metrics.SetGauge([]string{networkMetrics, "discovery_serving"}, serving)
+}
+
+// discoveryStreamOptions returns the size and time limits of the discovery gRPC calls,
+// so a malicious peer can't exhaust the node memory with an enormous response, or stall it.
+// The handler panics are logged with the server logger
+func (s *Server) discoveryStreamOptions() []grpc.StreamOption {
+	maxMsgSize := s.config.DiscoveryMaxMsgSize
+	if maxMsgSize <= 0 {
+		maxMsgSize = DefaultDiscoveryMaxMsgSize
+	}
+
+	callTimeout := s.config.DiscoveryCallTimeout
+	if callTimeout <= 0 {
+		callTimeout = DefaultDiscoveryCallTimeout
+	}
+
+	return []grpc.StreamOption{
+		grpc.WithMaxMsgSize(maxMsgSize),
+		grpc.WithCallTimeout(callTimeout),
+		grpc.WithLogger(s.logger),
+	}
 }
 
 // registerDiscoveryService registers the discovery protocol to be available
 func (s *Server) registerDiscoveryService(discovery *discovery.DiscoveryService) {
-	grpcStream := grpc.NewGrpcStream()
+	grpcStream := grpc.NewGrpcStream(s.discoveryStreamOptions()...)
 	proto.RegisterDiscoveryServer(grpcStream.GrpcServer(), discovery)
 	grpcStream.Serve()
 
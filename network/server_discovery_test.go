@@ -0,0 +1,175 @@
+package network
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/0xPolygon/polygon-edge/network/common"
+	"github.com/0xPolygon/polygon-edge/network/grpc"
+	"github.com/0xPolygon/polygon-edge/network/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// slowKey is the discovery request key for which the stub service stalls
+const slowKey = "slow"
+
+// oversizedDiscoveryService is a discovery service stub,
+// which responds with a huge number of nodes, or stalls
+type oversizedDiscoveryService struct {
+	proto.UnimplementedDiscoveryServer
+}
+
+func (o *oversizedDiscoveryService) FindPeers(
+	ctx context.Context,
+	req *proto.FindPeersReq,
+) (*proto.FindPeersResp, error) {
+	if req.Key == slowKey {
+		<-ctx.Done()
+
+		return nil, ctx.Err()
+	}
+
+	nodes := make([]string, 1000)
+	for i := range nodes {
+		nodes[i] = fmt.Sprintf("/ip4/10.0.%d.%d/tcp/1478/p2p/16Uiu2HAmPadding", i/256, i%256)
+	}
+
+	return &proto.FindPeersResp{Nodes: nodes}, nil
+}
+
+func TestDiscoveryClient_Limits(t *testing.T) {
+	const callTimeout = 500 * time.Millisecond
+
+	servers, createErr := createServers(2, map[int]*CreateServerParams{
+		0: {ConfigCallback: func(c *Config) {
+			c.NoDiscover = true
+			c.DiscoveryMaxMsgSize = 1024
+			c.DiscoveryCallTimeout = callTimeout
+		}},
+		1: {ConfigCallback: func(c *Config) { c.NoDiscover = true }},
+	})
+	require.NoError(t, createErr)
+
+	t.Cleanup(func() {
+		closeTestServers(t, servers)
+	})
+
+	server, malicious := servers[0], servers[1]
+
+	// The discovery service is turned off, so only the client side is registered
+	server.RegisterProtocol(common.DiscProto, grpc.NewGrpcStream(server.discoveryStreamOptions()...))
+
+	// The malicious peer serves the discovery protocol without any limits
+	grpcStream := grpc.NewGrpcStream()
+	proto.RegisterDiscoveryServer(grpcStream.GrpcServer(), &oversizedDiscoveryService{})
+	grpcStream.Serve()
+	malicious.RegisterProtocol(common.DiscProto, grpcStream)
+
+	require.NoError(t, JoinAndWait(server, malicious, DefaultBufferTimeout, DefaultJoinTimeout))
+
+	client, err := server.NewDiscoveryClient(malicious.host.ID())
+	require.NoError(t, err)
+
+	// The oversized response is rejected
+	_, err = client.FindPeers(context.Background(), &proto.FindPeersReq{Count: 16})
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+
+	// The stalled call is abandoned after the call timeout
+	start := time.Now()
+
+	_, err = client.FindPeers(context.Background(), &proto.FindPeersReq{Key: slowKey, Count: 16})
+	assert.Equal(t, codes.DeadlineExceeded, status.Code(err))
+	assert.Less(t, time.Since(start), 5*callTimeout)
+}
+
+func TestDiscovery_StartsOnBootnodeConn(t *testing.T) {
+	const bootnodeDelay = time.Second
+
+	bootnode, createErr := CreateServer(&CreateServerParams{
+		ConfigCallback: func(c *Config) { c.NoDiscover = true },
+	})
+	require.NoError(t, createErr)
+
+	t.Cleanup(func() {
+		assert.NoError(t, bootnode.Close())
+	})
+
+	bootnodeAddr, err := common.AddrInfoToString(bootnode.AddrInfo())
+	require.NoError(t, err)
+
+	server, createErr := CreateServer(&CreateServerParams{
+		ConfigCallback: func(c *Config) {
+			// The bootnode connection is delayed by holding back the first dials
+			c.StartupDialDelay = bootnodeDelay
+		},
+		ServerCallback: func(server *Server) {
+			server.config.Chain.Bootnodes = []string{bootnodeAddr}
+		},
+	})
+	require.NoError(t, createErr)
+
+	t.Cleanup(func() {
+		assert.NoError(t, server.Close())
+	})
+
+	// The crawling doesn't start while no bootnode is connected
+	select {
+	case <-server.discoveryStarted:
+		t.Fatal("discovery started before the bootnode connected")
+	case <-time.After(bootnodeDelay / 2):
+	}
+
+	assert.Zero(t, server.GetBootnodeConnCount())
+
+	// The crawling starts once the bootnode connects
+	select {
+	case <-server.discoveryStarted:
+	case <-time.After(DefaultJoinTimeout):
+		t.Fatal("discovery didn't start after the bootnode connected")
+	}
+
+	assert.Equal(t, int64(1), server.GetBootnodeConnCount())
+}
+
+func TestDiscovery_StartsOnTimeout(t *testing.T) {
+	bootnode, createErr := CreateServer(&CreateServerParams{
+		ConfigCallback: func(c *Config) { c.NoDiscover = true },
+	})
+	require.NoError(t, createErr)
+
+	t.Cleanup(func() {
+		assert.NoError(t, bootnode.Close())
+	})
+
+	bootnodeAddr, err := common.AddrInfoToString(bootnode.AddrInfo())
+	require.NoError(t, err)
+
+	server, createErr := CreateServer(&CreateServerParams{
+		ConfigCallback: func(c *Config) {
+			// The bootnode is not dialed before the discovery start timeout
+			c.StartupDialDelay = DefaultJoinTimeout
+			c.DiscoveryStartTimeout = 200 * time.Millisecond
+		},
+		ServerCallback: func(server *Server) {
+			server.config.Chain.Bootnodes = []string{bootnodeAddr}
+		},
+	})
+	require.NoError(t, createErr)
+
+	t.Cleanup(func() {
+		assert.NoError(t, server.Close())
+	})
+
+	select {
+	case <-server.discoveryStarted:
+	case <-time.After(5 * time.Second):
+		t.Fatal("discovery didn't start after the timeout")
+	}
+
+	assert.Zero(t, server.GetBootnodeConnCount())
+}
@@ -0,0 +1,189 @@
+package network
+
+import (
+	"context"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/0xPolygon/polygon-edge/network/common"
+	"github.com/libp2p/go-libp2p/core/network"
+	"github.com/libp2p/go-libp2p/core/peer"
+)
+
+const (
+	// DefaultGoodbyeTimeout is the default time spent on delivering a goodbye message
+	DefaultGoodbyeTimeout = 2 * time.Second
+
+	// DefaultGoodbyeBackoff is the default time a peer that said goodbye is not redialed
+	DefaultGoodbyeBackoff = 30 * time.Second
+)
+
+// GoodbyeReason is the reason code sent to a peer before it is deliberately disconnected
+type GoodbyeReason uint8
+
+const (
+	GoodbyeReasonUnknown    GoodbyeReason = iota // The disconnect reason is not known
+	GoodbyeReasonDisconnect                      // The peer is disconnected on request
+	GoodbyeReasonShutdown                        // The node is shutting down
+)
+
+var goodbyeReasonToName = map[GoodbyeReason]string{
+	GoodbyeReasonUnknown:    "unknown",
+	GoodbyeReasonDisconnect: "disconnect",
+	GoodbyeReasonShutdown:   "shutdown",
+}
+
+func (r GoodbyeReason) String() string {
+	name, ok := goodbyeReasonToName[r]
+	if !ok {
+		return "unknown"
+	}
+
+	return name
+}
+
+// goodbyeRecord keeps the last goodbye received from a peer
+type goodbyeRecord struct {
+	reason GoodbyeReason // the reason code sent by the peer
+	until  time.Time     // the time until the peer is not redialed
+}
+
+// goodbyeTimeout returns the configured goodbye timeout, or the default one
+func (s *Server) goodbyeTimeout() time.Duration {
+	if s.config.GoodbyeTimeout > 0 {
+		return s.config.GoodbyeTimeout
+	}
+
+	return DefaultGoodbyeTimeout
+}
+
+// goodbyeBackoff returns the configured goodbye backoff, or the default one
+func (s *Server) goodbyeBackoff() time.Duration {
+	if s.config.GoodbyeBackoff > 0 {
+		return s.config.GoodbyeBackoff
+	}
+
+	return DefaultGoodbyeBackoff
+}
+
+// setupGoodbye registers the handler for incoming goodbye messages
+func (s *Server) setupGoodbye() {
+	s.wrapStream(common.GoodbyeProto, s.handleGoodbye)
+}
+
+// handleGoodbye reads the reason code of an incoming goodbye message
+// and puts the peer in a local backoff, so it is not redialed right away
+func (s *Server) handleGoodbye(stream network.Stream) {
+	defer stream.Close()
+
+	peerID := stream.Conn().RemotePeer()
+
+	_ = stream.SetReadDeadline(time.Now().Add(s.goodbyeTimeout()))
+
+	buf := make([]byte, 1)
+	if _, err := io.ReadFull(stream, buf); err != nil {
+		s.logger.Debug("unable to read goodbye message", "peer", peerID, "err", err)
+
+		return
+	}
+
+	reason := GoodbyeReason(buf[0])
+
+	s.logger.Debug("Received goodbye", "peer", peerID, "reason", reason)
+
+	s.goodbyes.Store(peerID, &goodbyeRecord{
+		reason: reason,
+		until:  s.clock.Now().Add(s.goodbyeBackoff()),
+	})
+}
+
+// sendGoodbye sends a goodbye message with the reason code to the peer.
+// Delivery is best-effort and bounded by the goodbye timeout
+func (s *Server) sendGoodbye(peerID peer.ID, reason GoodbyeReason) {
+	ctx, cancel := context.WithTimeout(withRelayedConns(context.Background()), s.goodbyeTimeout())
+	defer cancel()
+
+	stream, err := s.newStream(ctx, common.GoodbyeProto, peerID)
+	if err != nil {
+		s.logger.Debug("unable to open goodbye stream", "peer", peerID, "err", err)
+
+		return
+	}
+
+	defer stream.Close()
+
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = stream.SetDeadline(deadline)
+	}
+
+	if _, err := stream.Write([]byte{byte(reason)}); err != nil {
+		s.logger.Debug("unable to send goodbye", "peer", peerID, "err", err)
+
+		return
+	}
+
+	// Wait for the peer to close its side of the stream, which signals
+	// the message was read before the connection itself is closed
+	_ = stream.CloseWrite()
+	_, _ = io.Copy(io.Discard, stream)
+}
+
+// sendGoodbyeToAll sends a goodbye message to all connected peers in parallel
+func (s *Server) sendGoodbyeToAll(reason GoodbyeReason) {
+	var wg sync.WaitGroup
+
+	for _, peerID := range s.host.Network().Peers() {
+		wg.Add(1)
+
+		go func(peerID peer.ID) {
+			defer wg.Done()
+
+			s.sendGoodbye(peerID, reason)
+		}(peerID)
+	}
+
+	wg.Wait()
+}
+
+// getGoodbyeReason returns the reason code of the last goodbye
+// received from the peer, if any [Thread safe]
+func (s *Server) getGoodbyeReason(peerID peer.ID) (GoodbyeReason, bool) {
+	value, ok := s.goodbyes.Load(peerID)
+	if !ok {
+		return GoodbyeReasonUnknown, false
+	}
+
+	record, ok := value.(*goodbyeRecord)
+	if !ok {
+		return GoodbyeReasonUnknown, false
+	}
+
+	return record.reason, true
+}
+
+// isInGoodbyeBackoff checks if the peer said goodbye recently,
+// and should not be redialed yet [Thread safe]
+func (s *Server) isInGoodbyeBackoff(peerID peer.ID) bool {
+	_, ok := s.getGoodbyeBackoff(peerID)
+
+	return ok
+}
+
+// getGoodbyeBackoff returns the goodbye of the peer, if the peer
+// is still in the backoff after it [Thread safe]
+func (s *Server) getGoodbyeBackoff(peerID peer.ID) (*goodbyeRecord, bool) {
+	value, ok := s.goodbyes.Load(peerID)
+	if !ok {
+		return nil, false
+	}
+
+	record, ok := value.(*goodbyeRecord)
+	if !ok || s.clock.Now().After(record.until) {
+		s.goodbyes.Delete(peerID)
+
+		return nil, false
+	}
+
+	return record, true
+}
@@ -0,0 +1,99 @@
+package network
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/0xPolygon/polygon-edge/helper/tests"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGoodbye_SentOnDisconnect(t *testing.T) {
+	servers, createErr := createServers(2, map[int]*CreateServerParams{
+		0: {ConfigCallback: func(c *Config) { c.NoDiscover = true }},
+		1: {ConfigCallback: func(c *Config) { c.NoDiscover = true }},
+	})
+	require.NoError(t, createErr)
+
+	t.Cleanup(func() {
+		closeTestServers(t, servers)
+	})
+
+	require.NoError(t, JoinAndWait(servers[0], servers[1], DefaultBufferTimeout, DefaultJoinTimeout))
+
+	sourceID := servers[0].host.ID()
+
+	require.NoError(t, DisconnectAndWait(servers[0], servers[1].host.ID(), DefaultLeaveTimeout))
+
+	ctx, cancelFn := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancelFn()
+
+	reason, err := tests.RetryUntilTimeout(ctx, func() (interface{}, bool) {
+		reason, ok := servers[1].getGoodbyeReason(sourceID)
+
+		return reason, !ok
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, GoodbyeReasonDisconnect, reason)
+	assert.True(t, servers[1].isInGoodbyeBackoff(sourceID))
+	assert.False(t, servers[0].isInGoodbyeBackoff(servers[1].host.ID()))
+}
+
+func TestGoodbye_SentOnShutdown(t *testing.T) {
+	servers, createErr := createServers(2, map[int]*CreateServerParams{
+		0: {ConfigCallback: func(c *Config) { c.NoDiscover = true }},
+		1: {ConfigCallback: func(c *Config) { c.NoDiscover = true }},
+	})
+	require.NoError(t, createErr)
+
+	t.Cleanup(func() {
+		assert.NoError(t, servers[1].Close())
+	})
+
+	require.NoError(t, JoinAndWait(servers[0], servers[1], DefaultBufferTimeout, DefaultJoinTimeout))
+
+	sourceID := servers[0].host.ID()
+
+	require.NoError(t, servers[0].Close())
+
+	ctx, cancelFn := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancelFn()
+
+	reason, err := tests.RetryUntilTimeout(ctx, func() (interface{}, bool) {
+		reason, ok := servers[1].getGoodbyeReason(sourceID)
+
+		return reason, !ok
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, GoodbyeReasonShutdown, reason)
+}
+
+func TestGoodbye_BackoffExpires(t *testing.T) {
+	server, createErr := CreateServer(&CreateServerParams{ConfigCallback: func(c *Config) {
+		c.NoDiscover = true
+		c.GoodbyeBackoff = time.Millisecond
+	}})
+	require.NoError(t, createErr)
+
+	t.Cleanup(func() {
+		assert.NoError(t, server.Close())
+	})
+
+	randomPeers, err := generateRandomPeers(t, 1)
+	require.NoError(t, err)
+
+	peerID := randomPeers[0].peerID
+
+	server.goodbyes.Store(peerID, &goodbyeRecord{
+		reason: GoodbyeReasonDisconnect,
+		until:  time.Now().Add(server.goodbyeBackoff()),
+	})
+
+	assert.Eventually(t, func() bool {
+		return !server.isInGoodbyeBackoff(peerID)
+	}, time.Second, 10*time.Millisecond)
+}
@@ -16,8 +16,19 @@ import (
 	rawGrpc "google.golang.org/grpc"
 )
 
+const (
+	// DefaultHandshakeMaxMsgSize is the default maximum size of an identity handshake request or response.
+	// It is well above the size of a valid handshake, so the malformed metadata is still caught by the validation
+	DefaultHandshakeMaxMsgSize = 16 * 1024
+)
+
 // NewIdentityClient returns a new identity service client connection
 func (s *Server) NewIdentityClient(peerID peer.ID) (proto.IdentityClient, error) {
+	// The identity client is only created to start the handshake, which can happen
+	// before the connection notification reaches the server
+	s.setConnState(peerID, ConnStateConnected)
+	s.setConnState(peerID, ConnStateHandshaking)
+
 	// Create a new stream connection and return it
 	protoStream, err := s.NewProtoConnection(common.IdentityProto, peerID)
 	if err != nil {
@@ -31,46 +42,74 @@ func (s *Server) NewIdentityClient(peerID peer.ID) (proto.IdentityClient, error)
 // AddPeer adds a new peer to the networking server's peer list,
 // and updates relevant counters and metrics
 func (s *Server) AddPeer(id peer.ID, direction network.Direction) {
-	s.logger.Info("Peer connected", "id", id.String())
+	// The slot of an evicted peer is reserved until it is gone,
+	// so the inbound connections don't exceed the limit in the meantime
+	if direction == network.DirInbound {
+		s.awaitEvictions()
+	}
+
+	s.logger.Info(s.peerLogMsg("Peer connected", id), "id", id.String())
+
+	s.setConnState(id, ConnStateReady)
+	s.readyWaiters.notify(id, true)
 
 	// Update the peer connection info
 	if connectionExists := s.addPeerInfo(id, direction); connectionExists {
 		// The peer connection information was already present in the networking
 		// server, so no connection metrics should be updated further
+		if s.config.DualConnPolicy == DualConnCloseRedundant {
+			s.closeRedundantConns(id)
+		}
+
 		return
 	}
 
+	s.peerHistory.record(id, PeerHistoryEntry{
+		At:        s.clock.Now(),
+		Outcome:   PeerHistoryConnected,
+		Direction: direction,
+	})
+	s.peerUptime.connected(id, s.clock.Now())
+	s.dialBackoff.reset(id)
+	s.lastDialFailures.Delete(id)
+
 	// Emit the event alerting listeners
 	// WARNING: THIS CALL IS POTENTIALLY BLOCKING
 	s.emitEvent(id, peerEvent.PeerConnected)
+
+	s.checkOutboundTarget(id)
+	s.checkVersionDiversity()
 }
 
 // addPeerInfo updates the networking server's internal peer info table
 // and returns a flag indicating if the same peer connection previously existed.
-// In case the peer connection previously existed, this is a noop
+// In case the peer connection previously existed, only the connection direction is saved
 func (s *Server) addPeerInfo(id peer.ID, direction network.Direction) bool {
 	s.peersLock.Lock()
 	defer s.peersLock.Unlock()
 
 	connectionInfo, connectionExists := s.peers[id]
-	if connectionExists && connectionInfo.connDirections[direction] {
-		// Check if this peer already has an active connection status (saved info).
-		// There is no need to do further processing
+	if connectionExists {
+		// A peer connected in both directions is still a single logical peer,
+		// so it stays accounted in the direction it connected in first
+		connectionInfo.connDirections[direction] = true
+
 		return true
 	}
 
-	// Check if the connection info is already initialized
-	if !connectionExists {
-		// Create a new record for the connection info
-		connectionInfo = &PeerConnInfo{
-			Info:            s.host.Peerstore().PeerInfo(id),
-			connDirections:  make(map[network.Direction]bool),
-			protocolStreams: make(map[string]*rawGrpc.ClientConn),
-		}
+	if direction == network.DirInbound {
+		s.recordPeerSource(id, PeerSourceInbound)
 	}
 
-	// Save the connection info to the networking server
-	connectionInfo.connDirections[direction] = true
+	// Create a new record for the connection info
+	connectionInfo = &PeerConnInfo{
+		Info:            s.host.Peerstore().PeerInfo(id),
+		IsBootnode:      s.bootnodes.isBootnode(id),
+		Source:          s.getPeerSource(id),
+		direction:       direction,
+		connDirections:  map[network.Direction]bool{direction: true},
+		protocolStreams: make(map[string]*rawGrpc.ClientConn),
+	}
 
 	s.peers[id] = connectionInfo
 
@@ -112,6 +151,7 @@ func (s *Server) setupIdentity() error {
 		s.logger,
 		s.config.Chain.Params.ChainID,
 		s.host.ID(),
+		identity.WithHandshakeTimeout(s.config.HandshakeGracePeriod),
 	)
 
 	// Register the identity service protocol
@@ -123,9 +163,33 @@ func (s *Server) setupIdentity() error {
 	return nil
 }
 
-// registerIdentityService registers the identity service
+// handshakeMaxMsgSize returns the configured maximum handshake message size, or the default one
+func (s *Server) handshakeMaxMsgSize() int {
+	if s.config.HandshakeMaxMsgSize > 0 {
+		return s.config.HandshakeMaxMsgSize
+	}
+
+	return DefaultHandshakeMaxMsgSize
+}
+
+// handleOversizedHandshake disconnects from the peer which sent a handshake over the size limit.
+// The oversized payload is rejected by its length prefix, so it is never read nor parsed
+func (s *Server) handleOversizedHandshake(peerID peer.ID) {
+	s.logger.Warn("Peer sent an oversized handshake", "peer", peerID, "limit", s.handshakeMaxMsgSize())
+
+	metrics.IncrCounter([]string{networkMetrics, "oversized_handshakes"}, 1)
+
+	go s.DisconnectFromPeer(peerID, "oversized handshake")
+}
+
+// registerIdentityService registers the identity service. The handshake messages are bounded,
+// so a peer can't exhaust the node memory with an enormous handshake before it is validated
 func (s *Server) registerIdentityService(identityService *identity.IdentityService) {
-	grpcStream := grpc.NewGrpcStream()
+	grpcStream := grpc.NewGrpcStream(
+		grpc.WithMaxMsgSize(s.handshakeMaxMsgSize()),
+		grpc.WithOversizedMsgHandler(s.handleOversizedHandshake),
+		grpc.WithLogger(s.logger),
+	)
 	proto.RegisterIdentityServer(grpcStream.GrpcServer(), identityService)
 	grpcStream.Serve()
 
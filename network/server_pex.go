@@ -0,0 +1,188 @@
+package network
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/0xPolygon/polygon-edge/network/common"
+	"github.com/libp2p/go-libp2p/core/network"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/multiformats/go-multiaddr"
+	madns "github.com/multiformats/go-multiaddr-dns"
+	manet "github.com/multiformats/go-multiaddr/net"
+)
+
+const (
+	// DefaultPexInterval is the default time between the peer exchanges
+	DefaultPexInterval = 30 * time.Second
+
+	// pexTimeout is the maximum time spent on a single peer exchange
+	pexTimeout = 10 * time.Second
+
+	// pexSampleSize is the maximum number of peers shared in a peer exchange
+	pexSampleSize = 16
+
+	// maxPexAddrsPerPeer is the maximum number of addresses shared per peer in a peer exchange
+	maxPexAddrsPerPeer = 2
+
+	// maxPexAddrs is the maximum number of addresses accepted in a peer exchange
+	maxPexAddrs = pexSampleSize * maxPexAddrsPerPeer
+)
+
+// pexInterval returns the configured peer exchange interval, or the default one
+func (s *Server) pexInterval() time.Duration {
+	if s.config.PexInterval <= 0 {
+		return DefaultPexInterval
+	}
+
+	return s.config.PexInterval
+}
+
+// setupPex registers the handler for incoming peer exchange requests
+func (s *Server) setupPex() {
+	s.wrapStream(common.PexProto, s.handlePex)
+}
+
+// runPeerExchanges periodically asks a random connected peer for a sample of its peers,
+// and queues the ones the node is not connected to for dialing
+func (s *Server) runPeerExchanges() {
+	for {
+		select {
+		case <-s.clock.After(s.pexInterval()):
+		case <-s.closeCh:
+			return
+		}
+
+		peerID := s.GetRandomPeer()
+		if peerID == nil {
+			continue
+		}
+
+		peers, err := s.requestPeerExchange(*peerID)
+		if err != nil {
+			s.logger.Debug("Unable to exchange peers", "peer", *peerID, "err", err)
+
+			continue
+		}
+
+		for _, peerInfo := range peers {
+			if s.IsConnected(peerInfo.ID) {
+				continue
+			}
+
+			s.AddToPeerStore(peerInfo)
+			s.addToDialQueue(peerInfo, common.PriorityRandomDial, PeerSourcePex)
+		}
+	}
+}
+
+// requestPeerExchange asks the peer for a sample of its connected peers
+func (s *Server) requestPeerExchange(peerID peer.ID) ([]*peer.AddrInfo, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), pexTimeout)
+	defer cancel()
+
+	stream, err := s.newStream(ctx, common.PexProto, peerID)
+	if err != nil {
+		return nil, fmt.Errorf("unable to open peer exchange stream, %w", err)
+	}
+
+	defer stream.Close()
+
+	_ = stream.SetDeadline(time.Now().Add(pexTimeout))
+	_ = stream.CloseWrite()
+
+	addrs, err := readAddrList(stream, maxPexAddrs)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read peer exchange response, %w", err)
+	}
+
+	return s.parsePexAddrs(addrs), nil
+}
+
+// parsePexAddrs groups the shared addresses by peer, dropping the ones with no peer ID
+// or an unexpected format, and the addresses over the per-peer limit,
+// so a single peer can't poison the peer store
+func (s *Server) parsePexAddrs(addrs []multiaddr.Multiaddr) []*peer.AddrInfo {
+	peers := make([]*peer.AddrInfo, 0)
+	peersByID := make(map[peer.ID]*peer.AddrInfo)
+
+	for _, addr := range addrs {
+		peerInfo, err := peer.AddrInfoFromP2pAddr(addr)
+		if err != nil || len(peerInfo.Addrs) != 1 || peerInfo.ID == s.host.ID() {
+			continue
+		}
+
+		transport := peerInfo.Addrs[0]
+		if !isPexTransport(transport) {
+			continue
+		}
+
+		existing, ok := peersByID[peerInfo.ID]
+		if !ok {
+			peersByID[peerInfo.ID] = peerInfo
+			peers = append(peers, peerInfo)
+
+			continue
+		}
+
+		if len(existing.Addrs) < maxPexAddrsPerPeer {
+			existing.Addrs = append(existing.Addrs, transport)
+		}
+	}
+
+	return peers
+}
+
+// isPexTransport checks if the shared address is a TCP address, on an IP or a DNS name
+func isPexTransport(addr multiaddr.Multiaddr) bool {
+	if !manet.IsThinWaist(addr) && !madns.Matches(addr) {
+		return false
+	}
+
+	_, err := addr.ValueForProtocol(multiaddr.P_TCP)
+
+	return err == nil
+}
+
+// handlePex sends a random sample of the connected peers to the requesting peer
+func (s *Server) handlePex(stream network.Stream) {
+	defer stream.Close()
+
+	requester := stream.Conn().RemotePeer()
+
+	_ = stream.SetDeadline(time.Now().Add(pexTimeout))
+
+	addrs := make([]multiaddr.Multiaddr, 0)
+
+	for _, peerID := range s.samplePeers(requester, pexSampleSize) {
+		peerAddrs := s.host.Peerstore().Addrs(peerID)
+		if len(peerAddrs) > maxPexAddrsPerPeer {
+			peerAddrs = peerAddrs[:maxPexAddrsPerPeer]
+		}
+
+		p2pAddrs, err := peer.AddrInfoToP2pAddrs(&peer.AddrInfo{ID: peerID, Addrs: peerAddrs})
+		if err != nil {
+			continue
+		}
+
+		addrs = append(addrs, p2pAddrs...)
+	}
+
+	if _, err := stream.Write(encodeAddrList(addrs)); err != nil {
+		s.logger.Debug("unable to send peer exchange response", "peer", requester, "err", err)
+	}
+}
+
+// samplePeers returns a random sample of the connected peers, excluding the given peer
+func (s *Server) samplePeers(excluded peer.ID, size int) []peer.ID {
+	peerIDs := make([]peer.ID, 0)
+
+	for _, connInfo := range s.Peers() {
+		if connInfo.Info.ID != excluded {
+			peerIDs = append(peerIDs, connInfo.Info.ID)
+		}
+	}
+
+	return sampleRandom(peerIDs, size)
+}
@@ -0,0 +1,109 @@
+package network
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	peerEvent "github.com/0xPolygon/polygon-edge/network/event"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/multiformats/go-multiaddr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPeerExchange(t *testing.T) {
+	servers, createErr := createServers(3, map[int]*CreateServerParams{
+		0: {ConfigCallback: func(c *Config) {
+			c.NoDiscover = true
+			c.EnablePex = true
+			c.PexInterval = 100 * time.Millisecond
+		}},
+		1: {ConfigCallback: func(c *Config) {
+			c.NoDiscover = true
+			c.EnablePex = true
+		}},
+		2: {ConfigCallback: func(c *Config) { c.NoDiscover = true }},
+	})
+	require.NoError(t, createErr)
+
+	t.Cleanup(func() {
+		closeTestServers(t, servers)
+	})
+
+	server, sharing, shared := servers[0], servers[1], servers[2]
+
+	// The sharing peer is connected to the peer the server doesn't know about
+	require.NoError(t, JoinAndWait(sharing, shared, DefaultBufferTimeout, DefaultJoinTimeout))
+
+	queuedCh := make(chan struct{}, 1)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	require.NoError(t, server.Subscribe(ctx, func(evnt *peerEvent.PeerEvent) {
+		if evnt.Type == peerEvent.PeerAddedToDialQueue && evnt.PeerID == shared.host.ID() {
+			select {
+			case queuedCh <- struct{}{}:
+			default:
+			}
+		}
+	}))
+
+	require.NoError(t, JoinAndWait(server, sharing, DefaultBufferTimeout, DefaultJoinTimeout))
+
+	select {
+	case <-queuedCh:
+	case <-time.After(DefaultJoinTimeout):
+		t.Fatal("peer shared in the peer exchange was not queued for dialing")
+	}
+
+	assert.Equal(t, PeerSourcePex, server.getPeerSource(shared.host.ID()))
+
+	require.Eventually(t, func() bool {
+		return server.IsConnected(shared.host.ID())
+	}, DefaultJoinTimeout, 50*time.Millisecond)
+}
+
+func TestParsePexAddrs(t *testing.T) {
+	server, createErr := CreateServer(&CreateServerParams{ConfigCallback: func(c *Config) {
+		c.NoDiscover = true
+	}})
+	require.NoError(t, createErr)
+
+	t.Cleanup(func() {
+		require.NoError(t, server.Close())
+	})
+
+	randomPeers, err := generateRandomPeers(t, 2)
+	require.NoError(t, err)
+
+	peerID, otherID := randomPeers[0].peerID, randomPeers[1].peerID
+
+	p2pAddr := func(transport string, id peer.ID) multiaddr.Multiaddr {
+		return multiaddr.StringCast(fmt.Sprintf("%s/p2p/%s", transport, id))
+	}
+
+	addrs := []multiaddr.Multiaddr{
+		p2pAddr("/ip4/10.0.0.1/tcp/1478", peerID),
+		p2pAddr("/ip4/10.0.0.2/tcp/1478", peerID),
+		// Addresses over the per-peer limit are dropped
+		p2pAddr("/ip4/10.0.0.3/tcp/1478", peerID),
+		// The node itself is not dialed
+		p2pAddr("/ip4/10.0.0.4/tcp/1478", server.host.ID()),
+		// Addresses without a peer ID, or with an unexpected format are dropped
+		multiaddr.StringCast("/ip4/10.0.0.5/tcp/1478"),
+		p2pAddr("/ip4/10.0.0.6/udp/1478", otherID),
+		p2pAddr("/unix/tmp/pex.sock", otherID),
+	}
+
+	peers := server.parsePexAddrs(addrs)
+
+	require.Len(t, peers, 1)
+	assert.Equal(t, peerID, peers[0].ID)
+	assert.Equal(t, addrs[:maxPexAddrsPerPeer], []multiaddr.Multiaddr{
+		p2pAddr(peers[0].Addrs[0].String(), peerID),
+		p2pAddr(peers[0].Addrs[1].String(), peerID),
+	})
+}
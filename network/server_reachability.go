@@ -0,0 +1,233 @@
+package network
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/0xPolygon/polygon-edge/network/common"
+	"github.com/libp2p/go-libp2p/core/network"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/multiformats/go-multiaddr"
+	manet "github.com/multiformats/go-multiaddr/net"
+)
+
+const (
+	// dialBackTimeout is the maximum time spent on a single reachability check,
+	// including all the dial-back attempts
+	dialBackTimeout = 10 * time.Second
+
+	// maxDialBackAddrs is the maximum number of addresses a peer can ask to be dialed back on
+	maxDialBackAddrs = 8
+)
+
+const (
+	dialBackUnreachable byte = iota // none of the addresses could be dialed back
+	dialBackReachable               // at least one of the addresses was dialed back
+)
+
+var (
+	ErrNoConnectedBootnode = errors.New("no connected bootnode")
+	ErrNoAdvertisedAddrs   = errors.New("no advertised addresses")
+)
+
+// ReachabilityResult is the outcome of a public reachability check
+type ReachabilityResult struct {
+	Reachable bool      // flag indicating if the node was dialed back on an advertised address
+	Bootnode  peer.ID   // the bootnode which performed the check
+	CheckedAt time.Time // the time the check finished
+	Err       error     // the reason the check could not be performed, if any
+}
+
+// reachabilityState keeps the result of the last reachability check
+type reachabilityState struct {
+	sync.RWMutex
+
+	result  ReachabilityResult
+	checked bool
+}
+
+// PublicReachabilityCheck returns the result of the last periodic reachability check,
+// in which a bootnode attempts to connect back to the node's advertised addresses.
+// The returned flag is false if no check has finished yet [Thread safe]
+func (s *Server) PublicReachabilityCheck() (ReachabilityResult, bool) {
+	s.reachability.RLock()
+	defer s.reachability.RUnlock()
+
+	return s.reachability.result, s.reachability.checked
+}
+
+// setupDialBack registers the handler for incoming dial-back requests
+func (s *Server) setupDialBack() {
+	s.wrapStream(common.DialBackProto, s.handleDialBack)
+}
+
+// runReachabilityChecks periodically checks if the node is publicly reachable
+func (s *Server) runReachabilityChecks() {
+	for {
+		select {
+		case <-s.clock.After(s.config.ReachabilityCheckInterval):
+		case <-s.closeCh:
+			return
+		}
+
+		result := s.checkPublicReachability()
+
+		if result.Err != nil {
+			s.logger.Debug("Unable to check public reachability", "err", result.Err)
+		} else if !result.Reachable {
+			s.logger.Warn("Node is not reachable on its advertised addresses", "bootnode", result.Bootnode)
+		}
+
+		s.reachability.Lock()
+		s.reachability.result = result
+		s.reachability.checked = true
+		s.reachability.Unlock()
+	}
+}
+
+// checkPublicReachability asks a random connected bootnode
+// to dial back the node's advertised addresses
+func (s *Server) checkPublicReachability() ReachabilityResult {
+	bootnode := s.getRandomConnectedBootnode()
+	if bootnode == nil {
+		return ReachabilityResult{CheckedAt: s.clock.Now(), Err: ErrNoConnectedBootnode}
+	}
+
+	reachable, err := s.requestDialBack(bootnode.ID, s.host.Addrs())
+
+	return ReachabilityResult{
+		Reachable: reachable,
+		Bootnode:  bootnode.ID,
+		CheckedAt: s.clock.Now(),
+		Err:       err,
+	}
+}
+
+// getRandomConnectedBootnode returns a random bootnode the node is connected to
+func (s *Server) getRandomConnectedBootnode() *peer.AddrInfo {
+	connectedNodes := make([]*peer.AddrInfo, 0)
+
+	for _, v := range s.bootnodes.getBootnodes() {
+		if s.IsConnected(v.ID) {
+			connectedNodes = append(connectedNodes, v)
+		}
+	}
+
+	if len(connectedNodes) > 0 {
+		randNum, _ := rand.Int(rand.Reader, big.NewInt(int64(len(connectedNodes))))
+
+		return connectedNodes[randNum.Int64()]
+	}
+
+	return nil
+}
+
+// requestDialBack asks the peer to dial back the given addresses,
+// and returns if any of them could be reached
+func (s *Server) requestDialBack(peerID peer.ID, addrs []multiaddr.Multiaddr) (bool, error) {
+	if len(addrs) == 0 {
+		return false, ErrNoAdvertisedAddrs
+	}
+
+	if len(addrs) > maxDialBackAddrs {
+		addrs = addrs[:maxDialBackAddrs]
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), dialBackTimeout)
+	defer cancel()
+
+	stream, err := s.newStream(ctx, common.DialBackProto, peerID)
+	if err != nil {
+		return false, fmt.Errorf("unable to open dial-back stream, %w", err)
+	}
+
+	defer stream.Close()
+
+	_ = stream.SetDeadline(time.Now().Add(dialBackTimeout))
+
+	// The request is a list of length-prefixed addresses
+	if _, err := stream.Write(encodeAddrList(addrs)); err != nil {
+		return false, fmt.Errorf("unable to send dial-back request, %w", err)
+	}
+
+	_ = stream.CloseWrite()
+
+	response := make([]byte, 1)
+	if _, err := io.ReadFull(stream, response); err != nil {
+		return false, fmt.Errorf("unable to read dial-back response, %w", err)
+	}
+
+	return response[0] == dialBackReachable, nil
+}
+
+// handleDialBack attempts to connect back to the addresses sent by the peer.
+// Only the addresses with the IP the peer is observed from are dialed,
+// so the node can't be used to probe arbitrary hosts
+func (s *Server) handleDialBack(stream network.Stream) {
+	defer stream.Close()
+
+	peerID := stream.Conn().RemotePeer()
+
+	_ = stream.SetDeadline(time.Now().Add(dialBackTimeout))
+
+	addrs, err := readAddrList(stream, maxDialBackAddrs)
+	if err != nil {
+		s.logger.Debug("unable to read dial-back request", "peer", peerID, "err", err)
+
+		return
+	}
+
+	status := dialBackUnreachable
+	if s.dialBack(stream.Conn().RemoteMultiaddr(), addrs) {
+		status = dialBackReachable
+	}
+
+	if _, err := stream.Write([]byte{status}); err != nil {
+		s.logger.Debug("unable to send dial-back response", "peer", peerID, "err", err)
+	}
+}
+
+// dialBack checks if any of the TCP addresses matching
+// the observed peer IP accept connections
+func (s *Server) dialBack(observed multiaddr.Multiaddr, addrs []multiaddr.Multiaddr) bool {
+	observedIP, err := manet.ToIP(observed)
+	if err != nil {
+		// The peer is not directly connected (e.g. relayed)
+		return false
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), dialBackTimeout)
+	defer cancel()
+
+	var dialer manet.Dialer
+
+	for _, addr := range addrs {
+		if _, err := addr.ValueForProtocol(multiaddr.P_TCP); err != nil {
+			continue
+		}
+
+		ip, err := manet.ToIP(addr)
+		if err != nil || !ip.Equal(observedIP) {
+			continue
+		}
+
+		conn, err := dialer.DialContext(ctx, addr)
+		if err != nil {
+			s.logger.Debug("Dial-back attempt failed", "addr", addr, "err", err)
+
+			continue
+		}
+
+		_ = conn.Close()
+
+		return true
+	}
+
+	return false
+}
@@ -0,0 +1,116 @@
+package network
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/0xPolygon/polygon-edge/network/common"
+	"github.com/libp2p/go-libp2p/core/network"
+	"github.com/libp2p/go-libp2p/core/protocol"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// createBootnodeClient creates a server which uses the specified bootnode,
+// and waits for the bootnode connection
+func createBootnodeClient(t *testing.T, bootnode *Server, checkInterval time.Duration) *Server {
+	t.Helper()
+
+	bootnodeAddr, err := common.AddrInfoToString(bootnode.AddrInfo())
+	require.NoError(t, err)
+
+	server, createErr := CreateServer(&CreateServerParams{
+		ConfigCallback: func(c *Config) {
+			c.ReachabilityCheckInterval = checkInterval
+		},
+		ServerCallback: func(server *Server) {
+			server.config.Chain.Bootnodes = []string{bootnodeAddr}
+		},
+	})
+	require.NoError(t, createErr)
+
+	t.Cleanup(func() {
+		assert.NoError(t, server.Close())
+	})
+
+	waitCtx, cancelWait := context.WithTimeout(context.Background(), DefaultJoinTimeout)
+	defer cancelWait()
+
+	_, err = WaitUntilPeerConnectsTo(waitCtx, server, bootnode.host.ID())
+	require.NoError(t, err)
+
+	return server
+}
+
+func TestPublicReachabilityCheck(t *testing.T) {
+	bootnode, createErr := CreateServer(nil)
+	require.NoError(t, createErr)
+
+	t.Cleanup(func() {
+		assert.NoError(t, bootnode.Close())
+	})
+
+	server := createBootnodeClient(t, bootnode, 100*time.Millisecond)
+
+	// The periodic check reports the node is reachable on its listen address
+	require.Eventually(t, func() bool {
+		result, checked := server.PublicReachabilityCheck()
+
+		return checked && result.Err == nil && result.Reachable
+	}, 5*time.Second, 50*time.Millisecond)
+
+	result, _ := server.PublicReachabilityCheck()
+	assert.Equal(t, bootnode.host.ID(), result.Bootnode)
+}
+
+func TestPublicReachabilityCheck_StubBootnode(t *testing.T) {
+	bootnode, createErr := CreateServer(nil)
+	require.NoError(t, createErr)
+
+	t.Cleanup(func() {
+		assert.NoError(t, bootnode.Close())
+	})
+
+	// The stub bootnode reports it is unable to dial back, regardless of the request
+	requestCh := make(chan int, 1)
+
+	bootnode.host.SetStreamHandler(protocol.ID(common.DialBackProto), func(stream network.Stream) {
+		defer stream.Close()
+
+		addrs, err := readAddrList(stream, maxDialBackAddrs)
+		if err != nil {
+			return
+		}
+
+		requestCh <- len(addrs)
+
+		_, _ = stream.Write([]byte{dialBackUnreachable})
+	})
+
+	server := createBootnodeClient(t, bootnode, 0)
+
+	result := server.checkPublicReachability()
+
+	assert.NoError(t, result.Err)
+	assert.False(t, result.Reachable)
+	assert.Equal(t, bootnode.host.ID(), result.Bootnode)
+	assert.Equal(t, len(server.host.Addrs()), <-requestCh)
+
+	// Periodic checks are disabled
+	_, checked := server.PublicReachabilityCheck()
+	assert.False(t, checked)
+}
+
+func TestPublicReachabilityCheck_NoBootnode(t *testing.T) {
+	server, createErr := CreateServer(&CreateServerParams{ConfigCallback: func(c *Config) {
+		c.NoDiscover = true
+	}})
+	require.NoError(t, createErr)
+
+	t.Cleanup(func() {
+		assert.NoError(t, server.Close())
+	})
+
+	assert.ErrorIs(t, server.checkPublicReachability().Err, ErrNoConnectedBootnode)
+}
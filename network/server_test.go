@@ -9,15 +9,23 @@ import (
 	"time"
 
 	"github.com/0xPolygon/polygon-edge/network/common"
+	"github.com/0xPolygon/polygon-edge/network/discovery"
 	peerEvent "github.com/0xPolygon/polygon-edge/network/event"
+	"github.com/0xPolygon/polygon-edge/network/proto"
+	networkTesting "github.com/0xPolygon/polygon-edge/network/testing"
 
 	"github.com/0xPolygon/polygon-edge/helper/tests"
 
+	"github.com/hashicorp/go-hclog"
+	kb "github.com/libp2p/go-libp2p-kbucket"
 	"github.com/libp2p/go-libp2p/core/crypto"
 	"github.com/libp2p/go-libp2p/core/network"
 	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/libp2p/go-libp2p/p2p/protocol/circuitv2/client"
 	"github.com/multiformats/go-multiaddr"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
 )
 
 func TestConnLimit_Inbound(t *testing.T) {
@@ -1092,7 +1100,8 @@ func TestPeerAdditionDeletion(t *testing.T) {
 			assert.True(t, connInfo.connDirections[network.DirInbound])
 		}
 
-		outbound, inbound := extractExpectedDirectionCounts(randomPeers)
+		// The peer is a single logical peer, accounted in the direction it connected in first
+		outbound, inbound := extractExpectedDirectionCounts(randomPeers[:1])
 		validateConnectionCounts(server, outbound, inbound)
 	})
 
@@ -1158,3 +1167,264 @@ func generateRandomPeers(t *testing.T, count int) ([]*randomPeer, error) {
 
 	return randomPeers, nil
 }
+
+func TestDialByID(t *testing.T) {
+	t.Run("discovery disabled", func(t *testing.T) {
+		server, createErr := CreateServer(&CreateServerParams{ConfigCallback: func(c *Config) {
+			c.NoDiscover = true
+		}})
+		require.NoError(t, createErr)
+
+		t.Cleanup(func() {
+			assert.NoError(t, server.Close())
+		})
+
+		assert.ErrorIs(t, server.DialByID(context.Background(), "RandomPeer"), ErrDiscoveryDisabled)
+	})
+
+	t.Run("peer resolved by discovery", func(t *testing.T) {
+		server, createErr := CreateServer(nil)
+		require.NoError(t, createErr)
+
+		t.Cleanup(func() {
+			assert.NoError(t, server.Close())
+		})
+
+		randomPeers, err := generateRandomPeers(t, 2)
+		require.NoError(t, err)
+
+		queriedPeer := randomPeers[0].peerID
+		targetAddr := tests.GenerateTestMultiAddr(t)
+
+		targetInfo, err := peer.AddrInfoFromP2pAddr(targetAddr)
+		require.NoError(t, err)
+
+		// Stub the discovery service, so it resolves the target
+		// by querying a (mock) connected peer
+		mockServer := networkTesting.NewMockNetworkingServer()
+		mockServer.GetMockDiscoveryClient().HookFindPeers(
+			func(
+				_ context.Context,
+				_ *proto.FindPeersReq,
+				_ ...grpc.CallOption,
+			) (*proto.FindPeersResp, error) {
+				return &proto.FindPeersResp{
+					Nodes: []string{targetAddr.String()},
+				}, nil
+			},
+		)
+
+		routingTable, err := kb.NewRoutingTable(
+			defaultBucketSize,
+			kb.ConvertPeerID(server.host.ID()),
+			time.Minute,
+			mockServer.GetMockPeerMetrics(),
+			10*time.Second,
+			nil,
+		)
+		require.NoError(t, err)
+
+		_, err = routingTable.TryAddPeer(queriedPeer, false, false)
+		require.NoError(t, err)
+
+		server.discovery.Close()
+		server.discovery = discovery.NewDiscoveryService(mockServer, routingTable, hclog.NewNullLogger(), 0)
+		server.discovery.Start()
+
+		eventCh, err := server.SubscribeCh(context.Background())
+		require.NoError(t, err)
+
+		require.NoError(t, server.DialByID(context.Background(), targetInfo.ID))
+
+		assert.Equal(t, targetInfo.Addrs, server.host.Peerstore().Addrs(targetInfo.ID))
+
+		select {
+		case evnt := <-eventCh:
+			assert.Equal(t, targetInfo.ID, evnt.PeerID)
+			assert.Equal(t, peerEvent.PeerAddedToDialQueue, evnt.Type)
+		case <-time.After(5 * time.Second):
+			t.Fatal("dial task not added to the dial queue")
+		}
+	})
+}
+
+func TestJoinPeer_RelayedConnection(t *testing.T) {
+	servers, createErr := createServers(3, map[int]*CreateServerParams{
+		0: {ConfigCallback: func(c *Config) {
+			c.NoDiscover = true
+			c.EnableRelayService = true
+		}},
+		1: {ConfigCallback: func(c *Config) { c.NoDiscover = true }},
+		2: {ConfigCallback: func(c *Config) { c.NoDiscover = true }},
+	})
+	require.NoError(t, createErr)
+
+	t.Cleanup(func() {
+		closeTestServers(t, servers)
+	})
+
+	relay, target, source := servers[0], servers[1], servers[2]
+
+	// The target reserves a slot on the relay, so it can be reached through it
+	ctx, cancelFn := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancelFn()
+
+	_, err := tests.RetryUntilTimeout(ctx, func() (interface{}, bool) {
+		_, reserveErr := client.Reserve(ctx, target.host, *relay.AddrInfo())
+
+		return nil, reserveErr != nil
+	})
+	require.NoError(t, err)
+
+	relayAddr, err := common.AddrInfoToString(relay.AddrInfo())
+	require.NoError(t, err)
+
+	circuitAddr := fmt.Sprintf("%s/p2p-circuit/p2p/%s", relayAddr, target.host.ID())
+
+	require.NoError(t, source.JoinPeer(circuitAddr))
+
+	waitCtx, cancelWait := context.WithTimeout(context.Background(), DefaultJoinTimeout)
+	defer cancelWait()
+
+	_, err = WaitUntilPeerConnectsTo(waitCtx, source, target.host.ID())
+	require.NoError(t, err)
+
+	// The relayed connection is tracked as a regular peer connection,
+	// along with the connection to the relay itself
+	assert.True(t, source.hasPeer(target.host.ID()))
+	assert.True(t, source.hasPeer(relay.host.ID()))
+	assert.Len(t, source.Peers(), 2)
+	assert.Equal(t, int64(2), source.connectionCounts.GetOutboundConnCount())
+
+	conns := source.host.Network().ConnsToPeer(target.host.ID())
+	require.NotEmpty(t, conns)
+
+	_, err = conns[0].RemoteMultiaddr().ValueForProtocol(multiaddr.P_CIRCUIT)
+	assert.NoError(t, err)
+}
+
+func TestBootnodeIsRegularPeer(t *testing.T) {
+	bootnode, createErr := CreateServer(nil)
+	require.NoError(t, createErr)
+
+	t.Cleanup(func() {
+		assert.NoError(t, bootnode.Close())
+	})
+
+	bootnodeAddr, err := common.AddrInfoToString(bootnode.AddrInfo())
+	require.NoError(t, err)
+
+	server, createErr := CreateServer(&CreateServerParams{
+		ConfigCallback: func(c *Config) {
+			// The bootnode says goodbye when disconnecting,
+			// which shouldn't delay the redial too much
+			c.GoodbyeBackoff = time.Millisecond
+		},
+		ServerCallback: func(server *Server) {
+			server.config.Chain.Bootnodes = []string{bootnodeAddr}
+		},
+	})
+	require.NoError(t, createErr)
+
+	t.Cleanup(func() {
+		assert.NoError(t, server.Close())
+	})
+
+	// The bootnode is dialed by the discovery service
+	waitCtx, cancelWait := context.WithTimeout(context.Background(), DefaultJoinTimeout)
+	defer cancelWait()
+
+	_, err = WaitUntilPeerConnectsTo(waitCtx, server, bootnode.host.ID())
+	require.NoError(t, err)
+
+	peers := server.Peers()
+	require.Len(t, peers, 1)
+
+	assert.Equal(t, bootnode.host.ID(), peers[0].Info.ID)
+	assert.True(t, peers[0].IsBootnode)
+	assert.True(t, peers[0].connDirections[network.DirOutbound])
+	assert.Equal(t, int64(1), server.GetBootnodeConnCount())
+
+	// The bootnode sees a regular inbound peer
+	bootnodePeers := bootnode.Peers()
+	require.Len(t, bootnodePeers, 1)
+
+	assert.False(t, bootnodePeers[0].IsBootnode)
+	assert.True(t, bootnodePeers[0].connDirections[network.DirInbound])
+
+	// The bootnode is redialed once it disconnects from the node
+	eventCh, err := server.SubscribeCh(waitCtx)
+	require.NoError(t, err)
+
+	bootnode.DisconnectFromPeer(server.host.ID(), "bye")
+
+	expectedEvents := []peerEvent.PeerEventType{
+		peerEvent.PeerDisconnected,
+		peerEvent.PeerAddedToDialQueue,
+		peerEvent.PeerConnected,
+	}
+
+	for _, expectedEvent := range expectedEvents {
+		for evnt := range eventCh {
+			if evnt.PeerID == bootnode.host.ID() && evnt.Type == expectedEvent {
+				break
+			}
+		}
+	}
+
+	require.NoError(t, waitCtx.Err())
+	assert.True(t, server.hasPeer(bootnode.host.ID()))
+}
+
+func TestRandomListenPort(t *testing.T) {
+	natAddr := net.ParseIP("10.1.2.3")
+
+	servers, createErr := createServers(2, map[int]*CreateServerParams{
+		0: {
+			ConfigCallback: func(c *Config) {
+				c.NoDiscover = true
+				c.Addr.Port = 0
+			},
+		},
+		1: {
+			ConfigCallback: func(c *Config) {
+				c.NoDiscover = true
+				c.Addr.Port = 0
+				c.NatAddr = natAddr
+			},
+		},
+	})
+	require.NoError(t, createErr)
+
+	t.Cleanup(func() {
+		closeTestServers(t, servers)
+	})
+
+	for _, server := range servers {
+		boundPort, err := boundTCPPort(server.host)
+		require.NoError(t, err)
+		require.NotZero(t, boundPort)
+
+		for _, addr := range server.AddrInfo().Addrs {
+			port, err := addr.ValueForProtocol(multiaddr.P_TCP)
+			require.NoError(t, err)
+
+			assert.Equal(t, strconv.Itoa(boundPort), port)
+		}
+	}
+
+	// The NAT address is advertised with the bound port
+	boundPort, err := boundTCPPort(servers[1].host)
+	require.NoError(t, err)
+
+	assert.Equal(
+		t,
+		[]multiaddr.Multiaddr{
+			multiaddr.StringCast(fmt.Sprintf("/ip4/%s/tcp/%d", natAddr, boundPort)),
+		},
+		servers[1].AddrInfo().Addrs,
+	)
+
+	// The advertised address of the server without NAT is reachable
+	require.NoError(t, JoinAndWait(servers[1], servers[0], DefaultBufferTimeout, DefaultJoinTimeout))
+}
@@ -0,0 +1,216 @@
+package network
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// DefaultShutdownTimeout is the time Close gives the in-flight work to complete
+// before the host is torn down
+const DefaultShutdownTimeout = 5 * time.Second
+
+// streamHandlerTracker keeps track of the running protocol stream handlers,
+// so the shutdown can stop accepting new streams, and wait for the running ones
+type streamHandlerTracker struct {
+	sync.Mutex
+
+	running  int           // the number of the running handlers
+	draining bool          // flag indicating if new handlers are refused
+	idle     chan struct{} // channel closed once draining and no handler is running
+}
+
+// newStreamHandlerTracker creates a new stream handler tracker
+func newStreamHandlerTracker() *streamHandlerTracker {
+	return &streamHandlerTracker{
+		idle: make(chan struct{}),
+	}
+}
+
+// enter registers a starting handler. Returns false if the handlers are drained,
+// in which case the stream should be refused [Thread safe]
+func (t *streamHandlerTracker) enter() bool {
+	t.Lock()
+	defer t.Unlock()
+
+	if t.draining {
+		return false
+	}
+
+	t.running++
+
+	return true
+}
+
+// exit marks a handler registered by enter as finished [Thread safe]
+func (t *streamHandlerTracker) exit() {
+	t.Lock()
+	defer t.Unlock()
+
+	t.running--
+
+	if t.draining && t.running == 0 {
+		close(t.idle)
+	}
+}
+
+// drain refuses any new handlers, and waits for the running ones to finish,
+// or for the context to be done [BLOCKING]
+func (t *streamHandlerTracker) drain(ctx context.Context) error {
+	t.Lock()
+
+	if !t.draining {
+		t.draining = true
+
+		if t.running == 0 {
+			close(t.idle)
+		}
+	}
+
+	t.Unlock()
+
+	select {
+	case <-t.idle:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// gracefulProtocol is a protocol serving its calls apart from the stream handler, e.g. a gRPC one,
+// so the calls in flight have to be waited for separately on shutdown
+type gracefulProtocol interface {
+	// GracefulStop stops accepting new calls, and waits for the in-flight ones to complete,
+	// or for the context to be done [BLOCKING]
+	GracefulStop(ctx context.Context) error
+}
+
+// stopProtocols gracefully stops the registered protocols serving their calls apart from
+// the stream handlers, up to the context deadline [BLOCKING]
+func (s *Server) stopProtocols(ctx context.Context) error {
+	s.protocolsLock.Lock()
+
+	protocols := make([]gracefulProtocol, 0, len(s.protocols))
+
+	for _, p := range s.protocols {
+		if graceful, ok := p.(gracefulProtocol); ok {
+			protocols = append(protocols, graceful)
+		}
+	}
+
+	s.protocolsLock.Unlock()
+
+	var (
+		wg       sync.WaitGroup
+		errLock  sync.Mutex
+		firstErr error
+	)
+
+	for _, p := range protocols {
+		wg.Add(1)
+
+		go func(p gracefulProtocol) {
+			defer wg.Done()
+
+			if err := p.GracefulStop(ctx); err != nil {
+				errLock.Lock()
+				if firstErr == nil {
+					firstErr = err
+				}
+				errLock.Unlock()
+			}
+		}(p)
+	}
+
+	wg.Wait()
+
+	return firstErr
+}
+
+// Shutdown gracefully stops the networking server. New streams and dials are refused first,
+// then the gossip queues are flushed, and the running protocol stream handlers and gRPC calls
+// are waited for, up to the context deadline. The host is torn down either way afterwards.
+// Returns an error if the in-flight work didn't complete in time, or the teardown failed
+func (s *Server) Shutdown(ctx context.Context) error {
+	// Stop accepting new streams and dials
+	drainDone := make(chan error, 1)
+	protocolsDone := make(chan error, 1)
+
+	go func() {
+		drainDone <- s.streamHandlers.drain(ctx)
+	}()
+
+	go func() {
+		protocolsDone <- s.stopProtocols(ctx)
+	}()
+
+	s.dialQueue.Close()
+
+	// Remember the connected peers, so they are redialed first after a restart
+	s.saveConnectedPeers()
+
+	// Give the queued gossip messages a chance to reach the peers
+	flushCtx := ctx
+
+	if timeout := s.config.GossipFlushTimeout; timeout > 0 {
+		var cancel context.CancelFunc
+
+		flushCtx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	if err := s.FlushGossip(flushCtx); err != nil {
+		s.logger.Debug("Unable to flush the gossip queues", "err", err)
+	}
+
+	// Let the running protocol handlers complete their exchanges
+	drainErr := <-drainDone
+	if drainErr != nil {
+		s.logger.Warn("Protocol stream handlers still running on shutdown", "err", drainErr)
+	}
+
+	protocolsErr := <-protocolsDone
+	if protocolsErr != nil {
+		s.logger.Warn("gRPC calls still running on shutdown", "err", protocolsErr)
+	}
+
+	if err := s.teardown(); err != nil {
+		return err
+	}
+
+	if drainErr != nil {
+		return fmt.Errorf("unable to drain the protocol stream handlers, %w", drainErr)
+	}
+
+	if protocolsErr != nil {
+		return fmt.Errorf("unable to drain the gRPC calls, %w", protocolsErr)
+	}
+
+	return nil
+}
+
+// teardown lets the peers know the node is going away, and closes the host and the services
+func (s *Server) teardown() error {
+	s.sendGoodbyeToAll(GoodbyeReasonShutdown)
+
+	err := s.host.Close()
+
+	// The datastore is closed only after the host closed the peerstore on top of it
+	if s.peerstoreDatastore != nil {
+		if closeErr := s.peerstoreDatastore.Close(); closeErr != nil && err == nil {
+			err = closeErr
+		}
+	}
+
+	if s.discoverer != nil {
+		s.discoverer.Close()
+	}
+
+	close(s.closeCh)
+
+	// Drain the dial loop and the in-flight dials
+	s.dialing.Wait()
+
+	return err
+}
@@ -0,0 +1,206 @@
+package network
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/0xPolygon/polygon-edge/network/grpc"
+	"github.com/0xPolygon/polygon-edge/network/proto"
+	"github.com/libp2p/go-libp2p/core/network"
+	"github.com/libp2p/go-libp2p/core/protocol"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const slowTestProto = "/slow-test/0.1"
+
+func TestShutdown_DrainsStreamHandlers(t *testing.T) {
+	const handlerDelay = 500 * time.Millisecond
+
+	servers, createErr := createServers(2, map[int]*CreateServerParams{
+		0: {ConfigCallback: func(c *Config) { c.NoDiscover = true }},
+		1: {ConfigCallback: func(c *Config) { c.NoDiscover = true }},
+	})
+	require.NoError(t, createErr)
+
+	server, remote := servers[0], servers[1]
+
+	t.Cleanup(func() {
+		assert.NoError(t, remote.Close())
+	})
+
+	require.NoError(t, JoinAndWait(remote, server, DefaultBufferTimeout, DefaultJoinTimeout))
+
+	handlerStarted := make(chan struct{})
+
+	server.wrapStream(slowTestProto, func(stream network.Stream) {
+		defer stream.Close()
+
+		close(handlerStarted)
+		time.Sleep(handlerDelay)
+
+		_, _ = stream.Write([]byte("done"))
+	})
+
+	stream, err := remote.host.NewStream(context.Background(), server.host.ID(), protocol.ID(slowTestProto))
+	require.NoError(t, err)
+
+	// The stream is negotiated lazily, on the first write
+	_, err = stream.Write([]byte("request"))
+	require.NoError(t, err)
+
+	select {
+	case <-handlerStarted:
+	case <-time.After(5 * time.Second):
+		t.Fatal("stream handler didn't start")
+	}
+
+	shutdownDone := make(chan error, 1)
+
+	go func() {
+		shutdownDone <- server.Shutdown(context.Background())
+	}()
+
+	// No new streams are accepted while shutting down
+	require.Eventually(t, func() bool {
+		return !server.streamHandlers.enter()
+	}, time.Second, 10*time.Millisecond)
+
+	// The running handler completes its exchange before the host is torn down
+	response, err := io.ReadAll(stream)
+	require.NoError(t, err)
+	assert.Equal(t, "done", string(response))
+
+	select {
+	case err := <-shutdownDone:
+		assert.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("shutdown didn't complete")
+	}
+}
+
+func TestShutdown_Deadline(t *testing.T) {
+	servers, createErr := createServers(2, map[int]*CreateServerParams{
+		0: {ConfigCallback: func(c *Config) { c.NoDiscover = true }},
+		1: {ConfigCallback: func(c *Config) { c.NoDiscover = true }},
+	})
+	require.NoError(t, createErr)
+
+	server, remote := servers[0], servers[1]
+
+	t.Cleanup(func() {
+		assert.NoError(t, remote.Close())
+	})
+
+	require.NoError(t, JoinAndWait(remote, server, DefaultBufferTimeout, DefaultJoinTimeout))
+
+	handlerStarted := make(chan struct{})
+
+	// The handler waits for a request that never completes
+	server.wrapStream(slowTestProto, func(stream network.Stream) {
+		close(handlerStarted)
+
+		_, _ = io.Copy(io.Discard, stream)
+	})
+
+	stream, err := remote.host.NewStream(context.Background(), server.host.ID(), protocol.ID(slowTestProto))
+	require.NoError(t, err)
+
+	_, err = stream.Write([]byte("request"))
+	require.NoError(t, err)
+
+	select {
+	case <-handlerStarted:
+	case <-time.After(5 * time.Second):
+		t.Fatal("stream handler didn't start")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	// The host is torn down once the deadline passes, tearing down the stuck handler
+	err = server.Shutdown(ctx)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	assert.Empty(t, server.host.Network().Conns())
+}
+
+// slowDiscoveryService is a discovery service answering the queries after a delay
+type slowDiscoveryService struct {
+	proto.UnimplementedDiscoveryServer
+
+	delay   time.Duration
+	started chan struct{}
+}
+
+func (s *slowDiscoveryService) FindPeers(context.Context, *proto.FindPeersReq) (*proto.FindPeersResp, error) {
+	close(s.started)
+	time.Sleep(s.delay)
+
+	return &proto.FindPeersResp{Nodes: []string{"done"}}, nil
+}
+
+func TestShutdown_DrainsGrpcCalls(t *testing.T) {
+	const slowGrpcProto = "/slow-grpc-test/0.1"
+
+	servers, createErr := createServers(2, map[int]*CreateServerParams{
+		0: {ConfigCallback: func(c *Config) { c.NoDiscover = true }},
+		1: {ConfigCallback: func(c *Config) { c.NoDiscover = true }},
+	})
+	require.NoError(t, createErr)
+
+	server, remote := servers[0], servers[1]
+
+	t.Cleanup(func() {
+		assert.NoError(t, remote.Close())
+	})
+
+	require.NoError(t, JoinAndWait(remote, server, DefaultBufferTimeout, DefaultJoinTimeout))
+
+	service := &slowDiscoveryService{delay: 500 * time.Millisecond, started: make(chan struct{})}
+
+	grpcStream := grpc.NewGrpcStream()
+	proto.RegisterDiscoveryServer(grpcStream.GrpcServer(), service)
+	grpcStream.Serve()
+	server.RegisterProtocol(slowGrpcProto, grpcStream)
+
+	remote.RegisterProtocol(slowGrpcProto, grpc.NewGrpcStream())
+
+	clientConn, err := remote.NewProtoConnection(slowGrpcProto, server.host.ID())
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		_ = clientConn.Close()
+	})
+
+	type callResult struct {
+		resp *proto.FindPeersResp
+		err  error
+	}
+
+	callDone := make(chan callResult, 1)
+
+	go func() {
+		resp, err := proto.NewDiscoveryClient(clientConn).FindPeers(context.Background(), &proto.FindPeersReq{Count: 1})
+		callDone <- callResult{resp, err}
+	}()
+
+	select {
+	case <-service.started:
+	case <-time.After(5 * time.Second):
+		t.Fatal("gRPC call didn't start")
+	}
+
+	// The in-flight call completes before the host is torn down
+	assert.NoError(t, server.Shutdown(context.Background()))
+
+	select {
+	case result := <-callDone:
+		require.NoError(t, result.err)
+		assert.Equal(t, []string{"done"}, result.resp.Nodes)
+	case <-time.After(5 * time.Second):
+		t.Fatal("gRPC call didn't complete")
+	}
+}
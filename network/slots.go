@@ -2,6 +2,7 @@ package network
 
 import (
 	"context"
+	"sync"
 )
 
 // Slots is synchronization structure
@@ -37,3 +38,81 @@ func (s Slots) Release() {
 	default: // No slot available to release, do nothing
 	}
 }
+
+// ResizableSlots is a Slots counterpart whose number of slots can be changed at runtime.
+// The slots that are taken are kept over a resize, and routines waiting in Take
+// keep waiting until a slot is available under the new size
+type ResizableSlots struct {
+	lock     sync.Mutex
+	maximal  int64
+	taken    int64
+	changeCh chan struct{} // closed and replaced whenever the taken slots or the size change
+}
+
+// NewResizableSlots creates ResizableSlots object with maximal slots available
+func NewResizableSlots(maximal int64) *ResizableSlots {
+	return &ResizableSlots{
+		maximal:  maximal,
+		changeCh: make(chan struct{}),
+	}
+}
+
+// Take takes slot if available or blocks until slot is available or context is done
+func (s *ResizableSlots) Take(ctx context.Context) bool {
+	for {
+		s.lock.Lock()
+
+		if s.taken < s.maximal {
+			s.taken++
+			s.lock.Unlock()
+
+			return false
+		}
+
+		changeCh := s.changeCh
+		s.lock.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return true
+		case <-changeCh:
+		}
+	}
+}
+
+// Release returns back one slot. If all slots are already released, nothing will happen
+func (s *ResizableSlots) Release() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.taken == 0 {
+		return
+	}
+
+	s.taken--
+	s.notifyChange()
+}
+
+// Resize changes the number of slots. If it is lowered below the taken slots,
+// no slot is available until enough of them are released
+func (s *ResizableSlots) Resize(maximal int64) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.maximal = maximal
+	s.notifyChange()
+}
+
+// Size returns the number of slots
+func (s *ResizableSlots) Size() int64 {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return s.maximal
+}
+
+// notifyChange wakes up the routines waiting in Take. Must be called with the lock held
+func (s *ResizableSlots) notifyChange() {
+	close(s.changeCh)
+	s.changeCh = make(chan struct{})
+}
@@ -39,3 +39,52 @@ func TestSlots(t *testing.T) {
 	assert.False(t, closed2)
 	assert.GreaterOrEqual(t, time.Now().UTC(), tm.Add(time.Millisecond*500*2))
 }
+
+func TestResizableSlots_WaiterKeepsLimitOverResize(t *testing.T) {
+	t.Parallel()
+
+	slots := NewResizableSlots(2)
+
+	assert.False(t, slots.Take(context.Background()))
+	assert.False(t, slots.Take(context.Background()))
+
+	taken := make(chan struct{})
+
+	go func() {
+		if !slots.Take(context.Background()) {
+			close(taken)
+		}
+	}()
+
+	// Lowering the size doesn't hand a slot to the waiting routine
+	slots.Resize(1)
+
+	select {
+	case <-taken:
+		t.Fatal("slot taken over the limit")
+	case <-time.After(200 * time.Millisecond):
+	}
+
+	// Still at the limit after one of the two taken slots is released
+	slots.Release()
+
+	select {
+	case <-taken:
+		t.Fatal("slot taken over the limit")
+	case <-time.After(200 * time.Millisecond):
+	}
+
+	// Raising the size makes a slot available
+	slots.Resize(2)
+
+	select {
+	case <-taken:
+	case <-time.After(time.Second):
+		t.Fatal("slot not taken after the resize")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	assert.True(t, slots.Take(ctx))
+}
@@ -0,0 +1,43 @@
+package network
+
+import (
+	"time"
+
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/libp2p/go-libp2p/p2p/net/swarm"
+)
+
+// waitStartupDialDelay holds back the first dials for the configured startup delay,
+// so the node connectivity can come up. Returns false if the server is closed in the meantime
+func (s *Server) waitStartupDialDelay() bool {
+	if s.config.StartupDialDelay <= 0 {
+		return true
+	}
+
+	s.logger.Debug("Delaying the first dials", "delay", s.config.StartupDialDelay)
+
+	select {
+	case <-s.closeCh:
+		return false
+	case <-s.clock.After(s.config.StartupDialDelay):
+		return true
+	}
+}
+
+// inStartupGrace checks if the dial loop, started at the given time,
+// is still within the configured startup grace period
+func (s *Server) inStartupGrace(startedAt time.Time) bool {
+	return s.clock.Now().Sub(startedAt) < s.config.StartupGracePeriod
+}
+
+// forgiveDialFailure reverts the dial backoff escalation of a failed dial to the peer,
+// as the failure is likely caused by the node connectivity not being up yet
+func (s *Server) forgiveDialFailure(peerID peer.ID) {
+	s.logger.Debug("Dial failed during the startup grace period, not backing off", "peer", peerID)
+
+	s.dialRate.refund(peerID)
+
+	if sw, ok := s.host.Network().(*swarm.Swarm); ok {
+		sw.Backoff().Clear(peerID)
+	}
+}
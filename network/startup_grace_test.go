@@ -0,0 +1,120 @@
+package network
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	peerEvent "github.com/0xPolygon/polygon-edge/network/event"
+	"github.com/libp2p/go-libp2p/core/host"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// offlineHost is a host which fails all the dials while it is offline
+type offlineHost struct {
+	host.Host
+
+	offline atomic.Bool
+}
+
+func (h *offlineHost) Connect(ctx context.Context, pi peer.AddrInfo) error {
+	if h.offline.Load() {
+		return errors.New("network is unreachable")
+	}
+
+	return h.Host.Connect(ctx, pi)
+}
+
+func TestStartupGracePeriod(t *testing.T) {
+	const maxDials = 2
+
+	testTable := []struct {
+		name         string
+		gracePeriod  time.Duration
+		backoffAfter bool
+	}{
+		{
+			"failures within the grace period are forgiven",
+			time.Minute,
+			false,
+		},
+		{
+			"failures without the grace period back off",
+			0,
+			true,
+		},
+	}
+
+	for _, testCase := range testTable {
+		testCase := testCase
+
+		t.Run(testCase.name, func(t *testing.T) {
+			var offline *offlineHost
+
+			servers, createErr := createServers(2, map[int]*CreateServerParams{
+				0: {
+					ConfigCallback: func(c *Config) {
+						c.NoDiscover = true
+						c.MaxDialsPerPeer = maxDials
+						c.StartupGracePeriod = testCase.gracePeriod
+					},
+					ServerCallback: func(server *Server) {
+						// The node has no connectivity right after start
+						offline = &offlineHost{Host: server.host}
+						offline.offline.Store(true)
+
+						server.host = offline
+					},
+				},
+				1: {ConfigCallback: func(c *Config) { c.NoDiscover = true }},
+			})
+			require.NoError(t, createErr)
+
+			t.Cleanup(func() {
+				closeTestServers(t, servers)
+			})
+
+			server, bootnode := servers[0], servers[1]
+			bootnodeID := bootnode.host.ID()
+
+			failures := make(chan struct{}, maxDials+1)
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			require.NoError(t, server.Subscribe(ctx, func(evnt *peerEvent.PeerEvent) {
+				if evnt.Type == peerEvent.PeerFailedToConnect && evnt.PeerID == bootnodeID {
+					failures <- struct{}{}
+				}
+			}))
+
+			// Dial the bootnode more times than the dial rate limit allows
+			for i := 0; i < maxDials; i++ {
+				require.NoError(t, server.joinPeer(bootnode.AddrInfo()))
+
+				select {
+				case <-failures:
+				case <-time.After(5 * time.Second):
+					t.Fatal("dial did not fail")
+				}
+			}
+
+			if testCase.backoffAfter {
+				assert.Greater(t, server.dialRate.retryAfter(bootnodeID, time.Now()), time.Duration(0))
+
+				return
+			}
+
+			// The bootnode is not backed off, so it is dialed right away once the connectivity is up
+			assert.Zero(t, server.dialRate.retryAfter(bootnodeID, time.Now()))
+
+			offline.offline.Store(false)
+
+			require.NoError(t, JoinAndWait(server, bootnode, DefaultBufferTimeout, 5*time.Second))
+		})
+	}
+}
@@ -0,0 +1,238 @@
+package network
+
+import (
+	"errors"
+	"io"
+	"sync"
+	"sync/atomic"
+
+	"github.com/armon/go-metrics"
+	"github.com/libp2p/go-libp2p/core/network"
+	"github.com/libp2p/go-libp2p/core/peer"
+)
+
+var (
+	// ErrTooManyStreams is returned when a stream can't be opened due to the node-wide stream limit
+	ErrTooManyStreams = errors.New("node-wide stream limit reached")
+
+	// ErrTooManyPeerStreams is returned when a stream can't be opened due to the per-peer outbound stream limit
+	ErrTooManyPeerStreams = errors.New("outbound stream limit of the peer reached")
+)
+
+// isStreamAllowed checks if the incoming stream is within the per-connection stream limit.
+// The limit applies to all the streams on the connection, regardless of their protocol
+// and direction, and serves as a backstop against muxer resource exhaustion
+func (s *Server) isStreamAllowed(stream network.Stream) bool {
+	if s.config.MaxStreamsPerConn <= 0 {
+		return true
+	}
+
+	return len(stream.Conn().GetStreams()) <= s.config.MaxStreamsPerConn
+}
+
+// resetExcessStream resets the stream that is over the per-connection stream limit
+func (s *Server) resetExcessStream(stream network.Stream) {
+	s.logger.Debug(
+		"Resetting stream, connection stream limit reached",
+		"protocol", stream.Protocol(),
+		"peer", stream.Conn().RemotePeer(),
+	)
+
+	metrics.IncrCounter([]string{networkMetrics, "streams_reset_over_limit"}, 1)
+
+	_ = stream.Reset()
+}
+
+// openStreamTracker keeps count of the streams served or opened by the networking server, node-wide.
+// A stream is open until it is closed or reset on either side, or until its connection closes
+type openStreamTracker struct {
+	count atomic.Int64 // the number of currently open streams
+
+	lock     sync.Mutex
+	streams  map[network.Conn]map[*countedStream]struct{} // conn -> open streams
+	outbound map[peer.ID]int                              // peerID -> number of streams opened (or being opened) to the peer
+}
+
+// newOpenStreamTracker creates a new open stream tracker
+func newOpenStreamTracker() *openStreamTracker {
+	return &openStreamTracker{
+		streams:  make(map[network.Conn]map[*countedStream]struct{}),
+		outbound: make(map[peer.ID]int),
+	}
+}
+
+// reserveOutbound reserves an outbound stream to the peer, unless the per-peer limit
+// is reached (unlimited if 0). The reservation is held by the stream opened with it,
+// or has to be returned with unreserveOutbound if the stream isn't opened [Thread safe]
+func (t *openStreamTracker) reserveOutbound(peerID peer.ID, limit int) bool {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if limit > 0 && t.outbound[peerID] >= limit {
+		return false
+	}
+
+	t.outbound[peerID]++
+
+	return true
+}
+
+// unreserveOutbound returns an outbound stream reservation of the peer [Thread safe]
+func (t *openStreamTracker) unreserveOutbound(peerID peer.ID) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	t.unreserveOutboundLocked(peerID)
+}
+
+// unreserveOutboundLocked returns an outbound stream reservation of the peer.
+// The tracker lock has to be held
+func (t *openStreamTracker) unreserveOutboundLocked(peerID peer.ID) {
+	if t.outbound[peerID]--; t.outbound[peerID] <= 0 {
+		delete(t.outbound, peerID)
+	}
+}
+
+// outboundCount returns the number of streams opened to the peer [Thread safe]
+func (t *openStreamTracker) outboundCount(peerID peer.ID) int {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	return t.outbound[peerID]
+}
+
+// open counts the stream as open, unless the limit is reached (unlimited if 0).
+// An outbound stream takes over the reservation it was opened with.
+// Returns the counted stream, which is released once closed [Thread safe]
+func (t *openStreamTracker) open(stream network.Stream, limit int, outbound bool) (*countedStream, bool) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if limit > 0 && t.count.Load() >= int64(limit) {
+		return nil, false
+	}
+
+	counted := &countedStream{Stream: stream, tracker: t, outbound: outbound}
+
+	conn := stream.Conn()
+	if _, ok := t.streams[conn]; !ok {
+		t.streams[conn] = make(map[*countedStream]struct{})
+	}
+
+	t.streams[conn][counted] = struct{}{}
+	t.count.Add(1)
+
+	return counted, true
+}
+
+// release stops counting the stream as open. Releasing a stream more than once is a no-op [Thread safe]
+func (t *openStreamTracker) release(stream *countedStream) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	conn := stream.Conn()
+
+	streams, ok := t.streams[conn]
+	if !ok {
+		return
+	}
+
+	if _, ok := streams[stream]; !ok {
+		return
+	}
+
+	delete(streams, stream)
+	t.count.Add(-1)
+
+	if stream.outbound {
+		t.unreserveOutboundLocked(conn.RemotePeer())
+	}
+
+	if len(streams) == 0 {
+		delete(t.streams, conn)
+	}
+}
+
+// connClosed releases all the streams of the closed connection [Thread safe]
+func (t *openStreamTracker) connClosed(conn network.Conn) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	t.count.Add(-int64(len(t.streams[conn])))
+
+	for stream := range t.streams[conn] {
+		if stream.outbound {
+			t.unreserveOutboundLocked(conn.RemotePeer())
+		}
+	}
+
+	delete(t.streams, conn)
+}
+
+// countedStream is a stream accounted in the node-wide open stream count
+type countedStream struct {
+	network.Stream
+
+	tracker  *openStreamTracker
+	outbound bool // flag indicating if the stream is accounted in the outbound streams of the peer
+}
+
+// Read reads from the stream. Once the remote side closed or reset the stream,
+// it is released from the open stream count
+func (s *countedStream) Read(p []byte) (int, error) {
+	n, err := s.Stream.Read(p)
+	if errors.Is(err, io.EOF) || errors.Is(err, network.ErrReset) {
+		s.tracker.release(s)
+	}
+
+	return n, err
+}
+
+// Write writes to the stream. Once the remote side reset the stream,
+// it is released from the open stream count
+func (s *countedStream) Write(p []byte) (int, error) {
+	n, err := s.Stream.Write(p)
+	if errors.Is(err, network.ErrReset) {
+		s.tracker.release(s)
+	}
+
+	return n, err
+}
+
+// Close closes the stream, and releases it from the open stream count
+func (s *countedStream) Close() error {
+	defer s.tracker.release(s)
+
+	return s.Stream.Close()
+}
+
+// Reset resets the stream, and releases it from the open stream count
+func (s *countedStream) Reset() error {
+	defer s.tracker.release(s)
+
+	return s.Stream.Reset()
+}
+
+// OpenStreamCount returns the number of the streams currently open node-wide,
+// both the served and the opened ones [Thread safe]
+func (s *Server) OpenStreamCount() int {
+	return int(s.openStreams.count.Load())
+}
+
+// OutboundStreamCount returns the number of the streams currently opened to the peer [Thread safe]
+func (s *Server) OutboundStreamCount(peerID peer.ID) int {
+	return s.openStreams.outboundCount(peerID)
+}
+
+// resetStreamOverTotalLimit resets the incoming stream that is over the node-wide stream limit
+func (s *Server) resetStreamOverTotalLimit(stream network.Stream) {
+	s.logger.Debug(
+		"Resetting stream, node-wide stream limit reached",
+		"protocol", stream.Protocol(),
+		"peer", stream.Conn().RemotePeer(),
+	)
+
+	metrics.IncrCounter([]string{networkMetrics, "streams_reset_over_total_limit"}, 1)
+
+	_ = stream.Reset()
+}
@@ -0,0 +1,417 @@
+package network
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/libp2p/go-libp2p/core/network"
+	"github.com/libp2p/go-libp2p/core/protocol"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	rawGrpc "google.golang.org/grpc"
+)
+
+// holdProtocol is a protocol which keeps the incoming streams open
+type holdProtocol struct{}
+
+func (holdProtocol) Client(network.Stream) (*rawGrpc.ClientConn, error) {
+	return nil, nil
+}
+
+func (holdProtocol) Handler() func(network.Stream) {
+	return func(stream network.Stream) {
+		go func() {
+			_, _ = io.Copy(io.Discard, stream)
+		}()
+	}
+}
+
+func TestMaxStreamsPerConn(t *testing.T) {
+	const (
+		maxStreams = 8
+		numStreams = maxStreams + 4
+	)
+
+	protocols := []string{"/hold-a/0.1", "/hold-b/0.1", "/hold-c/0.1"}
+
+	servers, createErr := createServers(2, map[int]*CreateServerParams{
+		0: {ConfigCallback: func(c *Config) {
+			c.NoDiscover = true
+			c.DisablePubSub = true
+			c.MaxStreamsPerConn = maxStreams
+		}},
+		1: {ConfigCallback: func(c *Config) {
+			c.NoDiscover = true
+			c.DisablePubSub = true
+		}},
+	})
+	require.NoError(t, createErr)
+
+	t.Cleanup(func() {
+		closeTestServers(t, servers)
+	})
+
+	server, client := servers[0], servers[1]
+
+	for _, id := range protocols {
+		server.RegisterProtocol(id, holdProtocol{})
+	}
+
+	require.NoError(t, JoinAndWait(client, server, DefaultBufferTimeout, DefaultJoinTimeout))
+
+	conns := server.host.Network().ConnsToPeer(client.host.ID())
+	require.Len(t, conns, 1)
+
+	baseline := len(conns[0].GetStreams())
+	require.Less(t, baseline, maxStreams)
+
+	// Open streams across multiple protocols on the same connection
+	streams := make([]network.Stream, numStreams)
+
+	for i := 0; i < numStreams; i++ {
+		stream, err := client.host.NewStream(
+			context.Background(),
+			server.host.ID(),
+			protocol.ID(protocols[i%len(protocols)]),
+		)
+		require.NoError(t, err)
+
+		t.Cleanup(func() {
+			_ = stream.Reset()
+		})
+
+		// Streams are negotiated lazily, so make sure the server handles them in order
+		_, err = stream.Write([]byte(fmt.Sprintf("stream %d", i)))
+		require.NoError(t, err)
+
+		require.Eventually(t, func() bool {
+			return len(conns[0].GetStreams()) >= baseline+i+1 || isStreamReset(stream)
+		}, time.Second, 10*time.Millisecond)
+
+		streams[i] = stream
+	}
+
+	// The streams above the cap are reset
+	reset := 0
+
+	for _, stream := range streams {
+		if isStreamReset(stream) {
+			reset++
+		}
+	}
+
+	assert.Equal(t, numStreams-(maxStreams-baseline), reset)
+	assert.LessOrEqual(t, len(conns[0].GetStreams()), maxStreams)
+}
+
+// isStreamReset checks if the remote side reset the stream
+func isStreamReset(stream network.Stream) bool {
+	_ = stream.SetReadDeadline(time.Now().Add(50 * time.Millisecond))
+	defer func() {
+		_ = stream.SetReadDeadline(time.Time{})
+	}()
+
+	_, err := stream.Read(make([]byte, 1))
+
+	return err != nil && !isTimeout(err)
+}
+
+// isTimeout checks if the error is a timeout error
+func isTimeout(err error) bool {
+	timeoutErr, ok := err.(interface{ Timeout() bool })
+
+	return ok && timeoutErr.Timeout()
+}
+
+// closeProtocol is a protocol which closes the incoming streams once the remote side is done
+type closeProtocol struct{}
+
+func (closeProtocol) Client(network.Stream) (*rawGrpc.ClientConn, error) {
+	return nil, nil
+}
+
+func (closeProtocol) Handler() func(network.Stream) {
+	return func(stream network.Stream) {
+		go func() {
+			_, _ = io.Copy(io.Discard, stream)
+			_ = stream.Close()
+		}()
+	}
+}
+
+// resetProtocol is a protocol which resets the incoming streams
+type resetProtocol struct{}
+
+func (resetProtocol) Client(network.Stream) (*rawGrpc.ClientConn, error) {
+	return nil, nil
+}
+
+func (resetProtocol) Handler() func(network.Stream) {
+	return func(stream network.Stream) {
+		_ = stream.Reset()
+	}
+}
+
+func TestOpenStreams_ReleasedByRemote(t *testing.T) {
+	const (
+		closeProto = "/close/0.1"
+		resetProto = "/reset/0.1"
+	)
+
+	servers, createErr := createServers(2, map[int]*CreateServerParams{
+		0: {ConfigCallback: func(c *Config) {
+			c.NoDiscover = true
+			c.DisablePubSub = true
+		}},
+		1: {ConfigCallback: func(c *Config) {
+			c.NoDiscover = true
+			c.DisablePubSub = true
+		}},
+	})
+	require.NoError(t, createErr)
+
+	t.Cleanup(func() {
+		closeTestServers(t, servers)
+	})
+
+	server, remote := servers[0], servers[1]
+
+	remote.RegisterProtocol(closeProto, closeProtocol{})
+	remote.RegisterProtocol(resetProto, resetProtocol{})
+
+	require.NoError(t, JoinAndWait(server, remote, DefaultBufferTimeout, DefaultJoinTimeout))
+
+	baseline := server.OpenStreamCount()
+	outboundBaseline := server.OutboundStreamCount(remote.host.ID())
+
+	// The stream closed by the remote side is released once its end is read
+	closedStream, err := server.NewStream(closeProto, remote.host.ID())
+	require.NoError(t, err)
+
+	assert.Equal(t, baseline+1, server.OpenStreamCount())
+
+	_, err = closedStream.Write([]byte("request"))
+	require.NoError(t, err)
+	require.NoError(t, closedStream.CloseWrite())
+
+	_, err = io.ReadAll(closedStream)
+	require.NoError(t, err)
+
+	assert.Equal(t, baseline, server.OpenStreamCount())
+
+	// The stream reset by the remote side is released once the reset is seen
+	resetStream, err := server.NewStream(resetProto, remote.host.ID())
+	require.NoError(t, err)
+
+	assert.Equal(t, baseline+1, server.OpenStreamCount())
+
+	_, _ = resetStream.Write([]byte("request"))
+	_, err = io.ReadAll(resetStream)
+	require.ErrorIs(t, err, network.ErrReset)
+
+	assert.Equal(t, baseline, server.OpenStreamCount())
+	assert.Equal(t, outboundBaseline, server.OutboundStreamCount(remote.host.ID()))
+}
+
+func TestMaxTotalStreams(t *testing.T) {
+	const (
+		closeProto = "/close/0.1"
+		holdProto  = "/hold/0.1"
+
+		numStreams = 4
+	)
+
+	servers, createErr := createServers(3, map[int]*CreateServerParams{
+		0: {ConfigCallback: func(c *Config) {
+			c.NoDiscover = true
+			c.DisablePubSub = true
+			c.MaxStreamsPerConn = 0
+		}},
+		1: {ConfigCallback: func(c *Config) {
+			c.NoDiscover = true
+			c.DisablePubSub = true
+		}},
+		2: {ConfigCallback: func(c *Config) {
+			c.NoDiscover = true
+			c.DisablePubSub = true
+		}},
+	})
+	require.NoError(t, createErr)
+
+	t.Cleanup(func() {
+		closeTestServers(t, servers)
+	})
+
+	server, clients := servers[0], servers[1:]
+
+	server.RegisterProtocol(closeProto, closeProtocol{})
+	server.RegisterProtocol(holdProto, holdProtocol{})
+	clients[0].RegisterProtocol(closeProto, closeProtocol{})
+
+	for _, client := range clients {
+		require.NoError(t, JoinAndWait(client, server, DefaultBufferTimeout, DefaultJoinTimeout))
+	}
+
+	// The handshake streams are accounted as well
+	baseline := server.OpenStreamCount()
+	maxStreams := baseline + numStreams + 1
+
+	server.config.MaxTotalStreams = maxStreams
+
+	// Open streams from both peers, over both protocols, until the cap trips
+	var (
+		streams = make(map[network.Stream]*Server)
+		reset   int
+	)
+
+	for i := 0; i < 2*numStreams; i++ {
+		client, proto := clients[i%len(clients)], closeProto
+		if i%len(clients) == 1 {
+			proto = holdProto
+		}
+
+		stream, err := client.host.NewStream(context.Background(), server.host.ID(), protocol.ID(proto))
+		require.NoError(t, err)
+
+		t.Cleanup(func() {
+			_ = stream.Reset()
+		})
+
+		// Streams are negotiated lazily, so make sure the server handles them in order
+		_, err = stream.Write([]byte(fmt.Sprintf("stream %d", i)))
+		require.NoError(t, err)
+
+		require.Eventually(t, func() bool {
+			return server.OpenStreamCount() >= baseline+len(streams)+1 || isStreamReset(stream)
+		}, time.Second, 10*time.Millisecond)
+
+		if isStreamReset(stream) {
+			reset++
+
+			continue
+		}
+
+		streams[stream] = client
+	}
+
+	// The streams above the cap are reset
+	assert.Equal(t, 2*numStreams-(maxStreams-baseline), reset)
+	assert.Equal(t, maxStreams, server.OpenStreamCount())
+
+	// The outbound streams are subject to the same cap
+	_, err := server.NewStream(closeProto, clients[0].host.ID())
+	assert.ErrorIs(t, err, ErrTooManyStreams)
+	assert.Equal(t, maxStreams, server.OpenStreamCount())
+
+	// The streams closed by the handler are released
+	for stream, client := range streams {
+		if client == clients[0] {
+			require.NoError(t, stream.Close())
+			delete(streams, stream)
+		}
+	}
+
+	require.Eventually(t, func() bool {
+		return server.OpenStreamCount() == baseline+len(streams)
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// The streams held by the handler are released once the connection closes
+	server.DisconnectFromPeer(clients[1].host.ID(), "Bye")
+
+	require.Eventually(t, func() bool {
+		return server.OpenStreamCount() == baseline/len(clients)
+	}, 5*time.Second, 10*time.Millisecond)
+
+	stream, err := server.NewStream(closeProto, clients[0].host.ID())
+	require.NoError(t, err)
+
+	assert.Equal(t, baseline/len(clients)+1, server.OpenStreamCount())
+	require.NoError(t, stream.Close())
+	assert.Equal(t, baseline/len(clients), server.OpenStreamCount())
+}
+
+func TestMaxOutboundStreamsPerPeer(t *testing.T) {
+	const (
+		holdProto = "/hold/0.1"
+
+		numStreams = 4
+	)
+
+	servers, createErr := createServers(3, map[int]*CreateServerParams{
+		0: {ConfigCallback: func(c *Config) {
+			c.NoDiscover = true
+			c.DisablePubSub = true
+		}},
+		1: {ConfigCallback: func(c *Config) {
+			c.NoDiscover = true
+			c.DisablePubSub = true
+		}},
+		2: {ConfigCallback: func(c *Config) {
+			c.NoDiscover = true
+			c.DisablePubSub = true
+		}},
+	})
+	require.NoError(t, createErr)
+
+	t.Cleanup(func() {
+		closeTestServers(t, servers)
+	})
+
+	server, busy, other := servers[0], servers[1], servers[2]
+
+	for _, remote := range []*Server{busy, other} {
+		remote.RegisterProtocol(holdProto, holdProtocol{})
+		require.NoError(t, JoinAndWait(server, remote, DefaultBufferTimeout, DefaultJoinTimeout))
+	}
+
+	// The handshake streams are accounted as well
+	baseline := server.OutboundStreamCount(busy.host.ID())
+	server.config.MaxOutboundStreamsPerPeer = baseline + numStreams
+
+	streams := make([]network.Stream, 0, numStreams)
+
+	for i := 0; i < numStreams; i++ {
+		stream, err := server.NewStream(holdProto, busy.host.ID())
+		require.NoError(t, err)
+
+		t.Cleanup(func() {
+			_ = stream.Reset()
+		})
+
+		streams = append(streams, stream)
+	}
+
+	assert.Equal(t, baseline+numStreams, server.OutboundStreamCount(busy.host.ID()))
+
+	// The excess streams to the peer are rejected, without opening them
+	openStreams := server.OpenStreamCount()
+
+	for i := 0; i < 2; i++ {
+		_, err := server.NewStream(holdProto, busy.host.ID())
+		assert.ErrorIs(t, err, ErrTooManyPeerStreams)
+	}
+
+	assert.Equal(t, openStreams, server.OpenStreamCount())
+
+	// Other peers are not affected
+	otherStream, err := server.NewStream(holdProto, other.host.ID())
+	require.NoError(t, err)
+	require.NoError(t, otherStream.Close())
+
+	// A closed stream frees a slot
+	require.NoError(t, streams[0].Close())
+
+	stream, err := server.NewStream(holdProto, busy.host.ID())
+	require.NoError(t, err)
+	require.NoError(t, stream.Reset())
+
+	// The streams are released once the connection closes
+	server.DisconnectFromPeer(busy.host.ID(), "Bye")
+
+	require.Eventually(t, func() bool {
+		return server.OutboundStreamCount(busy.host.ID()) == 0
+	}, 5*time.Second, 10*time.Millisecond)
+}
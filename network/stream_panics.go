@@ -0,0 +1,37 @@
+package network
+
+import (
+	"runtime/debug"
+
+	"github.com/armon/go-metrics"
+	"github.com/libp2p/go-libp2p/core/network"
+)
+
+// recoverStreamPanic recovers from a panic of the protocol handler serving the stream,
+// so a single faulty handler doesn't crash the node. The stream is reset, and the peer
+// is quarantined if configured, as the panic is likely triggered by its input.
+// Has to be deferred directly by the stream handler
+func (s *Server) recoverStreamPanic(id string, stream network.Stream) {
+	r := recover()
+	if r == nil {
+		return
+	}
+
+	peerID := stream.Conn().RemotePeer()
+
+	s.logger.Error(
+		"Protocol handler panicked, resetting the stream",
+		"protocol", id,
+		"peer", peerID,
+		"panic", r,
+		"stack", string(debug.Stack()),
+	)
+
+	metrics.IncrCounter([]string{networkMetrics, "stream_handler_panics"}, 1)
+
+	_ = stream.Reset()
+
+	if s.config.QuarantineOnHandlerPanic {
+		s.QuarantinePeer(peerID, "protocol handler panic")
+	}
+}
@@ -0,0 +1,101 @@
+package network
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/libp2p/go-libp2p/core/network"
+	"github.com/libp2p/go-libp2p/core/protocol"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	rawGrpc "google.golang.org/grpc"
+)
+
+// panicProtocol is a protocol which handler panics on every stream
+type panicProtocol struct{}
+
+func (panicProtocol) Client(network.Stream) (*rawGrpc.ClientConn, error) {
+	return nil, nil
+}
+
+func (panicProtocol) Handler() func(network.Stream) {
+	return func(network.Stream) {
+		panic("faulty handler")
+	}
+}
+
+func TestStreamHandlerPanic(t *testing.T) {
+	const (
+		panicProto = "/panic/0.1"
+		holdProto  = "/hold/0.1"
+	)
+
+	testTable := []struct {
+		name       string
+		quarantine bool
+	}{
+		{"panic is recovered", false},
+		{"panic is recovered and the peer quarantined", true},
+	}
+
+	for _, testCase := range testTable {
+		testCase := testCase
+
+		t.Run(testCase.name, func(t *testing.T) {
+			servers, createErr := createServers(2, map[int]*CreateServerParams{
+				0: {ConfigCallback: func(c *Config) {
+					c.NoDiscover = true
+					c.QuarantineOnHandlerPanic = testCase.quarantine
+				}},
+				1: {ConfigCallback: func(c *Config) { c.NoDiscover = true }},
+			})
+			require.NoError(t, createErr)
+
+			t.Cleanup(func() {
+				closeTestServers(t, servers)
+			})
+
+			server, client := servers[0], servers[1]
+
+			server.RegisterProtocol(panicProto, panicProtocol{})
+			server.RegisterProtocol(holdProto, holdProtocol{})
+
+			require.NoError(t, JoinAndWait(client, server, DefaultBufferTimeout, DefaultJoinTimeout))
+
+			openStream := func(proto string) network.Stream {
+				stream, err := client.host.NewStream(context.Background(), server.host.ID(), protocol.ID(proto))
+				require.NoError(t, err)
+
+				t.Cleanup(func() {
+					_ = stream.Reset()
+				})
+
+				// Streams are negotiated lazily, so the handler is invoked on the first write
+				_, err = stream.Write([]byte("hello"))
+				require.NoError(t, err)
+
+				return stream
+			}
+
+			// The stream of the panicking handler is reset
+			panicStream := openStream(panicProto)
+
+			require.Eventually(t, func() bool {
+				return isStreamReset(panicStream)
+			}, 5*time.Second, 50*time.Millisecond)
+
+			assert.Equal(t, testCase.quarantine, server.IsQuarantined(client.host.ID()))
+
+			if testCase.quarantine {
+				return
+			}
+
+			// The node is alive, and keeps serving the other streams
+			holdStream := openStream(holdProto)
+
+			assert.False(t, isStreamReset(holdStream))
+			assert.True(t, server.IsConnected(client.host.ID()))
+		})
+	}
+}
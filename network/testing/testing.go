@@ -30,6 +30,9 @@ type MockNetworkingServer struct {
 	emitEventFn              emitEventDelegate
 	isTemporaryDialFn        isTemporaryDialDelegate
 	hasFreeConnectionSlotFn  hasFreeConnectionSlotDelegate
+	isConnLimitExemptFn      isConnLimitExemptDelegate
+	makeRoomForPeerFn        makeRoomForPeerDelegate
+	quarantinePeerFn         quarantinePeerDelegate
 
 	// Discovery Hooks
 	newDiscoveryClientFn       newDiscoveryClientDelegate
@@ -74,6 +77,9 @@ type updatePendingConnCountDelegate func(int64, network.Direction)
 type emitEventDelegate func(*event.PeerEvent)
 type isTemporaryDialDelegate func(peer.ID) bool
 type hasFreeConnectionSlotDelegate func(network.Direction) bool
+type isConnLimitExemptDelegate func(peer.ID, network.Direction) bool
+type makeRoomForPeerDelegate func(peer.ID, network.Direction) bool
+type quarantinePeerDelegate func(peer.ID, string)
 
 // Required for Discovery
 type getRandomBootnodeDelegate func() *peer.AddrInfo
@@ -174,6 +180,40 @@ func (m *MockNetworkingServer) HookHasFreeConnectionSlot(fn hasFreeConnectionSlo
 	m.hasFreeConnectionSlotFn = fn
 }
 
+func (m *MockNetworkingServer) IsConnLimitExempt(peerID peer.ID, direction network.Direction) bool {
+	if m.isConnLimitExemptFn != nil {
+		return m.isConnLimitExemptFn(peerID, direction)
+	}
+
+	return false
+}
+
+func (m *MockNetworkingServer) HookIsConnLimitExempt(fn isConnLimitExemptDelegate) {
+	m.isConnLimitExemptFn = fn
+}
+
+func (m *MockNetworkingServer) MakeRoomForPeer(peerID peer.ID, direction network.Direction) bool {
+	if m.makeRoomForPeerFn != nil {
+		return m.makeRoomForPeerFn(peerID, direction)
+	}
+
+	return false
+}
+
+func (m *MockNetworkingServer) HookMakeRoomForPeer(fn makeRoomForPeerDelegate) {
+	m.makeRoomForPeerFn = fn
+}
+
+func (m *MockNetworkingServer) QuarantinePeer(peerID peer.ID, reason string) {
+	if m.quarantinePeerFn != nil {
+		m.quarantinePeerFn(peerID, reason)
+	}
+}
+
+func (m *MockNetworkingServer) HookQuarantinePeer(fn quarantinePeerDelegate) {
+	m.quarantinePeerFn = fn
+}
+
 func (m *MockNetworkingServer) GetRandomBootnode() *peer.AddrInfo {
 	if m.getRandomBootnodeFn != nil {
 		return m.getRandomBootnodeFn()
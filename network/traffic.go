@@ -0,0 +1,36 @@
+package network
+
+import (
+	libp2pMetrics "github.com/libp2p/go-libp2p/core/metrics"
+)
+
+// recordTrafficBaseline snapshots the traffic totals on start,
+// so the traffic made before (e.g. while binding the host) is not accounted
+func (s *Server) recordTrafficBaseline() {
+	baseline := s.bandwidthCounter.GetBandwidthTotals()
+
+	s.trafficBaseline.Store(&baseline)
+}
+
+// TotalTraffic returns the total number of bytes sent and received by the node since it started,
+// across all the peers and protocols. The totals are updated by the bandwidth counter
+// periodically (about every second), so the latest traffic may not be reflected yet [Thread safe]
+func (s *Server) TotalTraffic() (sent, received uint64) {
+	totals := s.bandwidthCounter.GetBandwidthTotals()
+
+	baseline := s.trafficBaseline.Load()
+	if baseline == nil {
+		baseline = &libp2pMetrics.Stats{}
+	}
+
+	return trafficSince(totals.TotalOut, baseline.TotalOut), trafficSince(totals.TotalIn, baseline.TotalIn)
+}
+
+// trafficSince returns the traffic made since the baseline total
+func trafficSince(total, baseline int64) uint64 {
+	if total < baseline {
+		return 0
+	}
+
+	return uint64(total - baseline)
+}
@@ -0,0 +1,80 @@
+package network
+
+import (
+	"bytes"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/libp2p/go-libp2p/core/network"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTotalTraffic(t *testing.T) {
+	const (
+		sinkProto = "/sink-test/0.1"
+		volume    = 1 << 20
+		// The protocol negotiation, identify and other background traffic
+		overhead = 64 * 1024
+	)
+
+	servers, createErr := createServers(2, map[int]*CreateServerParams{
+		0: {ConfigCallback: func(c *Config) { c.NoDiscover = true }},
+		1: {ConfigCallback: func(c *Config) { c.NoDiscover = true }},
+	})
+	require.NoError(t, createErr)
+
+	t.Cleanup(func() {
+		closeTestServers(t, servers)
+	})
+
+	receiver, sender := servers[0], servers[1]
+
+	received := make(chan int64, 1)
+
+	receiver.wrapStream(sinkProto, func(stream network.Stream) {
+		defer stream.Close()
+
+		n, _ := io.Copy(io.Discard, stream)
+		received <- n
+	})
+
+	require.NoError(t, JoinAndWait(sender, receiver, DefaultBufferTimeout, DefaultJoinTimeout))
+
+	// Let the handshake traffic settle in the totals
+	time.Sleep(2 * time.Second)
+
+	sentBefore, _ := sender.TotalTraffic()
+	_, receivedBefore := receiver.TotalTraffic()
+
+	stream, err := sender.NewStream(sinkProto, receiver.host.ID())
+	require.NoError(t, err)
+
+	_, err = io.Copy(stream, bytes.NewReader(make([]byte, volume)))
+	require.NoError(t, err)
+	require.NoError(t, stream.CloseWrite())
+
+	select {
+	case n := <-received:
+		require.Equal(t, int64(volume), n)
+	case <-time.After(10 * time.Second):
+		t.Fatal("the volume was not received")
+	}
+
+	_ = stream.Close()
+
+	// The totals are updated periodically
+	require.Eventually(t, func() bool {
+		sent, _ := sender.TotalTraffic()
+		_, received := receiver.TotalTraffic()
+
+		return sent-sentBefore >= volume && received-receivedBefore >= volume
+	}, 5*time.Second, 100*time.Millisecond)
+
+	sent, _ := sender.TotalTraffic()
+	_, receivedTotal := receiver.TotalTraffic()
+
+	assert.LessOrEqual(t, sent-sentBefore, uint64(volume+overhead))
+	assert.LessOrEqual(t, receivedTotal-receivedBefore, uint64(volume+overhead))
+}
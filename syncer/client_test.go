@@ -173,6 +173,12 @@ func TestStatusPubSub(t *testing.T) {
 	_, peerSrv := createTestSyncerService(t, &mockBlockchain{})
 	peerID := peerSrv.AddrInfo().ID
 
+	// the servers redial their lost peers, so they can't outlive the test
+	t.Cleanup(func() {
+		assert.NoError(t, clientSrv.Close())
+		assert.NoError(t, peerSrv.Close())
+	})
+
 	go client.startPeerEventProcess()
 
 	// run goroutine to collect events